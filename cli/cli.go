@@ -0,0 +1,473 @@
+// Package cli is a command-line interface of microgen.
+// It may be called from wrapper of microgen main, which registers additional tags with generator.RegisterTag.
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/recolabs/microgen/generator"
+	"github.com/recolabs/microgen/generator/protofile"
+	mstrings "github.com/recolabs/microgen/generator/strings"
+	"github.com/recolabs/microgen/generator/template"
+	"github.com/recolabs/microgen/generator/write_strategy"
+	lg "github.com/recolabs/microgen/logger"
+	"github.com/vetcher/go-astra"
+	"github.com/vetcher/go-astra/types"
+	"golang.org/x/term"
+)
+
+const (
+	Version = generator.Version
+)
+
+var (
+	flagFileName     = flag.String("file", "", "Path to input file or package directory with interfaces.")
+	flagPbGoFileName = flag.String("pb-go", "", "Path to XXX_service.pb.go file with protobuf implementation of interface structs.")
+	flagOutputDir    = flag.String("out", "", "Output directory.")
+	flagPackageName  = flag.String("package", "", "Package name for imports")
+	flagHelp         = flag.Bool("help", false, "Show help.")
+	flagVerbose      = flag.Int("v", 1, "Sets microgen verbose level.")
+	flagDebug        = flag.Bool("debug", false, "Print all microgen messages. Equivalent to -v=100.")
+	flagGenProtofile = flag.String(".proto", "", "Package field in protobuf file. If not empty, service.proto file will be generated.")
+	flagProtoFile    = flag.String("proto-file", "", "Path to .proto file, which interfaces are validated against.")
+	flagGenMain      = flag.Bool(generator.MainTag, false, "Generate main.go file.")
+	flagCheck        = flag.Bool("check", false, "Do not write files, but print diffs and exit with non-zero code, when generated files are out of date.")
+	flagDryRun       = flag.Bool("dry-run", false, "Do not write files, but print which files would be created, overwritten or appended, with diffs.")
+	flagConfig       = flag.String("config", "", "Path to config file. By default "+defaultConfigFileName+" from current directory is used, when it exists.")
+	flagListTags     = flag.Bool("list-tags", false, "Print registered tags with tags, which they turn on, and conflicts.")
+	flagTemplates    = flag.String("templates", "", "Directory with user templates (*"+template.UserTemplateExt+" files).")
+)
+
+// errUsage is returned, when user did not provide required parameter in interactive mode.
+var errUsage = errors.New("required parameter is empty")
+
+func readFromInput(prefix string, delim byte) (string, error) {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print(prefix)
+	input, err := reader.ReadString(delim)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(input, "\n \t\r\f\v"), nil
+}
+
+func findPackageNameFromGoModFile(filePath string) (string, error) {
+	buffer, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	r, err := regexp.Compile(`module\s+(.*)`)
+	if err != nil {
+		return "", err
+	}
+
+	result := r.FindStringSubmatch(string(buffer))
+	if len(result) > 0 {
+		return result[1], nil
+	}
+
+	return "", errors.New("could not find package name")
+}
+
+const (
+	goModFileName = "go.mod"
+)
+
+// Main parses command-line flags and runs generation. It exits the process on errors.
+func Main() {
+	if len(os.Args) > 1 && os.Args[1] == fromProtoCommand {
+		if err := fromProto(os.Args[2:]); err != nil {
+			lg.Logger.Logln(0, "fatal:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	flag.Parse()
+	lg.Logger.Level = *flagVerbose
+	if *flagDebug {
+		lg.Logger.Level = 100
+	}
+	lg.Logger.Logln(1, "@microgen", Version)
+	if *flagHelp {
+		flag.Usage()
+		os.Exit(0)
+	}
+	if *flagListTags {
+		listTags()
+		os.Exit(0)
+	}
+
+	cfg, err := loadConfig(*flagConfig)
+	if err != nil {
+		lg.Logger.Logln(0, "fatal:", err)
+		os.Exit(1)
+	}
+	// Prompts are shown only to user in terminal, when there is no config.
+	interactive := cfg == nil && isTerminal(os.Stdin)
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	if *flagTemplates != "" {
+		cfg.Templates = *flagTemplates
+	}
+	var userTemplates []*template.UserTemplate
+	if cfg.Templates != "" {
+		userTemplates, err = template.LoadUserTemplates(cfg.Templates)
+		if err != nil {
+			lg.Logger.Logln(0, "fatal:", err)
+			os.Exit(1)
+		}
+		lg.Logger.Logln(2, "User templates:", len(userTemplates))
+	}
+
+	// All units are rendered to memory first and written only when every unit succeeded.
+	// In check and dry-run modes rendered files are compared with files on disk instead.
+	files := write_strategy.NewFiles()
+	template.SetPendingFiles(files)
+	var errs []error
+	// Units are grouped by output directories, to write manifest of generated files to every directory.
+	units := make(map[string][]*generator.GenerationUnit)
+	for _, target := range cfg.targets(flag.CommandLine) {
+		err := completeTarget(&target, interactive)
+		if err == errUsage {
+			flag.Usage()
+			os.Exit(0)
+		}
+		if err != nil {
+			lg.Logger.Logln(0, "fatal:", err)
+			os.Exit(1)
+		}
+		errs = append(errs, generate(target, cfg, userTemplates, files, units)...)
+	}
+	if len(errs) > 0 {
+		for _, err := range errs {
+			lg.Logger.Logln(0, "fatal:", err)
+		}
+		lg.Logger.Logln(0, "nothing was written:", len(errs), "errors")
+		os.Exit(1)
+	}
+	if *flagCheck || *flagDryRun {
+		stale, err := report(files, units)
+		if err != nil {
+			lg.Logger.Logln(0, "fatal:", err)
+			os.Exit(1)
+		}
+		if *flagCheck && stale > 0 {
+			lg.Logger.Logln(0, stale, "generated files are out of date")
+			os.Exit(1)
+		}
+		if *flagDryRun {
+			lg.Logger.Logln(1, stale, "files would be changed")
+		} else {
+			lg.Logger.Logln(1, "all generated files are up to date")
+		}
+		return
+	}
+	if err := files.Commit(); err != nil {
+		lg.Logger.Logln(0, "fatal:", err)
+		os.Exit(1)
+	}
+	for dir, dirUnits := range units {
+		if err := generator.UpdateManifest(dir, dirUnits); err != nil {
+			lg.Logger.Logln(0, "fatal:", err)
+			os.Exit(1)
+		}
+	}
+	lg.Logger.Logln(1, "all files successfully generated")
+}
+
+// report prints diffs of files, which differ from files on disk, and orphaned files, which would be removed.
+// Returns number of files, which would be changed.
+func report(files *write_strategy.Files, units map[string][]*generator.GenerationUnit) (int, error) {
+	changes, err := generator.Changes(files)
+	if err != nil {
+		return 0, err
+	}
+	stale := 0
+	for _, c := range changes {
+		if c.Diff == "" {
+			lg.Logger.Logln(2, "up to date", c.Name)
+			continue
+		}
+		stale++
+		if !*flagDryRun {
+			fmt.Print(c.Diff)
+			continue
+		}
+		diff := c.Diff
+		if isTerminal(os.Stdout) {
+			diff = generator.ColorizeDiff(diff)
+		}
+		fmt.Println(c.Action, c.Name)
+		fmt.Print(diff)
+	}
+	for dir, dirUnits := range units {
+		manifest, err := generator.LoadManifest(dir)
+		if err != nil {
+			return 0, err
+		}
+		orphans, err := manifest.Orphans(dir, dirUnits)
+		if err != nil {
+			return 0, err
+		}
+		for _, o := range orphans {
+			if o.Edited {
+				lg.Logger.Logln(0, "Warning:", o.Name, "is not generated anymore, but it may contain user changes, so it would not be removed")
+				continue
+			}
+			stale++
+			fmt.Println("remove", o.Path)
+		}
+	}
+	return stale, nil
+}
+
+// listTags prints dependency graph of registered tags.
+func listTags() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TAG\tTURNS ON\tCONFLICTS\tDESCRIPTION")
+	for _, line := range generator.TagGraph() {
+		fmt.Fprintln(w, line)
+	}
+	w.Flush()
+}
+
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// completeTarget fills parameters, which were provided neither by flags nor by config.
+// In interactive mode user is asked for them, otherwise defaults are used or error is returned.
+func completeTarget(t *Target, interactive bool) error {
+	if t.File == "" {
+		if !interactive {
+			return errors.New("file with interfaces is not provided: use -file flag or " + defaultConfigFileName)
+		}
+		val, err := readFromInput("file path with interfaces: ", '\n')
+		if err != nil {
+			return err
+		}
+		if val == "" {
+			return errUsage
+		}
+		t.File = val
+	}
+	if t.Out == "" {
+		t.Out = filepath.Dir(t.File)
+		if isDir(t.File) {
+			t.Out = t.File
+		}
+		if interactive {
+			val, err := readFromInput(fmt.Sprintf("output directory [%v]: ", t.Out), '\n')
+			if err != nil {
+				return err
+			}
+			if val != "" {
+				t.Out = val
+			}
+		}
+	}
+	if t.Package == "" {
+		t.Package, _ = findPackageNameFromGoModFile(filepath.Join(t.Out, goModFileName))
+		if interactive {
+			val, err := readFromInput(fmt.Sprintf("pacakge name for imports [%v]: ", t.Package), '\n')
+			if err != nil {
+				return err
+			}
+			if val != "" {
+				t.Package = val
+			}
+		}
+		if t.Package == "" {
+			if interactive {
+				return errUsage
+			}
+			return errors.New("package name for imports is not provided: use -package flag or " + defaultConfigFileName)
+		}
+	}
+	if t.PbGo == "" && interactive {
+		val, err := readFromInput("path to XXX_service.pb.go (leave empty for no pb validation): ", '\n')
+		if err != nil {
+			return err
+		}
+		t.PbGo = val
+	}
+	return nil
+}
+
+// generate renders all services of target to files and adds generation units to units of output directory.
+// It does not stop on failed unit and returns errors of all of them.
+func generate(t Target, cfg *Config, userTemplates []*template.UserTemplate, files *write_strategy.Files, units map[string][]*generator.GenerationUnit) (errs []error) {
+	lg.Logger.Logln(4, "Source:", t.File)
+	services, err := findServices(t.File)
+	if err != nil {
+		return []error{err}
+	}
+	var pbGoFile *types.File = nil
+	if t.PbGo != "" {
+		pbGoFile, err = astra.ParseFile(t.PbGo)
+		if err != nil {
+			return []error{err}
+		}
+	}
+
+	if len(services) == 0 {
+		return []error{fmt.Errorf("%s: could not find interface with @microgen tag", t.File)}
+	}
+	var protoFile *protofile.File
+	if t.ProtoFile != "" {
+		protoFile, err = protofile.ParseFile(t.ProtoFile)
+		if err != nil {
+			return []error{err}
+		}
+	}
+
+	for _, s := range services {
+		if err := generator.ResolveEmbeddedInterfaces(s.iface, s.file); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := generator.ValidateInterface(s.iface, pbGoFile); err != nil {
+			errs = append(errs, fmt.Errorf("validation: %s: %v", s.iface.Name, err))
+		}
+		if protoFile != nil {
+			if err := generator.ValidateInterfaceProto(s.iface, s.file, protoFile); err != nil {
+				errs = append(errs, fmt.Errorf("validation: %s: %v", s.iface.Name, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	absOutputDir, err := filepath.Abs(t.Out)
+	if err != nil {
+		return []error{err}
+	}
+	genMain := t.Main != nil && *t.Main
+	for _, s := range services {
+		// Every service is generated into the same tree, so names are prefixed with interface name,
+		// when there is more than one service.
+		namespace := ""
+		if len(services) > 1 {
+			namespace = s.iface.Name
+			lg.Logger.Logln(1, "generate", s.iface.Name, "from", s.file)
+		}
+		// Previous service could add files to packages, that are parsed by templates.
+		template.ResetParsedCache()
+
+		ctx, err := prepareContext(t.Package, s.iface, cfg.tags(s.iface.Name))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		ctx = template.WithUserTemplates(ctx, userTemplates)
+		serviceUnits, err := generator.ListTemplatesForGen(ctx, s.iface, namespace, absOutputDir, s.file, t.Package, t.Proto, genMain, pbGoFile)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", s.iface.Name, err))
+			continue
+		}
+		units[absOutputDir] = append(units[absOutputDir], serviceUnits...)
+		for _, unit := range serviceUnits {
+			err := unit.GenerateInMemory(ctx, files)
+			if err != nil && err != generator.EmptyStrategyError {
+				path, _ := unit.File()
+				errs = append(errs, fmt.Errorf("%s: %v", path, err))
+			}
+		}
+	}
+	return errs
+}
+
+func listInterfaces(ii []types.Interface) string {
+	var s string
+	for _, i := range ii {
+		s = s + fmt.Sprintf("\t%s(%d methods, %d embedded interfaces)\n", i.Name, len(i.Methods), len(i.Interfaces))
+	}
+	return s
+}
+
+// prepareContext puts generation tags to context. When tags are nil, they are taken from interface docs.
+func prepareContext(packageName string, iface *types.Interface, tags []string) (context.Context, error) {
+	ctx := context.Background()
+	ctx = template.WithSourcePackageImport(ctx, packageName)
+
+	set := template.TagsSet{}
+	genTags := tags
+	if genTags == nil {
+		genTags = mstrings.FetchTags(iface.Docs, generator.TagMark+generator.MicrogenMainTag)
+	}
+	for _, tag := range genTags {
+		set.Add(tag)
+	}
+	ctx = template.WithTags(ctx, set)
+	return ctx, nil
+}
+
+// service is an interface with @microgen tag and path to file, where it was declared.
+type service struct {
+	iface *types.Interface
+	file  string
+}
+
+// findServices returns all interfaces with @microgen tag from file or from all go files of package directory.
+func findServices(path string) ([]service, error) {
+	files := []string{path}
+	if isDir(path) {
+		var err error
+		files, err = generator.SourceFiles(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var services []service
+	for _, filename := range files {
+		file, err := astra.ParseFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", filename, err)
+		}
+		ifaces := findInterfaces(file)
+		if len(ifaces) == 0 {
+			lg.Logger.Logln(4, "Interfaces of", filename+":")
+			lg.Logger.Logln(4, listInterfaces(file.Interfaces))
+		}
+		for _, i := range ifaces {
+			services = append(services, service{iface: i, file: filename})
+		}
+	}
+	return services, nil
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func findInterfaces(file *types.File) []*types.Interface {
+	var ifaces []*types.Interface
+	for i := range file.Interfaces {
+		if docsContainMicrogenTag(file.Interfaces[i].Docs) {
+			ifaces = append(ifaces, &file.Interfaces[i])
+		}
+	}
+	return ifaces
+}
+
+func docsContainMicrogenTag(strs []string) bool {
+	for _, str := range strs {
+		if strings.HasPrefix(str, generator.TagMark+generator.MicrogenMainTag) {
+			return true
+		}
+	}
+	return false
+}
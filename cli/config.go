@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	defaultConfigFileName = "microgen.yaml"
+)
+
+// Config is a content of microgen.yaml.
+//
+//	package: github.com/recolabs/reco/auth-service
+//	pb-go: pb/auth.pb.go
+//	proto-file: api/auth.proto
+//	main: true
+//	templates: templates
+//	sources:
+//	  - file: service.go
+//	    out: .
+//	services:
+//	  AuthService:
+//	    tags: [middleware, logging, grpc]
+//
+// Fields of the top level are defaults for every source.
+type Config struct {
+	Target   `yaml:",inline"`
+	Sources  []Target                 `yaml:"sources"`
+	Services map[string]ServiceConfig `yaml:"services"`
+	// Templates is a directory with user templates.
+	Templates string `yaml:"templates"`
+}
+
+// Target is a source file or package directory with interfaces and parameters of its generation.
+type Target struct {
+	File    string `yaml:"file"`
+	Out     string `yaml:"out"`
+	Package string `yaml:"package"`
+	PbGo    string `yaml:"pb-go"`
+	Proto   string `yaml:"proto"`
+	// ProtoFile is a protobuf file, which interfaces are validated against.
+	ProtoFile string `yaml:"proto-file"`
+	Main      *bool  `yaml:"main"`
+}
+
+// ServiceConfig overrides generation parameters of interface with the same name.
+type ServiceConfig struct {
+	// Tags replace tags from `// @microgen` comment of interface.
+	Tags []string `yaml:"tags"`
+}
+
+// loadConfig reads config from path. When path is empty, microgen.yaml from current directory is read, if it exists.
+// Relative paths of config are resolved from its directory.
+func loadConfig(path string) (*Config, error) {
+	if path == "" {
+		if _, err := os.Stat(defaultConfigFileName); err != nil {
+			return nil, nil
+		}
+		path = defaultConfigFileName
+	}
+	buffer, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := yaml.UnmarshalStrict(buffer, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	dir := filepath.Dir(path)
+	cfg.Target.resolvePaths(dir)
+	if cfg.Templates != "" && !filepath.IsAbs(cfg.Templates) {
+		cfg.Templates = filepath.Join(dir, cfg.Templates)
+	}
+	for i := range cfg.Sources {
+		cfg.Sources[i].resolvePaths(dir)
+	}
+	return &cfg, nil
+}
+
+func (t *Target) resolvePaths(dir string) {
+	for _, p := range []*string{&t.File, &t.Out, &t.PbGo, &t.ProtoFile} {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(dir, *p)
+		}
+	}
+}
+
+// Fills empty fields of target from defaults.
+func (t *Target) inherit(defaults Target) {
+	if t.File == "" {
+		t.File = defaults.File
+	}
+	if t.Out == "" {
+		t.Out = defaults.Out
+	}
+	if t.Package == "" {
+		t.Package = defaults.Package
+	}
+	if t.PbGo == "" {
+		t.PbGo = defaults.PbGo
+	}
+	if t.Proto == "" {
+		t.Proto = defaults.Proto
+	}
+	if t.ProtoFile == "" {
+		t.ProtoFile = defaults.ProtoFile
+	}
+	if t.Main == nil {
+		t.Main = defaults.Main
+	}
+}
+
+// targets merges config with command-line flags, which were set explicitly.
+// When -file is provided, it replaces sources of config.
+func (cfg *Config) targets(flags *flag.FlagSet) []Target {
+	defaults := cfg.Target
+	sources := cfg.Sources
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "file" {
+			sources = nil
+		}
+	})
+	if len(sources) == 0 {
+		sources = []Target{{}}
+	}
+	targets := make([]Target, len(sources))
+	for i := range sources {
+		targets[i] = sources[i]
+		targets[i].inherit(defaults)
+		flags.Visit(func(f *flag.Flag) {
+			targets[i].setFlag(f)
+		})
+	}
+	return targets
+}
+
+func (t *Target) setFlag(f *flag.Flag) {
+	value := f.Value.String()
+	switch f.Name {
+	case "file":
+		t.File = value
+	case "out":
+		t.Out = value
+	case "package":
+		t.Package = value
+	case "pb-go":
+		t.PbGo = value
+	case ".proto":
+		t.Proto = value
+	case "proto-file":
+		t.ProtoFile = value
+	case "main":
+		genMain := value == "true"
+		t.Main = &genMain
+	}
+}
+
+// tags returns tags from config for interface or nil, when config does not override them.
+func (cfg *Config) tags(ifaceName string) []string {
+	if s, ok := cfg.Services[ifaceName]; ok {
+		return s.Tags
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfig = `
+package: example.com/svc
+pb-go: pb/svc.pb.go
+proto-file: api/svc.proto
+main: true
+sources:
+  - file: users.go
+  - file: orders
+    out: gen
+    main: false
+services:
+  UserService:
+    tags: [middleware, logging]
+`
+
+func newTestFlagSet(args ...string) *flag.FlagSet {
+	flags := flag.NewFlagSet("microgen", flag.ContinueOnError)
+	flags.String("file", "", "")
+	flags.String("out", "", "")
+	flags.String("package", "", "")
+	flags.String("pb-go", "", "")
+	flags.String(".proto", "", "")
+	flags.String("proto-file", "", "")
+	flags.Bool("main", false, "")
+	if err := flags.Parse(args); err != nil {
+		panic(err)
+	}
+	return flags
+}
+
+func loadTestConfig(t *testing.T) (*Config, string) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, defaultConfigFileName)
+	if err := ioutil.WriteFile(path, []byte(testConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg, dir
+}
+
+func TestConfigTargets(t *testing.T) {
+	cfg, dir := loadTestConfig(t)
+	targets := cfg.targets(newTestFlagSet())
+	if assert.Len(t, targets, 2) {
+		assert.Equal(t, filepath.Join(dir, "users.go"), targets[0].File)
+		assert.Equal(t, "", targets[0].Out)
+		assert.Equal(t, "example.com/svc", targets[0].Package)
+		assert.Equal(t, filepath.Join(dir, "pb/svc.pb.go"), targets[0].PbGo)
+		assert.Equal(t, filepath.Join(dir, "api/svc.proto"), targets[0].ProtoFile)
+		assert.True(t, *targets[0].Main)
+
+		assert.Equal(t, filepath.Join(dir, "orders"), targets[1].File)
+		assert.Equal(t, filepath.Join(dir, "gen"), targets[1].Out)
+		assert.False(t, *targets[1].Main)
+	}
+	assert.Equal(t, []string{"middleware", "logging"}, cfg.tags("UserService"))
+	assert.Nil(t, cfg.tags("OrderService"))
+}
+
+func TestConfigTargetsFlags(t *testing.T) {
+	cfg, dir := loadTestConfig(t)
+
+	targets := cfg.targets(newTestFlagSet("-package", "example.com/other", "-main=false"))
+	if assert.Len(t, targets, 2) {
+		for _, target := range targets {
+			assert.Equal(t, "example.com/other", target.Package)
+			assert.False(t, *target.Main)
+		}
+	}
+
+	targets = cfg.targets(newTestFlagSet("-file", "svc.go"))
+	if assert.Len(t, targets, 1) {
+		assert.Equal(t, "svc.go", targets[0].File)
+		assert.Equal(t, "example.com/svc", targets[0].Package)
+		assert.Equal(t, filepath.Join(dir, "pb/svc.pb.go"), targets[0].PbGo)
+	}
+}
+
+func TestCompleteTargetNonInteractive(t *testing.T) {
+	err := completeTarget(&Target{}, false)
+	assert.Error(t, err)
+
+	target := Target{File: "testdata/svc.go", Package: "example.com/svc"}
+	if assert.NoError(t, completeTarget(&target, false)) {
+		assert.Equal(t, "testdata", target.Out)
+		assert.Equal(t, "", target.PbGo)
+	}
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/recolabs/microgen/generator"
+	"github.com/recolabs/microgen/generator/protofile"
+	lg "github.com/recolabs/microgen/logger"
+)
+
+const fromProtoCommand = "from-proto"
+
+// fromProto generates go file with service interfaces from protobuf file.
+//
+//	microgen from-proto -out service/service.go api.proto
+//
+func fromProto(args []string) error {
+	fs := flag.NewFlagSet(fromProtoCommand, flag.ExitOnError)
+	out := fs.String("out", "service.go", "Output go file.")
+	pkg := fs.String("package", "", "Name of go package. By default it is the name of output directory.")
+	pb := fs.String("protobuf", "", "Import path of compiled protobuf package. By default it is taken from go_package option.")
+	tags := fs.String("tags", generator.GrpcTag, "Comma separated generation tags of interfaces.")
+	force := fs.Bool("force", false, "Overwrite existing output file.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: microgen %s [OPTIONS] file.proto\n", fromProtoCommand)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("one protobuf file should be provided")
+	}
+	file, err := protofile.ParseFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *pkg == "" {
+		*pkg, err = packageNameOfDir(filepath.Dir(*out))
+		if err != nil {
+			return err
+		}
+	}
+	var tagList []string
+	for _, tag := range strings.Split(*tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tagList = append(tagList, tag)
+		}
+	}
+	src, err := generator.GenerateFromProto(file, generator.FromProtoOptions{
+		PackageName:    *pkg,
+		ProtobufImport: *pb,
+		Tags:           tagList,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(*out); err == nil && !*force {
+		return fmt.Errorf("%s already exists, use -force to overwrite it", *out)
+	}
+	if err := os.MkdirAll(filepath.Dir(*out), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		return err
+	}
+	lg.Logger.Logln(1, *out, "is generated from", fs.Arg(0)+", run microgen -file", *out, "to generate the service")
+	return nil
+}
+
+// Name of go package is the name of directory, without characters, which are not allowed in identifiers.
+func packageNameOfDir(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	name := strings.Map(func(r rune) rune {
+		if r == '_' || 'a' <= r && r <= 'z' || '0' <= r && r <= '9' {
+			return r
+		}
+		return -1
+	}, strings.ToLower(filepath.Base(abs)))
+	if name == "" || '0' <= name[0] && name[0] <= '9' {
+		return "service", nil
+	}
+	return name, nil
+}
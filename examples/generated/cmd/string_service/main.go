@@ -13,13 +13,21 @@ import (
 	"syscall"
 
 	log "github.com/go-kit/kit/log"
-	opentracinggo "github.com/opentracing/opentracing-go"
+	promhttp "github.com/prometheus/client_golang/prometheus/promhttp"
 	generated "github.com/recolabs/microgen/examples/generated"
 	service "github.com/recolabs/microgen/examples/generated/service"
 	transport "github.com/recolabs/microgen/examples/generated/transport"
 	grpc "github.com/recolabs/microgen/examples/generated/transport/grpc"
 	http "github.com/recolabs/microgen/examples/generated/transport/http"
+	jsonrpc "github.com/recolabs/microgen/examples/generated/transport/jsonrpc"
 	protobuf "github.com/recolabs/microgen/examples/protobuf"
+	otel "go.opentelemetry.io/otel"
+	attribute "go.opentelemetry.io/otel/attribute"
+	otlptracegrpc "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	stdouttrace "go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	propagation "go.opentelemetry.io/otel/propagation"
+	resource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	errgroup "golang.org/x/sync/errgroup"
 	grpc1 "google.golang.org/grpc"
 )
@@ -30,18 +38,27 @@ func main() {
 	logger.Log("message", "Hello, I am alive")
 	defer logger.Log("message", "goodbye, good luck")
 
+	tracerProvider, err := InitTracer(context.Background(), "string_service")
+	if err != nil {
+		logger.Log("error", err)
+		return
+	}
+	defer tracerProvider.Shutdown(context.Background())
+
 	g, ctx := errgroup.WithContext(context.Background())
 	g.Go(func() error {
 		return InterruptHandler(ctx)
 	})
 
-	var svc generated.StringService                      // TODO: = service.NewStringService () // Create new service.
-	svc = service.LoggingMiddleware(logger)(svc)         // Setup service logging.
-	svc = service.ErrorLoggingMiddleware(logger)(svc)    // Setup error logging.
-	svc = service.RecoveringMiddleware(errorLogger)(svc) // Setup service recovering.
+	var svc generated.StringService                                     // TODO: = service.NewStringService () // Create new service.
+	svc = service.RateLimitingMiddleware()(svc)                         // Setup rate limiting of methods.
+	svc = service.LoggingMiddleware(logger)(svc)                        // Setup service logging.
+	svc = service.ErrorLoggingMiddleware(logger)(svc)                   // Setup error logging.
+	svc = service.PrometheusMetricsMiddleware("string_service")(svc)    // Setup service metrics.
+	svc = service.TracingMiddleware(otel.Tracer("string_service"))(svc) // Setup service tracing.
+	svc = service.RecoveringMiddleware(errorLogger)(svc)                // Setup service recovering.
 
 	endpoints := transport.Endpoints(svc)
-	endpoints = transport.TraceServerEndpoints(endpoints, opentracinggo.NoopTracer{}) // TODO: Add tracer
 
 	grpcAddr := ":8081" // TODO: use normal address
 	// Start grpc server.
@@ -55,6 +72,18 @@ func main() {
 		return ServeHTTP(ctx, &endpoints, httpAddr, log.With(logger, "transport", "HTTP"))
 	})
 
+	jsonrpcAddr := ":8082" // TODO: use normal address
+	// Start json-rpc server.
+	g.Go(func() error {
+		return ServeJSONRPC(ctx, &endpoints, jsonrpcAddr, log.With(logger, "transport", "JSONRPC"))
+	})
+
+	metricsAddr := ":9090" // TODO: use normal address
+	// Start metrics server.
+	g.Go(func() error {
+		return ServeMetrics(ctx, metricsAddr, log.With(logger, "transport", "metrics"))
+	})
+
 	if err := g.Wait(); err != nil {
 		logger.Log("error", err)
 	}
@@ -68,6 +97,28 @@ func InitLogger(writer io.Writer) log.Logger {
 	return logger
 }
 
+// InitTracer registers global tracer provider of OpenTelemetry and W3C trace context propagator.
+// Spans are exported to OTLP collector, when OTEL_EXPORTER_OTLP_ENDPOINT is set, and to stdout otherwise.
+func InitTracer(ctx context.Context, name string) (*sdktrace.TracerProvider, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" {
+		exporter, err = otlptracegrpc.New(ctx)
+	} else {
+		exporter, err = stdouttrace.New()
+	}
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", name))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider, nil
+}
+
 // InterruptHandler handles first SIGINT and SIGTERM and returns it as error.
 func InterruptHandler(ctx context.Context) error {
 	interruptHandler := make(chan os.Signal, 1)
@@ -87,10 +138,7 @@ func ServeGRPC(ctx context.Context, endpoints *transport.EndpointsSet, addr stri
 		return err
 	}
 	// Here you can add middlewares for grpc server.
-	server := grpc.NewGRPCServer(endpoints,
-		logger,
-		opentracinggo.NoopTracer{}, // TODO: Add tracer
-	)
+	server := grpc.NewGRPCServer(endpoints, otel.GetTextMapPropagator())
 	grpcServer := grpc1.NewServer()
 	protobuf.RegisterStringServiceServer(grpcServer, server)
 	logger.Log("listen on", addr)
@@ -109,10 +157,7 @@ func ServeGRPC(ctx context.Context, endpoints *transport.EndpointsSet, addr stri
 
 // ServeHTTP starts new HTTP server on address and sends first error to channel.
 func ServeHTTP(ctx context.Context, endpoints *transport.EndpointsSet, addr string, logger log.Logger) error {
-	handler := http.NewHTTPHandler(endpoints,
-		logger,
-		opentracinggo.NoopTracer{}, // TODO: Add tracer
-	)
+	handler := http.NewHTTPHandler(endpoints, otel.GetTextMapPropagator())
 	httpServer := &http1.Server{
 		Addr:    addr,
 		Handler: handler,
@@ -132,3 +177,50 @@ func ServeHTTP(ctx context.Context, endpoints *transport.EndpointsSet, addr stri
 		return httpServer.Shutdown(context.Background())
 	}
 }
+
+// ServeJSONRPC starts new JSON-RPC server on address and sends first error to channel.
+func ServeJSONRPC(ctx context.Context, endpoints *transport.EndpointsSet, addr string, logger log.Logger) error {
+	handler := jsonrpc.NewJSONRPCHandler(endpoints, otel.GetTextMapPropagator())
+	jsonrpcServer := &http1.Server{
+		Addr:    addr,
+		Handler: handler,
+	}
+	logger.Log("listen on", addr)
+	ch := make(chan error)
+	go func() {
+		ch <- jsonrpcServer.ListenAndServe()
+	}()
+	select {
+	case err := <-ch:
+		if err == http1.ErrServerClosed {
+			return nil
+		}
+		return fmt.Errorf("json-rpc server: serve: %v", err)
+	case <-ctx.Done():
+		return jsonrpcServer.Shutdown(context.Background())
+	}
+}
+
+// ServeMetrics starts new HTTP server, that exposes prometheus metrics on /metrics, and sends first error to channel.
+func ServeMetrics(ctx context.Context, addr string, logger log.Logger) error {
+	mux := http1.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	metricsServer := &http1.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+	logger.Log("listen on", addr)
+	ch := make(chan error)
+	go func() {
+		ch <- metricsServer.ListenAndServe()
+	}()
+	select {
+	case err := <-ch:
+		if err == http1.ErrServerClosed {
+			return nil
+		}
+		return fmt.Errorf("metrics server: serve: %v", err)
+	case <-ctx.Done():
+		return metricsServer.Shutdown(context.Background())
+	}
+}
@@ -2,17 +2,25 @@ package stringsvc
 
 import (
 	"context"
+	"errors"
 )
 
-// @microgen middleware, logging, grpc, http, recovering, error-logging, tracing, caching, metrics, service-discovery
+// ErrNotFound is returned, when there is nothing to count.
+var ErrNotFound = errors.New("not found")
+
+// @microgen middleware, logging, grpc, http, json-rpc, recovering, error-logging, opentelemetry, caching, metrics, ratelimit, service-discovery, circuit-breaker, retry
 // @grpc-addr service.string.StringService
+// @errors ErrNotFound=404/NOT_FOUND
 // @protobuf github.com/recolabs/microgen/examples/protobuf
 type StringService interface {
 	// @logs-ignore ans, err
 	// @cache
+	// @retry attempts=3 backoff=10ms idempotent
 	Uppercase(ctx context.Context, stringsMap map[string]string) (ans string, err error)
 	// @http-method geT
 	// @cache-key text
+	// @rate-limit 20/s burst=2
+	// @rate-limit-key text
 	// @json-rpc-prefix v1.
 	Count(ctx context.Context, text string, symbol string) (count int, positions []int, err error)
 	// @logs-len comments
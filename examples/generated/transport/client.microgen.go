@@ -4,21 +4,32 @@ package transport
 
 import (
 	"context"
-	"errors"
-	opentracing "github.com/go-kit/kit/tracing/opentracing"
-	opentracinggo "github.com/opentracing/opentracing-go"
+	endpoint "github.com/go-kit/kit/endpoint"
 	generated "github.com/recolabs/microgen/examples/generated"
-	codes "google.golang.org/grpc/codes"
-	status "google.golang.org/grpc/status"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	trace "go.opentelemetry.io/otel/trace"
 )
 
-// TraceClientEndpoints is used for tracing endpoints on client side.
-func TraceClientEndpoints(endpoints EndpointsSet, tracer opentracinggo.Tracer) EndpointsSet {
+// TraceClientEndpoints starts client span of OpenTelemetry for every endpoint call.
+func TraceClientEndpoints(endpoints EndpointsSet, tracer trace.Tracer) EndpointsSet {
 	return EndpointsSet{
-		CountEndpoint:       opentracing.TraceClient(tracer, "Count")(endpoints.CountEndpoint),
-		DummyMethodEndpoint: opentracing.TraceClient(tracer, "DummyMethod")(endpoints.DummyMethodEndpoint),
-		TestCaseEndpoint:    opentracing.TraceClient(tracer, "TestCase")(endpoints.TestCaseEndpoint),
-		UppercaseEndpoint:   opentracing.TraceClient(tracer, "Uppercase")(endpoints.UppercaseEndpoint),
+		CountEndpoint:       traceClientEndpoint(tracer, "StringService.Count", endpoints.CountEndpoint),
+		DummyMethodEndpoint: traceClientEndpoint(tracer, "StringService.DummyMethod", endpoints.DummyMethodEndpoint),
+		TestCaseEndpoint:    traceClientEndpoint(tracer, "StringService.TestCase", endpoints.TestCaseEndpoint),
+		UppercaseEndpoint:   traceClientEndpoint(tracer, "StringService.Uppercase", endpoints.UppercaseEndpoint),
+	}
+}
+
+func traceClientEndpoint(tracer trace.Tracer, name string, next endpoint.Endpoint) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+		defer span.End()
+		response, err := next(ctx, request)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(otelcodes.Error, err.Error())
+		}
+		return response, err
 	}
 }
 
@@ -26,9 +37,7 @@ func (set EndpointsSet) Uppercase(arg0 context.Context, arg1 map[string]string)
 	request := UppercaseRequest{StringsMap: arg1}
 	response, res1 := set.UppercaseEndpoint(arg0, &request)
 	if res1 != nil {
-		if e, ok := status.FromError(res1); ok || e.Code() == codes.Internal || e.Code() == codes.Unknown {
-			res1 = errors.New(e.Message())
-		}
+		res1 = DecodeGRPCError(res1)
 		return
 	}
 	return response.(*UppercaseResponse).Ans, res1
@@ -41,9 +50,7 @@ func (set EndpointsSet) Count(arg0 context.Context, arg1 string, arg2 string) (r
 	}
 	response, res2 := set.CountEndpoint(arg0, &request)
 	if res2 != nil {
-		if e, ok := status.FromError(res2); ok || e.Code() == codes.Internal || e.Code() == codes.Unknown {
-			res2 = errors.New(e.Message())
-		}
+		res2 = DecodeGRPCError(res2)
 		return
 	}
 	return response.(*CountResponse).Count, response.(*CountResponse).Positions, res2
@@ -53,9 +60,7 @@ func (set EndpointsSet) TestCase(arg0 context.Context, arg1 []*generated.Comment
 	request := TestCaseRequest{Comments: arg1}
 	response, res1 := set.TestCaseEndpoint(arg0, &request)
 	if res1 != nil {
-		if e, ok := status.FromError(res1); ok || e.Code() == codes.Internal || e.Code() == codes.Unknown {
-			res1 = errors.New(e.Message())
-		}
+		res1 = DecodeGRPCError(res1)
 		return
 	}
 	return response.(*TestCaseResponse).Tree, res1
@@ -65,9 +70,7 @@ func (set EndpointsSet) DummyMethod(arg0 context.Context) (res0 error) {
 	request := DummyMethodRequest{}
 	_, res0 = set.DummyMethodEndpoint(arg0, &request)
 	if res0 != nil {
-		if e, ok := status.FromError(res0); ok || e.Code() == codes.Internal || e.Code() == codes.Unknown {
-			res0 = errors.New(e.Message())
-		}
+		res0 = DecodeGRPCError(res0)
 		return
 	}
 	return res0
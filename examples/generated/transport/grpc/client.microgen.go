@@ -3,17 +3,29 @@
 package transportgrpc
 
 import (
+	"context"
+	endpoint "github.com/go-kit/kit/endpoint"
 	log "github.com/go-kit/kit/log"
-	opentracing "github.com/go-kit/kit/tracing/opentracing"
+	sd "github.com/go-kit/kit/sd"
 	grpckit "github.com/go-kit/kit/transport/grpc"
 	empty "github.com/golang/protobuf/ptypes/empty"
-	opentracinggo "github.com/opentracing/opentracing-go"
 	transport "github.com/recolabs/microgen/examples/generated/transport"
 	pb "github.com/recolabs/microgen/examples/protobuf"
+	propagation "go.opentelemetry.io/otel/propagation"
 	grpc "google.golang.org/grpc"
+	metadata "google.golang.org/grpc/metadata"
+	"io"
+	"time"
 )
 
-func NewGRPCClient(conn *grpc.ClientConn, addr string, opts ...grpckit.ClientOption) transport.EndpointsSet {
+// NewGRPCClient creates endpoints of StringService, which call server by conn.
+// Endpoints are wrapped with circuit breakers, which breaker returns for name of method.
+// Failed calls are retried by policies of @retry tags.
+func NewGRPCClient(conn *grpc.ClientConn, addr string, breaker func(method string) endpoint.Middleware, opts ...grpckit.ClientOption) transport.EndpointsSet {
+	return transport.RetryClientEndpoints(transport.CircuitBreakerClientEndpoints(grpcClientEndpoints(conn, addr, opts...), breaker))
+}
+
+func grpcClientEndpoints(conn *grpc.ClientConn, addr string, opts ...grpckit.ClientOption) transport.EndpointsSet {
 	if addr == "" {
 		addr = "service.string.StringService"
 	}
@@ -49,10 +61,39 @@ func NewGRPCClient(conn *grpc.ClientConn, addr string, opts ...grpckit.ClientOpt
 	}
 }
 
-func TracingGRPCClientOptions(tracer opentracinggo.Tracer, logger log.Logger) func([]grpckit.ClientOption) []grpckit.ClientOption {
+func TracingGRPCClientOptions(propagator propagation.TextMapPropagator) func([]grpckit.ClientOption) []grpckit.ClientOption {
 	return func(opts []grpckit.ClientOption) []grpckit.ClientOption {
 		return append(opts, grpckit.ClientBefore(
-			opentracing.ContextToGRPC(tracer, logger),
+			injectTraceContext(propagator),
 		))
 	}
 }
+
+// injectTraceContext writes trace context of OpenTelemetry to metadata of request.
+func injectTraceContext(propagator propagation.TextMapPropagator) grpckit.ClientRequestFunc {
+	return func(ctx context.Context, md *metadata.MD) context.Context {
+		carrier := propagation.MapCarrier{}
+		propagator.Inject(ctx, carrier)
+		for key, value := range carrier {
+			md.Set(key, value)
+		}
+		return ctx
+	}
+}
+
+// NewGRPCClientSD creates endpoints of StringService, which are balanced between instances of instancer.
+// Instance is address of server, which is dialed once with dialOpts. Connection is closed, when instance disappears
+// or closer is closed.
+// Failed calls of idempotent methods are tried on next instances up to retryMax times within retryTimeout.
+// Endpoints of every instance are wrapped with circuit breakers, which breaker returns for name of method.
+// Failed calls are retried on next instances by policies of @retry tags.
+func NewGRPCClientSD(instancer sd.Instancer, logger log.Logger, retryMax int, retryTimeout time.Duration, addr string, dialOpts []grpc.DialOption, breaker func(method string) endpoint.Middleware, opts ...grpckit.ClientOption) (transport.EndpointsSet, io.Closer) {
+	endpoints, closer := transport.BalancedEndpoints(instancer, func(instance string) (transport.EndpointsSet, io.Closer, error) {
+		conn, err := grpc.Dial(instance, dialOpts...)
+		if err != nil {
+			return transport.EndpointsSet{}, nil, err
+		}
+		return transport.CircuitBreakerClientEndpoints(grpcClientEndpoints(conn, addr, opts...), breaker), conn, nil
+	}, logger, retryMax, retryTimeout)
+	return transport.RetryClientEndpoints(endpoints), closer
+}
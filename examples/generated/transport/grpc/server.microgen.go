@@ -4,14 +4,13 @@
 package transportgrpc
 
 import (
-	log "github.com/go-kit/kit/log"
-	opentracing "github.com/go-kit/kit/tracing/opentracing"
 	grpc "github.com/go-kit/kit/transport/grpc"
 	empty "github.com/golang/protobuf/ptypes/empty"
-	opentracinggo "github.com/opentracing/opentracing-go"
 	transport "github.com/recolabs/microgen/examples/generated/transport"
 	pb "github.com/recolabs/microgen/examples/protobuf"
+	propagation "go.opentelemetry.io/otel/propagation"
 	context "golang.org/x/net/context"
+	metadata "google.golang.org/grpc/metadata"
 )
 
 type stringServiceServer struct {
@@ -21,35 +20,35 @@ type stringServiceServer struct {
 	dummyMethod grpc.Handler
 }
 
-func NewGRPCServer(endpoints *transport.EndpointsSet, logger log.Logger, tracer opentracinggo.Tracer, opts ...grpc.ServerOption) pb.StringServiceServer {
+func NewGRPCServer(endpoints *transport.EndpointsSet, propagator propagation.TextMapPropagator, opts ...grpc.ServerOption) pb.StringServiceServer {
 	return &stringServiceServer{
 		count: grpc.NewServer(
 			endpoints.CountEndpoint,
 			_Decode_Count_Request,
 			_Encode_Count_Response,
 			append(opts, grpc.ServerBefore(
-				opentracing.GRPCToContext(tracer, "Count", logger)))...,
+				extractTraceContext(propagator)))...,
 		),
 		dummyMethod: grpc.NewServer(
 			endpoints.DummyMethodEndpoint,
 			_Decode_DummyMethod_Request,
 			_Encode_DummyMethod_Response,
 			append(opts, grpc.ServerBefore(
-				opentracing.GRPCToContext(tracer, "DummyMethod", logger)))...,
+				extractTraceContext(propagator)))...,
 		),
 		testCase: grpc.NewServer(
 			endpoints.TestCaseEndpoint,
 			_Decode_TestCase_Request,
 			_Encode_TestCase_Response,
 			append(opts, grpc.ServerBefore(
-				opentracing.GRPCToContext(tracer, "TestCase", logger)))...,
+				extractTraceContext(propagator)))...,
 		),
 		uppercase: grpc.NewServer(
 			endpoints.UppercaseEndpoint,
 			_Decode_Uppercase_Request,
 			_Encode_Uppercase_Response,
 			append(opts, grpc.ServerBefore(
-				opentracing.GRPCToContext(tracer, "Uppercase", logger)))...,
+				extractTraceContext(propagator)))...,
 		),
 	}
 }
@@ -57,7 +56,7 @@ func NewGRPCServer(endpoints *transport.EndpointsSet, logger log.Logger, tracer
 func (S *stringServiceServer) Uppercase(ctx context.Context, req *pb.UppercaseRequest) (*pb.UppercaseResponse, error) {
 	_, resp, err := S.uppercase.ServeGRPC(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, transport.EncodeError(err)
 	}
 	return resp.(*pb.UppercaseResponse), nil
 }
@@ -65,7 +64,7 @@ func (S *stringServiceServer) Uppercase(ctx context.Context, req *pb.UppercaseRe
 func (S *stringServiceServer) Count(ctx context.Context, req *pb.CountRequest) (*pb.CountResponse, error) {
 	_, resp, err := S.count.ServeGRPC(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, transport.EncodeError(err)
 	}
 	return resp.(*pb.CountResponse), nil
 }
@@ -73,7 +72,7 @@ func (S *stringServiceServer) Count(ctx context.Context, req *pb.CountRequest) (
 func (S *stringServiceServer) TestCase(ctx context.Context, req *pb.TestCaseRequest) (*pb.TestCaseResponse, error) {
 	_, resp, err := S.testCase.ServeGRPC(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, transport.EncodeError(err)
 	}
 	return resp.(*pb.TestCaseResponse), nil
 }
@@ -81,7 +80,20 @@ func (S *stringServiceServer) TestCase(ctx context.Context, req *pb.TestCaseRequ
 func (S *stringServiceServer) DummyMethod(ctx context.Context, req *empty.Empty) (*empty.Empty, error) {
 	_, resp, err := S.dummyMethod.ServeGRPC(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, transport.EncodeError(err)
 	}
 	return resp.(*empty.Empty), nil
 }
+
+// extractTraceContext reads trace context of OpenTelemetry from metadata of request.
+func extractTraceContext(propagator propagation.TextMapPropagator) grpc.ServerRequestFunc {
+	return func(ctx context.Context, md metadata.MD) context.Context {
+		carrier := propagation.MapCarrier{}
+		for key, values := range md {
+			if len(values) > 0 {
+				carrier[key] = values[0]
+			}
+		}
+		return propagator.Extract(ctx, carrier)
+	}
+}
@@ -3,19 +3,28 @@
 package transporthttp
 
 import (
+	"context"
 	endpoint "github.com/go-kit/kit/endpoint"
 	log "github.com/go-kit/kit/log"
 	sd "github.com/go-kit/kit/sd"
-	lb "github.com/go-kit/kit/sd/lb"
-	opentracing "github.com/go-kit/kit/tracing/opentracing"
 	httpkit "github.com/go-kit/kit/transport/http"
-	opentracinggo "github.com/opentracing/opentracing-go"
 	transport "github.com/recolabs/microgen/examples/generated/transport"
+	propagation "go.opentelemetry.io/otel/propagation"
 	"io"
+	"net/http"
 	"net/url"
+	"strings"
+	"time"
 )
 
-func NewHTTPClient(u *url.URL, opts ...httpkit.ClientOption) transport.EndpointsSet {
+// NewHTTPClient creates endpoints of StringService, which call server at u.
+// Endpoints are wrapped with circuit breakers, which breaker returns for name of method.
+// Failed calls are retried by policies of @retry tags.
+func NewHTTPClient(u *url.URL, breaker func(method string) endpoint.Middleware, opts ...httpkit.ClientOption) transport.EndpointsSet {
+	return transport.RetryClientEndpoints(transport.CircuitBreakerClientEndpoints(httpClientEndpoints(u, opts...), breaker))
+}
+
+func httpClientEndpoints(u *url.URL, opts ...httpkit.ClientOption) transport.EndpointsSet {
 	return transport.EndpointsSet{
 		CountEndpoint: httpkit.NewClient(
 			"GET", u,
@@ -44,75 +53,40 @@ func NewHTTPClient(u *url.URL, opts ...httpkit.ClientOption) transport.Endpoints
 	}
 }
 
-func TracingHTTPClientOptions(tracer opentracinggo.Tracer, logger log.Logger) func([]httpkit.ClientOption) []httpkit.ClientOption {
+func TracingHTTPClientOptions(propagator propagation.TextMapPropagator) func([]httpkit.ClientOption) []httpkit.ClientOption {
 	return func(opts []httpkit.ClientOption) []httpkit.ClientOption {
 		return append(opts, httpkit.ClientBefore(
-			opentracing.ContextToHTTP(tracer, logger),
+			injectTraceContext(propagator),
 		))
 	}
 }
 
-// NewHTTPClientSD is a http client for StringService and uses service discovery inside.
-var NewHTTPClientSD = sdClientFactory(httpClientFactoryMaker)
-
-// sdClientFactory is a factory to create constructors for HTTPClientSD
-func sdClientFactory(
-	maker func(opts ...httpkit.ClientOption) func(string) (transport.EndpointsSet, error),
-) func(sd.Instancer, log.Logger, ...httpkit.ClientOption) transport.EndpointsSet {
-	return func(instancer sd.Instancer, logger log.Logger, opts ...httpkit.ClientOption) transport.EndpointsSet {
-		var endpoints transport.EndpointsSet
-		{
-			endpointer := sd.NewEndpointer(instancer, uppercaseSDFactory(maker(opts...)), logger)
-			endpoints.UppercaseEndpoint, _ = lb.NewRoundRobin(endpointer).Endpoint()
-		}
-		{
-			endpointer := sd.NewEndpointer(instancer, countSDFactory(maker(opts...)), logger)
-			endpoints.CountEndpoint, _ = lb.NewRoundRobin(endpointer).Endpoint()
-		}
-		{
-			endpointer := sd.NewEndpointer(instancer, testCaseSDFactory(maker(opts...)), logger)
-			endpoints.TestCaseEndpoint, _ = lb.NewRoundRobin(endpointer).Endpoint()
-		}
-		{
-			endpointer := sd.NewEndpointer(instancer, dummyMethodSDFactory(maker(opts...)), logger)
-			endpoints.DummyMethodEndpoint, _ = lb.NewRoundRobin(endpointer).Endpoint()
-		}
-		return endpoints
+// injectTraceContext writes trace context of OpenTelemetry to headers of request.
+func injectTraceContext(propagator propagation.TextMapPropagator) httpkit.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		propagator.Inject(ctx, propagation.HeaderCarrier(r.Header))
+		return ctx
 	}
 }
 
-// httpClientFactoryMaker returns function, that describes what to do with `instance string` to create new instance of client.
-// Commonly, for http protocol it would be some sort of url, e.g. `host:port`.
-func httpClientFactoryMaker(opts ...httpkit.ClientOption) func(string) (transport.EndpointsSet, error) {
-	return func(instance string) (transport.EndpointsSet, error) {
+// NewHTTPClientSD creates endpoints of StringService, which are balanced between instances of instancer.
+// Instance is address of server, e.g. `host:port` or `http://host:port`. Closer stops watching instancer.
+// Failed calls of idempotent methods are tried on next instances up to retryMax times within retryTimeout.
+// Endpoints of every instance are wrapped with circuit breakers, which breaker returns for name of method.
+// Failed calls are retried on next instances by policies of @retry tags.
+func NewHTTPClientSD(instancer sd.Instancer, logger log.Logger, retryMax int, retryTimeout time.Duration, breaker func(method string) endpoint.Middleware, opts ...httpkit.ClientOption) (transport.EndpointsSet, io.Closer) {
+	endpoints, closer := transport.BalancedEndpoints(instancer, func(instance string) (transport.EndpointsSet, io.Closer, error) {
+		if !strings.Contains(instance, "://") {
+			instance = "http://" + instance
+		}
 		u, err := url.Parse(instance)
 		if err != nil {
-			return transport.EndpointsSet{}, err
+			return transport.EndpointsSet{}, nil, err
 		}
-		return NewHTTPClient(u, opts...), nil
-	}
-}
-func uppercaseSDFactory(clientMaker func(string) (transport.EndpointsSet, error)) sd.Factory {
-	return func(instance string) (endpoint.Endpoint, io.Closer, error) {
-		c, err := clientMaker(instance)
-		return c.UppercaseEndpoint, nil, err
-	}
-}
-func countSDFactory(clientMaker func(string) (transport.EndpointsSet, error)) sd.Factory {
-	return func(instance string) (endpoint.Endpoint, io.Closer, error) {
-		c, err := clientMaker(instance)
-		return c.CountEndpoint, nil, err
-	}
-}
-func testCaseSDFactory(clientMaker func(string) (transport.EndpointsSet, error)) sd.Factory {
-	return func(instance string) (endpoint.Endpoint, io.Closer, error) {
-		c, err := clientMaker(instance)
-		return c.TestCaseEndpoint, nil, err
-	}
-}
-func dummyMethodSDFactory(clientMaker func(string) (transport.EndpointsSet, error)) sd.Factory {
-	return func(instance string) (endpoint.Endpoint, io.Closer, error) {
-		c, err := clientMaker(instance)
-		return c.DummyMethodEndpoint, nil, err
-	}
+		if u.Path == "" {
+			u.Path = "/"
+		}
+		return transport.CircuitBreakerClientEndpoints(httpClientEndpoints(u, opts...), breaker), nil, nil
+	}, logger, retryMax, retryTimeout)
+	return transport.RetryClientEndpoints(endpoints), closer
 }
@@ -29,6 +29,23 @@ func CommonHTTPResponseEncoder(_ context.Context, w http.ResponseWriter, respons
 	return json.NewEncoder(w).Encode(response)
 }
 
+// HTTPErrorEncoder writes error of service as JSON with mapped HTTP status.
+func HTTPErrorEncoder(_ context.Context, err error, w http.ResponseWriter) {
+	e := transport.EncodeError(err)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(e.Status)
+	json.NewEncoder(w).Encode(e)
+}
+
+// HTTPErrorDecoder returns error of service from response with error status.
+func HTTPErrorDecoder(r *http.Response) error {
+	e := transport.Error{Status: r.StatusCode}
+	if err := json.NewDecoder(r.Body).Decode(&e); err != nil || e.Message == "" {
+		e.Message = http.StatusText(r.StatusCode)
+	}
+	return transport.DecodeError(&e)
+}
+
 func _Decode_Uppercase_Request(_ context.Context, r *http.Request) (interface{}, error) {
 	var req transport.UppercaseRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
@@ -70,24 +87,36 @@ func _Decode_DummyMethod_Request(_ context.Context, r *http.Request) (interface{
 }
 
 func _Decode_Uppercase_Response(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode >= http.StatusBadRequest {
+		return nil, HTTPErrorDecoder(r)
+	}
 	var resp transport.UppercaseResponse
 	err := json.NewDecoder(r.Body).Decode(&resp)
 	return &resp, err
 }
 
 func _Decode_Count_Response(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode >= http.StatusBadRequest {
+		return nil, HTTPErrorDecoder(r)
+	}
 	var resp transport.CountResponse
 	err := json.NewDecoder(r.Body).Decode(&resp)
 	return &resp, err
 }
 
 func _Decode_TestCase_Response(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode >= http.StatusBadRequest {
+		return nil, HTTPErrorDecoder(r)
+	}
 	var resp transport.TestCaseResponse
 	err := json.NewDecoder(r.Body).Decode(&resp)
 	return &resp, err
 }
 
 func _Decode_DummyMethod_Response(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode >= http.StatusBadRequest {
+		return nil, HTTPErrorDecoder(r)
+	}
 	var resp transport.DummyMethodResponse
 	err := json.NewDecoder(r.Body).Decode(&resp)
 	return &resp, err
@@ -3,16 +3,16 @@
 package transporthttp
 
 import (
-	log "github.com/go-kit/kit/log"
-	opentracing "github.com/go-kit/kit/tracing/opentracing"
+	"context"
 	http "github.com/go-kit/kit/transport/http"
 	mux "github.com/gorilla/mux"
-	opentracinggo "github.com/opentracing/opentracing-go"
 	transport "github.com/recolabs/microgen/examples/generated/transport"
+	propagation "go.opentelemetry.io/otel/propagation"
 	http1 "net/http"
 )
 
-func NewHTTPHandler(endpoints *transport.EndpointsSet, logger log.Logger, tracer opentracinggo.Tracer, opts ...http.ServerOption) http1.Handler {
+func NewHTTPHandler(endpoints *transport.EndpointsSet, propagator propagation.TextMapPropagator, opts ...http.ServerOption) http1.Handler {
+	opts = append([]http.ServerOption{http.ServerErrorEncoder(HTTPErrorEncoder)}, opts...)
 	mux := mux.NewRouter()
 	mux.Methods("POST").Path("/uppercase").Handler(
 		http.NewServer(
@@ -20,27 +20,34 @@ func NewHTTPHandler(endpoints *transport.EndpointsSet, logger log.Logger, tracer
 			_Decode_Uppercase_Request,
 			_Encode_Uppercase_Response,
 			append(opts, http.ServerBefore(
-				opentracing.HTTPToContext(tracer, "Uppercase", logger)))...))
+				extractTraceContext(propagator)))...))
 	mux.Methods("GET").Path("/count/{text}/{symbol}").Handler(
 		http.NewServer(
 			endpoints.CountEndpoint,
 			_Decode_Count_Request,
 			_Encode_Count_Response,
 			append(opts, http.ServerBefore(
-				opentracing.HTTPToContext(tracer, "Count", logger)))...))
+				extractTraceContext(propagator)))...))
 	mux.Methods("POST").Path("/test-case").Handler(
 		http.NewServer(
 			endpoints.TestCaseEndpoint,
 			_Decode_TestCase_Request,
 			_Encode_TestCase_Response,
 			append(opts, http.ServerBefore(
-				opentracing.HTTPToContext(tracer, "TestCase", logger)))...))
+				extractTraceContext(propagator)))...))
 	mux.Methods("POST").Path("/dummy-method").Handler(
 		http.NewServer(
 			endpoints.DummyMethodEndpoint,
 			_Decode_DummyMethod_Request,
 			_Encode_DummyMethod_Response,
 			append(opts, http.ServerBefore(
-				opentracing.HTTPToContext(tracer, "DummyMethod", logger)))...))
+				extractTraceContext(propagator)))...))
 	return mux
 }
+
+// extractTraceContext reads trace context of OpenTelemetry from headers of request.
+func extractTraceContext(propagator propagation.TextMapPropagator) http.RequestFunc {
+	return func(ctx context.Context, r *http1.Request) context.Context {
+		return propagator.Extract(ctx, propagation.HeaderCarrier(r.Header))
+	}
+}
@@ -5,8 +5,6 @@ package transport
 import (
 	"context"
 	endpoint "github.com/go-kit/kit/endpoint"
-	opentracing "github.com/go-kit/kit/tracing/opentracing"
-	opentracinggo "github.com/opentracing/opentracing-go"
 	generated "github.com/recolabs/microgen/examples/generated"
 )
 
@@ -19,16 +17,6 @@ func Endpoints(svc generated.StringService) EndpointsSet {
 	}
 }
 
-// TraceServerEndpoints is used for tracing endpoints on server side.
-func TraceServerEndpoints(endpoints EndpointsSet, tracer opentracinggo.Tracer) EndpointsSet {
-	return EndpointsSet{
-		CountEndpoint:       opentracing.TraceServer(tracer, "Count")(endpoints.CountEndpoint),
-		DummyMethodEndpoint: opentracing.TraceServer(tracer, "DummyMethod")(endpoints.DummyMethodEndpoint),
-		TestCaseEndpoint:    opentracing.TraceServer(tracer, "TestCase")(endpoints.TestCaseEndpoint),
-		UppercaseEndpoint:   opentracing.TraceServer(tracer, "Uppercase")(endpoints.UppercaseEndpoint),
-	}
-}
-
 func UppercaseEndpoint(svc generated.StringService) endpoint.Endpoint {
 	return func(arg0 context.Context, request interface{}) (interface{}, error) {
 		req := request.(*UppercaseRequest)
@@ -0,0 +1,59 @@
+// Package pb contains messages and stream interfaces of StreamService in the form, which protoc-gen-go generates for them.
+// Stream methods over HTTP use nothing else, so they are written by hand instead of compilation of .proto file.
+package pb
+
+import "google.golang.org/grpc"
+
+type CountResponse struct {
+	Letter string `json:"letter,omitempty"`
+}
+
+type ChatRequest struct {
+	Text string `json:"text,omitempty"`
+}
+
+type ChatResponse struct {
+	Text string `json:"text,omitempty"`
+}
+
+type SumRequest struct {
+	Number int64 `json:"number,omitempty"`
+}
+
+type SumResponse struct {
+	Sum int64 `json:"sum,omitempty"`
+}
+
+type StreamService_CountServer interface {
+	Send(*CountResponse) error
+	grpc.ServerStream
+}
+
+type StreamService_CountClient interface {
+	Recv() (*CountResponse, error)
+	grpc.ClientStream
+}
+
+type StreamService_ChatServer interface {
+	Send(*ChatResponse) error
+	Recv() (*ChatRequest, error)
+	grpc.ServerStream
+}
+
+type StreamService_ChatClient interface {
+	Send(*ChatRequest) error
+	Recv() (*ChatResponse, error)
+	grpc.ClientStream
+}
+
+type StreamService_SumServer interface {
+	SendAndClose(*SumResponse) error
+	Recv() (*SumRequest, error)
+	grpc.ServerStream
+}
+
+type StreamService_SumClient interface {
+	Send(*SumRequest) error
+	CloseAndRecv() (*SumResponse, error)
+	grpc.ClientStream
+}
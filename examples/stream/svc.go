@@ -0,0 +1,19 @@
+package stream
+
+import "github.com/recolabs/microgen/examples/stream/pb"
+
+// @microgen http
+// @protobuf github.com/recolabs/microgen/examples/stream/pb
+type StreamService interface {
+	// Count sends every letter of text.
+	// @microgen one-to-many
+	// @http-method GET
+	// @http-query text
+	Count(text string, stream pb.StreamService_CountServer) (err error)
+	// Chat answers every message.
+	// @microgen many-to-many
+	Chat(stream pb.StreamService_ChatServer) (err error)
+	// Sum returns sum of all numbers.
+	// @microgen many-to-one
+	Sum(stream pb.StreamService_SumServer) (err error)
+}
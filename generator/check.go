@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/recolabs/microgen/generator/write_strategy"
+)
+
+// FileDiff is a unified diff between file on disk and its generated version.
+type FileDiff struct {
+	Path string
+	// Name is a path relative to current directory.
+	Name   string
+	Action write_strategy.Action
+	// Diff is empty, when file on disk is up to date.
+	Diff string
+}
+
+// Changes compares generated files with files on disk and returns diffs of all files.
+// Diff of file, which is up to date, is empty. Names in diffs are relative to current directory.
+func Changes(files *write_strategy.Files) ([]FileDiff, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	var diffs []FileDiff
+	for _, path := range files.Paths() {
+		generated, _ := files.Get(path)
+		current, err := ioutil.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		exists := err == nil
+		name := path
+		if rel, err := filepath.Rel(wd, path); err == nil {
+			name = filepath.ToSlash(rel)
+		}
+		if exists && bytes.Equal(current, generated) {
+			diffs = append(diffs, FileDiff{Path: path, Name: name, Action: files.Action(path)})
+			continue
+		}
+		from := "a/" + name
+		if !exists {
+			from = "/dev/null"
+		}
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(current),
+			B:        splitLines(generated),
+			FromFile: from,
+			ToFile:   "b/" + name,
+			Context:  3,
+		})
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, FileDiff{Path: path, Name: name, Action: files.Action(path), Diff: diff})
+	}
+	return diffs, nil
+}
+
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	return difflib.SplitLines(string(content))
+}
+
+const (
+	colorReset = "\x1b[0m"
+	colorBold  = "\x1b[1m"
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorCyan  = "\x1b[36m"
+)
+
+// ColorizeDiff adds terminal colors to unified diff, like git does.
+func ColorizeDiff(diff string) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		color := ""
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			color = colorBold
+		case strings.HasPrefix(line, "+"):
+			color = colorGreen
+		case strings.HasPrefix(line, "-"):
+			color = colorRed
+		case strings.HasPrefix(line, "@@"):
+			color = colorCyan
+		}
+		if color == "" {
+			b.WriteString(line)
+			continue
+		}
+		b.WriteString(color + strings.TrimSuffix(line, "\n") + colorReset + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/recolabs/microgen/generator/write_strategy"
+	"github.com/stretchr/testify/assert"
+)
+
+type stringRenderer string
+
+func (r stringRenderer) Render(w io.Writer) error {
+	_, err := io.WriteString(w, string(r))
+	return err
+}
+
+func TestChanges(t *testing.T) {
+	dir := t.TempDir()
+	const (
+		created  = "package a\n\nfunc A() {}\n"
+		appended = "package b\n\nfunc B() {}\n"
+	)
+	for name, content := range map[string]string{"a.go": created, "b.go": appended, "c.go": created} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := write_strategy.NewFiles()
+	units := []struct {
+		strategy write_strategy.Strategy
+		code     string
+	}{
+		{write_strategy.NewCreateFileStrategy(dir, "a.go"), created},
+		{write_strategy.NewAppendToFileStrategy(dir, "b.go"), "func C() {}\n"},
+		{write_strategy.NewCreateFileStrategy(dir, "c.go"), "package a\n\nfunc D() {}\n"},
+		{write_strategy.NewCreateFileStrategy(dir, "d.go"), created},
+	}
+	for _, u := range units {
+		if err := write_strategy.NewMemoryStrategy(files, u.strategy).Write(stringRenderer(u.code)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, ok := files.Get(filepath.Join(dir, "b.go"))
+	assert.True(t, ok)
+	assert.Equal(t, appended+"\nfunc C() {}\n", string(content))
+
+	changes, err := Changes(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := make(map[string]write_strategy.Action)
+	var diffs []FileDiff
+	for _, c := range changes {
+		actions[filepath.Base(c.Path)] = c.Action
+		if c.Diff != "" {
+			diffs = append(diffs, c)
+		}
+	}
+	assert.Equal(t, map[string]write_strategy.Action{
+		"a.go": write_strategy.OverwriteAction,
+		"b.go": write_strategy.AppendAction,
+		"c.go": write_strategy.OverwriteAction,
+		"d.go": write_strategy.CreateAction,
+	}, actions)
+	if assert.Len(t, diffs, 3) {
+		assert.Equal(t, filepath.Join(dir, "b.go"), diffs[0].Path)
+		assert.Contains(t, diffs[0].Diff, "+func C() {}\n")
+		assert.Equal(t, filepath.Join(dir, "c.go"), diffs[1].Path)
+		assert.Contains(t, diffs[1].Diff, "-func A() {}\n+func D() {}\n")
+		assert.Equal(t, filepath.Join(dir, "d.go"), diffs[2].Path)
+		assert.True(t, strings.HasPrefix(diffs[2].Diff, "--- /dev/null\n"), diffs[2].Diff)
+	}
+
+	// Files on disk are not changed.
+	content, err = ioutil.ReadFile(filepath.Join(dir, "c.go"))
+	assert.NoError(t, err)
+	assert.Equal(t, created, string(content))
+}
+
+func TestColorizeDiff(t *testing.T) {
+	diff := "--- a/x.go\n+++ b/x.go\n@@ -1 +1 @@\n-a\n+b\n c\n"
+	assert.Equal(t,
+		"\x1b[1m--- a/x.go\x1b[0m\n\x1b[1m+++ b/x.go\x1b[0m\n\x1b[36m@@ -1 +1 @@\x1b[0m\n\x1b[31m-a\x1b[0m\n\x1b[32m+b\x1b[0m\n c\n",
+		ColorizeDiff(diff),
+	)
+}
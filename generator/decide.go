@@ -31,7 +31,11 @@ const (
 	MainTag                   = template.MainTag
 	ErrorLoggingMiddlewareTag = template.ErrorLoggingMiddlewareTag
 	TracingMiddlewareTag      = template.TracingMiddlewareTag
+	OpenTelemetryTag          = template.OpenTelemetryTag
 	CachingMiddlewareTag      = template.CachingMiddlewareTag
+	RateLimitMiddlewareTag    = template.RateLimitMiddlewareTag
+	CircuitBreakerTag         = template.CircuitBreakerTag
+	RetryTag                  = template.RetryTag
 	JSONRPCTag                = template.JSONRPCTag
 	JSONRPCServerTag          = template.JSONRPCServerTag
 	JSONRPCClientTag          = template.JSONRPCClientTag
@@ -40,12 +44,15 @@ const (
 	TransportServer           = template.TransportServer
 	MetricsMiddlewareTag      = template.MetricsMiddlewareTag
 	ServiceDiscoveryTag       = template.ServiceDiscoveryTag
+	OpenAPITag                = template.OpenAPITag
+	ProtobufAPIv2Tag          = template.ProtobufAPIv2Tag
 
-	HttpMethodTag  = template.HttpMethodTag
-	HttpMethodPath = template.HttpMethodPath
+	HttpMethodTag          = template.HttpMethodTag
+	HttpMethodPath         = template.HttpMethodPath
+	JSONRPCMethodPrefixTag = template.JSONRPCMethodPrefixTag
 )
 
-func ListTemplatesForGen(ctx context.Context, iface *types.Interface, absOutPath, sourcePath, packageName string, genProto string, genMain bool) (units []*GenerationUnit, err error) {
+func ListTemplatesForGen(ctx context.Context, iface *types.Interface, namespace, absOutPath, sourcePath, packageName string, genProto string, genMain bool, pbGoFile *types.File) (units []*GenerationUnit, err error) {
 
 	absSourcePath, err := filepath.Abs(sourcePath)
 	if err != nil {
@@ -69,12 +76,14 @@ func ListTemplatesForGen(ctx context.Context, iface *types.Interface, absOutPath
 		OutputPackageImport:     packageName,
 		OutputFilePath:          absOutPath,
 		ProtobufPackageImport:   mstrings.FetchMetaInfo(TagMark+ProtobufTag, iface.Docs),
+		ProtobufGoFile:          pbGoFile,
 		FileHeader:              defaultFileHeader,
 		AllowedMethods:          allowedMethods,
 		OneToManyStreamMethods:  oneToManyStreamMethods,
 		ManyToManyStreamMethods: manyToManyStreamMethods,
 		ManyToOneStreamMethods:  manyToOneStreamMethods,
 		ProtobufClientAddr:      mstrings.FetchMetaInfo(TagMark+GRPCClientAddr, iface.Docs),
+		Namespace:               namespace,
 	}
 	lg.Logger.Logln(3, "\nGeneration Info:", info.String())
 	/*stubSvc, err := NewGenUnit(ctx, template.NewStubInterfaceTemplate(info), absOutPath)
@@ -83,26 +92,48 @@ func ListTemplatesForGen(ctx context.Context, iface *types.Interface, absOutPath
 	}
 	units = append(units, stubSvc)*/
 
-	genTags := mstrings.FetchTags(iface.Docs, TagMark+MicrogenMainTag)
+	genTags, unknown, err := ResolveTags(template.Tags(ctx).List())
+	if err != nil {
+		return nil, err
+	}
 	lg.Logger.Logln(2, "Tags:", strings.Join(genTags, ", "))
 	uniqueTemplate := make(map[string]template.Template)
 	for _, tag := range genTags {
-		templates := tagToTemplate(tag, info)
-		if templates == nil {
-			lg.Logger.Logln(1, "Warning: Unexpected tag", tag)
+		spec, _ := LookupTag(tag)
+		if spec.Deprecated != "" {
+			lg.Logger.Logln(1, "Warning: Tag", tag, "is deprecated,", spec.Deprecated)
+		}
+		if spec.Factory == nil {
 			continue
 		}
-		for _, t := range templates {
+		for _, t := range spec.Factory(info) {
 			uniqueTemplate[t.DefaultPath()] = t
 		}
 	}
+	// Tags of user templates may be unknown for registry.
+	for _, t := range template.UserTemplates(ctx) {
+		if mstrings.IsInStringSlice(t.Tag, genTags) || mstrings.IsInStringSlice(t.Tag, unknown) {
+			uniqueTemplate[t.Name] = t.For(info)
+		}
+	}
+	for _, tag := range unknown {
+		if !hasUserTemplate(ctx, tag) {
+			lg.Logger.Logln(1, "Warning: Unexpected tag", tag)
+		}
+	}
+	// Units of all templates are prepared, so errors of all of them are reported at once.
+	var errs []error
 	for _, t := range uniqueTemplate {
 		unit, err := NewGenUnit(ctx, t, absOutPath)
 		if err != nil {
-			return nil, fmt.Errorf("%s: %v", absOutPath, err)
+			errs = append(errs, fmt.Errorf("%s: %v", absOutPath, err))
+			continue
 		}
 		units = append(units, unit)
 	}
+	if len(errs) > 0 {
+		return nil, composeErrors(errs...)
+	}
 	if genProto != "" {
 		u, err := NewGenUnit(ctx, template.NewProtoTemplate(info, genProto), absOutPath)
 		if err != nil {
@@ -120,101 +151,13 @@ func ListTemplatesForGen(ctx context.Context, iface *types.Interface, absOutPath
 	return units, nil
 }
 
-func tagToTemplate(tag string, info *template.GenerationInfo) (tmpls []template.Template) {
-	switch tag {
-	case MiddlewareTag:
-		return append(tmpls, template.NewMiddlewareTemplate(info))
-	case LoggingMiddlewareTag:
-		return append(
-			append(tmpls, tagToTemplate(MiddlewareTag, info)...),
-			template.NewLoggingTemplate(info),
-		)
-	case GrpcTag:
-		return append(
-			append(tmpls, tagToTemplate(Transport, info)...),
-			template.NewGRPCClientTemplate(info),
-			template.NewGRPCServerTemplate(info),
-			template.NewGRPCEndpointConverterTemplate(info),
-			template.NewStubGRPCTypeConverterTemplate(info),
-		)
-	case GrpcClientTag:
-		return append(
-			append(tmpls, tagToTemplate(TransportClient, info)...),
-			template.NewGRPCClientTemplate(info),
-			template.NewGRPCEndpointConverterTemplate(info),
-			template.NewStubGRPCTypeConverterTemplate(info),
-		)
-	case GrpcServerTag:
-		return append(
-			append(tmpls, tagToTemplate(TransportServer, info)...),
-			template.NewGRPCServerTemplate(info),
-			template.NewGRPCEndpointConverterTemplate(info),
-			template.NewStubGRPCTypeConverterTemplate(info),
-		)
-	case HttpTag:
-		return append(
-			append(tmpls, tagToTemplate(Transport, info)...),
-			template.NewHttpServerTemplate(info),
-			template.NewHttpClientTemplate(info),
-			template.NewHttpConverterTemplate(info),
-		)
-	case HttpServerTag:
-		return append(
-			append(tmpls, tagToTemplate(TransportServer, info)...),
-			template.NewHttpServerTemplate(info),
-			template.NewHttpConverterTemplate(info),
-		)
-	case HttpClientTag:
-		return append(
-			append(tmpls, tagToTemplate(TransportClient, info)...),
-			template.NewHttpClientTemplate(info),
-			template.NewHttpConverterTemplate(info),
-		)
-	case RecoveringMiddlewareTag:
-		return append(
-			append(tmpls, tagToTemplate(MiddlewareTag, info)...),
-			template.NewRecoverTemplate(info),
-		)
-	case MainTag:
-		lg.Logger.Logln(1, "Warning: Tag main is deprecated, use flag -main instead.")
-		return nil
-	case ErrorLoggingMiddlewareTag:
-		return append(
-			append(tmpls, tagToTemplate(MiddlewareTag, info)...),
-			template.NewErrorLoggingTemplate(info),
-		)
-	case CachingMiddlewareTag:
-		return append(
-			append(tmpls, tagToTemplate(MiddlewareTag, info)...),
-			template.NewCacheMiddlewareTemplate(info),
-		)
-	case TracingMiddlewareTag:
-		return append(tmpls, template.EmptyTemplate{})
-	case MetricsMiddlewareTag:
-		return append(tmpls, template.EmptyTemplate{})
-	case ServiceDiscoveryTag:
-		return append(tmpls, template.EmptyTemplate{})
-	case Transport:
-		return append(tmpls,
-			template.NewExchangeTemplate(info),
-			template.NewEndpointsTemplate(info),
-			template.NewEndpointsClientTemplate(info),
-			template.NewEndpointsServerTemplate(info),
-		)
-	case TransportClient:
-		return append(tmpls,
-			template.NewExchangeTemplate(info),
-			template.NewEndpointsTemplate(info),
-			template.NewEndpointsClientTemplate(info),
-		)
-	case TransportServer:
-		return append(tmpls,
-			template.NewExchangeTemplate(info),
-			template.NewEndpointsTemplate(info),
-			template.NewEndpointsServerTemplate(info),
-		)
-	}
-	return nil
+func hasUserTemplate(ctx context.Context, tag string) bool {
+	for _, t := range template.UserTemplates(ctx) {
+		if t.Tag == tag {
+			return true
+		}
+	}
+	return false
 }
 
 func resolvePackagePath(outPath string) (string, error) {
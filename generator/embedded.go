@@ -0,0 +1,310 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	lg "github.com/recolabs/microgen/logger"
+	"github.com/vetcher/go-astra"
+	"github.com/vetcher/go-astra/types"
+)
+
+// ResolveEmbeddedInterfaces adds methods of all interfaces, embedded to iface, to its methods list.
+// Embedded interfaces are searched in the package of sourcePath and in imported packages,
+// types of methods from other packages are qualified with import path of their package.
+// Methods of iface go first, so generated code keeps the order of declaration.
+func ResolveEmbeddedInterfaces(iface *types.Interface, sourcePath string) error {
+	r := &embeddedResolver{visited: make(map[string]bool)}
+	file, spec, err := findInterfaceSpec(sourcePath, iface.Name)
+	if err != nil {
+		return err
+	}
+	if spec == nil {
+		return fmt.Errorf("%s: could not find interface %s", sourcePath, iface.Name)
+	}
+	r.visited[filepath.Dir(sourcePath)+"."+iface.Name] = true
+	methods, err := r.embeddedMethods(file, spec, filepath.Dir(sourcePath), nil)
+	if err != nil {
+		return fmt.Errorf("%s: %v", iface.Name, err)
+	}
+	existing := make(map[string]*types.Function, len(iface.Methods))
+	for _, fn := range iface.Methods {
+		existing[fn.Name] = fn
+	}
+	for _, fn := range methods {
+		if m, ok := existing[fn.Name]; ok {
+			if m.String() != fn.String() {
+				return fmt.Errorf("%s: duplicate method %s with different signatures", iface.Name, fn.Name)
+			}
+			continue
+		}
+		lg.Logger.Logln(4, "Embedded method:", iface.Name+"."+fn.Name)
+		existing[fn.Name] = fn
+		iface.Methods = append(iface.Methods, fn)
+	}
+	iface.Interfaces = nil
+	return nil
+}
+
+type embeddedResolver struct {
+	// Interfaces, which methods are already collected, in form <dir>.<name>.
+	visited map[string]bool
+}
+
+// Returns methods of interface with given name from package in dir, including methods of its embedded interfaces.
+// When qualifier is not nil, types of package are qualified with it.
+func (r *embeddedResolver) interfaceMethods(dir, name string, qualifier *types.Import) ([]*types.Function, error) {
+	key := dir + "." + name
+	if r.visited[key] {
+		return nil, nil
+	}
+	r.visited[key] = true
+	files, err := SourceFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, filename := range files {
+		file, spec, err := findInterfaceSpec(filename, name)
+		if err != nil {
+			return nil, err
+		}
+		if spec == nil {
+			continue
+		}
+		parsed, err := astra.ParseFile(filename, astra.AllowAnyImportAliases)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", filename, err)
+		}
+		var methods []*types.Function
+		for i := range parsed.Interfaces {
+			if parsed.Interfaces[i].Name != name {
+				continue
+			}
+			for _, fn := range parsed.Interfaces[i].Methods {
+				methods = append(methods, qualifyFunction(fn, qualifier))
+			}
+		}
+		embedded, err := r.embeddedMethods(file, spec, dir, qualifier)
+		if err != nil {
+			return nil, err
+		}
+		return append(methods, embedded...), nil
+	}
+	return nil, fmt.Errorf("could not find interface %s in %s", name, dir)
+}
+
+// Collects methods of interfaces, embedded to spec.
+func (r *embeddedResolver) embeddedMethods(file *ast.File, spec *ast.InterfaceType, dir string, qualifier *types.Import) ([]*types.Function, error) {
+	var methods []*types.Function
+	for _, field := range spec.Methods.List {
+		if len(field.Names) != 0 {
+			continue
+		}
+		var (
+			fns []*types.Function
+			err error
+		)
+		switch t := field.Type.(type) {
+		case *ast.Ident:
+			fns, err = r.interfaceMethods(dir, t.Name, qualifier)
+		case *ast.SelectorExpr:
+			x, ok := t.X.(*ast.Ident)
+			if !ok {
+				return nil, fmt.Errorf("unexpected embedded type %T", t.X)
+			}
+			importPath, pkgDir, e := resolveImport(file, dir, x.Name)
+			if e != nil {
+				return nil, fmt.Errorf("%s.%s: %v", x.Name, t.Sel.Name, e)
+			}
+			fns, err = r.interfaceMethods(pkgDir, t.Sel.Name, &types.Import{Base: types.Base{Name: x.Name}, Package: importPath})
+		default:
+			return nil, fmt.Errorf("unexpected embedded type %T", t)
+		}
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, fns...)
+	}
+	return methods, nil
+}
+
+// Parses file and returns declaration of interface with given name or nil, when file does not declare it.
+func findInterfaceSpec(filename, name string) (*ast.File, *ast.InterfaceType, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), filename, nil, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, s := range gen.Specs {
+			ts := s.(*ast.TypeSpec)
+			if ts.Name.Name != name {
+				continue
+			}
+			if it, ok := ts.Type.(*ast.InterfaceType); ok {
+				return file, it, nil
+			}
+			return nil, nil, fmt.Errorf("%s is not an interface", name)
+		}
+	}
+	return file, nil, nil
+}
+
+// Finds import of file with given package name and returns its import path and directory.
+func resolveImport(file *ast.File, dir, pkgName string) (importPath, pkgDir string, err error) {
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return "", "", err
+		}
+		if spec.Name != nil && spec.Name.Name != pkgName {
+			continue
+		}
+		if spec.Name == nil && filepath.Base(path) != pkgName && packageName(path, dir) != pkgName {
+			continue
+		}
+		pkgDir, err := importDir(path, dir)
+		if err != nil {
+			return "", "", err
+		}
+		return path, pkgDir, nil
+	}
+	return "", "", fmt.Errorf("could not find import of package %s", pkgName)
+}
+
+// Returns name of imported package or empty string, when package can not be found.
+func packageName(importPath, dir string) string {
+	pkgDir, err := importDir(importPath, dir)
+	if err != nil {
+		return ""
+	}
+	files, err := SourceFiles(pkgDir)
+	if err != nil || len(files) == 0 {
+		return ""
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), files[0], nil, parser.PackageClauseOnly)
+	if err != nil {
+		return ""
+	}
+	return file.Name.Name
+}
+
+var moduleRegexp = regexp.MustCompile(`module\s+(\S+)`)
+
+// Returns directory of imported package.
+// Packages of the module, which contains dir, are resolved by go.mod, others are searched by go tool.
+func importDir(importPath, dir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for root := absDir; ; root = filepath.Dir(root) {
+		if buffer, err := ioutil.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+			if m := moduleRegexp.FindSubmatch(buffer); m != nil {
+				module := string(m[1])
+				if importPath == module {
+					return root, nil
+				}
+				if strings.HasPrefix(importPath, module+"/") {
+					return filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(importPath, module+"/"))), nil
+				}
+			}
+			break
+		}
+		if filepath.Dir(root) == root {
+			break
+		}
+	}
+	pkg, err := build.Import(importPath, absDir, build.FindOnly)
+	if err != nil {
+		return "", err
+	}
+	return pkg.Dir, nil
+}
+
+// SourceFiles returns go files of package directory, except tests.
+func SourceFiles(dir string) ([]string, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, name))
+	}
+	return files, nil
+}
+
+func qualifyFunction(fn *types.Function, qualifier *types.Import) *types.Function {
+	if qualifier == nil {
+		return fn
+	}
+	return &types.Function{
+		Base:    fn.Base,
+		Args:    qualifyVariables(fn.Args, qualifier),
+		Results: qualifyVariables(fn.Results, qualifier),
+	}
+}
+
+func qualifyVariables(vars []types.Variable, qualifier *types.Import) []types.Variable {
+	qualified := make([]types.Variable, len(vars))
+	for i := range vars {
+		qualified[i] = types.Variable{Base: vars[i].Base, Type: qualifyType(vars[i].Type, qualifier)}
+	}
+	return qualified
+}
+
+// Qualifies not builtin type names with import of package, where they were declared.
+func qualifyType(t types.Type, qualifier *types.Import) types.Type {
+	switch tt := t.(type) {
+	case types.TName:
+		if types.IsBuiltin(tt) {
+			return tt
+		}
+		return types.TImport{Import: qualifier, Next: tt}
+	case types.TPointer:
+		tt.Next = qualifyType(tt.Next, qualifier)
+		return tt
+	case types.TArray:
+		tt.Next = qualifyType(tt.Next, qualifier)
+		return tt
+	case types.TEllipsis:
+		tt.Next = qualifyType(tt.Next, qualifier)
+		return tt
+	case types.TChan:
+		tt.Next = qualifyType(tt.Next, qualifier)
+		return tt
+	case types.TMap:
+		tt.Key = qualifyType(tt.Key, qualifier)
+		tt.Value = qualifyType(tt.Value, qualifier)
+		return tt
+	case types.TInterface:
+		if tt.Interface == nil {
+			return tt
+		}
+		iface := &types.Interface{Base: tt.Interface.Base}
+		for _, fn := range tt.Interface.Methods {
+			iface.Methods = append(iface.Methods, qualifyFunction(fn, qualifier))
+		}
+		return types.TInterface{Interface: iface}
+	case *types.Function:
+		return qualifyFunction(tt, qualifier)
+	default:
+		return t
+	}
+}
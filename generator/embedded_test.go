@@ -0,0 +1,95 @@
+package generator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetcher/go-astra/types"
+)
+
+var embeddedTestFiles = map[string]string{
+	"go.mod": "module example.com/emb\n",
+	"admin/admin.go": `package admin
+
+import "context"
+
+type Status struct{}
+
+type Admin interface {
+	Base
+	Status(ctx context.Context) (status *Status, err error)
+}
+
+type Base interface {
+	Reload(ctx context.Context, opts map[string]Status) (err error)
+}
+`,
+	"svc/health.go": `package svc
+
+import "context"
+
+type HealthChecker interface {
+	Check(ctx context.Context) (ok bool, err error)
+}
+`,
+	"svc/svc.go": `package svc
+
+import (
+	"context"
+
+	adm "example.com/emb/admin"
+)
+
+type Item struct{}
+
+// @microgen middleware
+type ItemService interface {
+	HealthChecker
+	adm.Admin
+	Get(ctx context.Context, id string) (item *Item, err error)
+	Check(ctx context.Context) (ok bool, err error)
+}
+`,
+}
+
+func TestResolveEmbeddedInterfaces(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range embeddedTestFiles {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sourcePath := filepath.Join(dir, "svc", "svc.go")
+	iface, err := loadInterface(sourcePath, "ItemService")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ResolveEmbeddedInterfaces(iface, sourcePath); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, fn := range iface.Methods {
+		names = append(names, fn.Name)
+	}
+	assert.Equal(t, []string{"Get", "Check", "Status", "Reload"}, names)
+
+	status := iface.Methods[2].Results[0].Type.(types.TPointer).Next.(types.TImport)
+	assert.Equal(t, "example.com/emb/admin", status.Import.Package)
+	assert.Equal(t, "Status", status.Next.(types.TName).TypeName)
+
+	opts := iface.Methods[3].Args[1].Type.(types.TMap)
+	assert.Equal(t, types.TName{TypeName: "string"}, opts.Key)
+	assert.Equal(t, "example.com/emb/admin", opts.Value.(types.TImport).Import.Package)
+
+	item := iface.Methods[0].Results[0].Type.(types.TPointer).Next
+	assert.Equal(t, types.TName{TypeName: "Item"}, item)
+}
@@ -0,0 +1,366 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"path"
+	"strings"
+
+	. "github.com/dave/jennifer/jen"
+	"github.com/recolabs/microgen/generator/protofile"
+	mstrings "github.com/recolabs/microgen/generator/strings"
+	"github.com/recolabs/microgen/generator/template"
+)
+
+// FromProtoOptions are parameters of go file, generated from protobuf file.
+type FromProtoOptions struct {
+	// Name of generated go package.
+	PackageName string
+	// Import path of compiled protobuf package. When empty, path from `go_package` option is used.
+	ProtobufImport string
+	// Generation tags of interfaces.
+	Tags []string
+}
+
+// Scalar types of protobuf.
+var protoScalarTypes = map[string]string{
+	"double":   "float64",
+	"float":    "float32",
+	"int32":    "int32",
+	"sint32":   "int32",
+	"sfixed32": "int32",
+	"int64":    "int64",
+	"sint64":   "int64",
+	"sfixed64": "int64",
+	"uint32":   "uint32",
+	"fixed32":  "uint32",
+	"uint64":   "uint64",
+	"fixed64":  "uint64",
+	"bool":     "bool",
+	"string":   "string",
+}
+
+// Wrapper messages, which are pointers to scalar types in service.
+var protoWrapperTypes = map[string]string{
+	"google.protobuf.DoubleValue": "float64",
+	"google.protobuf.FloatValue":  "float32",
+	"google.protobuf.Int64Value":  "int64",
+	"google.protobuf.UInt64Value": "uint64",
+	"google.protobuf.Int32Value":  "int32",
+	"google.protobuf.UInt32Value": "uint32",
+	"google.protobuf.BoolValue":   "bool",
+	"google.protobuf.StringValue": "string",
+}
+
+// GenerateFromProto renders go file with interfaces of protobuf services and structures and enums for
+// their messages, so it can be used as a source of generation.
+// Methods get `context.Context` first and `error` last, fields of `<Method>Request` and `<Method>Response`
+// messages become arguments and results, streaming rpcs get stream tags.
+//
+//		// @microgen grpc
+//		// @protobuf github.com/user/stringsvc/pb
+//		// @grpc-addr stringsvc.StringService
+//		type StringService interface {
+//			Count(ctx context.Context, text string, symbol string) (count int64, positions []int64, err error)
+//			// @microgen one-to-many
+//			Watch(text string, stream pb.StringService_WatchServer) (err error)
+//		}
+//
+func GenerateFromProto(file *protofile.File, opts FromProtoOptions) ([]byte, error) {
+	if len(file.Services) == 0 {
+		return nil, fmt.Errorf("%s: no services declared", file.Name)
+	}
+	g := &protoToGo{
+		file:     file,
+		opts:     opts,
+		declared: make(map[string]bool),
+	}
+	if g.pbImport = opts.ProtobufImport; g.pbImport == "" {
+		g.pbImport, g.pbName = file.GoPackage()
+	}
+	if g.pbImport == "" {
+		return nil, fmt.Errorf("%s: go_package option is not declared, provide import path of protobuf package", file.Name)
+	}
+	if g.pbName == "" {
+		g.pbName = path.Base(g.pbImport)
+	}
+	f := NewFile(opts.PackageName)
+	f.HeaderComment(fmt.Sprintf("Service declarations generated by microgen from %s.", path.Base(file.Name)))
+	f.ImportName(g.pbImport, g.pbName)
+	for _, svc := range file.Services {
+		code, err := g.service(svc)
+		if err != nil {
+			return nil, err
+		}
+		f.Add(code)
+		f.Line()
+	}
+	// Rendering of structure may add new types to queue.
+	for i := 0; i < len(g.queue); i++ {
+		f.Add(g.declaration(g.queue[i]))
+		f.Line()
+	}
+	var buf bytes.Buffer
+	if err := f.Render(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type protoToGo struct {
+	file     *protofile.File
+	opts     FromProtoOptions
+	pbImport string
+	pbName   string
+
+	declared map[string]bool
+	// Messages and enums to declare, in order of first use.
+	queue []interface{}
+}
+
+// goParam is an argument or a result of generated method.
+type goParam struct {
+	name string
+	typ  Code
+}
+
+func (g *protoToGo) service(svc *protofile.Service) (Code, error) {
+	var docs []Code
+	for _, c := range svc.Comments {
+		docs = append(docs, Comment(c).Line())
+	}
+	tags := g.opts.Tags
+	if len(tags) == 0 {
+		tags = []string{GrpcTag}
+	}
+	addr := svc.Name
+	if g.file.Package != "" {
+		addr = g.file.Package + "." + svc.Name
+	}
+	docs = append(docs,
+		Comment(fmt.Sprintf("@%s %s", MicrogenMainTag, strings.Join(tags, ", "))).Line(),
+		Comment(fmt.Sprintf("@%s %s", ProtobufTag, g.pbImport)).Line(),
+		Comment(fmt.Sprintf("@%s %s", GRPCClientAddr, addr)).Line(),
+	)
+	var methods []Code
+	for _, rpc := range svc.RPCs {
+		method, err := g.method(svc, rpc)
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, method...)
+	}
+	return Add(docs...).Type().Id(svc.Name).Interface(methods...), nil
+}
+
+func (g *protoToGo) method(svc *protofile.Service, rpc *protofile.RPC) ([]Code, error) {
+	var (
+		code     []Code
+		args     []goParam
+		results  []goParam
+		streamed = rpc.ClientStreaming || rpc.ServerStreaming
+		stream   = goParam{name: "stream", typ: Qual(g.pbImport, svc.Name+"_"+rpc.Name+"Server")}
+	)
+	for _, c := range rpc.Comments {
+		code = append(code, Comment(c))
+	}
+	switch {
+	case rpc.ClientStreaming && rpc.ServerStreaming:
+		code = append(code, Comment("@microgen many-to-many"))
+	case rpc.ClientStreaming:
+		code = append(code, Comment("@microgen many-to-one"))
+	case rpc.ServerStreaming:
+		code = append(code, Comment("@microgen one-to-many"))
+	}
+	if !streamed {
+		args = append(args, goParam{name: "ctx", typ: Qual(template.PackagePathContext, "Context")})
+	}
+	if !rpc.ClientStreaming {
+		params, todo, err := g.params(rpc, rpc.Request, rpc.Name+"Request", nil)
+		if err != nil {
+			return nil, err
+		}
+		if todo != "" {
+			code = append(code, Comment(todo))
+		}
+		args = append(args, params...)
+	}
+	if streamed {
+		args = append(args, stream)
+	} else {
+		params, todo, err := g.params(rpc, rpc.Response, rpc.Name+"Response", args)
+		if err != nil {
+			return nil, err
+		}
+		if todo != "" {
+			code = append(code, Comment(todo))
+		}
+		results = append(results, params...)
+	}
+	results = append(results, goParam{name: "err", typ: Error()})
+	return append(code, Id(rpc.Name).Params(paramsCode(args)...).Params(paramsCode(results)...)), nil
+}
+
+func paramsCode(params []goParam) []Code {
+	code := make([]Code, len(params))
+	for i, p := range params {
+		code[i] = Id(p.name).Add(p.typ)
+	}
+	return code
+}
+
+// Returns parameters of method for message. Fields of message with expected name become parameters,
+// other messages are passed as a single parameter. Names, which are used by args, are renamed.
+func (g *protoToGo) params(rpc *protofile.RPC, typeName string, expected string, args []goParam) ([]goParam, string, error) {
+	used := map[string]bool{"ctx": true, "err": true, "stream": true}
+	for _, arg := range args {
+		used[arg.name] = true
+	}
+	name := strings.TrimPrefix(typeName, ".")
+	switch {
+	case name == "google.protobuf.Empty":
+		return nil, "", nil
+	case name == "google.protobuf.Timestamp":
+		return []goParam{{name: uniqueName("timestamp", used), typ: Qual(template.PackagePathTime, "Time")}}, "", nil
+	case protoWrapperTypes[name] != "":
+		return []goParam{{name: uniqueName("value", used), typ: Op("*").Id(protoWrapperTypes[name])}}, "", nil
+	}
+	m := g.file.Message(name)
+	if m == nil {
+		return nil, "", fmt.Errorf("%s: %s: message %s is not declared in %s", rpc.Pos, rpc.Name, typeName, path.Base(g.file.Name))
+	}
+	if m.Name != expected {
+		todo := fmt.Sprintf("TODO: microgen expects message %s, but rpc %s uses %s", expected, rpc.Name, m.FullName)
+		return []goParam{{name: uniqueName(mstrings.ToLowerCamelCase(m.Name), used), typ: g.fieldType(&protofile.Field{Type: m.FullName})}}, todo, nil
+	}
+	var params []goParam
+	for _, field := range m.Fields {
+		params = append(params, goParam{name: uniqueName(mstrings.ToLowerCamelCase(field.Name), used), typ: g.fieldType(field)})
+	}
+	return params, "", nil
+}
+
+// Adds `_` to names, which are go keywords or are already used.
+func uniqueName(name string, used map[string]bool) string {
+	for token.IsKeyword(name) || used[name] {
+		name += "_"
+	}
+	used[name] = true
+	return name
+}
+
+// Returns go type of field. Messages and enums of file are queued for declaration.
+func (g *protoToGo) fieldType(field *protofile.Field) Code {
+	typ := g.valueType(field.Type, field.Label == protofile.LabelOptional)
+	switch {
+	case field.IsMap():
+		return Map(g.valueType(field.KeyType, false)).Add(typ)
+	case field.IsRepeated():
+		return Index().Add(typ)
+	}
+	return typ
+}
+
+func (g *protoToGo) valueType(typeName string, optional bool) Code {
+	name := strings.TrimPrefix(typeName, ".")
+	ptr := func(c Code) Code {
+		if optional {
+			return Op("*").Add(c)
+		}
+		return c
+	}
+	if scalar, ok := protoScalarTypes[name]; ok {
+		return ptr(Id(scalar))
+	}
+	switch name {
+	case "bytes", "google.protobuf.BytesValue":
+		return Index().Byte()
+	case "google.protobuf.Timestamp":
+		return Qual(template.PackagePathTime, "Time")
+	case "google.protobuf.Duration":
+		return Qual(template.PackagePathTime, "Duration")
+	case "google.protobuf.Struct":
+		return Map(String()).Interface()
+	case "google.protobuf.Value", "google.protobuf.Any":
+		return Interface()
+	case "google.protobuf.ListValue":
+		return Index().Interface()
+	case "google.protobuf.FieldMask":
+		return Index().String()
+	case "google.protobuf.Empty":
+		return Struct()
+	}
+	if scalar, ok := protoWrapperTypes[name]; ok {
+		return Op("*").Id(scalar)
+	}
+	if m := g.file.Message(name); m != nil {
+		g.declare(m.FullName, m)
+		return Op("*").Id(goTypeName(m.FullName))
+	}
+	if e := g.file.Enum(name); e != nil {
+		g.declare(e.FullName, e)
+		return ptr(Id(goTypeName(e.FullName)))
+	}
+	return Interface().Comment(fmt.Sprintf("/* TODO: type %s is not declared in %s */", typeName, path.Base(g.file.Name)))
+}
+
+func (g *protoToGo) declare(name string, decl interface{}) {
+	if !g.declared[name] {
+		g.declared[name] = true
+		g.queue = append(g.queue, decl)
+	}
+}
+
+// Go name of nested declarations is joined by `_`, as protoc-gen-go does.
+func goTypeName(fullName string) string {
+	return strings.Replace(fullName, ".", "_", -1)
+}
+
+func (g *protoToGo) declaration(decl interface{}) Code {
+	switch decl := decl.(type) {
+	case *protofile.Message:
+		return g.structure(decl)
+	case *protofile.Enum:
+		return g.enum(decl)
+	}
+	return Null()
+}
+
+func (g *protoToGo) structure(m *protofile.Message) Code {
+	var docs []Code
+	for _, c := range m.Comments {
+		docs = append(docs, Comment(c).Line())
+	}
+	used := make(map[string]bool)
+	var fields []Code
+	for _, field := range m.Fields {
+		for _, c := range field.Comments {
+			fields = append(fields, Comment(c))
+		}
+		if field.OneOf != "" {
+			fields = append(fields, Comment("oneof "+field.OneOf))
+		}
+		fields = append(fields, Id(uniqueName(mstrings.ToUpperCamelCase(field.Name), used)).Add(g.fieldType(field)))
+	}
+	return Add(docs...).Type().Id(goTypeName(m.FullName)).Struct(fields...)
+}
+
+// Enum values are named by enum name and value name without enum prefix: KIND_PLAIN of Kind is KindPlain.
+func (g *protoToGo) enum(e *protofile.Enum) Code {
+	name := goTypeName(e.FullName)
+	var docs []Code
+	for _, c := range e.Comments {
+		docs = append(docs, Comment(c).Line())
+	}
+	prefix := strings.ToUpper(mstrings.ToSnakeCase(e.Name)) + "_"
+	return Add(docs...).Type().Id(name).Int32().Line().Line().Const().DefsFunc(func(group *Group) {
+		for _, v := range e.Values {
+			for _, c := range v.Comments {
+				group.Comment(c)
+			}
+			valueName := name + mstrings.ToUpperCamelCase(strings.ToLower(strings.TrimPrefix(v.Name, prefix)))
+			group.Id(valueName).Id(name).Op("=").Lit(v.Number)
+		}
+	})
+}
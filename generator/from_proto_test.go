@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/recolabs/microgen/generator/protofile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vetcher/go-astra"
+)
+
+const fromProtoTestFile = `syntax = "proto3";
+
+package stringsvc;
+
+option go_package = "example.com/stringsvc/pb;pb";
+
+import "google/protobuf/empty.proto";
+import "google/protobuf/wrappers.proto";
+import "google/protobuf/timestamp.proto";
+
+service StringService {
+    // Counts symbols.
+    rpc Count (CountRequest) returns (CountResponse);
+    rpc Ping (google.protobuf.Empty) returns (google.protobuf.Empty);
+    rpc Get (google.protobuf.StringValue) returns (Text);
+    rpc Watch (WatchRequest) returns (stream Text);
+    rpc Upload (stream Text) returns (UploadResponse);
+}
+
+message CountRequest {
+    string text = 1;
+    string symbol = 2;
+    string type = 3;
+}
+
+message CountResponse {
+    int64 count = 1;
+    repeated int64 positions = 2;
+    string text = 3;
+}
+
+message WatchRequest {
+    repeated Text.Kind kinds = 1;
+}
+
+message UploadResponse {}
+
+message Text {
+    string user_id = 1;
+    Kind kind = 2;
+    google.protobuf.Timestamp created = 3;
+    map<string, Text> replies = 4;
+    optional double score = 5;
+    other.Unknown unknown = 6;
+
+    enum Kind {
+        KIND_UNSPECIFIED = 0;
+        KIND_PLAIN = 1;
+    }
+}
+`
+
+func TestGenerateFromProto(t *testing.T) {
+	file, err := protofile.Parse("api.proto", []byte(fromProtoTestFile))
+	require.NoError(t, err)
+	src, err := GenerateFromProto(file, FromProtoOptions{PackageName: "stringsvc", Tags: []string{"grpc", "logging"}})
+	require.NoError(t, err)
+	code := string(src)
+	for _, s := range []string{
+		"// @microgen grpc, logging\n// @protobuf example.com/stringsvc/pb\n// @grpc-addr stringsvc.StringService\ntype StringService interface {",
+		"// Counts symbols.\n\tCount(ctx context.Context, text string, symbol string, type_ string) (count int64, positions []int64, text_ string, err error)",
+		"Ping(ctx context.Context) (err error)",
+		"// TODO: microgen expects message GetResponse, but rpc Get uses Text\n\tGet(ctx context.Context, value *string) (text *Text, err error)",
+		"// @microgen one-to-many\n\tWatch(kinds []Text_Kind, stream pb.StringService_WatchServer) (err error)",
+		"// @microgen many-to-one\n\tUpload(stream pb.StringService_UploadServer) (err error)",
+		"type Text struct {\n\tUserID  string\n\tKind    Text_Kind\n\tCreated time.Time\n\tReplies map[string]*Text\n\tScore   *float64\n\tUnknown interface{} /* TODO: type other.Unknown is not declared in api.proto */\n}",
+		"type Text_Kind int32",
+		"Text_KindUnspecified Text_Kind = 0",
+		"Text_KindPlain       Text_Kind = 1",
+	} {
+		assert.Contains(t, code, s)
+	}
+
+	// Generated file should be a valid source of generation.
+	source := filepath.Join(t.TempDir(), "service.go")
+	require.NoError(t, ioutil.WriteFile(source, src, 0644))
+	parsed, err := astra.ParseFile(source)
+	require.NoError(t, err)
+	require.Len(t, parsed.Interfaces, 1)
+	assert.NoError(t, ValidateInterface(&parsed.Interfaces[0], nil))
+	// Generated file is validated against the same protobuf file: only Get, which is marked with TODO, does not match it.
+	assert.EqualError(t, ValidateInterfaceProto(&parsed.Interfaces[0], source, file),
+		source+":19:2: Get: message of results should be GetResponse, but rpc uses Text (api.proto:15:5)")
+
+	_, err = GenerateFromProto(&protofile.File{Name: "empty.proto"}, FromProtoOptions{PackageName: "svc"})
+	assert.EqualError(t, err, "empty.proto: no services declared")
+}
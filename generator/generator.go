@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
 
 	"github.com/recolabs/microgen/generator/template"
 	"github.com/recolabs/microgen/generator/write_strategy"
@@ -47,20 +48,46 @@ func NewGenUnit(ctx context.Context, tmpl template.Template, outPath string) (*G
 }
 
 func (g *GenerationUnit) Generate(ctx context.Context) error {
+	return g.generate(ctx, g.writeStrategy)
+}
+
+// GenerateInMemory renders unit to files instead of disk. Files get the same content, as Generate would write.
+func (g *GenerationUnit) GenerateInMemory(ctx context.Context, files *write_strategy.Files) error {
+	if g.writeStrategy == nil {
+		return EmptyStrategyError
+	}
+	return g.generate(ctx, write_strategy.NewMemoryStrategy(files, g.writeStrategy))
+}
+
+func (g *GenerationUnit) generate(ctx context.Context, strategy write_strategy.Strategy) error {
 	if g.template == nil {
 		return EmptyTemplateError
 	}
-	if g.writeStrategy == nil {
+	if strategy == nil {
 		return EmptyStrategyError
 	}
 	code := g.template.Render(ctx)
-	err := g.writeStrategy.Write(code)
+	err := strategy.Write(code)
 	if err != nil {
 		return fmt.Errorf("write error: %v", err)
 	}
 	return nil
 }
 
+// File returns absolute path of file, that unit generates, and whether the whole file is generated.
+// Files, that are only appended by microgen, may contain user code.
+// Returns empty path for templates, which do not produce files.
+func (g *GenerationUnit) File() (path string, whole bool) {
+	action, path := write_strategy.ActionOf(g.writeStrategy)
+	if action == "" {
+		if g.template.DefaultPath() == "" {
+			return "", false
+		}
+		return filepath.Join(g.absOutPath, g.template.DefaultPath()), false
+	}
+	return path, action == write_strategy.OverwriteAction
+}
+
 func (g GenerationUnit) Path() string {
 	return g.absOutPath
 }
@@ -1,21 +1,37 @@
 package generator
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
 
-	"context"
-
-	strings2 "github.com/recolabs/microgen/generator/strings"
+	mstrings "github.com/recolabs/microgen/generator/strings"
 	"github.com/recolabs/microgen/generator/template"
+	"github.com/recolabs/microgen/generator/write_strategy"
 	"github.com/stretchr/testify/assert"
 	"github.com/vetcher/go-astra"
 	"github.com/vetcher/go-astra/types"
 )
 
+var update = flag.Bool("update", false, "rewrite golden files of test_assets with generated code")
+
+const (
+	testAssetsDir = "test_assets"
+	testOutDir    = "test_out"
+	// Import path of testOutDir, generated code of cases is built inside of module.
+	testOutPackage = "github.com/recolabs/microgen/generator/" + testOutDir
+	// Source of case: file with interface, which has @microgen tag.
+	testSourceFile = "service.go.txt"
+	// Optional pb.go file of case for protobuf converters, it is copied to pb package of case.
+	testPbGoFile = "pb.go.txt"
+)
+
 func findInterface(file *types.File, ifaceName string) *types.Interface {
 	for i := range file.Interfaces {
 		if file.Interfaces[i].Name == ifaceName {
@@ -41,20 +57,35 @@ func TestTemplates(t *testing.T) {
 	outPath := "./test_out/"
 	sourcePath := "./test_assets/service.go.txt"
 	absSourcePath, err := filepath.Abs(sourcePath)
-	importPackagePath, err := resolvePackagePath(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
 	iface, err := loadInterface(sourcePath, "StringService")
 	if err != nil {
 		t.Fatal(err)
 	}
+	defer os.RemoveAll(outPath)
 
+	allowedMethods := make(map[string]bool, len(iface.Methods))
+	for _, fn := range iface.Methods {
+		allowedMethods[fn.Name] = true
+	}
 	genInfo := &template.GenerationInfo{
-		SourcePackageImport:   importPackagePath,
+		SourcePackageImport:   testOutPackage,
 		Iface:                 iface,
+		OutputPackageImport:   testOutPackage,
 		OutputFilePath:        outPath,
 		SourceFilePath:        absSourcePath,
-		ProtobufPackageImport: strings2.FetchMetaInfo(TagMark+ProtobufTag, iface.Docs),
+		FileHeader:            defaultFileHeader,
+		AllowedMethods:        allowedMethods,
+		ProtobufPackageImport: mstrings.FetchMetaInfo(TagMark+ProtobufTag, iface.Docs),
 	}
 	t.Log("protobuf pkg", genInfo.ProtobufPackageImport)
+	set := template.TagsSet{}
+	for _, tag := range mstrings.FetchTags(iface.Docs, TagMark+MicrogenMainTag) {
+		set.Add(tag)
+	}
+	ctx := template.WithTags(context.Background(), set)
 
 	allTemplateTests := []struct {
 		TestName    string
@@ -64,12 +95,12 @@ func TestTemplates(t *testing.T) {
 		{
 			TestName:    "Endpoints",
 			Template:    template.NewEndpointsTemplate(genInfo),
-			OutFilePath: "transport_endpoints.go.txt",
+			OutFilePath: "endpoints.go.txt",
 		},
 		{
 			TestName:    "Exchange",
 			Template:    template.NewExchangeTemplate(genInfo),
-			OutFilePath: "transport_exchanges.go.txt",
+			OutFilePath: "exchanges.go.txt",
 		},
 		{
 			TestName:    "Middleware",
@@ -101,20 +132,20 @@ func TestTemplates(t *testing.T) {
 			Template:    template.NewStubGRPCTypeConverterTemplate(genInfo),
 			OutFilePath: "grpc_type.go.txt",
 		},
+		{
+			TestName:    "OpenAPI",
+			Template:    template.NewOpenAPITemplate(genInfo),
+			OutFilePath: "openapi.yaml.txt",
+		},
 	}
 	for _, test := range allTemplateTests {
 		t.Run(test.TestName, func(t *testing.T) {
-			expected, err := ioutil.ReadFile("test_assets/" + test.OutFilePath)
-			if err != nil {
-				t.Fatalf("read expected file error: %v", err)
-			}
-
 			absOutPath := "./test_out/"
-			gen, err := NewGenUnit(context.Background(), test.Template, absOutPath)
+			gen, err := NewGenUnit(ctx, test.Template, absOutPath)
 			if err != nil {
 				t.Fatalf("NewGenUnit: %v", err)
 			}
-			err = gen.Generate(context.Background())
+			err = gen.Generate(ctx)
 			if err != nil {
 				t.Fatalf("unable to generate: %v", err)
 			}
@@ -122,6 +153,16 @@ func TestTemplates(t *testing.T) {
 			if err != nil {
 				t.Fatalf("read actual file error: %v", err)
 			}
+			if *update {
+				if err := ioutil.WriteFile("test_assets/"+test.OutFilePath, actual, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			expected, err := ioutil.ReadFile("test_assets/" + test.OutFilePath)
+			if err != nil {
+				t.Fatalf("read expected file error: %v", err)
+			}
 			assert.Equal(t,
 				strings.Split(string(expected[:]), "\n"),
 				strings.Split(string(actual[:]), "\n"),
@@ -129,3 +170,357 @@ func TestTemplates(t *testing.T) {
 		})
 	}
 }
+
+// Every case is a directory of test_assets with service.go.txt and golden files of feature, which it tests:
+// path of golden file is path of generated file relative to output directory with .txt suffix.
+// Existing goldens are rewritten by go test -run TestCases -update, new golden is added as empty file before it.
+func TestCases(t *testing.T) {
+	allTemplateTests := []struct {
+		TestName string
+		Dir      string
+		// Build generated code with source and pb package of pb.go.txt.
+		Build bool
+		// pb.go.txt is only built and is not passed to generator: validation compares types of pb.go file by names
+		// and expects request messages for methods without arguments, so it rejects well-known types and empty requests.
+		BuildPbOnly bool
+	}{
+		{
+			TestName: "HTTP binding",
+			Dir:      "http_binding",
+			Build:    true,
+		},
+		{
+			TestName: "Errors",
+			Dir:      "errors",
+			Build:    true,
+		},
+		{
+			TestName: "HTTP stream",
+			Dir:      "http_stream",
+			Build:    true,
+		},
+		{
+			TestName: "Protobuf converters",
+			Dir:      "protobuf_converters",
+			Build:    true,
+		},
+		{
+			TestName:    "Protobuf API v2",
+			Dir:         "protobuf_apiv2",
+			Build:       true,
+			BuildPbOnly: true,
+		},
+		{
+			TestName:    "OpenTelemetry",
+			Dir:         "opentelemetry",
+			Build:       true,
+			BuildPbOnly: true,
+		},
+		{
+			TestName: "Service discovery",
+			Dir:      "service_discovery",
+			Build:    true,
+		},
+		{
+			TestName: "Rate limit",
+			Dir:      "ratelimit",
+			Build:    true,
+		},
+		{
+			TestName:    "Retry and circuit breaker",
+			Dir:         "retry",
+			Build:       true,
+			BuildPbOnly: true,
+		},
+	}
+	for _, test := range allTemplateTests {
+		test := test
+		t.Run(test.TestName, func(t *testing.T) {
+			files, outPath := generateTestCase(t, test.Dir, !test.BuildPbOnly)
+			compareGoldenFiles(t, files, outPath, filepath.Join(testAssetsDir, test.Dir))
+			if test.Build {
+				buildTestCase(t, files, outPath)
+			}
+		})
+	}
+}
+
+// generateTestCase renders all files of case to memory the same way, as cli does.
+// Source and pb.go.txt are copied to test_out, so generated code may be built with them.
+// Parsed pb.go.txt is passed to generator, when withPbGoFile is true.
+func generateTestCase(t *testing.T, dir string, withPbGoFile bool) (*write_strategy.Files, string) {
+	outPath, err := filepath.Abs(filepath.Join(testOutDir, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(outPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(outPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(outPath) })
+	source, err := ioutil.ReadFile(filepath.Join(testAssetsDir, dir, testSourceFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sourcePath := filepath.Join(outPath, "service.go")
+	if err := ioutil.WriteFile(sourcePath, source, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := astra.ParseFile(sourcePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var iface *types.Interface
+	for i := range file.Interfaces {
+		if mstrings.HasTag(file.Interfaces[i].Docs, TagMark+MicrogenMainTag) {
+			iface = &file.Interfaces[i]
+			break
+		}
+	}
+	if iface == nil {
+		t.Fatalf("%s: could not find interface with @microgen tag", dir)
+	}
+	var pbGoFile *types.File
+	if pbGoPath := filepath.Join(testAssetsDir, dir, testPbGoFile); fileExists(pbGoPath) {
+		if withPbGoFile {
+			pbGoFile, err = astra.ParseFile(pbGoPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		pbGo, err := ioutil.ReadFile(pbGoPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(outPath, "pb"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(outPath, "pb", "pb.go"), pbGo, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ValidateInterface(iface, pbGoFile); err != nil {
+		t.Fatalf("validation: %v", err)
+	}
+
+	packagePath := testOutPackage + "/" + dir
+	ctx := template.WithSourcePackageImport(context.Background(), packagePath)
+	set := template.TagsSet{}
+	for _, tag := range mstrings.FetchTags(iface.Docs, TagMark+MicrogenMainTag) {
+		set.Add(tag)
+	}
+	ctx = template.WithTags(ctx, set)
+
+	files := write_strategy.NewFiles()
+	template.SetPendingFiles(files)
+	t.Cleanup(func() { template.SetPendingFiles(nil) })
+	units, err := ListTemplatesForGen(ctx, iface, "", outPath, sourcePath, packagePath, "", false, pbGoFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, unit := range units {
+		if err := unit.GenerateInMemory(ctx, files); err != nil && err != EmptyStrategyError {
+			path, _ := unit.File()
+			t.Fatalf("%s: %v", path, err)
+		}
+	}
+	return files, outPath
+}
+
+// compareGoldenFiles checks, that every golden file of case is generated and equal to generated file.
+// Generated files without golden files are not compared, they are checked by build of case.
+func compareGoldenFiles(t *testing.T, files *write_strategy.Files, outPath, goldenDir string) {
+	err := filepath.Walk(goldenDir, func(golden string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Dir(golden) == goldenDir && (info.Name() == testSourceFile || info.Name() == testPbGoFile) {
+			return err
+		}
+		rel, err := filepath.Rel(goldenDir, strings.TrimSuffix(golden, ".txt"))
+		if err != nil {
+			return err
+		}
+		actual, ok := files.Get(filepath.Join(outPath, rel))
+		if !ok {
+			t.Errorf("%s is not generated", rel)
+			return nil
+		}
+		if *update {
+			return ioutil.WriteFile(golden, actual, 0644)
+		}
+		expected, err := ioutil.ReadFile(golden)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t,
+			strings.Split(string(expected), "\n"),
+			strings.Split(string(actual), "\n"),
+			rel,
+		)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// buildTestCase writes generated files near the source and compiles them.
+func buildTestCase(t *testing.T, files *write_strategy.Files, outPath string) {
+	if err := files.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("go", "build", "./...")
+	cmd.Dir = outPath
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v\n%s", err, out)
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Converters of protobuf_converters case have TODO only for fields, which have no matching or convertible field in pb.
+func TestStructConvertersTODO(t *testing.T) {
+	files, outPath := generateTestCase(t, "protobuf_converters", true)
+	converters, ok := files.Get(filepath.Join(outPath, "transport", "grpc", "protobuf_type_converters.microgen.go"))
+	if !ok {
+		t.Fatal("converters are not generated")
+	}
+	var todo []string
+	for _, line := range strings.Split(string(converters), "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, "// TODO") {
+			todo = append(todo, line)
+		}
+	}
+	unmatched := []string{
+		"// TODO: can not convert Rating of type float64 to Rating of type string",
+		"// TODO: Secret has no matching field in pb.Comment",
+	}
+	// Both of PtrCommentToProto and ProtoToPtrComment.
+	assert.Equal(t, append(unmatched, unmatched...), todo)
+}
+
+// Test of generated converters, which decodes message with unset fields.
+const unsetFieldsTest = `package transportgrpc
+
+import (
+	"testing"
+
+	pb "` + testOutPackage + `/protobuf_converters/pb"
+)
+
+func TestProtoToPtrCommentUnsetFields(t *testing.T) {
+	comment, err := ProtoToPtrComment(&pb.Comment{Id: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comment.ID != "1" || !comment.Created.IsZero() || comment.TTL != 0 || comment.Edited != nil || comment.Note != nil {
+		t.Fatalf("unexpected comment: %+v", comment)
+	}
+	created, err := ProtoToPtrTimeTime(nil)
+	if err != nil || created != nil {
+		t.Fatalf("unexpected time: %v, %v", created, err)
+	}
+}
+`
+
+// Unset message fields of pb.Comment are decoded to zero values without errors.
+func TestStructConvertersUnsetFields(t *testing.T) {
+	files, outPath := generateTestCase(t, "protobuf_converters", true)
+	if err := files.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(outPath, "transport", "grpc")
+	if err := ioutil.WriteFile(filepath.Join(dir, "unset_fields_test.go"), []byte(unsetFieldsTest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("go", "test", ".")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go test: %v\n%s", err, out)
+	}
+}
+
+const appendUserTemplate = `---
+tag: audit
+path: audit.go
+strategy: append
+---
+{{range .Methods}}
+// Audit{{.Name}} is name of audited method.
+const Audit{{.Name}} = "{{.Name}}"
+
+func (a *auditor) {{.Name}}() string { return Audit{{.Name}} }
+
+func audit{{.Name}}() string { return (&auditor{}).{{.Name}}() }
+{{end}}`
+
+// User template with append strategy appends only declarations, which do not exist, so package builds after every run.
+func TestUserTemplateAppend(t *testing.T) {
+	outPath, err := filepath.Abs(filepath.Join(testOutDir, "user_append"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(outPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(outPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(outPath) })
+	sourcePath := filepath.Join(outPath, "service.go")
+	source := "package svc\n\nimport \"context\"\n\n// @microgen audit\ntype AuditService interface {\n\tFoo(ctx context.Context) (err error)\n\tBar(ctx context.Context) (err error)\n}\n"
+	if err := ioutil.WriteFile(sourcePath, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	auditPath := filepath.Join(outPath, "audit.go")
+	if err := ioutil.WriteFile(auditPath, []byte("package svc\n\ntype auditor struct{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := template.ParseUserTemplate("audit.tmpl", []byte(appendUserTemplate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	iface, err := loadInterface(sourcePath, "AuditService")
+	if err != nil {
+		t.Fatal(err)
+	}
+	packagePath := testOutPackage + "/user_append"
+	ctx := template.WithSourcePackageImport(context.Background(), packagePath)
+	ctx = template.WithTags(ctx, template.TagsSet{"audit": {}})
+	ctx = template.WithUserTemplates(ctx, []*template.UserTemplate{tmpl})
+	t.Cleanup(func() { template.SetPendingFiles(nil) })
+
+	for run := 0; run < 2; run++ {
+		files := write_strategy.NewFiles()
+		template.SetPendingFiles(files)
+		units, err := ListTemplatesForGen(ctx, iface, "", outPath, sourcePath, packagePath, "", false, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, unit := range units {
+			if err := unit.GenerateInMemory(ctx, files); err != nil && err != EmptyStrategyError {
+				t.Fatal(err)
+			}
+		}
+		if err := files.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	audit, err := ioutil.ReadFile(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, decl := range []string{"const AuditFoo", "func (a *auditor) Foo()", "func auditFoo()"} {
+		assert.Equal(t, 1, strings.Count(string(audit), decl), decl)
+	}
+	cmd := exec.Command("go", "build", "./...")
+	cmd.Dir = outPath
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v\n%s", err, out)
+	}
+}
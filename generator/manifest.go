@@ -0,0 +1,193 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/recolabs/microgen/generator/write_strategy"
+	lg "github.com/recolabs/microgen/logger"
+)
+
+const (
+	ManifestFileName = ".microgen.json"
+
+	RemoveFileMark = "Remove"
+)
+
+// Manifest lists files, which microgen generated into output directory, with hashes of their content.
+// It is used to find files, which are not generated anymore.
+// Manifest tracks whole files only: functions, which microgen appended to file, are not tracked,
+// so functions of removed methods are never removed from appended files and should be removed by hand.
+type Manifest struct {
+	Version string `json:"version"`
+	// Files are keyed by slash separated path, relative to output directory.
+	Files map[string]ManifestEntry `json:"files"`
+}
+
+type ManifestEntry struct {
+	Hash string `json:"hash"`
+	// Append is true for files, which microgen only appends to, so they may contain user code and stale functions.
+	Append bool `json:"append,omitempty"`
+}
+
+// Orphan is a file from manifest, which is not generated anymore.
+type Orphan struct {
+	Path string
+	// Name is a path relative to output directory.
+	Name string
+	// Edited is true, when file was changed after generation or may contain user code, so it should not be removed.
+	Edited bool
+}
+
+// LoadManifest reads manifest from output directory. Returns empty manifest, when there is no manifest file.
+func LoadManifest(dir string) (*Manifest, error) {
+	m := &Manifest{Files: make(map[string]ManifestEntry)}
+	buffer, err := ioutil.ReadFile(filepath.Join(dir, ManifestFileName))
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(buffer, m); err != nil {
+		return nil, fmt.Errorf("%s: %v", ManifestFileName, err)
+	}
+	if m.Files == nil {
+		m.Files = make(map[string]ManifestEntry)
+	}
+	return m, nil
+}
+
+// Save writes manifest to output directory.
+func (m *Manifest) Save(dir string) error {
+	buffer, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, ManifestFileName), append(buffer, '\n'), 0644)
+}
+
+// Orphans returns files of manifest, which are not generated by units.
+func (m *Manifest) Orphans(dir string, units []*GenerationUnit) ([]Orphan, error) {
+	owned := make(map[string]bool, len(units))
+	for _, unit := range units {
+		if path, _ := unit.File(); path != "" {
+			owned[manifestName(dir, path)] = true
+		}
+	}
+	var orphans []Orphan
+	for _, name := range sortedKeys(m.Files) {
+		if owned[name] {
+			continue
+		}
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		hash, err := hashFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		entry := m.Files[name]
+		orphans = append(orphans, Orphan{
+			Path:   path,
+			Name:   name,
+			Edited: entry.Append || entry.Hash != hash,
+		})
+	}
+	return orphans, nil
+}
+
+// UpdateManifest writes manifest with files, generated by units, to output directory.
+// Orphaned files of previous manifest are removed, when they were not edited,
+// edited ones are kept in manifest and reported on every run.
+func UpdateManifest(dir string, units []*GenerationUnit) error {
+	old, err := LoadManifest(dir)
+	if err != nil {
+		return err
+	}
+	orphans, err := old.Orphans(dir, units)
+	if err != nil {
+		return err
+	}
+	m := &Manifest{Version: Version, Files: make(map[string]ManifestEntry)}
+	for _, o := range orphans {
+		if o.Edited {
+			lg.Logger.Logln(0, "Warning:", o.Name, "is not generated anymore, but it may contain user changes, so it was not removed")
+			m.Files[o.Name] = old.Files[o.Name]
+			continue
+		}
+		if err := removeFile(dir, o.Path); err != nil {
+			return err
+		}
+		lg.Logger.Logln(1, RemoveFileMark, o.Path)
+	}
+	for _, unit := range units {
+		path, whole := unit.File()
+		if path == "" {
+			continue
+		}
+		name := manifestName(dir, path)
+		if action, _ := write_strategy.ActionOf(unit.writeStrategy); action == "" {
+			// File was not written in this run (e.g. once file exists), so it keeps entry of the run, which generated it.
+			if entry, ok := old.Files[name]; ok {
+				m.Files[name] = entry
+			}
+			continue
+		}
+		hash, err := hashFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		m.Files[name] = ManifestEntry{Hash: hash, Append: !whole}
+	}
+	return m.Save(dir)
+}
+
+// Removes file and its parent directories inside dir, which became empty.
+func removeFile(dir, path string) error {
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	for parent := filepath.Dir(path); parent != dir && len(parent) > len(dir); parent = filepath.Dir(parent) {
+		if os.Remove(parent) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+func manifestName(dir, path string) string {
+	name, err := filepath.Rel(dir, path)
+	if err != nil {
+		name = path
+	}
+	return filepath.ToSlash(name)
+}
+
+func hashFile(path string) (string, error) {
+	buffer, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(buffer)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+func sortedKeys(m map[string]ManifestEntry) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
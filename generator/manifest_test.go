@@ -0,0 +1,141 @@
+package generator
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/recolabs/microgen/generator/write_strategy"
+	"github.com/stretchr/testify/assert"
+)
+
+type fileTemplate struct {
+	path, code string
+}
+
+func (t fileTemplate) Prepare(context.Context) error { return nil }
+func (t fileTemplate) DefaultPath() string           { return t.path }
+func (t fileTemplate) ChooseStrategy(context.Context) (write_strategy.Strategy, error) {
+	return write_strategy.NewCreateFileStrategy("", t.path), nil
+}
+func (t fileTemplate) Render(context.Context) write_strategy.Renderer { return stringRenderer(t.code) }
+
+func generateFiles(t *testing.T, dir string, paths ...string) []*GenerationUnit {
+	ctx := context.Background()
+	var units []*GenerationUnit
+	for _, path := range paths {
+		unit, err := NewGenUnit(ctx, fileTemplate{path: filepath.Join(dir, path), code: "package a\n"}, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := unit.Generate(ctx); err != nil {
+			t.Fatal(err)
+		}
+		units = append(units, unit)
+	}
+	if err := UpdateManifest(dir, units); err != nil {
+		t.Fatal(err)
+	}
+	return units
+}
+
+func TestUpdateManifest(t *testing.T) {
+	dir := t.TempDir()
+	generateFiles(t, dir, "a.go", "sub/b.go", "c.go")
+
+	m, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, Version, m.Version)
+	assert.Len(t, m.Files, 3)
+	assert.Contains(t, m.Files, "sub/b.go")
+
+	// c.go was edited by user, so it should be kept.
+	if err := ioutil.WriteFile(filepath.Join(dir, "c.go"), []byte("package a\n\nfunc C() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	units := generateFiles(t, dir, "a.go")
+
+	_, err = os.Stat(filepath.Join(dir, "sub"))
+	assert.True(t, os.IsNotExist(err), "sub/b.go and empty sub directory should be removed")
+	_, err = os.Stat(filepath.Join(dir, "c.go"))
+	assert.NoError(t, err)
+
+	m, err = LoadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, m.Files, 2)
+	orphans, err := m.Orphans(dir, units)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []Orphan{{Path: filepath.Join(dir, "c.go"), Name: "c.go", Edited: true}}, orphans)
+}
+
+// onceTemplate generates file only, when it does not exist.
+type onceTemplate struct {
+	dir, path string
+}
+
+func (t onceTemplate) Prepare(context.Context) error { return nil }
+func (t onceTemplate) DefaultPath() string           { return t.path }
+func (t onceTemplate) ChooseStrategy(context.Context) (write_strategy.Strategy, error) {
+	if _, err := os.Stat(filepath.Join(t.dir, t.path)); err == nil {
+		return write_strategy.NewNopStrategy(t.dir, t.path), nil
+	}
+	return write_strategy.NewCreateFileStrategy(t.dir, t.path), nil
+}
+func (t onceTemplate) Render(context.Context) write_strategy.Renderer {
+	return stringRenderer("package a\n")
+}
+
+func generateOnce(t *testing.T, dir string, paths ...string) {
+	ctx := context.Background()
+	var units []*GenerationUnit
+	for _, path := range paths {
+		unit, err := NewGenUnit(ctx, onceTemplate{dir: dir, path: path}, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := unit.Generate(ctx); err != nil && err != EmptyStrategyError {
+			t.Fatal(err)
+		}
+		units = append(units, unit)
+	}
+	if err := UpdateManifest(dir, units); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpdateManifestOnce(t *testing.T) {
+	dir := t.TempDir()
+	// user.go is created by user, so it is not generated and not recorded.
+	if err := ioutil.WriteFile(filepath.Join(dir, "user.go"), []byte("package a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	generateOnce(t, dir, "once.go", "user.go")
+	first, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"once.go"}, sortedKeys(first.Files))
+
+	// once.go exists, so it is not written, but keeps entry of the first run.
+	generateOnce(t, dir, "once.go", "user.go")
+	m, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, first.Files, m.Files)
+
+	// once.go is not generated anymore and was not edited, so it is removed.
+	generateOnce(t, dir)
+	_, err = os.Stat(filepath.Join(dir, "once.go"))
+	assert.True(t, os.IsNotExist(err), "once.go should be removed")
+	_, err = os.Stat(filepath.Join(dir, "user.go"))
+	assert.NoError(t, err)
+}
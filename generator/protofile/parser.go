@@ -0,0 +1,641 @@
+package protofile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseFile reads and parses protobuf file.
+func ParseFile(filename string) (*File, error) {
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(filename, src)
+}
+
+// Parse parses protobuf file content. Filename is used in positions of declarations and errors.
+func Parse(filename string, src []byte) (*File, error) {
+	tokens, err := scan(filename, string(src))
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	file := &File{Name: filename, Options: make(map[string]string)}
+	if err := p.parseFile(file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenSymbol
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   Pos
+	// Comments, which are placed on lines before token.
+	comments []string
+}
+
+// scan splits source to tokens. Comments are attached to the next token, when they are placed on lines right before it.
+func scan(filename, src string) ([]token, error) {
+	var (
+		tokens     []token
+		comments   []string
+		commentEnd int
+		line       = 1
+		col        = 1
+		i          int
+	)
+	advance := func(n int) {
+		for _, r := range src[i : i+n] {
+			if r == '\n' {
+				line++
+				col = 1
+			} else {
+				col++
+			}
+		}
+		i += n
+	}
+	emit := func(kind tokenKind, value string, pos Pos) {
+		t := token{kind: kind, value: value, pos: pos}
+		if len(comments) > 0 && commentEnd >= pos.Line-1 {
+			t.comments = comments
+		}
+		comments = nil
+		tokens = append(tokens, t)
+	}
+	comment := func(start int, text []string) {
+		// Empty line separates comments.
+		if start > commentEnd+1 {
+			comments = nil
+		}
+		comments = append(comments, text...)
+		commentEnd = line
+	}
+	for i < len(src) {
+		c := src[i]
+		pos := Pos{Filename: filename, Line: line, Column: col}
+		switch {
+		case unicode.IsSpace(rune(c)):
+			advance(1)
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			text := strings.TrimSpace(strings.TrimPrefix(src[i:i+end], "//"))
+			advance(end)
+			// Trailing comment belongs to previous declaration.
+			if len(tokens) == 0 || tokens[len(tokens)-1].pos.Line != pos.Line {
+				comment(pos.Line, []string{text})
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("%s: comment is not terminated", pos)
+			}
+			var text []string
+			for _, s := range strings.Split(src[i+2:i+2+end], "\n") {
+				if s = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), "*")); s != "" {
+					text = append(text, s)
+				}
+			}
+			advance(end + 4)
+			comment(pos.Line, text)
+		case c == '"' || c == '\'':
+			j := i + 1
+			for ; j < len(src) && src[j] != c && src[j] != '\n'; j++ {
+				if src[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(src) || src[j] != c {
+				return nil, fmt.Errorf("%s: string is not terminated", pos)
+			}
+			quoted := src[i : j+1]
+			if c == '\'' {
+				quoted = `"` + strings.ReplaceAll(strings.ReplaceAll(src[i+1:j], `\'`, `'`), `"`, `\"`) + `"`
+			}
+			value, err := strconv.Unquote(quoted)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid string: %v", pos, err)
+			}
+			advance(j + 1 - i)
+			emit(tokenString, value, pos)
+		case isIdentStart(c) || c == '.' && i+1 < len(src) && isIdentStart(src[i+1]):
+			j := i + 1
+			for j < len(src) && (isIdentPart(src[j]) || src[j] == '.') {
+				j++
+			}
+			value := src[i:j]
+			advance(j - i)
+			emit(tokenIdent, value, pos)
+		case isDigit(c) || (c == '-' || c == '.') && i+1 < len(src) && isDigit(src[i+1]):
+			j := i + 1
+			for j < len(src) && (isIdentPart(src[j]) || src[j] == '.' ||
+				(src[j] == '-' || src[j] == '+') && (src[j-1] == 'e' || src[j-1] == 'E')) {
+				j++
+			}
+			value := src[i:j]
+			advance(j - i)
+			emit(tokenNumber, value, pos)
+		case strings.IndexByte("{}()<>[];=,:-+", c) >= 0:
+			advance(1)
+			emit(tokenSymbol, string(c), pos)
+		default:
+			return nil, fmt.Errorf("%s: unexpected character %q", pos, c)
+		}
+	}
+	tokens = append(tokens, token{kind: tokenEOF, pos: Pos{Filename: filename, Line: line, Column: col}})
+	return tokens, nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+type parser struct {
+	tokens []token
+	i      int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokenEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) is(value string) bool {
+	t := p.peek()
+	return t.kind != tokenString && t.value == value
+}
+
+func (p *parser) expect(value string) error {
+	t := p.next()
+	if t.kind == tokenString || t.value != value {
+		return unexpected(t, value)
+	}
+	return nil
+}
+
+func (p *parser) ident() (token, error) {
+	t := p.next()
+	if t.kind != tokenIdent {
+		return t, unexpected(t, "identifier")
+	}
+	return t, nil
+}
+
+func (p *parser) number() (int, error) {
+	t := p.next()
+	if t.kind != tokenNumber {
+		return 0, unexpected(t, "number")
+	}
+	n, err := strconv.ParseInt(t.value, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid number %s", t.pos, t.value)
+	}
+	return int(n), nil
+}
+
+func unexpected(t token, expected string) error {
+	if t.kind == tokenEOF {
+		return fmt.Errorf("%s: unexpected end of file, expected %s", t.pos, expected)
+	}
+	return fmt.Errorf("%s: unexpected %q, expected %s", t.pos, t.value, expected)
+}
+
+func (p *parser) parseFile(file *File) error {
+	for {
+		t := p.peek()
+		if t.kind == tokenEOF {
+			return nil
+		}
+		var err error
+		switch t.value {
+		case "syntax":
+			p.next()
+			if err = p.expect("="); err == nil {
+				file.Syntax = p.next().value
+				err = p.expect(";")
+			}
+		case "package":
+			p.next()
+			var name token
+			if name, err = p.ident(); err == nil {
+				file.Package = name.value
+				err = p.expect(";")
+			}
+		case "import":
+			p.next()
+			if p.is("public") || p.is("weak") {
+				p.next()
+			}
+			path := p.next()
+			if path.kind != tokenString {
+				return unexpected(path, "import path")
+			}
+			file.Imports = append(file.Imports, path.value)
+			err = p.expect(";")
+		case "option":
+			var name, value string
+			if name, value, err = p.parseOption(); err == nil {
+				file.Options[name] = value
+			}
+		case "message":
+			var m *Message
+			if m, err = p.parseMessage(""); err == nil {
+				file.Messages = append(file.Messages, m)
+			}
+		case "enum":
+			var e *Enum
+			if e, err = p.parseEnum(""); err == nil {
+				file.Enums = append(file.Enums, e)
+			}
+		case "service":
+			var s *Service
+			if s, err = p.parseService(); err == nil {
+				file.Services = append(file.Services, s)
+			}
+		case "extend":
+			err = p.skipDeclaration()
+		case ";":
+			p.next()
+		default:
+			return unexpected(t, "declaration")
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Parses `option name = value;` statement. Values of aggregate options are skipped.
+func (p *parser) parseOption() (string, string, error) {
+	p.next()
+	var name strings.Builder
+	for !p.is("=") {
+		t := p.next()
+		if t.kind == tokenEOF || p.is(";") {
+			return "", "", unexpected(p.peek(), "=")
+		}
+		name.WriteString(t.value)
+	}
+	p.next()
+	if p.is("{") {
+		if err := p.skipBlock(); err != nil {
+			return "", "", err
+		}
+		return name.String(), "", p.expect(";")
+	}
+	value := p.next().value
+	for p.peek().kind == tokenString {
+		value += p.next().value
+	}
+	return name.String(), value, p.expect(";")
+}
+
+// Skips field options in square brackets.
+func (p *parser) skipFieldOptions() error {
+	if !p.is("[") {
+		return nil
+	}
+	depth := 0
+	for {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return unexpected(t, "]")
+		case t.kind == tokenSymbol && (t.value == "[" || t.value == "{"):
+			depth++
+		case t.kind == tokenSymbol && (t.value == "]" || t.value == "}"):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// Skips block in braces, current token should be `{`.
+func (p *parser) skipBlock() error {
+	depth := 0
+	for {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return unexpected(t, "}")
+		case t.kind == tokenSymbol && t.value == "{":
+			depth++
+		case t.kind == tokenSymbol && t.value == "}":
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// Skips statement till `;` or declaration with block.
+func (p *parser) skipDeclaration() error {
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokenEOF:
+			return unexpected(t, ";")
+		case t.kind == tokenSymbol && t.value == ";":
+			p.next()
+			return nil
+		case t.kind == tokenSymbol && t.value == "{":
+			return p.skipBlock()
+		}
+		p.next()
+	}
+}
+
+func (p *parser) parseMessage(parent string) (*Message, error) {
+	keyword := p.next()
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	m := &Message{Name: name.value, FullName: joinName(parent, name.value), Comments: keyword.comments, Pos: keyword.pos}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	if err := p.parseMessageBody(m, ""); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Parses fields and declarations of message till the closing brace. Fields of oneof are added to message.
+func (p *parser) parseMessageBody(m *Message, oneOf string) error {
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokenSymbol && t.value == "}":
+			p.next()
+			return nil
+		case t.kind == tokenSymbol && t.value == ";":
+			p.next()
+		case t.kind != tokenIdent:
+			return unexpected(t, "field")
+		case t.value == "message" && oneOf == "":
+			nested, err := p.parseMessage(m.FullName)
+			if err != nil {
+				return err
+			}
+			m.Messages = append(m.Messages, nested)
+		case t.value == "enum" && oneOf == "":
+			nested, err := p.parseEnum(m.FullName)
+			if err != nil {
+				return err
+			}
+			m.Enums = append(m.Enums, nested)
+		case t.value == "oneof" && oneOf == "":
+			p.next()
+			name, err := p.ident()
+			if err != nil {
+				return err
+			}
+			if err := p.expect("{"); err != nil {
+				return err
+			}
+			if err := p.parseMessageBody(m, name.value); err != nil {
+				return err
+			}
+		case t.value == "option":
+			if _, _, err := p.parseOption(); err != nil {
+				return err
+			}
+		case t.value == "reserved" || t.value == "extensions" || t.value == "extend":
+			if err := p.skipDeclaration(); err != nil {
+				return err
+			}
+		default:
+			field, err := p.parseField()
+			if err != nil {
+				return err
+			}
+			field.OneOf = oneOf
+			m.Fields = append(m.Fields, field)
+		}
+	}
+}
+
+//		repeated string tags = 1 [deprecated = true];
+//		map<string, Project> projects = 3;
+//
+func (p *parser) parseField() (*Field, error) {
+	first := p.peek()
+	f := &Field{Comments: first.comments, Pos: first.pos}
+	if p.is(LabelRepeated) || p.is(LabelOptional) || p.is(LabelRequired) {
+		f.Label = p.next().value
+	}
+	if p.is("map") && p.tokens[p.i+1].value == "<" {
+		p.next()
+		p.next()
+		key, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		value, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(">"); err != nil {
+			return nil, err
+		}
+		f.KeyType, f.Type = key.value, value.value
+	} else {
+		typ, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if typ.value == "group" {
+			return nil, fmt.Errorf("%s: groups are not supported", typ.pos)
+		}
+		f.Type = typ.value
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	f.Name = name.value
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	if f.Number, err = p.number(); err != nil {
+		return nil, err
+	}
+	if err := p.skipFieldOptions(); err != nil {
+		return nil, err
+	}
+	return f, p.expect(";")
+}
+
+func (p *parser) parseEnum(parent string) (*Enum, error) {
+	keyword := p.next()
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	e := &Enum{Name: name.value, FullName: joinName(parent, name.value), Comments: keyword.comments, Pos: keyword.pos}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokenSymbol && t.value == "}":
+			p.next()
+			return e, nil
+		case t.kind == tokenSymbol && t.value == ";":
+			p.next()
+		case t.value == "option":
+			if _, _, err := p.parseOption(); err != nil {
+				return nil, err
+			}
+		case t.value == "reserved":
+			if err := p.skipDeclaration(); err != nil {
+				return nil, err
+			}
+		default:
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			number, err := p.number()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.skipFieldOptions(); err != nil {
+				return nil, err
+			}
+			if err := p.expect(";"); err != nil {
+				return nil, err
+			}
+			e.Values = append(e.Values, &EnumValue{Name: name.value, Number: number, Comments: name.comments, Pos: name.pos})
+		}
+	}
+}
+
+//		service StringService {
+//		    rpc Count (CountRequest) returns (CountResponse);
+//		    rpc Watch (WatchRequest) returns (stream WatchResponse) {}
+//		}
+//
+func (p *parser) parseService() (*Service, error) {
+	keyword := p.next()
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	s := &Service{Name: name.value, Comments: keyword.comments, Pos: keyword.pos}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokenSymbol && t.value == "}":
+			p.next()
+			return s, nil
+		case t.kind == tokenSymbol && t.value == ";":
+			p.next()
+		case t.value == "option":
+			if _, _, err := p.parseOption(); err != nil {
+				return nil, err
+			}
+		case t.value == "rpc":
+			rpc, err := p.parseRPC()
+			if err != nil {
+				return nil, err
+			}
+			s.RPCs = append(s.RPCs, rpc)
+		default:
+			return nil, unexpected(t, "rpc")
+		}
+	}
+}
+
+func (p *parser) parseRPC() (*RPC, error) {
+	keyword := p.next()
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	rpc := &RPC{Name: name.value, Comments: keyword.comments, Pos: keyword.pos}
+	if rpc.Request, rpc.ClientStreaming, err = p.parseRPCType(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("returns"); err != nil {
+		return nil, err
+	}
+	if rpc.Response, rpc.ServerStreaming, err = p.parseRPCType(); err != nil {
+		return nil, err
+	}
+	if p.is("{") {
+		return rpc, p.skipBlock()
+	}
+	return rpc, p.expect(";")
+}
+
+func (p *parser) parseRPCType() (string, bool, error) {
+	if err := p.expect("("); err != nil {
+		return "", false, err
+	}
+	stream := false
+	// `stream` may be a name of message type.
+	if p.is("stream") && p.tokens[p.i+1].kind == tokenIdent {
+		p.next()
+		stream = true
+	}
+	typ, err := p.ident()
+	if err != nil {
+		return "", false, err
+	}
+	return typ.value, stream, p.expect(")")
+}
+
+func joinName(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
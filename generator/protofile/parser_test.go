@@ -0,0 +1,150 @@
+package protofile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testProto = `// File comment.
+
+syntax = "proto3";
+
+package api.v1;
+
+import "google/protobuf/timestamp.proto";
+import public "other.proto";
+
+option go_package = "example.com/api/v1/pb;pb";
+option (custom.file_opt) = { a: 1 b: "}" };
+
+// Comments of service.
+service CommentService {
+    option deprecated = true;
+    // Creates comment.
+    rpc Create (CreateRequest) returns (CreateResponse);
+    rpc Watch (WatchRequest) returns (stream Comment) {
+        option (google.api.http) = { get: "/v1/comments" };
+    }
+    rpc Chat (stream .api.v1.Comment) returns (stream Comment);
+}
+
+message CreateRequest {
+    Comment comment = 1;
+}
+
+message CreateResponse {
+    string id = 1; // trailing comment
+}
+
+message WatchRequest {}
+
+/* Comment
+ * of message. */
+message Comment {
+    reserved 2, 15 to max;
+    reserved "old";
+    string id = 1 [json_name = "ID"];
+    repeated string tags = 3;
+    map<string, Author> authors = 4;
+    optional int64 rating = 5;
+    google.protobuf.Timestamp created = 6;
+    oneof body {
+        string text = 7;
+        bytes raw = 8;
+    }
+    Kind kind = 9;
+
+    message Author {
+        string name = 1;
+        Role role = 2;
+
+        enum Role {
+            ROLE_UNSPECIFIED = 0;
+            ROLE_ADMIN = 1;
+        }
+    }
+}
+
+enum Kind {
+    option allow_alias = true;
+    KIND_UNSPECIFIED = 0;
+    KIND_PLAIN = 1;
+    KIND_TEXT = 1 [deprecated = true];
+    KIND_NEGATIVE = -1;
+}
+`
+
+func TestParse(t *testing.T) {
+	file, err := Parse("api.proto", []byte(testProto))
+	require.NoError(t, err)
+	assert.Equal(t, "proto3", file.Syntax)
+	assert.Equal(t, "api.v1", file.Package)
+	assert.Equal(t, []string{"google/protobuf/timestamp.proto", "other.proto"}, file.Imports)
+	path, name := file.GoPackage()
+	assert.Equal(t, "example.com/api/v1/pb", path)
+	assert.Equal(t, "pb", name)
+
+	require.Len(t, file.Services, 1)
+	svc := file.Service("CommentService")
+	require.NotNil(t, svc)
+	assert.Equal(t, []string{"Comments of service."}, svc.Comments)
+	require.Len(t, svc.RPCs, 3)
+	assert.Equal(t, &RPC{
+		Name: "Create", Request: "CreateRequest", Response: "CreateResponse",
+		Comments: []string{"Creates comment."}, Pos: Pos{Filename: "api.proto", Line: 17, Column: 5},
+	}, svc.RPCs[0])
+	assert.True(t, svc.RPCs[1].ServerStreaming)
+	assert.False(t, svc.RPCs[1].ClientStreaming)
+	assert.True(t, svc.RPCs[2].ClientStreaming)
+	assert.Equal(t, ".api.v1.Comment", svc.RPCs[2].Request)
+
+	assert.Len(t, file.Message("CreateResponse").Fields, 1)
+	assert.Nil(t, file.Message("CreateResponse").Fields[0].Comments)
+	assert.Empty(t, file.Message("WatchRequest").Fields)
+
+	comment := file.Message(".api.v1.Comment")
+	require.NotNil(t, comment)
+	assert.Equal(t, []string{"Comment", "of message."}, comment.Comments)
+	require.Len(t, comment.Fields, 8)
+	assert.Equal(t, "id", comment.Fields[0].Name)
+	assert.True(t, comment.Fields[1].IsRepeated())
+	assert.Equal(t, &Field{
+		Name: "authors", Type: "Author", KeyType: "string", Number: 4,
+		Pos: Pos{Filename: "api.proto", Line: 41, Column: 5},
+	}, comment.Fields[2])
+	assert.Equal(t, LabelOptional, comment.Fields[3].Label)
+	assert.Equal(t, "google.protobuf.Timestamp", comment.Fields[4].Type)
+	assert.Equal(t, "body", comment.Fields[5].OneOf)
+	assert.Equal(t, 8, comment.Fields[6].Number)
+
+	author := file.Message("Comment.Author")
+	require.NotNil(t, author)
+	assert.Equal(t, "Comment.Author", author.FullName)
+	assert.Equal(t, author, file.Message("Author"))
+	role := file.Enum("Role")
+	require.NotNil(t, role)
+	assert.Equal(t, "Comment.Author.Role", role.FullName)
+	assert.Len(t, file.AllMessages(), 5)
+
+	kind := file.Enum("api.v1.Kind")
+	require.NotNil(t, kind)
+	require.Len(t, kind.Values, 4)
+	assert.Equal(t, 1, kind.Values[2].Number)
+	assert.Equal(t, -1, kind.Values[3].Number)
+}
+
+func TestParseErrors(t *testing.T) {
+	for src, msg := range map[string]string{
+		`message A { string a = ; }`:      `a.proto:1:24: unexpected ";", expected number`,
+		`service S { rpc A (B) returns }`: `a.proto:1:31: unexpected "}", expected (`,
+		`message A {`:                     `a.proto:1:12: unexpected end of file, expected field`,
+		`syntax = "proto3`:                `a.proto:1:10: string is not terminated`,
+	} {
+		_, err := Parse("a.proto", []byte(src))
+		if assert.Error(t, err, src) {
+			assert.Equal(t, msg, err.Error())
+		}
+	}
+}
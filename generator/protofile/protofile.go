@@ -0,0 +1,210 @@
+// Package protofile parses protobuf files without protoc.
+// It understands declarations of proto2 and proto3 files, which are needed to describe gRPC services:
+// services, messages, enums, their fields and options. Custom options and extensions are skipped.
+package protofile
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	LabelRepeated = "repeated"
+	LabelOptional = "optional"
+	LabelRequired = "required"
+)
+
+// Pos is a position of declaration in protobuf file.
+type Pos struct {
+	Filename string
+	Line     int
+	Column   int
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+}
+
+type File struct {
+	Name    string
+	Syntax  string
+	Package string
+	Imports []string
+	// Options of file by names, e.g. `go_package`.
+	Options  map[string]string
+	Services []*Service
+	Messages []*Message
+	Enums    []*Enum
+}
+
+type Service struct {
+	Name     string
+	Comments []string
+	RPCs     []*RPC
+	Pos      Pos
+}
+
+type RPC struct {
+	Name            string
+	Request         string
+	Response        string
+	ClientStreaming bool
+	ServerStreaming bool
+	Comments        []string
+	Pos             Pos
+}
+
+type Message struct {
+	Name string
+	// Name with names of parent messages, separated by dot: `Outer.Inner`.
+	FullName string
+	Fields   []*Field
+	Messages []*Message
+	Enums    []*Enum
+	Comments []string
+	Pos      Pos
+}
+
+type Field struct {
+	Name   string
+	Type   string
+	Number int
+	// One of LabelRepeated, LabelOptional, LabelRequired or empty string.
+	Label string
+	// Type of map key, when field is a map. Type is a type of map value.
+	KeyType string
+	// Name of oneof, which contains field.
+	OneOf    string
+	Comments []string
+	Pos      Pos
+}
+
+func (f *Field) IsMap() bool {
+	return f.KeyType != ""
+}
+
+func (f *Field) IsRepeated() bool {
+	return f.Label == LabelRepeated
+}
+
+type Enum struct {
+	Name     string
+	FullName string
+	Values   []*EnumValue
+	Comments []string
+	Pos      Pos
+}
+
+type EnumValue struct {
+	Name     string
+	Number   int
+	Comments []string
+	Pos      Pos
+}
+
+// GoPackage returns import path from `go_package` option without package name.
+//
+//		option go_package = "github.com/user/stringsvc/pb;pb";
+//
+func (f *File) GoPackage() (path string, name string) {
+	opt := f.Options["go_package"]
+	if i := strings.Index(opt, ";"); i >= 0 {
+		return opt[:i], opt[i+1:]
+	}
+	return opt, ""
+}
+
+// Service returns service by name or nil.
+func (f *File) Service(name string) *Service {
+	for _, s := range f.Services {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// Message resolves name of message type, which may be qualified with package and parent messages.
+// Returns nil, when message is not declared in file.
+func (f *File) Message(name string) *Message {
+	var found *Message
+	f.resolve(name, func(messages []*Message, _ []*Enum, last string) bool {
+		for _, m := range messages {
+			if m.Name == last {
+				found = m
+				return true
+			}
+		}
+		return false
+	})
+	return found
+}
+
+// Enum resolves name of enum type, as Message does.
+func (f *File) Enum(name string) *Enum {
+	var found *Enum
+	f.resolve(name, func(_ []*Message, enums []*Enum, last string) bool {
+		for _, e := range enums {
+			if e.Name == last {
+				found = e
+				return true
+			}
+		}
+		return false
+	})
+	return found
+}
+
+// Walks by path of name from the top level of file. When name is not found by path,
+// nested declarations are searched by the last part of name.
+func (f *File) resolve(name string, find func(messages []*Message, enums []*Enum, last string) bool) {
+	name = strings.TrimPrefix(name, ".")
+	if f.Package != "" {
+		name = strings.TrimPrefix(name, f.Package+".")
+	}
+	parts := strings.Split(name, ".")
+	messages, enums := f.Messages, f.Enums
+	for i, part := range parts {
+		if i == len(parts)-1 {
+			if find(messages, enums, part) {
+				return
+			}
+			break
+		}
+		var next *Message
+		for _, m := range messages {
+			if m.Name == part {
+				next = m
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		messages, enums = next.Messages, next.Enums
+	}
+	var walk func(messages []*Message) bool
+	walk = func(messages []*Message) bool {
+		for _, m := range messages {
+			if find(m.Messages, m.Enums, parts[len(parts)-1]) || walk(m.Messages) {
+				return true
+			}
+		}
+		return false
+	}
+	walk(f.Messages)
+}
+
+// AllMessages returns messages of file with nested messages, parents go first.
+func (f *File) AllMessages() []*Message {
+	var all []*Message
+	var walk func(messages []*Message)
+	walk = func(messages []*Message) {
+		for _, m := range messages {
+			all = append(all, m)
+			walk(m.Messages)
+		}
+	}
+	walk(f.Messages)
+	return all
+}
@@ -0,0 +1,394 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/recolabs/microgen/generator/template"
+)
+
+// TemplateFactory returns templates of tag for service.
+type TemplateFactory func(info *template.GenerationInfo) []template.Template
+
+// TagSpec describes generation tag of `// @microgen` comment.
+type TagSpec struct {
+	Tag         string
+	Description string
+	// Requires are tags, which are turned on together with this tag, e.g. logging requires middleware.
+	Requires []string
+	// Conflicts are tags, which can not be used together with this tag.
+	Conflicts []string
+	// Factory returns templates of tag. It may be nil for tags, which only turn on other tags.
+	Factory TemplateFactory
+	// Deprecated is a warning, which is printed, when tag is used.
+	Deprecated string
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]TagSpec)
+)
+
+// RegisterTag adds tag to registry. Third-party modules register their tags from wrapper of microgen main:
+//
+//		func main() {
+//			generator.RegisterTag(generator.TagSpec{
+//				Tag:      "audit",
+//				Requires: []string{generator.MiddlewareTag},
+//				Factory: func(info *template.GenerationInfo) []template.Template {
+//					return []template.Template{NewAuditTemplate(info)}
+//				},
+//			})
+//			cli.Main()
+//		}
+//
+// It panics, when tag is empty or already registered.
+func RegisterTag(spec TagSpec) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if spec.Tag == "" {
+		panic("microgen: RegisterTag with empty tag")
+	}
+	if _, dup := registry[spec.Tag]; dup {
+		panic("microgen: RegisterTag called twice for tag " + spec.Tag)
+	}
+	registry[spec.Tag] = spec
+}
+
+// LookupTag returns registered tag.
+func LookupTag(tag string) (TagSpec, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	spec, ok := registry[tag]
+	return spec, ok
+}
+
+// RegisteredTags returns sorted names of all registered tags.
+func RegisteredTags() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	tags := make([]string, 0, len(registry))
+	for tag := range registry {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// ResolveTags returns sorted tags with all tags, which they require.
+// Tags, which are not registered, are returned in unknown list.
+// Returns error, when resolved tags conflict with each other or requirement is not registered.
+func ResolveTags(tags []string) (resolved, unknown []string, err error) {
+	set := make(map[string]bool)
+	var visit func(tag, by string) error
+	visit = func(tag, by string) error {
+		if set[tag] {
+			return nil
+		}
+		spec, ok := LookupTag(tag)
+		if !ok {
+			if by != "" {
+				return fmt.Errorf("tag %s requires unknown tag %s", by, tag)
+			}
+			unknown = append(unknown, tag)
+			return nil
+		}
+		set[tag] = true
+		for _, req := range spec.Requires {
+			if err := visit(req, tag); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, tag := range tags {
+		if err := visit(tag, ""); err != nil {
+			return nil, nil, err
+		}
+	}
+	for tag := range set {
+		resolved = append(resolved, tag)
+	}
+	sort.Strings(resolved)
+	for _, tag := range resolved {
+		spec, _ := LookupTag(tag)
+		for _, c := range spec.Conflicts {
+			if set[c] {
+				return nil, nil, fmt.Errorf("tag %s conflicts with tag %s", tag, c)
+			}
+		}
+	}
+	return resolved, unknown, nil
+}
+
+// TagGraph returns tab separated lines with every registered tag, all tags, that it turns on,
+// its conflicts and description.
+//
+//		logging	middleware		Logging middleware.
+//
+func TagGraph() []string {
+	var lines []string
+	for _, tag := range RegisteredTags() {
+		spec, _ := LookupTag(tag)
+		resolved, _, err := ResolveTags([]string{tag})
+		requires := make([]string, 0, len(resolved))
+		for _, r := range resolved {
+			if r != tag {
+				requires = append(requires, r)
+			}
+		}
+		line := []string{tag, strings.Join(requires, ", "), strings.Join(spec.Conflicts, ", "), spec.Description}
+		if err != nil {
+			line[1] = "error: " + err.Error()
+		}
+		if spec.Deprecated != "" {
+			line[3] = "deprecated: " + spec.Deprecated
+		}
+		lines = append(lines, strings.Join(line, "\t"))
+	}
+	return lines
+}
+
+func templates(tt ...func(info *template.GenerationInfo) template.Template) TemplateFactory {
+	return func(info *template.GenerationInfo) []template.Template {
+		tmpls := make([]template.Template, len(tt))
+		for i := range tt {
+			tmpls[i] = tt[i](info)
+		}
+		return tmpls
+	}
+}
+
+// Typed errors of transport are rendered only for services with @errors tag.
+func errorsTemplate(info *template.GenerationInfo) template.Template {
+	if !template.HasErrorsTag(info.Iface.Docs) {
+		return template.EmptyTemplate{}
+	}
+	return template.NewErrorsTemplate(info)
+}
+
+// Stream methods over http are rendered only for services with stream methods.
+func httpStreamTemplate(info *template.GenerationInfo) template.Template {
+	if !template.HasStreamMethods(info) {
+		return template.EmptyTemplate{}
+	}
+	return template.NewHttpStreamTemplate(info)
+}
+
+func init() {
+	for _, spec := range []TagSpec{
+		{
+			Tag:         MiddlewareTag,
+			Description: "Middleware type for service.",
+			Factory:     templates(template.NewMiddlewareTemplate),
+		},
+		{
+			Tag:         LoggingMiddlewareTag,
+			Description: "Logging middleware.",
+			Requires:    []string{MiddlewareTag},
+			Factory:     templates(template.NewLoggingTemplate),
+		},
+		{
+			Tag:         RecoveringMiddlewareTag,
+			Description: "Middleware, which recovers panics.",
+			Requires:    []string{MiddlewareTag},
+			Factory:     templates(template.NewRecoverTemplate),
+		},
+		{
+			Tag:         ErrorLoggingMiddlewareTag,
+			Description: "Middleware, which logs errors.",
+			Requires:    []string{MiddlewareTag},
+			Factory:     templates(template.NewErrorLoggingTemplate),
+		},
+		{
+			Tag:         CachingMiddlewareTag,
+			Description: "Caching middleware.",
+			Requires:    []string{MiddlewareTag},
+			Factory:     templates(template.NewCacheMiddlewareTemplate),
+		},
+		{
+			Tag:         RateLimitMiddlewareTag,
+			Description: "Middleware, which limits rate of calls of methods with @rate-limit tag.",
+			Requires:    []string{MiddlewareTag},
+			Factory:     templates(template.NewRateLimitTemplate),
+		},
+		{
+			Tag:         MetricsMiddlewareTag,
+			Description: "Prometheus metrics middleware.",
+			Requires:    []string{MiddlewareTag},
+			Factory:     templates(template.NewMetricsTemplate),
+		},
+		{
+			Tag:         TracingMiddlewareTag,
+			Description: "Opentracing in endpoints and main.",
+			Conflicts:   []string{OpenTelemetryTag},
+			Deprecated:  "it does not generate tracing middleware, use opentelemetry tag instead.",
+		},
+		{
+			Tag:         OpenTelemetryTag,
+			Description: "OpenTelemetry tracing middleware, trace context propagation in transports and exporter in main.",
+			Requires:    []string{MiddlewareTag},
+			Conflicts:   []string{TracingMiddlewareTag},
+			Factory:     templates(template.NewTracingTemplate),
+		},
+		{
+			Tag:         ServiceDiscoveryTag,
+			Description: "HTTP and gRPC clients, balanced between instances of service discovery.",
+			Requires:    []string{TransportClient},
+			Factory:     templates(template.NewServiceDiscoveryTemplate),
+		},
+		{
+			Tag:         CircuitBreakerTag,
+			Description: "Wrapping of client endpoints with circuit breakers.",
+			Requires:    []string{TransportClient},
+			Factory:     templates(template.NewCircuitBreakerTemplate),
+		},
+		{
+			Tag:         RetryTag,
+			Description: "Retries of client endpoints of idempotent methods with @retry tag.",
+			Requires:    []string{TransportClient},
+			Factory:     templates(template.NewRetryTemplate),
+		},
+		{
+			Tag:         Transport,
+			Description: "Exchanges and endpoints for client and server.",
+			Factory: templates(
+				template.NewExchangeTemplate,
+				template.NewEndpointsTemplate,
+				template.NewEndpointsClientTemplate,
+				template.NewEndpointsServerTemplate,
+			),
+		},
+		{
+			Tag:         TransportClient,
+			Description: "Exchanges and endpoints for client.",
+			Factory: templates(
+				template.NewExchangeTemplate,
+				template.NewEndpointsTemplate,
+				template.NewEndpointsClientTemplate,
+			),
+		},
+		{
+			Tag:         TransportServer,
+			Description: "Exchanges and endpoints for server.",
+			Factory: templates(
+				template.NewExchangeTemplate,
+				template.NewEndpointsTemplate,
+				template.NewEndpointsServerTemplate,
+			),
+		},
+		{
+			Tag:         GrpcTag,
+			Description: "gRPC client and server.",
+			Requires:    []string{Transport},
+			Factory: templates(
+				template.NewGRPCClientTemplate,
+				template.NewGRPCServerTemplate,
+				template.NewGRPCEndpointConverterTemplate,
+				template.NewStubGRPCTypeConverterTemplate,
+				errorsTemplate,
+			),
+		},
+		{
+			Tag:         GrpcClientTag,
+			Description: "gRPC client.",
+			Requires:    []string{TransportClient},
+			Factory: templates(
+				template.NewGRPCClientTemplate,
+				template.NewGRPCEndpointConverterTemplate,
+				template.NewStubGRPCTypeConverterTemplate,
+				errorsTemplate,
+			),
+		},
+		{
+			Tag:         GrpcServerTag,
+			Description: "gRPC server.",
+			Requires:    []string{TransportServer},
+			Factory: templates(
+				template.NewGRPCServerTemplate,
+				template.NewGRPCEndpointConverterTemplate,
+				template.NewStubGRPCTypeConverterTemplate,
+				errorsTemplate,
+			),
+		},
+		{
+			Tag:         HttpTag,
+			Description: "HTTP client and server.",
+			Requires:    []string{Transport},
+			Factory: templates(
+				template.NewHttpServerTemplate,
+				template.NewHttpClientTemplate,
+				template.NewHttpConverterTemplate,
+				httpStreamTemplate,
+				errorsTemplate,
+			),
+		},
+		{
+			Tag:         HttpServerTag,
+			Description: "HTTP server.",
+			Requires:    []string{TransportServer},
+			Factory: templates(
+				template.NewHttpServerTemplate,
+				template.NewHttpConverterTemplate,
+				httpStreamTemplate,
+				errorsTemplate,
+			),
+		},
+		{
+			Tag:         HttpClientTag,
+			Description: "HTTP client.",
+			Requires:    []string{TransportClient},
+			Factory: templates(
+				template.NewHttpClientTemplate,
+				template.NewHttpConverterTemplate,
+				httpStreamTemplate,
+				errorsTemplate,
+			),
+		},
+		{
+			Tag:         JSONRPCTag,
+			Description: "JSON-RPC client and server.",
+			Requires:    []string{Transport},
+			Factory: templates(
+				template.NewJSONRPCServerTemplate,
+				template.NewJSONRPCClientTemplate,
+				template.NewJSONRPCEndpointConverterTemplate,
+			),
+		},
+		{
+			Tag:         JSONRPCServerTag,
+			Description: "JSON-RPC server.",
+			Requires:    []string{TransportServer},
+			Factory: templates(
+				template.NewJSONRPCServerTemplate,
+				template.NewJSONRPCEndpointConverterTemplate,
+			),
+		},
+		{
+			Tag:         JSONRPCClientTag,
+			Description: "JSON-RPC client.",
+			Requires:    []string{TransportClient},
+			Factory: templates(
+				template.NewJSONRPCClientTemplate,
+				template.NewJSONRPCEndpointConverterTemplate,
+			),
+		},
+		{
+			Tag:         OpenAPITag,
+			Description: "OpenAPI 3 specification of HTTP transport.",
+			Factory:     templates(template.NewOpenAPITemplate),
+		},
+		{
+			Tag:         ProtobufAPIv2Tag,
+			Description: "gRPC converters use well-known types of google.golang.org/protobuf.",
+		},
+		{
+			Tag:        MainTag,
+			Deprecated: "use flag -main instead.",
+		},
+	} {
+		RegisterTag(spec)
+	}
+}
@@ -0,0 +1,48 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/recolabs/microgen/generator/template"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveTags(t *testing.T) {
+	resolved, unknown, err := ResolveTags([]string{LoggingMiddlewareTag, GrpcClientTag, "unknown-tag"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{GrpcClientTag, LoggingMiddlewareTag, MiddlewareTag, TransportClient}, resolved)
+	assert.Equal(t, []string{"unknown-tag"}, unknown)
+
+	// tracing tag generates no middleware, so users are warned about it.
+	spec, _ := LookupTag(TracingMiddlewareTag)
+	assert.NotEmpty(t, spec.Deprecated)
+	assert.Contains(t, TagGraph(), "tracing		opentelemetry	deprecated: "+spec.Deprecated)
+}
+
+func TestRegisterTag(t *testing.T) {
+	RegisterTag(TagSpec{
+		Tag:       "test-audit",
+		Requires:  []string{LoggingMiddlewareTag},
+		Conflicts: []string{CachingMiddlewareTag},
+		Factory: func(info *template.GenerationInfo) []template.Template {
+			return []template.Template{template.EmptyTemplate{}}
+		},
+	})
+	RegisterTag(TagSpec{Tag: "test-broken", Requires: []string{"test-missing"}})
+
+	resolved, _, err := ResolveTags([]string{"test-audit"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{LoggingMiddlewareTag, MiddlewareTag, "test-audit"}, resolved)
+
+	_, _, err = ResolveTags([]string{CachingMiddlewareTag, "test-audit"})
+	assert.EqualError(t, err, "tag test-audit conflicts with tag caching")
+	_, _, err = ResolveTags([]string{"test-broken"})
+	assert.EqualError(t, err, "tag test-broken requires unknown tag test-missing")
+
+	assert.Contains(t, TagGraph(), "test-audit\tlogging, middleware\tcaching\t")
+	assert.Panics(t, func() { RegisterTag(TagSpec{Tag: "test-audit"}) })
+}
@@ -97,3 +97,40 @@ func LastUpperOrFirst(str string) string {
 	}
 	return string(str[0])
 }
+
+// Initialisms, which are written in upper case by ToUpperCamelCase and ToLowerCamelCase, as golint suggests.
+var commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true, "EOF": true, "GUID": true,
+	"HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true, "QPS": true, "RAM": true,
+	"RPC": true, "SLA": true, "SMTP": true, "SQL": true, "SSH": true, "TCP": true, "TLS": true, "TTL": true,
+	"UDP": true, "UI": true, "UID": true, "UUID": true, "URI": true, "URL": true, "UTF8": true, "VM": true,
+	"XML": true, "XMPP": true, "XSRF": true, "XSS": true,
+}
+
+// ToUpperCamelCase converts snake_case to UpperCamelCase: user_id -> UserID.
+func ToUpperCamelCase(s string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(s, isExtendedSpace) {
+		if upper := strings.ToUpper(word); commonInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		b.WriteString(ToUpperFirst(word))
+	}
+	return b.String()
+}
+
+// ToLowerCamelCase converts snake_case to lowerCamelCase: user_id -> userID, id_list -> idList.
+func ToLowerCamelCase(s string) string {
+	words := strings.FieldsFunc(s, isExtendedSpace)
+	if len(words) == 0 {
+		return ""
+	}
+	first := words[0]
+	if commonInitialisms[strings.ToUpper(first)] {
+		first = strings.ToLower(first)
+	} else {
+		first = ToLowerFirst(first)
+	}
+	return first + ToUpperCamelCase(strings.Join(words[1:], "_"))
+}
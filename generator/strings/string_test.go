@@ -61,3 +61,21 @@ func TestLastUpperOrFirst(t *testing.T) {
 		}
 	}
 }
+
+func TestCamelCase(t *testing.T) {
+	for s, ans := range map[string][2]string{
+		"user_id":     {"UserID", "userID"},
+		"id_list":     {"IDList", "idList"},
+		"name":        {"Name", "name"},
+		"createdAt":   {"CreatedAt", "createdAt"},
+		"http_status": {"HTTPStatus", "httpStatus"},
+		"_private":    {"Private", "private"},
+	} {
+		if upper := ToUpperCamelCase(s); upper != ans[0] {
+			t.Error(s, ":", upper, "!=", ans[0])
+		}
+		if lower := ToLowerCamelCase(s); lower != ans[1] {
+			t.Error(s, ":", lower, "!=", ans[1])
+		}
+	}
+}
@@ -14,8 +14,11 @@ const (
 	nameInterruptHandler = "InterruptHandler"
 	nameMain             = "main"
 	nameInitLogger       = "InitLogger"
+	nameInitTracer       = "InitTracer"
 	nameServeGRPC        = "ServeGRPC"
 	nameServeHTTP        = "ServeHTTP"
+	nameServeJSONRPC     = "ServeJSONRPC"
+	nameServeMetrics     = "ServeMetrics"
 )
 
 const (
@@ -40,15 +43,19 @@ func (t *mainTemplate) Render(ctx context.Context) write_strategy.Renderer {
 	f := &Statement{}
 	f.Line().Add(t.mainFunc(ctx))
 	f.Line().Add(t.initLogger())
+	f.Line().Add(t.initTracer(ctx))
 	f.Line().Add(t.interruptHandler())
 	f.Line().Add(t.serveGrpc(ctx))
 	f.Line().Add(t.serveHTTP(ctx))
+	f.Line().Add(t.serveJSONRPC(ctx))
+	f.Line().Add(t.serveMetrics(ctx))
 
 	if t.state == AppendStrat {
 		return f
 	}
 
 	file := NewFile("main")
+	file.ImportAlias(PackagePathOTelSDKTrace, "sdktrace")
 	file.PackageComment(`Microgen appends missed functions.`)
 	file.Add(f)
 
@@ -117,6 +124,15 @@ func (t *mainTemplate) mainFunc(ctx context.Context) *Statement {
 		}
 		main.Id(_logger_).Dot("Log").Call(Lit("message"), Lit("Hello, I am alive"))
 		main.Defer().Id(_logger_).Dot("Log").Call(Lit("message"), Lit("goodbye, good luck"))
+		if Tags(ctx).Has(OpenTelemetryTag) {
+			main.Line()
+			main.List(Id("tracerProvider"), Err()).Op(":=").Id(nameInitTracer).Call(Qual(PackagePathContext, "Background").Call(), Lit(mstrings.ToSnakeCase(t.Info.Iface.Name)))
+			main.If(Err().Op("!=").Nil()).Block(
+				Id(_logger_).Dot("Log").Call(Lit("error"), Err()),
+				Return(),
+			)
+			main.Defer().Id("tracerProvider").Dot("Shutdown").Call(Qual(PackagePathContext, "Background").Call())
+		}
 		main.Line()
 		main.List(Id("g"), Id(_ctx_)).Op(":=").Qual(PackagePathSyncErrgroup, "WithContext").Call(Qual(PackagePathContext, "Background").Call())
 		main.Id("g").Dot("Go").Call(
@@ -132,24 +148,39 @@ func (t *mainTemplate) mainFunc(ctx context.Context) *Statement {
 		//		Qual(filepath.Join(t.Info.SourcePackageImport, PathService), CachingMiddlewareName).Call(Id("errorLogger")).Call(Id(_service_)).
 		//		Comment(`Setup service caching.`)
 		//}
+		if Tags(ctx).Has(RateLimitMiddlewareTag) {
+			main.Id(_service_).Op("=").
+				Qual(filepath.Join(t.Info.OutputPackageImport, PathService), t.Info.nsName(ServiceRateLimitingMiddlewareName)).Call().Call(Id(_service_)).
+				Comment(`Setup rate limiting of methods.`)
+		}
 		if Tags(ctx).Has(LoggingMiddlewareTag) {
 			main.Id(_service_).Op("=").
-				Qual(filepath.Join(t.Info.OutputPackageImport, PathService), ServiceLoggingMiddlewareName).Call(Id(_logger_)).Call(Id(_service_)).
+				Qual(filepath.Join(t.Info.OutputPackageImport, PathService), t.Info.nsName(ServiceLoggingMiddlewareName)).Call(Id(_logger_)).Call(Id(_service_)).
 				Comment(`Setup service logging.`)
 		}
 		if Tags(ctx).Has(ErrorLoggingMiddlewareTag) {
 			main.Id(_service_).Op("=").
-				Qual(filepath.Join(t.Info.OutputPackageImport, PathService), ServiceErrorLoggingMiddlewareName).Call(Id(_logger_)).Call(Id(_service_)).
+				Qual(filepath.Join(t.Info.OutputPackageImport, PathService), t.Info.nsName(ServiceErrorLoggingMiddlewareName)).Call(Id(_logger_)).Call(Id(_service_)).
 				Comment(`Setup error logging.`)
 		}
+		if Tags(ctx).Has(MetricsMiddlewareTag) {
+			main.Id(_service_).Op("=").
+				Qual(filepath.Join(t.Info.OutputPackageImport, PathService), t.Info.nsName(ServicePrometheusMetricsMiddlewareName)).Call(Lit(mstrings.ToSnakeCase(t.Info.Iface.Name))).Call(Id(_service_)).
+				Comment(`Setup service metrics.`)
+		}
+		if Tags(ctx).Has(OpenTelemetryTag) {
+			main.Id(_service_).Op("=").
+				Qual(filepath.Join(t.Info.OutputPackageImport, PathService), t.Info.nsName(ServiceTracingMiddlewareName)).Call(Qual(PackagePathOTel, "Tracer").Call(Lit(mstrings.ToSnakeCase(t.Info.Iface.Name)))).Call(Id(_service_)).
+				Comment(`Setup service tracing.`)
+		}
 		if Tags(ctx).Has(RecoveringMiddlewareTag) {
 			main.Id(_service_).Op("=").
-				Qual(filepath.Join(t.Info.OutputPackageImport, PathService), ServiceRecoveringMiddlewareName).Call(Id("errorLogger")).Call(Id(_service_)).
+				Qual(filepath.Join(t.Info.OutputPackageImport, PathService), t.Info.nsName(ServiceRecoveringMiddlewareName)).Call(Id("errorLogger")).Call(Id(_service_)).
 				Comment(`Setup service recovering.`)
 		}
-		main.Line().Id("endpoints").Op(":=").Qual(t.Info.OutputPackageImport+"/transport", "Endpoints").Call(t.endpointsParams(ctx))
+		main.Line().Id("endpoints").Op(":=").Qual(t.Info.OutputPackageImport+"/transport", t.Info.nsName("Endpoints")).Call(t.endpointsParams(ctx))
 		if Tags(ctx).HasAny(TracingMiddlewareTag) {
-			main.Id("endpoints").Op("=").Qual(t.Info.OutputPackageImport+"/transport", "TraceServerEndpoints").Call(
+			main.Id("endpoints").Op("=").Qual(t.Info.OutputPackageImport+"/transport", t.Info.nsName("TraceServerEndpoints")).Call(
 				Id("endpoints"),
 				Qual(PackagePathOpenTracingGo, "NoopTracer{}"),
 			).Comment("TODO: Add tracer")
@@ -184,6 +215,35 @@ func (t *mainTemplate) mainFunc(ctx context.Context) *Statement {
 				),
 			)
 		}
+		if Tags(ctx).HasAny(JSONRPCTag, JSONRPCServerTag) {
+			main.Line()
+			main.Id("jsonrpcAddr").Op(":=").Lit(":8082").Comment("TODO: use normal address")
+			main.Comment(`Start json-rpc server.`)
+			main.Id("g").Dot("Go").Call(
+				Func().Params().Params(Error()).Block(
+					Return().Id(nameServeJSONRPC).Call(
+						Id(_ctx_),
+						Op("&").Id("endpoints"),
+						Id("jsonrpcAddr"),
+						Qual(PackagePathGoKitLog, "With").Call(Id(_logger_), Lit("transport"), Lit("JSONRPC")),
+					),
+				),
+			)
+		}
+		if Tags(ctx).Has(MetricsMiddlewareTag) {
+			main.Line()
+			main.Id("metricsAddr").Op(":=").Lit(":9090").Comment("TODO: use normal address")
+			main.Comment(`Start metrics server.`)
+			main.Id("g").Dot("Go").Call(
+				Func().Params().Params(Error()).Block(
+					Return().Id(nameServeMetrics).Call(
+						Id(_ctx_),
+						Id("metricsAddr"),
+						Qual(PackagePathGoKitLog, "With").Call(Id(_logger_), Lit("transport"), Lit("metrics")),
+					),
+				),
+			)
+		}
 		main.Line()
 		main.If(Err().Op(":=").Id("g").Dot("Wait").Call(), Err().Op("!=").Nil()).Block(
 			Id(_logger_).Dot("Log").Call(Lit("error"), Err()),
@@ -211,6 +271,58 @@ func (t *mainTemplate) initLogger() *Statement {
 	})
 }
 
+// Renders something like this
+//		// InitTracer registers global tracer provider of OpenTelemetry and W3C trace context propagator.
+//		// Spans are exported to OTLP collector, when OTEL_EXPORTER_OTLP_ENDPOINT is set, and to stdout otherwise.
+//		func InitTracer(ctx context.Context, name string) (*sdktrace.TracerProvider, error) {
+//			var exporter sdktrace.SpanExporter
+//			var err error
+//			if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" {
+//				exporter, err = otlptracegrpc.New(ctx)
+//			} else {
+//				exporter, err = stdouttrace.New()
+//			}
+//			if err != nil {
+//				return nil, err
+//			}
+//			provider := sdktrace.NewTracerProvider(
+//				sdktrace.WithBatcher(exporter),
+//				sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", name))),
+//			)
+//			otel.SetTracerProvider(provider)
+//			otel.SetTextMapPropagator(propagation.TraceContext{})
+//			return provider, nil
+//		}
+func (t *mainTemplate) initTracer(ctx context.Context) *Statement {
+	if !Tags(ctx).Has(OpenTelemetryTag) || mstrings.IsInStringSlice(nameInitTracer, t.rendered) {
+		return nil
+	}
+	return Comment(nameInitTracer + ` registers global tracer provider of OpenTelemetry and W3C trace context propagator.`).Line().
+		Comment(`Spans are exported to OTLP collector, when OTEL_EXPORTER_OTLP_ENDPOINT is set, and to stdout otherwise.`).Line().
+		Func().Id(nameInitTracer).Params(Id(_ctx_).Qual(PackagePathContext, "Context"), Id("name").String()).Params(Op("*").Qual(PackagePathOTelSDKTrace, "TracerProvider"), Error()).BlockFunc(func(body *Group) {
+		body.Var().Id("exporter").Qual(PackagePathOTelSDKTrace, "SpanExporter")
+		body.Var().Err().Error()
+		body.If(Qual(PackagePathOs, "Getenv").Call(Lit("OTEL_EXPORTER_OTLP_ENDPOINT")).Op("!=").Lit("")).Block(
+			List(Id("exporter"), Err()).Op("=").Qual(PackagePathOTelOTLPGRPC, "New").Call(Id(_ctx_)),
+		).Else().Block(
+			List(Id("exporter"), Err()).Op("=").Qual(PackagePathOTelStdout, "New").Call(),
+		)
+		body.If(Err().Op("!=").Nil()).Block(
+			Return(Nil(), Err()),
+		)
+		body.Id("provider").Op(":=").Qual(PackagePathOTelSDKTrace, "NewTracerProvider").Call(
+			Line().Qual(PackagePathOTelSDKTrace, "WithBatcher").Call(Id("exporter")),
+			Line().Qual(PackagePathOTelSDKTrace, "WithResource").Call(
+				Qual(PackagePathOTelResource, "NewSchemaless").Call(Qual(PackagePathOTelAttribute, "String").Call(Lit("service.name"), Id("name"))),
+			),
+			Line(),
+		)
+		body.Qual(PackagePathOTel, "SetTracerProvider").Call(Id("provider"))
+		body.Qual(PackagePathOTel, "SetTextMapPropagator").Call(Qual(PackagePathOTelPropagation, "TraceContext").Values())
+		body.Return(Id("provider"), Nil())
+	})
+}
+
 // Renders something like this
 //		func serveGRPC(endpoints *clientsvc.Endpoints, errCh chan error) {
 // 			logger := log.With(logger, "transport", "grpc")
@@ -234,7 +346,7 @@ func (t *mainTemplate) serveGrpc(ctx context.Context) *Statement {
 	return Comment(nameServeGRPC+` starts new GRPC server on address and sends first error to channel.`).Line().
 		Func().Id(nameServeGRPC).Params(
 		ctx_contextContext,
-		Id("endpoints").Op("*").Qual(filepath.Join(t.Info.OutputPackageImport, "transport"), EndpointsSetName),
+		Id("endpoints").Op("*").Qual(filepath.Join(t.Info.OutputPackageImport, "transport"), t.Info.endpointsSetName()),
 		Id("addr").Id("string"),
 		Id(_logger_).Qual(PackagePathGoKitLog, "Logger"),
 	).Params(
@@ -245,7 +357,7 @@ func (t *mainTemplate) serveGrpc(ctx context.Context) *Statement {
 			Return().Err(),
 		)
 		body.Comment(`Here you can add middlewares for grpc server.`)
-		body.Id("server").Op(":=").Qual(filepath.Join(t.Info.OutputPackageImport, "transport/grpc"), "NewGRPCServer").Call(t.newServerParams(ctx))
+		body.Id("server").Op(":=").Qual(filepath.Join(t.Info.OutputPackageImport, "transport/grpc"), t.Info.nsNewName("GRPCServer")).Call(t.newServerParams(ctx))
 		body.Id("grpcServer").Op(":=").Qual(PackagePathGoogleGRPC, "NewServer").Call()
 		body.Qual(t.Info.ProtobufPackageImport, "Register"+mstrings.ToUpperFirst(t.Info.Iface.Name)+"Server").Call(Id("grpcServer"), Id("server"))
 		body.Id(_logger_).Dot("Log").Call(Lit("listen on"), Id("addr"))
@@ -270,13 +382,13 @@ func (t *mainTemplate) serveHTTP(ctx context.Context) *Statement {
 	return Comment(nameServeHTTP+` starts new HTTP server on address and sends first error to channel.`).Line().
 		Func().Id(nameServeHTTP).Params(
 		ctx_contextContext,
-		Id("endpoints").Op("*").Qual(t.Info.OutputPackageImport+"/transport", EndpointsSetName),
+		Id("endpoints").Op("*").Qual(t.Info.OutputPackageImport+"/transport", t.Info.endpointsSetName()),
 		Id("addr").Id("string"),
 		Id(_logger_).Qual(PackagePathGoKitLog, "Logger"),
 	).Params(
 		Error(),
 	).BlockFunc(func(body *Group) {
-		body.Id("handler").Op(":=").Qual(t.Info.OutputPackageImport+"/transport/http", "NewHTTPHandler").Call(t.newServerParams(ctx))
+		body.Id("handler").Op(":=").Qual(t.Info.OutputPackageImport+"/transport/http", t.Info.nsNewName("HTTPHandler")).Call(t.newServerParams(ctx))
 		body.Id("httpServer").Op(":=").Op("&").Qual(PackagePathHttp, "Server").Values(DictFunc(func(d Dict) {
 			d[Id("Addr")] = Id("addr")
 			d[Id("Handler")] = Id("handler")
@@ -298,12 +410,89 @@ func (t *mainTemplate) serveHTTP(ctx context.Context) *Statement {
 	})
 }
 
+func (t *mainTemplate) serveJSONRPC(ctx context.Context) *Statement {
+	if !Tags(ctx).HasAny(JSONRPCTag, JSONRPCServerTag) || mstrings.IsInStringSlice(nameServeJSONRPC, t.rendered) {
+		return nil
+	}
+	return Comment(nameServeJSONRPC+` starts new JSON-RPC server on address and sends first error to channel.`).Line().
+		Func().Id(nameServeJSONRPC).Params(
+		ctx_contextContext,
+		Id("endpoints").Op("*").Qual(t.Info.OutputPackageImport+"/transport", t.Info.endpointsSetName()),
+		Id("addr").Id("string"),
+		Id(_logger_).Qual(PackagePathGoKitLog, "Logger"),
+	).Params(
+		Error(),
+	).BlockFunc(func(body *Group) {
+		body.Id("handler").Op(":=").Qual(t.Info.OutputPackageImport+"/transport/jsonrpc", t.Info.nsNewName("JSONRPCHandler")).Call(t.newServerParams(ctx))
+		body.Id("jsonrpcServer").Op(":=").Op("&").Qual(PackagePathHttp, "Server").Values(DictFunc(func(d Dict) {
+			d[Id("Addr")] = Id("addr")
+			d[Id("Handler")] = Id("handler")
+		}))
+		body.Id(_logger_).Dot("Log").Call(Lit("listen on"), Id("addr"))
+		body.Id("ch").Op(":=").Make(Id("chan error"))
+		body.Go().Func().Call().Block(
+			Id("ch").Op("<-").Id("jsonrpcServer").Dot("ListenAndServe").Call(),
+		).Call()
+		body.Select().Block(
+			Case(Err().Op(":= <-").Id("ch")),
+			If(Err().Op("==").Qual(PackagePathHttp, "ErrServerClosed")).Block(
+				Return().Nil(),
+			),
+			Return().Qual(PackagePathFmt, "Errorf").Call(Lit("json-rpc server: serve: %v"), Err()),
+			Case(Op("<-").Id(_ctx_).Dot("Done").Call()),
+			Return().Id("jsonrpcServer").Dot("Shutdown").Call(Qual(PackagePathContext, "Background").Call()),
+		)
+	})
+}
+
+// Renders something like this
+//		func ServeMetrics(ctx context.Context, addr string, logger log.Logger) error {
+//			mux := http.NewServeMux()
+//			mux.Handle("/metrics", promhttp.Handler())
+//			metricsServer := &http.Server{
+//				Addr:    addr,
+//				Handler: mux,
+//			}
+//			...
+//		}
+func (t *mainTemplate) serveMetrics(ctx context.Context) *Statement {
+	if !Tags(ctx).Has(MetricsMiddlewareTag) || mstrings.IsInStringSlice(nameServeMetrics, t.rendered) {
+		return nil
+	}
+	return Comment(nameServeMetrics+` starts new HTTP server, that exposes prometheus metrics on /metrics, and sends first error to channel.`).Line().
+		Func().Id(nameServeMetrics).Params(
+		ctx_contextContext,
+		Id("addr").Id("string"),
+		Id(_logger_).Qual(PackagePathGoKitLog, "Logger"),
+	).Params(
+		Error(),
+	).BlockFunc(func(body *Group) {
+		body.Id("mux").Op(":=").Qual(PackagePathHttp, "NewServeMux").Call()
+		body.Id("mux").Dot("Handle").Call(Lit("/metrics"), Qual(PackagePathPrometheusHTTP, "Handler").Call())
+		body.Id("metricsServer").Op(":=").Op("&").Qual(PackagePathHttp, "Server").Values(DictFunc(func(d Dict) {
+			d[Id("Addr")] = Id("addr")
+			d[Id("Handler")] = Id("mux")
+		}))
+		body.Id(_logger_).Dot("Log").Call(Lit("listen on"), Id("addr"))
+		body.Id("ch").Op(":=").Make(Id("chan error"))
+		body.Go().Func().Call().Block(
+			Id("ch").Op("<-").Id("metricsServer").Dot("ListenAndServe").Call(),
+		).Call()
+		body.Select().Block(
+			Case(Err().Op(":= <-").Id("ch")),
+			If(Err().Op("==").Qual(PackagePathHttp, "ErrServerClosed")).Block(
+				Return().Nil(),
+			),
+			Return().Qual(PackagePathFmt, "Errorf").Call(Lit("metrics server: serve: %v"), Err()),
+			Case(Op("<-").Id(_ctx_).Dot("Done").Call()),
+			Return().Id("metricsServer").Dot("Shutdown").Call(Qual(PackagePathContext, "Background").Call()),
+		)
+	})
+}
+
 func (t *mainTemplate) endpointsParams(ctx context.Context) *Statement {
 	s := &Statement{}
 	s.Id(_service_)
-	/*if Tags(ctx).HasAny(TracingMiddlewareTag) {
-		s.Op(",").Line().Qual(PackagePathOpenTracingGo, "NoopTracer{}").Op(",").Comment("TODO: Add tracer").Line()
-	}*/
 	return s
 }
 
@@ -312,9 +501,10 @@ func (t *mainTemplate) newServerParams(ctx context.Context) *Statement {
 	s.Id("endpoints")
 	if Tags(ctx).HasAny(TracingMiddlewareTag) {
 		s.Op(",").Line().Id(_logger_)
-	}
-	if Tags(ctx).HasAny(TracingMiddlewareTag) {
 		s.Op(",").Line().Qual(PackagePathOpenTracingGo, "NoopTracer{}").Op(",").Comment("TODO: Add tracer").Line()
 	}
+	if Tags(ctx).HasAny(OpenTelemetryTag) {
+		s.Op(",").Qual(PackagePathOTel, "GetTextMapPropagator").Call()
+	}
 	return s
 }
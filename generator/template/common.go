@@ -14,40 +14,59 @@ import (
 )
 
 const (
-	PackagePathGoKitEndpoint         = "github.com/go-kit/kit/endpoint"
-	PackagePathContext               = "context"
-	PackagePathGoKitLog              = "github.com/go-kit/kit/log"
-	PackagePathTime                  = "time"
-	PackagePathGoogleGRPC            = "google.golang.org/grpc"
-	PackagePathGoogleGRPCStatus      = "google.golang.org/grpc/status"
-	PackagePathGoogleGRPCCodes       = "google.golang.org/grpc/codes"
-	PackagePathNetContext            = "golang.org/x/net/context"
-	PackagePathGoKitTransportGRPC    = "github.com/go-kit/kit/transport/grpc"
-	PackagePathHttp                  = "net/http"
-	PackagePathGoKitTransportHTTP    = "github.com/go-kit/kit/transport/http"
-	PackagePathBytes                 = "bytes"
-	PackagePathJson                  = "encoding/json"
-	PackagePathIOUtil                = "io/ioutil"
-	PackagePathIO                    = "io"
-	PackagePathStrings               = "strings"
-	PackagePathUrl                   = "net/url"
-	PackagePathEmptyProtobuf         = "github.com/golang/protobuf/ptypes/empty"
-	PackagePathFmt                   = "fmt"
-	PackagePathOs                    = "os"
-	PackagePathOsSignal              = "os/signal"
-	PackagePathSyscall               = "syscall"
-	PackagePathErrors                = "errors"
-	PackagePathNet                   = "net"
-	PackagePathGorillaMux            = "github.com/gorilla/mux"
-	PackagePathPath                  = "path"
-	PackagePathStrconv               = "strconv"
-	PackagePathOpenTracingGo         = "github.com/opentracing/opentracing-go"
-	PackagePathGoKitTracing          = "github.com/go-kit/kit/tracing/opentracing"
-	PackagePathGoKitTransportJSONRPC = "github.com/go-kit/kit/transport/http/jsonrpc"
-	PackagePathGoKitMetrics          = "github.com/go-kit/kit/metrics"
-	PackagePathGoKitSD               = "github.com/go-kit/kit/sd"
-	PackagePathGoKitLB               = "github.com/go-kit/kit/sd/lb"
-	PackagePathSyncErrgroup          = "golang.org/x/sync/errgroup"
+	PackagePathGoKitEndpoint          = "github.com/go-kit/kit/endpoint"
+	PackagePathContext                = "context"
+	PackagePathGoKitLog               = "github.com/go-kit/kit/log"
+	PackagePathTime                   = "time"
+	PackagePathSync                   = "sync"
+	PackagePathMath                   = "math"
+	PackagePathGoogleGRPC             = "google.golang.org/grpc"
+	PackagePathGoogleGRPCStatus       = "google.golang.org/grpc/status"
+	PackagePathGoogleGRPCCodes        = "google.golang.org/grpc/codes"
+	PackagePathGoogleErrDetails       = "google.golang.org/genproto/googleapis/rpc/errdetails"
+	PackagePathGoogleGRPCMetadata     = "google.golang.org/grpc/metadata"
+	PackagePathNetContext             = "golang.org/x/net/context"
+	PackagePathGoKitTransportGRPC     = "github.com/go-kit/kit/transport/grpc"
+	PackagePathHttp                   = "net/http"
+	PackagePathGoKitTransportHTTP     = "github.com/go-kit/kit/transport/http"
+	PackagePathBytes                  = "bytes"
+	PackagePathJson                   = "encoding/json"
+	PackagePathIOUtil                 = "io/ioutil"
+	PackagePathIO                     = "io"
+	PackagePathStrings                = "strings"
+	PackagePathUrl                    = "net/url"
+	PackagePathEmptyProtobuf          = "github.com/golang/protobuf/ptypes/empty"
+	PackagePathFmt                    = "fmt"
+	PackagePathOs                     = "os"
+	PackagePathOsSignal               = "os/signal"
+	PackagePathSyscall                = "syscall"
+	PackagePathErrors                 = "errors"
+	PackagePathNet                    = "net"
+	PackagePathGorillaMux             = "github.com/gorilla/mux"
+	PackagePathGorillaWebsocket       = "github.com/gorilla/websocket"
+	PackagePathBufio                  = "bufio"
+	PackagePathPath                   = "path"
+	PackagePathStrconv                = "strconv"
+	PackagePathOpenTracingGo          = "github.com/opentracing/opentracing-go"
+	PackagePathGoKitTracing           = "github.com/go-kit/kit/tracing/opentracing"
+	PackagePathOTel                   = "go.opentelemetry.io/otel"
+	PackagePathOTelAttribute          = "go.opentelemetry.io/otel/attribute"
+	PackagePathOTelCodes              = "go.opentelemetry.io/otel/codes"
+	PackagePathOTelTrace              = "go.opentelemetry.io/otel/trace"
+	PackagePathOTelPropagation        = "go.opentelemetry.io/otel/propagation"
+	PackagePathOTelSDKTrace           = "go.opentelemetry.io/otel/sdk/trace"
+	PackagePathOTelResource           = "go.opentelemetry.io/otel/sdk/resource"
+	PackagePathOTelStdout             = "go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	PackagePathOTelOTLPGRPC           = "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	PackagePathGoKitTransportJSONRPC  = "github.com/go-kit/kit/transport/http/jsonrpc"
+	PackagePathGoKitMetrics           = "github.com/go-kit/kit/metrics"
+	PackagePathGoKitMetricsPrometheus = "github.com/go-kit/kit/metrics/prometheus"
+	PackagePathPrometheus             = "github.com/prometheus/client_golang/prometheus"
+	PackagePathPrometheusHTTP         = "github.com/prometheus/client_golang/prometheus/promhttp"
+	PackagePathGoKitSD                = "github.com/go-kit/kit/sd"
+	PackagePathGoKitLB                = "github.com/go-kit/kit/sd/lb"
+	PackagePathGoKitSDDNSSRV          = "github.com/go-kit/kit/sd/dnssrv"
+	PackagePathSyncErrgroup           = "golang.org/x/sync/errgroup"
 
 	TagMark         = "// @"
 	MicrogenMainTag = "microgen"
@@ -67,7 +86,11 @@ const (
 	MainTag                   = "main"
 	ErrorLoggingMiddlewareTag = "error-logging"
 	TracingMiddlewareTag      = "tracing"
+	OpenTelemetryTag          = "opentelemetry"
 	CachingMiddlewareTag      = "caching"
+	RateLimitMiddlewareTag    = "ratelimit"
+	CircuitBreakerTag         = "circuit-breaker"
+	RetryTag                  = "retry"
 	JSONRPCTag                = "json-rpc"
 	JSONRPCServerTag          = "json-rpc-server"
 	JSONRPCClientTag          = "json-rpc-client"
@@ -76,6 +99,8 @@ const (
 	TransportServer           = "transport-server"
 	MetricsMiddlewareTag      = "metrics"
 	ServiceDiscoveryTag       = "service-discovery"
+	OpenAPITag                = "openapi"
+	ProtobufAPIv2Tag          = "protobuf-apiv2"
 )
 
 const (
@@ -106,6 +131,14 @@ type GenerationInfo struct {
 	OneToManyStreamMethods  map[string]bool
 	ManyToManyStreamMethods map[string]bool
 	ManyToOneStreamMethods  map[string]bool
+
+	// Parsed XXX_service.pb.go file, nil when it is not provided.
+	// Converters between service structures and protobuf messages are generated from it.
+	ProtobufGoFile *types.File
+
+	// Namespace is not empty, when several services are generated into one tree.
+	// It prefixes names of generated files and declarations, so services do not clash.
+	Namespace string
 }
 
 func (i GenerationInfo) String() string {
@@ -124,11 +157,50 @@ func (i GenerationInfo) String() string {
 		fmt.Sprint("OneToManyStreamMethods: ", listKeysOfMap(i.OneToManyStreamMethods)),
 		fmt.Sprint("ManyToManyStreamMethods: ", listKeysOfMap(i.ManyToManyStreamMethods)),
 		fmt.Sprint("ManyToOneStreamMethods: ", listKeysOfMap(i.ManyToOneStreamMethods)),
+		fmt.Sprint("Namespace: ", i.Namespace),
 		fmt.Sprint(),
 	)
 	return strings.Join(ss, "\n\t")
 }
 
+// Returns name of exported declaration with service namespace.
+//
+//		EndpointsSet -> StringServiceEndpointsSet
+//
+func (i *GenerationInfo) nsName(name string) string {
+	return i.Namespace + name
+}
+
+// Returns name of constructor with service namespace.
+//
+//		GRPCServer -> NewStringServiceGRPCServer
+//
+func (i *GenerationInfo) nsNewName(name string) string {
+	return "New" + i.Namespace + name
+}
+
+// Returns name of unexported declaration with service namespace.
+//
+//		loggingMiddleware -> stringServiceLoggingMiddleware
+//
+func (i *GenerationInfo) nsPrivateName(name string) string {
+	if i.Namespace == "" {
+		return name
+	}
+	return mstrings.ToLowerFirst(i.Namespace) + mstrings.ToUpperFirst(name)
+}
+
+// Returns file name with service namespace.
+//
+//		endpoints -> string_service_endpoints
+//
+func (i *GenerationInfo) nsFile(name string) string {
+	if i.Namespace == "" {
+		return name
+	}
+	return mstrings.ToSnakeCase(i.Namespace) + "_" + name
+}
+
 func listKeysOfMap(m map[string]bool) string {
 	var keys = make([]string, len(m))
 	i := 0
@@ -1,11 +1,15 @@
 package template
 
-import "context"
+import (
+	"context"
+	"sort"
+)
 
 const (
 	spi                = "SourcePackageImport"
 	ael                = "AllowEllipsis"
 	mainTagsContextKey = "MainTags"
+	userTemplatesKey   = "UserTemplates"
 )
 
 func WithSourcePackageImport(parent context.Context, val string) context.Context {
@@ -24,6 +28,17 @@ func Tags(ctx context.Context) TagsSet {
 	return ctx.Value(mainTagsContextKey).(TagsSet)
 }
 
+// WithUserTemplates puts templates from user templates directory to context.
+func WithUserTemplates(parent context.Context, tt []*UserTemplate) context.Context {
+	return context.WithValue(parent, userTemplatesKey, tt)
+}
+
+// UserTemplates returns user templates from context or nil, when there are no user templates.
+func UserTemplates(ctx context.Context) []*UserTemplate {
+	tt, _ := ctx.Value(userTemplatesKey).([]*UserTemplate)
+	return tt
+}
+
 type TagsSet map[string]struct{}
 
 func (s TagsSet) Has(item string) bool {
@@ -42,6 +57,16 @@ func (s TagsSet) Add(item string) {
 	s[item] = struct{}{}
 }
 
+// List returns sorted tags of set.
+func (s TagsSet) List() []string {
+	list := make([]string, 0, len(s))
+	for item := range s {
+		list = append(list, item)
+	}
+	sort.Strings(list)
+	return list
+}
+
 func AllowEllipsis(ctx context.Context) bool {
 	v, ok := ctx.Value(ael).(bool)
 	return ok && v
@@ -0,0 +1,499 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	mstrings "github.com/recolabs/microgen/generator/strings"
+	"github.com/recolabs/microgen/generator/write_strategy"
+	"github.com/vetcher/go-astra/types"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	openAPIVersion     = "3.0.3"
+	openAPISchemasRef  = "#/components/schemas/"
+	openAPIJSONContent = "application/json"
+)
+
+// Matches gorilla mux path variables: {name} or {name:pattern}.
+var pathVarRegexp = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+type openapiTemplate struct {
+	info *GenerationInfo
+
+	// Source package with domain types.
+	source *types.File
+	// Schemas of components, keyed by name.
+	schemas map[string]yaml.MapSlice
+	// Errors from @errors tag.
+	errors []errorMapping
+}
+
+func NewOpenAPITemplate(info *GenerationInfo) Template {
+	return &openapiTemplate{
+		info: info,
+	}
+}
+
+func (t *openapiTemplate) DefaultPath() string {
+	return t.info.nsFile("openapi") + ".yaml"
+}
+
+func (t *openapiTemplate) Prepare(ctx context.Context) error {
+	file, err := parsePackage(t.info.SourceFilePath)
+	if err != nil {
+		return err
+	}
+	t.source = file
+	t.errors, err = fetchErrorMappings(t.info.Iface.Docs)
+	return err
+}
+
+func (t *openapiTemplate) ChooseStrategy(ctx context.Context) (write_strategy.Strategy, error) {
+	return write_strategy.NewCreateRawFileStrategy(t.info.OutputFilePath, t.DefaultPath()), nil
+}
+
+// Render OpenAPI 3 specification of HTTP transport.
+//
+//		openapi: 3.0.3
+//		info:
+//		  title: StringService
+//		  version: 1.0.0
+//		paths:
+//		  /count/{text}/{symbol}:
+//		    get:
+//		      operationId: Count
+//		      parameters:
+//		      - name: text
+//		        in: path
+//		        required: true
+//		        schema:
+//		          type: string
+//		      ...
+//		      responses:
+//		        "200":
+//		          content:
+//		            application/json:
+//		              schema:
+//		                $ref: '#/components/schemas/CountResponse'
+//
+func (t *openapiTemplate) Render(ctx context.Context) write_strategy.Renderer {
+	t.schemas = make(map[string]yaml.MapSlice)
+	info := yaml.MapSlice{{Key: "title", Value: t.info.Iface.Name}}
+	if description := docsDescription(t.info.Iface.Docs); description != "" {
+		info = append(info, yaml.MapItem{Key: "description", Value: description})
+	}
+	info = append(info, yaml.MapItem{Key: "version", Value: "1.0.0"})
+
+	paths := yaml.MapSlice{}
+	pathIndex := make(map[string]int)
+	for _, fn := range t.info.Iface.Methods {
+		if !t.info.AllowedMethods[fn.Name] ||
+			t.info.ManyToManyStreamMethods[fn.Name] ||
+			t.info.ManyToOneStreamMethods[fn.Name] ||
+			t.info.OneToManyStreamMethods[fn.Name] {
+			continue
+		}
+		path := "/" + strings.TrimPrefix(buildMethodPath(fn), "/")
+		i, ok := pathIndex[path]
+		if !ok {
+			i = len(paths)
+			pathIndex[path] = i
+			paths = append(paths, yaml.MapItem{Key: path, Value: yaml.MapSlice{}})
+		}
+		operations := paths[i].Value.(yaml.MapSlice)
+		paths[i].Value = append(operations, yaml.MapItem{
+			Key:   strings.ToLower(FetchHttpMethodTag(fn.Docs)),
+			Value: t.operation(fn, path),
+		})
+	}
+
+	doc := yaml.MapSlice{
+		{Key: "openapi", Value: openAPIVersion},
+		{Key: "info", Value: info},
+		{Key: "paths", Value: paths},
+	}
+	if len(t.schemas) > 0 {
+		schemas := yaml.MapSlice{}
+		for _, name := range sortedSchemaNames(t.schemas) {
+			schemas = append(schemas, yaml.MapItem{Key: name, Value: t.schemas[name]})
+		}
+		doc = append(doc, yaml.MapItem{Key: "components", Value: yaml.MapSlice{{Key: "schemas", Value: schemas}}})
+	}
+	return openapiRenderer{header: t.info.FileHeader, doc: doc}
+}
+
+func (t *openapiTemplate) operation(fn *types.Function, path string) yaml.MapSlice {
+	op := yaml.MapSlice{
+		{Key: "operationId", Value: fn.Name},
+		{Key: "tags", Value: []string{t.info.Iface.Name}},
+	}
+	if description := docsDescription(fn.Docs); description != "" {
+		op = append(op, yaml.MapItem{Key: "description", Value: description})
+	}
+	args := RemoveContextIfFirst(fn.Args)
+	if HasHTTPBinding(fn) {
+		op = append(op, t.boundOperation(fn)...)
+	} else if FetchHttpMethodTag(fn.Docs) == "GET" {
+		var params []yaml.MapSlice
+		for _, name := range pathVars(path) {
+			schema := yaml.MapSlice{{Key: "type", Value: "string"}}
+			if arg := findPathArg(args, name); arg != nil {
+				schema = t.schema(arg.Type)
+			}
+			params = append(params, yaml.MapSlice{
+				{Key: "name", Value: name},
+				{Key: "in", Value: "path"},
+				{Key: "required", Value: true},
+				{Key: "schema", Value: schema},
+			})
+		}
+		if len(params) > 0 {
+			op = append(op, yaml.MapItem{Key: "parameters", Value: params})
+		}
+	} else {
+		op = append(op, yaml.MapItem{Key: "requestBody", Value: yaml.MapSlice{
+			{Key: "required", Value: true},
+			{Key: "content", Value: jsonContent(t.exchangeSchema(t.info.requestStructName(fn), args))},
+		}})
+	}
+	responses := yaml.MapSlice{
+		{Key: "200", Value: yaml.MapSlice{
+			{Key: "description", Value: "Successful response."},
+			{Key: "content", Value: jsonContent(t.exchangeSchema(t.info.responseStructName(fn), removeErrorIfLast(fn.Results)))},
+		}},
+	}
+	if HasErrorsTag(t.info.Iface.Docs) {
+		responses = append(responses, t.errorResponses()...)
+	} else {
+		responses = append(responses, yaml.MapItem{Key: "default", Value: yaml.MapSlice{
+			{Key: "description", Value: "Error."},
+			{Key: "content", Value: yaml.MapSlice{
+				{Key: "text/plain", Value: yaml.MapSlice{{Key: "schema", Value: yaml.MapSlice{{Key: "type", Value: "string"}}}}},
+			}},
+		}})
+	}
+	op = append(op, yaml.MapItem{Key: "responses", Value: responses})
+	return op
+}
+
+// Returns responses with statuses from @errors tag and default one. Their body is JSON of transport error.
+func (t *openapiTemplate) errorResponses() yaml.MapSlice {
+	name := t.info.nsName("Error")
+	t.schemas[name] = yaml.MapSlice{
+		{Key: "type", Value: "object"},
+		{Key: "properties", Value: yaml.MapSlice{
+			{Key: "error", Value: yaml.MapSlice{{Key: "type", Value: "string"}, {Key: "description", Value: "Name of error from @errors tag."}}},
+			{Key: "message", Value: yaml.MapSlice{{Key: "type", Value: "string"}}},
+			{Key: "details", Value: yaml.MapSlice{{Key: "type", Value: "object"}, {Key: "description", Value: "Value of error type."}}},
+		}},
+		{Key: "required", Value: []string{"message"}},
+	}
+	var statuses []int
+	names := make(map[int][]string)
+	for _, m := range t.errors {
+		if len(names[m.status]) == 0 {
+			statuses = append(statuses, m.status)
+		}
+		names[m.status] = append(names[m.status], m.name)
+	}
+	sort.Ints(statuses)
+	var responses yaml.MapSlice
+	for _, status := range statuses {
+		responses = append(responses, yaml.MapItem{Key: strconv.Itoa(status), Value: yaml.MapSlice{
+			{Key: "description", Value: strings.Join(names[status], ", ") + "."},
+			{Key: "content", Value: jsonContent(schemaRef(name))},
+		}})
+	}
+	return append(responses, yaml.MapItem{Key: "default", Value: yaml.MapSlice{
+		{Key: "description", Value: "Error."},
+		{Key: "content", Value: jsonContent(schemaRef(name))},
+	}})
+}
+
+// Returns parameters and request body of method with @http-query, @http-header or @http-path-param tags.
+// Body contains only arguments, which are not bound to parameters.
+func (t *openapiTemplate) boundOperation(fn *types.Function) yaml.MapSlice {
+	b, err := newHTTPBinding(fn)
+	if err != nil {
+		// Binding is validated before generation.
+		return nil
+	}
+	var op yaml.MapSlice
+	var params []yaml.MapSlice
+	for _, p := range b.params {
+		param := yaml.MapSlice{
+			{Key: "name", Value: p.key},
+			{Key: "in", Value: p.in},
+		}
+		if p.in == httpInPath {
+			param = append(param, yaml.MapItem{Key: "required", Value: true})
+		}
+		params = append(params, append(param, yaml.MapItem{Key: "schema", Value: t.schema(p.arg.Type)}))
+	}
+	if len(params) > 0 {
+		op = append(op, yaml.MapItem{Key: "parameters", Value: params})
+	}
+	if len(b.body) > 0 {
+		op = append(op, yaml.MapItem{Key: "requestBody", Value: yaml.MapSlice{
+			{Key: "required", Value: true},
+			{Key: "content", Value: jsonContent(t.exchangeSchema(t.info.requestStructName(fn), b.body))},
+		}})
+	}
+	return op
+}
+
+// Adds schema of exchange struct to components and returns reference to it.
+// Fields are named in the same way, as exchange struct tags.
+func (t *openapiTemplate) exchangeSchema(name string, params []types.Variable) yaml.MapSlice {
+	properties := yaml.MapSlice{}
+	for _, param := range params {
+		properties = append(properties, yaml.MapItem{Key: mstrings.ToSnakeCase(param.Name), Value: t.schema(param.Type)})
+	}
+	schema := yaml.MapSlice{{Key: "type", Value: "object"}}
+	if len(properties) > 0 {
+		schema = append(schema, yaml.MapItem{Key: "properties", Value: properties})
+	}
+	t.schemas[name] = schema
+	return schemaRef(name)
+}
+
+// Returns JSON schema of go type. Named types of source package are added to components.
+func (t *openapiTemplate) schema(typ types.Type) yaml.MapSlice {
+	switch x := typ.(type) {
+	case types.TPointer:
+		return t.schema(x.Next)
+	case types.TArray:
+		if name := types.TypeName(x.Next); name != nil && *name == "byte" && types.TypeImport(x.Next) == nil {
+			return yaml.MapSlice{{Key: "type", Value: "string"}, {Key: "format", Value: "byte"}}
+		}
+		return yaml.MapSlice{{Key: "type", Value: "array"}, {Key: "items", Value: t.schema(x.Next)}}
+	case types.TEllipsis:
+		return yaml.MapSlice{{Key: "type", Value: "array"}, {Key: "items", Value: t.schema(x.Next)}}
+	case types.TMap:
+		return yaml.MapSlice{{Key: "type", Value: "object"}, {Key: "additionalProperties", Value: t.schema(x.Value)}}
+	case types.TInterface:
+		return yaml.MapSlice{}
+	case types.TImport:
+		return importedTypeSchema(x)
+	case types.TName:
+		if schema, ok := builtinTypeSchema(x.TypeName); ok {
+			return schema
+		}
+		return t.namedTypeSchema(x.TypeName)
+	}
+	return yaml.MapSlice{}
+}
+
+func (t *openapiTemplate) namedTypeSchema(name string) yaml.MapSlice {
+	if _, ok := t.schemas[name]; ok {
+		return schemaRef(name)
+	}
+	for i := range t.source.Structures {
+		if t.source.Structures[i].Name == name {
+			// Reserve name first, so recursive types refer to it.
+			t.schemas[name] = yaml.MapSlice{}
+			t.schemas[name] = t.structSchema(&t.source.Structures[i])
+			return schemaRef(name)
+		}
+	}
+	for i := range t.source.Types {
+		if t.source.Types[i].Name == name {
+			t.schemas[name] = yaml.MapSlice{}
+			schema := t.schema(t.source.Types[i].Type)
+			if description := docsDescription(t.source.Types[i].Docs); description != "" {
+				schema = append(schema, yaml.MapItem{Key: "description", Value: description})
+			}
+			t.schemas[name] = schema
+			return schemaRef(name)
+		}
+	}
+	return yaml.MapSlice{{Key: "type", Value: "object"}, {Key: "description", Value: "Go type " + name}}
+}
+
+func (t *openapiTemplate) structSchema(s *types.Struct) yaml.MapSlice {
+	properties := yaml.MapSlice{}
+	var required []string
+	var embedded []yaml.MapSlice
+	for _, field := range s.Fields {
+		name, omitempty, skip := jsonFieldName(&field)
+		if skip {
+			continue
+		}
+		if name == "" {
+			// Fields of embedded struct are promoted to parent object.
+			embedded = append(embedded, t.schema(field.Type))
+			continue
+		}
+		schema := t.schema(field.Type)
+		if description := docsDescription(field.Docs); description != "" && !isRef(schema) {
+			schema = append(schema, yaml.MapItem{Key: "description", Value: description})
+		}
+		properties = append(properties, yaml.MapItem{Key: name, Value: schema})
+		if _, ptr := field.Type.(types.TPointer); !omitempty && !ptr {
+			required = append(required, name)
+		}
+	}
+	schema := yaml.MapSlice{{Key: "type", Value: "object"}}
+	if description := docsDescription(s.Docs); description != "" {
+		schema = append(schema, yaml.MapItem{Key: "description", Value: description})
+	}
+	if len(properties) > 0 {
+		schema = append(schema, yaml.MapItem{Key: "properties", Value: properties})
+	}
+	if len(required) > 0 {
+		schema = append(schema, yaml.MapItem{Key: "required", Value: required})
+	}
+	if len(embedded) > 0 {
+		return yaml.MapSlice{{Key: "allOf", Value: append(embedded, schema)}}
+	}
+	return schema
+}
+
+// Returns name of field in JSON as encoding/json does. Empty name is returned for embedded fields without tag.
+func jsonFieldName(field *types.StructField) (name string, omitempty, skip bool) {
+	name = field.Name
+	if tag := field.Tags["json"]; len(tag) > 0 {
+		if tag[0] == "-" && len(tag) == 1 {
+			return "", false, true
+		}
+		if tag[0] != "" {
+			name = tag[0]
+		}
+		omitempty = mstrings.IsInStringSlice("omitempty", tag[1:])
+	}
+	if field.Name != "" && !isExported(field.Name) {
+		return "", false, true
+	}
+	return name, omitempty, false
+}
+
+func typeName(typ types.Type) string {
+	if name := types.TypeName(typ); name != nil {
+		return *name
+	}
+	return ""
+}
+
+func isExported(name string) bool {
+	return name != "" && mstrings.ToUpperFirst(name) == name
+}
+
+func builtinTypeSchema(name string) (yaml.MapSlice, bool) {
+	switch name {
+	case "string", "error":
+		return yaml.MapSlice{{Key: "type", Value: "string"}}, true
+	case "bool":
+		return yaml.MapSlice{{Key: "type", Value: "boolean"}}, true
+	case "int", "int64":
+		return yaml.MapSlice{{Key: "type", Value: "integer"}, {Key: "format", Value: "int64"}}, true
+	case "int8", "int16", "int32", "rune":
+		return yaml.MapSlice{{Key: "type", Value: "integer"}, {Key: "format", Value: "int32"}}, true
+	case "uint", "uint64", "uintptr":
+		return yaml.MapSlice{{Key: "type", Value: "integer"}, {Key: "format", Value: "int64"}, {Key: "minimum", Value: 0}}, true
+	case "uint8", "uint16", "uint32", "byte":
+		return yaml.MapSlice{{Key: "type", Value: "integer"}, {Key: "format", Value: "int32"}, {Key: "minimum", Value: 0}}, true
+	case "float32":
+		return yaml.MapSlice{{Key: "type", Value: "number"}, {Key: "format", Value: "float"}}, true
+	case "float64":
+		return yaml.MapSlice{{Key: "type", Value: "number"}, {Key: "format", Value: "double"}}, true
+	case "interface{}", "any":
+		return yaml.MapSlice{}, true
+	}
+	return nil, false
+}
+
+// Returns schema of type from another package. Only well-known types are described.
+func importedTypeSchema(typ types.TImport) yaml.MapSlice {
+	name := typeName(typ.Next)
+	switch typ.Import.Package + "." + name {
+	case "time.Time":
+		return yaml.MapSlice{{Key: "type", Value: "string"}, {Key: "format", Value: "date-time"}}
+	case "time.Duration":
+		return yaml.MapSlice{{Key: "type", Value: "integer"}, {Key: "format", Value: "int64"}, {Key: "description", Value: "Duration in nanoseconds."}}
+	case "github.com/google/uuid.UUID", "github.com/satori/go.uuid.UUID":
+		return yaml.MapSlice{{Key: "type", Value: "string"}, {Key: "format", Value: "uuid"}}
+	case "encoding/json.RawMessage":
+		return yaml.MapSlice{}
+	}
+	return yaml.MapSlice{{Key: "type", Value: "object"}, {Key: "description", Value: "Go type " + typ.Import.Package + "." + name}}
+}
+
+// Returns names of variables of gorilla mux path.
+func pathVars(path string) []string {
+	var vars []string
+	for _, m := range pathVarRegexp.FindAllStringSubmatch(path, -1) {
+		vars = append(vars, m[1])
+	}
+	return vars
+}
+
+func findPathArg(args []types.Variable, name string) *types.Variable {
+	for i := range args {
+		if args[i].Name == name || mstrings.ToURLSnakeCase(args[i].Name) == name {
+			return &args[i]
+		}
+	}
+	return nil
+}
+
+// Returns text of comments without comment marks and microgen tags.
+func docsDescription(docs []string) string {
+	var lines []string
+	for _, doc := range docs {
+		if strings.HasPrefix(doc, TagMark) {
+			continue
+		}
+		line := strings.TrimPrefix(doc, "//")
+		line = strings.TrimPrefix(line, " ")
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func jsonContent(schema yaml.MapSlice) yaml.MapSlice {
+	return yaml.MapSlice{{Key: openAPIJSONContent, Value: yaml.MapSlice{{Key: "schema", Value: schema}}}}
+}
+
+func schemaRef(name string) yaml.MapSlice {
+	return yaml.MapSlice{{Key: "$ref", Value: openAPISchemasRef + name}}
+}
+
+func isRef(schema yaml.MapSlice) bool {
+	return len(schema) == 1 && schema[0].Key == "$ref"
+}
+
+func sortedSchemaNames(m map[string]yaml.MapSlice) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type openapiRenderer struct {
+	header string
+	doc    yaml.MapSlice
+}
+
+func (r openapiRenderer) Render(w io.Writer) error {
+	content, err := yaml.Marshal(r.doc)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if r.header != "" {
+		fmt.Fprintf(&buf, "# %s\n", r.header)
+	}
+	buf.Write(content)
+	_, err = w.Write(buf.Bytes())
+	return err
+}
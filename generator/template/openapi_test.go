@@ -0,0 +1,115 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetcher/go-astra"
+	"gopkg.in/yaml.v2"
+)
+
+const openapiTestSource = `package svc
+
+import "context"
+
+// @microgen openapi
+type UserService interface {
+	// GetUser returns user by id.
+	// @http-method GET
+	// @http-path users/{id}
+	GetUser(ctx context.Context, id int64) (user *User, err error)
+	CreateUser(ctx context.Context, user User) (err error)
+}
+
+// User of service.
+type User struct {
+	Base
+	// Name of user.
+	Name  string   ` + "`json:\"name\"`" + `
+	Tags  []string ` + "`json:\"tags,omitempty\"`" + `
+	Photo []byte   ` + "`json:\"-\"`" + `
+}
+
+type Base struct {
+	ID int64 ` + "`json:\"id\"`" + `
+}
+`
+
+func TestOpenAPITemplate(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "svc.go")
+	if err := ioutil.WriteFile(source, []byte(openapiTestSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := astra.ParseFile(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ResetParsedCache()
+	info := &GenerationInfo{
+		Iface:          &file.Interfaces[0],
+		SourceFilePath: source,
+		AllowedMethods: map[string]bool{"GetUser": true, "CreateUser": true},
+	}
+	ctx := WithTags(context.Background(), TagsSet{OpenAPITag: {}})
+	tmpl := NewOpenAPITemplate(info)
+	if err := tmpl.Prepare(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Render(ctx).Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Paths map[string]map[string]struct {
+			OperationID string `yaml:"operationId"`
+			Description string `yaml:"description"`
+			Parameters  []struct {
+				Name   string            `yaml:"name"`
+				In     string            `yaml:"in"`
+				Schema map[string]string `yaml:"schema"`
+			} `yaml:"parameters"`
+		} `yaml:"paths"`
+		Components struct {
+			Schemas map[string]interface{} `yaml:"schemas"`
+		} `yaml:"components"`
+	}
+	if err := yaml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err, buf.String())
+	}
+	get := doc.Paths["/users/{id}"]["get"]
+	assert.Equal(t, "GetUser", get.OperationID)
+	assert.Equal(t, "GetUser returns user by id.", get.Description)
+	if assert.Len(t, get.Parameters, 1) {
+		assert.Equal(t, "path", get.Parameters[0].In)
+		assert.Equal(t, map[string]string{"type": "integer", "format": "int64"}, get.Parameters[0].Schema)
+	}
+	assert.Equal(t, "CreateUser", doc.Paths["/create-user"]["post"].OperationID)
+
+	var user interface{}
+	assert.NoError(t, yaml.Unmarshal([]byte(`
+allOf:
+- $ref: '#/components/schemas/Base'
+- type: object
+  description: User of service.
+  properties:
+    name:
+      type: string
+      description: Name of user.
+    tags:
+      type: array
+      items:
+        type: string
+  required:
+  - name
+`), &user))
+	assert.Equal(t, user, doc.Components.Schemas["User"])
+	assert.Contains(t, doc.Components.Schemas, "Base")
+	assert.Contains(t, doc.Components.Schemas, "GetUserResponse")
+	assert.Contains(t, doc.Components.Schemas, "CreateUserRequest")
+}
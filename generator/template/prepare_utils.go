@@ -2,9 +2,14 @@ package template
 
 import (
 	"fmt"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 
+	"github.com/recolabs/microgen/generator/write_strategy"
 	"github.com/vetcher/go-astra"
 	"github.com/vetcher/go-astra/types"
 )
@@ -20,7 +25,7 @@ func parsePackage(path string) (*types.File, error) {
 	if file, ok := parsedCache[path]; ok {
 		return file, nil
 	}
-	files, err := astra.ParsePackage(path, astra.AllowAnyImportAliases)
+	files, err := parsePackageFiles(path)
 	if err != nil {
 		return nil, err
 	}
@@ -32,11 +37,83 @@ func parsePackage(path string) (*types.File, error) {
 	return file, nil
 }
 
+// pendingFiles are rendered, but not yet written files. Templates see them, as if they were on disk.
+var pendingFiles *write_strategy.Files
+
+// SetPendingFiles makes templates see files, rendered to memory, as written to disk,
+// so all units may be rendered before anything is written.
+func SetPendingFiles(files *write_strategy.Files) {
+	pendingFiles = files
+	ResetParsedCache()
+}
+
+// Parses all go files of directory. Pending files replace files on disk.
+// Constants are ignored: templates do not use them and astra fails on typed iota sequences.
+func parsePackageFiles(dir string) ([]*types.File, error) {
+	if pendingFiles == nil {
+		return astra.ParsePackage(dir, astra.AllowAnyImportAliases, astra.IgnoreConstants)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("can not read dir: %v", err)
+	}
+	names := make(map[string]bool)
+	for _, info := range infos {
+		if !info.IsDir() && filepath.Ext(info.Name()) == ".go" {
+			names[filepath.Join(dir, info.Name())] = true
+		}
+	}
+	for _, path := range pendingFiles.Paths() {
+		if filepath.Dir(path) == dir && filepath.Ext(path) == ".go" {
+			names[path] = true
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("can not read dir: %s: %v", dir, os.ErrNotExist)
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	var parsed []*types.File
+	for _, name := range sorted {
+		var src interface{}
+		if content, ok := pendingFiles.Get(name); ok {
+			src = content
+		}
+		tree, err := parser.ParseFile(token.NewFileSet(), name, src, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("can not parse %s: %v", filepath.Base(name), err)
+		}
+		file, err := astra.ParseAstFile(tree, astra.AllowAnyImportAliases, astra.IgnoreConstants)
+		if err != nil {
+			return nil, fmt.Errorf("can not parse %s: %v", filepath.Base(name), err)
+		}
+		parsed = append(parsed, file)
+	}
+	return parsed, nil
+}
+
+// ResetParsedCache drops parsed packages, so files, generated for previous service, become visible.
+func ResetParsedCache() {
+	parsedCache = map[string]*types.File{}
+}
+
 func statFile(absPath, relPath string) error {
 	outpath, err := filepath.Abs(filepath.Join(absPath, relPath))
 	if err != nil {
 		return fmt.Errorf("unable to resolve path: %v", err)
 	}
+	if pendingFiles != nil {
+		if _, ok := pendingFiles.Get(outpath); ok {
+			return nil
+		}
+	}
 
 	fileInfo, err := os.Stat(outpath)
 	if os.IsNotExist(err) || os.IsPermission(err) {
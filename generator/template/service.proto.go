@@ -3,10 +3,20 @@ package template
 import (
 	"context"
 	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	gotypes "go/types"
+	"os"
+	"path/filepath"
+	"regexp"
 	"sort"
+	gostrings "strings"
 
 	"github.com/recolabs/microgen/generator/strings"
 	"github.com/recolabs/microgen/generator/write_strategy"
+	"github.com/recolabs/microgen/logger"
 	"github.com/vetcher/go-astra/types"
 )
 
@@ -15,6 +25,17 @@ const tab = "    "
 type protoTemplate struct {
 	info     *GenerationInfo
 	protoPkg string
+
+	// Parsed service package and constants of its enums.
+	source *types.File
+	enums  map[string][]protoEnumValue
+	// Messages of existing protobuf file, which numbers of fields are kept.
+	existing map[string]*protoMessage
+
+	imports  map[string]struct{}
+	declared map[string]bool
+	// Names of structures and enums of service package to declare, in order of first use.
+	queue []string
 }
 
 func NewProtoTemplate(info *GenerationInfo, protoPkg string) Template {
@@ -24,19 +45,32 @@ func NewProtoTemplate(info *GenerationInfo, protoPkg string) Template {
 	}
 }
 
-// Renders stub code for service, its methods and constructor, that implements service interface.
+// Renders protobuf file with service, request and response messages, messages and enums for
+// structures and enums of service package.
+//
+//		syntax = "proto3";
+//
+//		option go_package = "github.com/user/stringsvc/pb;pb";
+//
+//		package stringsvc;
+//
+//		service StringService {
+//		    rpc Count (CountRequest) returns (CountResponse);
+//		    rpc Watch (WatchRequest) returns (stream WatchResponse);
+//		}
 //
-//		// Generated by "microgen" tool.
-//		// Structure stringService implements StringService interface.
-//		type stringService struct {
+//		message CountRequest {
+//		    Text text = 1;
 //		}
 //
-//		func NewStringService() StringService {
-//			panic("constructor not provided")
+//		message Text {
+//		    string value = 1;
+//		    Kind kind = 2;
 //		}
 //
-//		func (s *stringService) Count(ctx context.Context, text string, symbol string) (count int, positions []int) {
-//			panic("method not provided")
+//		enum Kind {
+//		    KIND_PLAIN = 0;
+//		    KIND_HTML = 1;
 //		}
 //
 func (t *protoTemplate) Render(ctx context.Context) write_strategy.Renderer {
@@ -49,20 +83,22 @@ func (t *protoTemplate) Render(ctx context.Context) write_strategy.Renderer {
 	f.Ln()
 	{
 		d := f.Hold()
-		imports := make(map[string]struct{})
+		t.imports = make(map[string]struct{})
+		t.declared = make(map[string]bool)
+		t.queue = nil
 		// Draw service
 		d.Lnf("service %s {", t.info.Iface.Name)
 		for _, method := range t.info.Iface.Methods {
 			if !t.info.AllowedMethods[method.Name] {
 				continue
 			}
-			reqTypeName, externalImport := protoMessageName(RemoveContextIfFirst(method.Args), requestStructName(method))
-			if externalImport != nil {
-				imports[*externalImport] = struct{}{}
+			reqTypeName := t.requestMessageName(method)
+			respTypeName := t.responseMessageName(method)
+			if t.info.ManyToManyStreamMethods[method.Name] || t.info.ManyToOneStreamMethods[method.Name] {
+				reqTypeName = "stream " + reqTypeName
 			}
-			respTypeName, externalImport := protoMessageName(removeErrorIfLast(method.Results), responseStructName(method))
-			if externalImport != nil {
-				imports[*externalImport] = struct{}{}
+			if t.info.ManyToManyStreamMethods[method.Name] || t.info.OneToManyStreamMethods[method.Name] {
+				respTypeName = "stream " + respTypeName
 			}
 			d.Lnf(tab+"rpc %s (%s) returns (%s);", method.Name, reqTypeName, respTypeName)
 		}
@@ -73,41 +109,38 @@ func (t *protoTemplate) Render(ctx context.Context) write_strategy.Renderer {
 			if !t.info.AllowedMethods[method.Name] {
 				continue
 			}
-			{
-				args := RemoveContextIfFirst(method.Args)
-				reqTypeName, externalImport := protoMessageName(args, requestStructName(method))
-				if externalImport == nil {
-					d.Ln()
-					d.Lnf("message %s {", reqTypeName)
-					for i, arg := range args {
-						n, imp := protoTypeName(arg.Type)
-						if imp != nil {
-							imports[*imp] = struct{}{}
-						}
-						d.Lnf(tab+"%s %s = %d;", n, strings.ToSnakeCase(arg.Name), i+1)
-					}
-					d.Ln("}")
+			// Numbers are validated before generation.
+			numbers, _ := ProtoFieldNumbers(method)
+			if reqTypeName := t.requestMessageName(method); reqTypeName == requestMessageName(method) {
+				if t.isStreamedRequest(method) {
+					t.renderMessage(d, reqTypeName, nil, true, nil)
+				} else {
+					t.renderMessage(d, reqTypeName, t.requestArgs(method), false, numbers)
 				}
 			}
-			{
-				params := removeErrorIfLast(method.Results)
-				reqTypeName, externalImport := protoMessageName(params, responseStructName(method))
-				if externalImport == nil {
-					d.Ln()
-					d.Lnf("message %s {", reqTypeName)
-					for i, arg := range params {
-						n, imp := protoTypeName(arg.Type)
-						if imp != nil {
-							imports[*imp] = struct{}{}
-						}
-						d.Lnf(tab+"%s %s = %d;", n, strings.ToSnakeCase(arg.Name), i+1)
+			if respTypeName := t.responseMessageName(method); respTypeName == responseMessageName(method) {
+				streamed := t.isStreamedResponse(method) || t.info.ManyToOneStreamMethods[method.Name]
+				t.renderMessage(d, respTypeName, removeErrorIfLast(method.Results), streamed, numbers)
+			}
+		}
+		// Draw structures and enums of service package, which are used by messages.
+		// Rendering of message may add new types to queue.
+		for i := 0; i < len(t.queue); i++ {
+			name := t.queue[i]
+			if s := findStructInFile(types.TName{TypeName: name}, t.source, t.info.SourcePackageImport); s != nil {
+				var fields []types.Variable
+				for _, field := range s.Fields {
+					if isPublic(field.Name) {
+						fields = append(fields, field.Variable)
 					}
-					d.Ln("}")
 				}
+				t.renderMessage(d, name, fields, false, nil)
+				continue
 			}
+			t.renderEnum(d, name)
 		}
 
-		for _, imp := range sortedSliceFromStringSet(imports) {
+		for _, imp := range sortedSliceFromStringSet(t.imports) {
 			f.Lnf(`import "%s";`, imp)
 		}
 
@@ -117,11 +150,238 @@ func (t *protoTemplate) Render(ctx context.Context) write_strategy.Renderer {
 	return f
 }
 
-func (protoTemplate) DefaultPath() string {
-	return "service.proto"
+func (t *protoTemplate) isStreamedRequest(fn *types.Function) bool {
+	return t.info.ManyToManyStreamMethods[fn.Name] || t.info.ManyToOneStreamMethods[fn.Name]
+}
+
+func (t *protoTemplate) isStreamedResponse(fn *types.Function) bool {
+	return t.info.ManyToManyStreamMethods[fn.Name] || t.info.OneToManyStreamMethods[fn.Name]
+}
+
+// Arguments of method, which are sent in request message. Stream argument of one-to-many method is removed.
+func (t *protoTemplate) requestArgs(fn *types.Function) []types.Variable {
+	if t.info.OneToManyStreamMethods[fn.Name] {
+		return removeLastVar(RemoveContextIfFirst(fn.Args))
+	}
+	return RemoveContextIfFirst(fn.Args)
+}
+
+// Streamed messages are declared by name, because generated stream code uses them.
+func (t *protoTemplate) requestMessageName(fn *types.Function) string {
+	if t.isStreamedRequest(fn) {
+		return requestMessageName(fn)
+	}
+	return t.messageName(t.requestArgs(fn), requestMessageName(fn))
+}
+
+func (t *protoTemplate) responseMessageName(fn *types.Function) string {
+	if t.isStreamedResponse(fn) || t.info.ManyToOneStreamMethods[fn.Name] {
+		return responseMessageName(fn)
+	}
+	return t.messageName(removeErrorIfLast(fn.Results), responseMessageName(fn))
+}
+
+func (t *protoTemplate) messageName(params []types.Variable, def string) string {
+	name, imp := protoMessageName(params, def)
+	if imp != nil {
+		t.imports[*imp] = struct{}{}
+	}
+	return name
+}
+
+// Renders message with fields. Fields keep numbers of existing service.proto, unless numbers are
+// provided by `@proto-field` tags. Removed fields are reserved.
+// Fields of streamed messages are not declared by method signature, they are copied from existing file.
+//
+//		message CountRequest {
+//		    reserved 2;
+//		    reserved "symbol";
+//		    string text = 1;
+//		    // TODO: field Handler of type func() can not be represented in protobuf
+//		}
+//
+func (t *protoTemplate) renderMessage(d *DelayBuffer, name string, fields []types.Variable, streamed bool, overrides map[string]int) {
+	existing := t.existing[name]
+	d.Ln()
+	d.Lnf("message %s {", name)
+	if streamed {
+		if existing != nil && existing.hasFields() {
+			for _, line := range existing.body {
+				d.Ln(line)
+			}
+		} else {
+			d.Ln(tab + protoStreamedMessageTodo)
+		}
+		d.Ln("}")
+		return
+	}
+	type protoField struct {
+		typ, name, todo string
+	}
+	var (
+		protoFields []protoField
+		names       []string
+		numbersOf   = make(map[string]int)
+	)
+	for _, field := range fields {
+		n, imp := t.protoFieldType(field.Type)
+		if n == "" {
+			protoFields = append(protoFields, protoField{
+				todo: fmt.Sprintf("// TODO: field %s of type %s can not be represented in protobuf", field.Name, field.Type),
+			})
+			continue
+		}
+		if imp != nil {
+			t.imports[*imp] = struct{}{}
+		}
+		protoName := strings.ToSnakeCase(field.Name)
+		if number, ok := overrides[field.Name]; ok {
+			numbersOf[protoName] = number
+		}
+		protoFields = append(protoFields, protoField{typ: n, name: protoName})
+		names = append(names, protoName)
+	}
+	numbers, reserved, reservedNames := numberProtoFields(existing, names, numbersOf)
+	if len(reserved) > 0 {
+		d.Lnf(tab+"reserved %s;", formatProtoRanges(reserved))
+	}
+	if len(reservedNames) > 0 {
+		d.Lnf(tab+"reserved \"%s\";", gostrings.Join(reservedNames, `", "`))
+	}
+	for _, field := range protoFields {
+		if field.todo != "" {
+			d.Ln(tab + field.todo)
+			continue
+		}
+		d.Lnf(tab+"%s %s = %d;", field.typ, field.name, numbers[field.name])
+	}
+	d.Ln("}")
+}
+
+// Renders enum. Values of integer enums are the values of constants, string enums are numbered in
+// order of declaration and named by values, so converters may use generated `XXX_value` and `XXX_name` maps.
+//
+//		enum Kind {
+//		    KIND_UNSPECIFIED = 0;
+//		    KIND_PLAIN = 1;
+//		}
+//
+func (t *protoTemplate) renderEnum(d *DelayBuffer, name string) {
+	values := t.enums[name]
+	d.Ln()
+	d.Lnf("enum %s {", name)
+	if values[0].str {
+		for i, v := range values {
+			d.Lnf(tab+"%s = %d;", v.value, i)
+		}
+		d.Ln("}")
+		return
+	}
+	seen := make(map[string]bool)
+	for _, v := range values {
+		if seen[v.value] {
+			d.Ln(tab + "option allow_alias = true;")
+			break
+		}
+		seen[v.value] = true
+	}
+	if !seen["0"] {
+		d.Lnf(tab+"%s_UNSPECIFIED = 0;", protoEnumValueName(name))
+	}
+	for _, v := range values {
+		d.Lnf(tab+"%s = %s;", protoEnumValueName(v.name), v.value)
+	}
+	d.Ln("}")
+}
+
+func protoEnumValueName(name string) string {
+	return gostrings.ToUpper(strings.ToSnakeCase(name))
+}
+
+// Returns protobuf type of golang type or empty string, when type can not be represented.
+// Structures and enums of service package are queued for declaration.
+func (t *protoTemplate) protoFieldType(v types.Type) (string, *string) {
+	switch {
+	case v.String() == "[]byte":
+		return "bytes", nil
+	case isNamedTypeOf(v, "Duration", PackagePathTime):
+		return googleProtobufDuration, sp(importGoogleProtobufDuration)
+	case isStructMap(v):
+		return googleProtobufStruct, sp(importGoogleProtobufStruct)
+	}
+	if name, imp := wellKnownProtoType(v); imp != nil {
+		return name, imp
+	}
+	switch tt := v.(type) {
+	case types.TMap:
+		key, _ := t.protoFieldType(tt.Key)
+		value, imp := t.protoFieldType(tt.Value)
+		if key == "" || value == "" {
+			return "", nil
+		}
+		return fmt.Sprintf("map<%s, %s>", key, value), imp
+	case types.TArray:
+		elem, imp := t.protoFieldType(tt.Next)
+		if elem == "" {
+			return "", nil
+		}
+		return "repeated " + elem, imp
+	case types.TInterface, types.TChan, types.TEllipsis:
+		return "", nil
+	}
+	if name, ok := t.declare(derefType(v)); ok {
+		return name, nil
+	}
+	// Pointers to scalars without wrapper messages are proto3 optional fields.
+	if elem, ok := pointerElem(v); ok && types.IsBuiltin(elem) {
+		if name, imp := protoTypeName(v); imp != nil {
+			return name, imp
+		}
+		name, _ := protoTypeName(elem)
+		return "optional " + name, nil
+	}
+	if name, ok := basicType(v, t.source, t.info.SourcePackageImport); ok && !types.IsBuiltin(v) {
+		return protoTypeName(types.TName{TypeName: name})
+	}
+	return protoTypeName(v)
+}
+
+// Queues declaration of structure or enum of service package and returns its name.
+func (t *protoTemplate) declare(v types.Type) (string, bool) {
+	name, pkg, ok := namedType(v)
+	if !ok || (pkg != "" && pkg != t.info.SourcePackageImport) || types.IsBuiltinTypeString(name) {
+		return "", false
+	}
+	if !t.declared[name] {
+		if findStructInFile(v, t.source, t.info.SourcePackageImport) == nil && len(t.enums[name]) == 0 {
+			return "", false
+		}
+		t.declared[name] = true
+		t.queue = append(t.queue, name)
+	}
+	return name, true
+}
+
+func (t *protoTemplate) DefaultPath() string {
+	return t.info.nsFile("service") + ".proto"
 }
 
 func (t *protoTemplate) Prepare(ctx context.Context) error {
+	existing, err := readProtoMessages(t.info.OutputFilePath, t.DefaultPath())
+	if err != nil {
+		return fmt.Errorf("can't read %s: %v", t.DefaultPath(), err)
+	}
+	t.existing = existing
+	file, err := parsePackage(t.info.SourceFilePath)
+	if err != nil {
+		logger.Logger.Logln(1, "can't parse service package, structures will not be declared in", t.DefaultPath()+":", err)
+		return nil
+	}
+	t.source = file
+	t.enums, err = parseEnumValues(filepath.Dir(t.info.SourceFilePath), file.Name)
+	if err != nil {
+		logger.Logger.Logln(1, "can't find enums of service package:", err)
+	}
 	return nil
 }
 
@@ -141,13 +401,54 @@ const (
 	googleProtobufFloat64Value = googleProtobuf + "DoubleValue"
 	googleProtobufFloat32Value = googleProtobuf + "FloatValue"
 	googleProtobufTimestamp    = googleProtobuf + "Timestamp"
+	googleProtobufDuration     = googleProtobuf + "Duration"
+	googleProtobufStruct       = googleProtobuf + "Struct"
 
 	importGoogleProtobuf          = "google/protobuf/"
 	importGoogleProtobufWrappers  = importGoogleProtobuf + "wrappers.proto"
 	importGoogleProtobufEmpty     = importGoogleProtobuf + "empty.proto"
 	importGoogleProtobufTimestamp = importGoogleProtobuf + "timestamp.proto"
+	importGoogleProtobufDuration  = importGoogleProtobuf + "duration.proto"
+	importGoogleProtobufStruct    = importGoogleProtobuf + "struct.proto"
 )
 
+// Files with declarations of well-known types by packages of google.golang.org/protobuf.
+var wellKnownTypeFiles = map[string]string{
+	GoogleProtobufTimestamppb: "timestamp.proto",
+	GoogleProtobufDurationpb:  "duration.proto",
+	GoogleProtobufWrapperspb:  "wrappers.proto",
+	GoogleProtobufEmptypb:     "empty.proto",
+	GoogleProtobufStructpb:    "struct.proto",
+	GoogleProtobufFieldmaskpb: "field_mask.proto",
+}
+
+// WellKnownProtoType returns full name of well-known message for pointer to type
+// of google.golang.org/protobuf/types/known packages, e.g. google.protobuf.FieldMask for *fieldmaskpb.FieldMask.
+func WellKnownProtoType(t types.Type) (string, bool) {
+	name, imp := wellKnownProtoType(t)
+	return name, imp != nil
+}
+
+func wellKnownProtoType(t types.Type) (string, *string) {
+	elem, ok := pointerElem(t)
+	if !ok {
+		return "", nil
+	}
+	name, pkg, ok := namedType(elem)
+	file, known := wellKnownTypeFiles[pkg]
+	if !ok || !known {
+		return "", nil
+	}
+	return googleProtobuf + name, sp(importGoogleProtobuf + file)
+}
+
+// ProtoMessageName returns name of message, which is declared in service.proto for params: google.protobuf.Empty
+// for no params, wrapper or Timestamp for single param of their type and def for other params.
+func ProtoMessageName(params []types.Variable, def string) string {
+	name, _ := protoMessageName(params, def)
+	return name
+}
+
 func protoMessageName(params []types.Variable, def string) (string, *string) {
 	switch len(params) {
 	case 0:
@@ -205,6 +506,10 @@ func protoTypeName(v types.Type) (t string, imp *string) {
 		t = "int64"
 	case "uint":
 		t = "uint64"
+	case "float64":
+		t = "double"
+	case "float32":
+		t = "float"
 	default:
 		if n := types.TypeName(v); n != nil {
 			t = *n
@@ -235,3 +540,76 @@ func sortedSliceFromStringSet(s map[string]struct{}) []string {
 	sort.Strings(slice)
 	return slice
 }
+
+// protoEnumValue is a constant of enum type: integer value or string value, that is a valid protobuf identifier.
+type protoEnumValue struct {
+	name  string
+	value string
+	str   bool
+}
+
+var protoIdentRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Finds constants of named integer and string types of package in dir. Imports of package are not resolved,
+// so only constants, that do not depend on other packages, are found.
+// String enums with values, which are not protobuf identifiers, are skipped.
+func parseEnumValues(dir string, pkgName string) (map[string][]protoEnumValue, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !gostrings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	astPkg, ok := pkgs[pkgName]
+	if !ok {
+		return nil, fmt.Errorf("package %s not found in %s", pkgName, dir)
+	}
+	var files []*ast.File
+	for _, file := range astPkg.Files {
+		files = append(files, file)
+	}
+	conf := gotypes.Config{
+		Importer: importerFunc(func(path string) (*gotypes.Package, error) {
+			return nil, fmt.Errorf("imports are not resolved")
+		}),
+		Error: func(error) {},
+	}
+	pkg, _ := conf.Check(pkgName, fset, files, nil)
+	var consts []*gotypes.Const
+	for _, name := range pkg.Scope().Names() {
+		c, ok := pkg.Scope().Lookup(name).(*gotypes.Const)
+		if !ok || !c.Exported() {
+			continue
+		}
+		if named, ok := c.Type().(*gotypes.Named); ok && named.Obj().Pkg() == pkg {
+			consts = append(consts, c)
+		}
+	}
+	sort.Slice(consts, func(i, j int) bool { return consts[i].Pos() < consts[j].Pos() })
+	enums := make(map[string][]protoEnumValue)
+	invalid := make(map[string]bool)
+	for _, c := range consts {
+		name := c.Type().(*gotypes.Named).Obj().Name()
+		switch c.Val().Kind() {
+		case constant.Int:
+			enums[name] = append(enums[name], protoEnumValue{name: c.Name(), value: c.Val().ExactString()})
+		case constant.String:
+			value := constant.StringVal(c.Val())
+			if !protoIdentRegexp.MatchString(value) {
+				invalid[name] = true
+			}
+			enums[name] = append(enums[name], protoEnumValue{name: c.Name(), value: value, str: true})
+		}
+	}
+	for name := range invalid {
+		delete(enums, name)
+	}
+	return enums, nil
+}
+
+type importerFunc func(path string) (*gotypes.Package, error)
+
+func (f importerFunc) Import(path string) (*gotypes.Package, error) {
+	return f(path)
+}
@@ -0,0 +1,352 @@
+package template
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vetcher/go-astra/types"
+)
+
+const (
+	ProtoFieldTag = "proto-field"
+
+	protoStreamedMessageTodo = "// TODO: declare fields of streamed message"
+
+	protoMaxFieldNumber = 1<<29 - 1
+	// Numbers, reserved for the protobuf implementation.
+	protoFirstReservedNumber = 19000
+	protoLastReservedNumber  = 19999
+)
+
+// ProtoFieldNumbers parses `@proto-field` tag of method, which sets field numbers of request and
+// response messages by names of arguments and results.
+//
+//		// @proto-field text=1,symbol=3
+//		Count(ctx context.Context, text string, symbol string) (count int, err error)
+//
+func ProtoFieldNumbers(fn *types.Function) (map[string]int, error) {
+	values := fetchTagValues(fn.Docs, ProtoFieldTag)
+	if len(values) == 0 {
+		return nil, nil
+	}
+	params := make(map[string]bool)
+	for _, param := range append(RemoveContextIfFirst(fn.Args), removeErrorIfLast(fn.Results)...) {
+		params[param.Name] = true
+	}
+	numbers := make(map[string]int)
+	for _, value := range values {
+		eq := strings.Index(value, "=")
+		if eq < 0 {
+			return nil, fmt.Errorf("%s: @%s: %s should be name=number", fn.Name, ProtoFieldTag, value)
+		}
+		name := value[:eq]
+		number, err := strconv.Atoi(value[eq+1:])
+		if err != nil {
+			return nil, fmt.Errorf("%s: @%s: %s: field number should be integer", fn.Name, ProtoFieldTag, value)
+		}
+		if !params[name] {
+			return nil, fmt.Errorf("%s: @%s: %s is not an argument or result", fn.Name, ProtoFieldTag, name)
+		}
+		if _, ok := numbers[name]; ok {
+			return nil, fmt.Errorf("%s: @%s: %s is numbered twice", fn.Name, ProtoFieldTag, name)
+		}
+		if number < 1 || number > protoMaxFieldNumber ||
+			(number >= protoFirstReservedNumber && number <= protoLastReservedNumber) {
+			return nil, fmt.Errorf("%s: @%s: %s: field number is out of allowed range", fn.Name, ProtoFieldTag, value)
+		}
+		numbers[name] = number
+	}
+	// Arguments and results are sent in different messages, so only they may share numbers.
+	for _, params := range [][]types.Variable{RemoveContextIfFirst(fn.Args), removeErrorIfLast(fn.Results)} {
+		used := make(map[int]string)
+		for _, param := range params {
+			number, ok := numbers[param.Name]
+			if !ok {
+				continue
+			}
+			if other, ok := used[number]; ok {
+				return nil, fmt.Errorf("%s: @%s: %s and %s have the same number %d", fn.Name, ProtoFieldTag, other, param.Name, number)
+			}
+			used[number] = param.Name
+		}
+	}
+	return numbers, nil
+}
+
+// protoRange is a range of field numbers, `to` is inclusive.
+type protoRange struct {
+	from, to int
+}
+
+func (r protoRange) String() string {
+	switch {
+	case r.from == r.to:
+		return strconv.Itoa(r.from)
+	case r.to == protoMaxFieldNumber:
+		return fmt.Sprintf("%d to max", r.from)
+	}
+	return fmt.Sprintf("%d to %d", r.from, r.to)
+}
+
+func formatProtoRanges(ranges []protoRange) string {
+	s := make([]string, len(ranges))
+	for i := range ranges {
+		s[i] = ranges[i].String()
+	}
+	return strings.Join(s, ", ")
+}
+
+// protoMessage is a message of existing protobuf file.
+type protoMessage struct {
+	// Numbers of fields by names.
+	numbers       map[string]int
+	reserved      []protoRange
+	reservedNames []string
+	// Lines inside message braces, as they are written in file.
+	body []string
+}
+
+func (m *protoMessage) hasFields() bool {
+	for _, line := range m.body {
+		if s := strings.TrimSpace(line); s != "" && s != protoStreamedMessageTodo {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *protoMessage) isReserved(number int) bool {
+	for _, r := range m.reserved {
+		if number >= r.from && number <= r.to {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	protoMessageRegexp  = regexp.MustCompile(`^\s*message\s+(\w+)\s*\{`)
+	protoFieldRegexp    = regexp.MustCompile(`^\s*(?:repeated\s+|optional\s+)?(?:map\s*<[^>]*>|[\w.]+)\s+(\w+)\s*=\s*(\d+)`)
+	protoReservedRegexp = regexp.MustCompile(`^\s*reserved\s+([^;]*);`)
+)
+
+// Parses top-level messages of protobuf file, which is generated by microgen. Nested messages,
+// enums and oneofs are kept in body of message, but their fields are not numbered.
+func parseProtoMessages(content string) map[string]*protoMessage {
+	messages := make(map[string]*protoMessage)
+	var (
+		current *protoMessage
+		depth   int
+	)
+	for _, line := range strings.Split(content, "\n") {
+		code := line
+		if i := strings.Index(code, "//"); i >= 0 {
+			code = code[:i]
+		}
+		if current == nil {
+			if m := protoMessageRegexp.FindStringSubmatch(code); m != nil {
+				current = &protoMessage{numbers: make(map[string]int)}
+				messages[m[1]] = current
+				depth = 1
+			}
+			continue
+		}
+		opened, closed := strings.Count(code, "{"), strings.Count(code, "}")
+		if depth == 1 && opened == 0 {
+			if m := protoFieldRegexp.FindStringSubmatch(code); m != nil {
+				current.numbers[m[1]], _ = strconv.Atoi(m[2])
+			} else if m := protoReservedRegexp.FindStringSubmatch(code); m != nil {
+				current.parseReserved(m[1])
+			}
+		}
+		depth += opened - closed
+		if depth <= 0 {
+			current = nil
+			continue
+		}
+		current.body = append(current.body, line)
+	}
+	return messages
+}
+
+func (m *protoMessage) parseReserved(s string) {
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if unquoted, err := strconv.Unquote(item); err == nil {
+			m.reservedNames = append(m.reservedNames, unquoted)
+			continue
+		}
+		bounds := strings.Fields(item)
+		from, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			continue
+		}
+		r := protoRange{from: from, to: from}
+		if len(bounds) == 3 && bounds[1] == "to" {
+			if bounds[2] == "max" {
+				r.to = protoMaxFieldNumber
+			} else if r.to, err = strconv.Atoi(bounds[2]); err != nil {
+				continue
+			}
+		}
+		m.reserved = append(m.reserved, r)
+	}
+}
+
+// Reads messages of existing protobuf file. File, which is rendered in this run, has priority.
+func readProtoMessages(absPath, relPath string) (map[string]*protoMessage, error) {
+	path, err := filepath.Abs(filepath.Join(absPath, relPath))
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve path: %v", err)
+	}
+	if pendingFiles != nil {
+		if content, ok := pendingFiles.Get(path); ok {
+			return parseProtoMessages(string(content)), nil
+		}
+	}
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseProtoMessages(string(content)), nil
+}
+
+// Numbers fields of message. Fields with numbers from `@proto-field` tag keep them, other fields keep
+// numbers of existing message, new fields get numbers, that were never used in message.
+// Numbers of removed and renumbered fields and names of removed fields are returned as reserved.
+func numberProtoFields(existing *protoMessage, names []string, fixed map[string]int) (map[string]int, []protoRange, []string) {
+	if existing == nil {
+		existing = &protoMessage{}
+	}
+	numbers := make(map[string]int, len(names))
+	used := make(map[int]bool)
+	for name, number := range fixed {
+		numbers[name] = number
+		used[number] = true
+	}
+	for _, name := range names {
+		if _, ok := numbers[name]; ok {
+			continue
+		}
+		if number, ok := existing.numbers[name]; ok && !used[number] {
+			numbers[name] = number
+			used[number] = true
+		}
+	}
+	// New numbers are greater than any number, that was used in message.
+	next := 0
+	for _, number := range existing.numbers {
+		if number > next {
+			next = number
+		}
+	}
+	for number := range used {
+		if number > next {
+			next = number
+		}
+	}
+	for _, r := range existing.reserved {
+		if r.to > next && r.to != protoMaxFieldNumber {
+			next = r.to
+		}
+	}
+	for _, name := range names {
+		if _, ok := numbers[name]; ok {
+			continue
+		}
+		next++
+		for used[next] || existing.isReserved(next) ||
+			(next >= protoFirstReservedNumber && next <= protoLastReservedNumber) {
+			next++
+		}
+		numbers[name] = next
+		used[next] = true
+	}
+
+	current := make(map[string]bool, len(names))
+	for _, name := range names {
+		current[name] = true
+	}
+	// Numbers of removed and renumbered fields.
+	var removed []int
+	for name, number := range existing.numbers {
+		if !current[name] || numbers[name] != number {
+			removed = append(removed, number)
+		}
+	}
+	reserved := subtractProtoNumbers(append(append([]protoRange(nil), existing.reserved...), numberRanges(removed)...), used)
+
+	var reservedNames []string
+	seen := make(map[string]bool)
+	for _, name := range existing.reservedNames {
+		if !current[name] && !seen[name] {
+			reservedNames = append(reservedNames, name)
+			seen[name] = true
+		}
+	}
+	for name := range existing.numbers {
+		if !current[name] && !seen[name] {
+			reservedNames = append(reservedNames, name)
+			seen[name] = true
+		}
+	}
+	sort.Strings(reservedNames)
+	return numbers, reserved, reservedNames
+}
+
+func numberRanges(numbers []int) []protoRange {
+	ranges := make([]protoRange, len(numbers))
+	for i, number := range numbers {
+		ranges[i] = protoRange{from: number, to: number}
+	}
+	return ranges
+}
+
+// Merges ranges and removes numbers, which are used by fields, from them.
+func subtractProtoNumbers(ranges []protoRange, used map[int]bool) []protoRange {
+	if len(ranges) == 0 {
+		return nil
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].from < ranges[j].from })
+	merged := []protoRange{ranges[0]}
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.from <= last.to+1 {
+			if r.to > last.to {
+				last.to = r.to
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	usedNumbers := make([]int, 0, len(used))
+	for number := range used {
+		usedNumbers = append(usedNumbers, number)
+	}
+	sort.Ints(usedNumbers)
+	var result []protoRange
+	for _, r := range merged {
+		for _, number := range usedNumbers {
+			if number < r.from || number > r.to {
+				continue
+			}
+			if number > r.from {
+				result = append(result, protoRange{from: r.from, to: number - 1})
+			}
+			r.from = number + 1
+		}
+		if r.from <= r.to {
+			result = append(result, r)
+		}
+	}
+	return result
+}
@@ -0,0 +1,224 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetcher/go-astra"
+)
+
+const protoTestSource = `package svc
+
+import (
+	"context"
+	"time"
+
+	"example.com/svc/pb"
+)
+
+type CommentService interface {
+	Create(ctx context.Context, comment *Comment) (id string, err error)
+	// @microgen one-to-many
+	Watch(filter Filter, stream pb.CommentService_WatchServer) (err error)
+	// @microgen many-to-many
+	Chat(stream pb.CommentService_ChatServer) (err error)
+	// @microgen many-to-one
+	Upload(stream pb.CommentService_UploadServer) (err error)
+}
+
+type Comment struct {
+	ID       string
+	Kind     Kind
+	Status   Status
+	Rating   *int
+	Created  time.Time
+	Tags     map[string]float64
+	Replies  []*Comment
+	Callback func()
+	internal int
+}
+
+type Filter struct {
+	Kinds []Kind
+}
+`
+
+const protoTestEnums = `package svc
+
+type Kind int
+
+const (
+	KindPlain Kind = iota + 1
+	KindHTML
+)
+
+type Status string
+
+const (
+	StatusDraft     Status = "DRAFT"
+	StatusPublished Status = "PUBLISHED"
+)
+`
+
+func TestProtoTemplate(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "svc.go")
+	if err := ioutil.WriteFile(source, []byte(protoTestSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "enums.go"), []byte(protoTestEnums), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := astra.ParseFile(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ResetParsedCache()
+	info := &GenerationInfo{
+		Iface:                   &file.Interfaces[0],
+		SourceFilePath:          source,
+		SourcePackageImport:     "example.com/svc",
+		ProtobufPackageImport:   "example.com/svc/pb",
+		AllowedMethods:          map[string]bool{"Create": true, "Watch": true, "Chat": true, "Upload": true},
+		OneToManyStreamMethods:  map[string]bool{"Watch": true},
+		ManyToManyStreamMethods: map[string]bool{"Chat": true},
+		ManyToOneStreamMethods:  map[string]bool{"Upload": true},
+	}
+	tmpl := NewProtoTemplate(info, "svc")
+	if err := tmpl.Prepare(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Render(context.Background()).Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	proto := buf.String()
+	for _, s := range []string{
+		`import "google/protobuf/timestamp.proto";`,
+		"rpc Create (CreateRequest) returns (CreateResponse);",
+		"rpc Watch (WatchRequest) returns (stream WatchResponse);",
+		"rpc Chat (stream ChatRequest) returns (stream ChatResponse);",
+		"rpc Upload (stream UploadRequest) returns (UploadResponse);",
+		"message WatchRequest {\n    Filter filter = 1;\n}",
+		"message ChatRequest {\n    // TODO: declare fields of streamed message\n}",
+		"message Comment {\n" +
+			"    string id = 1;\n" +
+			"    Kind kind = 2;\n" +
+			"    Status status = 3;\n" +
+			"    optional int64 rating = 4;\n" +
+			"    google.protobuf.Timestamp created = 5;\n" +
+			"    map<string, double> tags = 6;\n" +
+			"    repeated Comment replies = 7;\n" +
+			"    // TODO: field Callback of type func () () can not be represented in protobuf\n" +
+			"}",
+		"message Filter {\n    repeated Kind kinds = 1;\n}",
+		"enum Kind {\n    KIND_UNSPECIFIED = 0;\n    KIND_PLAIN = 1;\n    KIND_HTML = 2;\n}",
+		"enum Status {\n    DRAFT = 0;\n    PUBLISHED = 1;\n}",
+	} {
+		assert.Contains(t, proto, s)
+	}
+}
+
+const protoFieldsTestSource = `package svc
+
+import (
+	"context"
+
+	"example.com/svc/pb"
+)
+
+type StringService interface {
+	// @proto-field limit=10
+	Count(ctx context.Context, text string, limit int, offset int) (count int, err error)
+	// @microgen many-to-many
+	Chat(stream pb.StringService_ChatServer) (err error)
+}
+`
+
+const protoFieldsTestExisting = `syntax = "proto3";
+
+service StringService {
+    rpc Count (CountRequest) returns (CountResponse);
+    rpc Chat (stream ChatRequest) returns (stream ChatResponse);
+}
+
+message CountRequest {
+    reserved 7;
+    reserved "old";
+    string text = 2;
+    string symbol = 3; // removed
+    int64 limit = 4;
+}
+
+message CountResponse {
+    int64 count = 1;
+}
+
+message ChatRequest {
+    string text = 1;
+}
+`
+
+func TestProtoFieldNumbers(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "svc.go")
+	if err := ioutil.WriteFile(source, []byte(protoFieldsTestSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "service.proto"), []byte(protoFieldsTestExisting), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := astra.ParseFile(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ResetParsedCache()
+	info := &GenerationInfo{
+		Iface:                   &file.Interfaces[0],
+		SourceFilePath:          source,
+		OutputFilePath:          dir,
+		SourcePackageImport:     "example.com/svc",
+		ProtobufPackageImport:   "example.com/svc/pb",
+		AllowedMethods:          map[string]bool{"Count": true, "Chat": true},
+		ManyToManyStreamMethods: map[string]bool{"Chat": true},
+	}
+	tmpl := NewProtoTemplate(info, "svc")
+	if err := tmpl.Prepare(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Render(context.Background()).Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	proto := buf.String()
+	for _, s := range []string{
+		"message CountRequest {\n" +
+			"    reserved 3 to 4, 7;\n" +
+			"    reserved \"old\", \"symbol\";\n" +
+			"    string text = 2;\n" +
+			"    int64 limit = 10;\n" +
+			"    int64 offset = 11;\n" +
+			"}",
+		"message CountResponse {\n    int64 count = 1;\n}",
+		"message ChatRequest {\n    string text = 1;\n}",
+		"message ChatResponse {\n    // TODO: declare fields of streamed message\n}",
+	} {
+		assert.Contains(t, proto, s)
+	}
+
+	for _, doc := range []string{
+		"// @proto-field limit=0",
+		"// @proto-field limit=19000",
+		"// @proto-field size=2",
+		"// @proto-field text=2,limit=2",
+	} {
+		fn := file.Interfaces[0].Methods[0]
+		fn.Docs = []string{doc}
+		_, err := ProtoFieldNumbers(fn)
+		assert.Error(t, err, doc)
+	}
+}
@@ -33,21 +33,21 @@ func NewCacheMiddlewareTemplate(info *GenerationInfo) Template {
 func (t *cacheMiddlewareTemplate) Render(ctx context.Context) write_strategy.Renderer {
 	f := &Statement{}
 	// Render type Cache
-	f.Comment("Cache interface uses for middleware as key-value storage for requests.")
-	f.Line().Type().Id(cacheInterfaceName).Interface(
+	f.Comment(t.info.nsName(cacheInterfaceName) + " interface uses for middleware as key-value storage for requests.")
+	f.Line().Type().Id(t.info.nsName(cacheInterfaceName)).Interface(
 		Id("Set").Call(Op("key, value interface{}")).Call(Op("err error")),
 		Id("Get").Call(Op("key interface{}")).Call(Op("value interface{}, err error")),
 	)
 	f.Line()
 
-	f.Line().Func().Id(CachingMiddlewareName).Params(Id("cache").Id(cacheInterfaceName)).Params(Id(MiddlewareTypeName)).
+	f.Line().Func().Id(t.info.nsName(CachingMiddlewareName)).Params(Id("cache").Id(t.info.nsName(cacheInterfaceName))).Params(Id(t.info.nsName(MiddlewareTypeName))).
 		Block(t.newCacheBody(t.info.Iface))
 
 	f.Line()
 
 	// Render middleware struct
-	f.Type().Id(cachingMiddlewareStructName).Struct(
-		Id("cache").Id(cacheInterfaceName),
+	f.Type().Id(t.info.nsPrivateName(cachingMiddlewareStructName)).Struct(
+		Id("cache").Id(t.info.nsName(cacheInterfaceName)),
 		Id(_logger_).Qual(PackagePathGoKitLog, "Logger"),
 		Id(_next_).Qual(t.info.SourcePackageImport, t.info.Iface.Name),
 	)
@@ -59,7 +59,7 @@ func (t *cacheMiddlewareTemplate) Render(ctx context.Context) write_strategy.Ren
 		if !t.info.AllowedMethods[signature.Name] {
 			continue
 		}
-		f.Add(cacheEntity(ctx, signature)).Line()
+		f.Add(t.cacheEntity(ctx, signature)).Line()
 	}
 
 	file := NewFile("service")
@@ -69,8 +69,8 @@ func (t *cacheMiddlewareTemplate) Render(ctx context.Context) write_strategy.Ren
 	return file
 }
 
-func (cacheMiddlewareTemplate) DefaultPath() string {
-	return filenameBuilder(PathService, "caching")
+func (t *cacheMiddlewareTemplate) DefaultPath() string {
+	return filenameBuilder(PathService, t.info.nsFile("caching"))
 }
 
 func (t *cacheMiddlewareTemplate) Prepare(ctx context.Context) error {
@@ -99,7 +99,7 @@ func (t *cacheMiddlewareTemplate) newCacheBody(i *types.Interface) *Statement {
 	).Params(
 		Qual(t.info.SourcePackageImport, i.Name),
 	).BlockFunc(func(g *Group) {
-		g.Return(Op("&").Id(cachingMiddlewareStructName).Values(
+		g.Return(Op("&").Id(t.info.nsPrivateName(cachingMiddlewareStructName)).Values(
 			Dict{
 				Id("cache"): Id("cache"),
 				Id(_next_):  Id(_next_),
@@ -110,7 +110,7 @@ func (t *cacheMiddlewareTemplate) newCacheBody(i *types.Interface) *Statement {
 
 func (t *cacheMiddlewareTemplate) cacheFunc(ctx context.Context, signature *types.Function) *Statement {
 	normalized := normalizeFunctionResults(signature)
-	return methodDefinition(ctx, cachingMiddlewareStructName, &normalized.Function).
+	return methodDefinition(ctx, t.info.nsPrivateName(cachingMiddlewareStructName), &normalized.Function).
 		BlockFunc(t.cacheFuncBody(signature, &normalized.Function))
 }
 
@@ -121,41 +121,41 @@ func (t *cacheMiddlewareTemplate) cacheFuncBody(signature *types.Function, norma
 			if len(normalized.Results) > 0 {
 				s.Return()
 			}
-			s.Id(rec(cachingMiddlewareStructName)).Dot(_next_).Dot(signature.Name).Call(paramNames(normalized.Args))
+			s.Id(rec(t.info.nsPrivateName(cachingMiddlewareStructName))).Dot(_next_).Dot(signature.Name).Call(paramNames(normalized.Args))
 			g.Add(s)
 			return
 		}
 		if t.caching[signature.Name] {
-			g.List(Id("value"), Id("e")).Op(":=").Id(rec(cachingMiddlewareStructName)).Dot("cache").Dot("Get").Call(Id(t.cacheKeys[signature.Name]))
+			g.List(Id("value"), Id("e")).Op(":=").Id(rec(t.info.nsPrivateName(cachingMiddlewareStructName))).Dot("cache").Dot("Get").Call(Id(t.cacheKeys[signature.Name]))
 			g.If(Id("e").Op("==").Nil()).Block(
 				ReturnFunc(func(group *Group) {
 					for _, field := range removeErrorIfLast(signature.Results) {
-						group.Id("value").Assert(Op("*").Id(cacheEntityStructName(normalized))).Op(".").Add(structFieldName(&field))
+						group.Id("value").Assert(Op("*").Id(t.cacheEntityStructName(normalized))).Op(".").Add(structFieldName(&field))
 					}
 					group.Id(nameOfLastResultError(normalized))
 				}),
 			)
 			g.Defer().Func().Params().Block(
-				Id(rec(cachingMiddlewareStructName)).Dot("cache").Dot("Set").Call(
+				Id(rec(t.info.nsPrivateName(cachingMiddlewareStructName))).Dot("cache").Dot("Set").Call(
 					Id(t.cacheKeys[signature.Name]),
-					Op("&").Id(cacheEntityStructName(normalized)).Values(dictByNormalVariables(
+					Op("&").Id(t.cacheEntityStructName(normalized)).Values(dictByNormalVariables(
 						removeErrorIfLast(signature.Results),
 						removeErrorIfLast(normalized.Results),
 					)),
 				),
 			).Call()
 		}
-		g.Return().Id(rec(cachingMiddlewareStructName)).Dot(_next_).Dot(signature.Name).Call(paramNames(normalized.Args))
+		g.Return().Id(rec(t.info.nsPrivateName(cachingMiddlewareStructName))).Dot(_next_).Dot(signature.Name).Call(paramNames(normalized.Args))
 	}
 }
 
-func cacheEntityStructName(signature *types.Function) string {
-	return mstrings.ToLowerFirst(responseStructName(signature) + "CacheEntity")
+func (t *cacheMiddlewareTemplate) cacheEntityStructName(signature *types.Function) string {
+	return t.info.nsPrivateName(mstrings.ToLowerFirst(signature.Name + "ResponseCacheEntity"))
 }
 
-func cacheEntity(ctx context.Context, signature *types.Function) *Statement {
+func (t *cacheMiddlewareTemplate) cacheEntity(ctx context.Context, signature *types.Function) *Statement {
 	s := &Statement{}
-	s.Type().Id(cacheEntityStructName(signature)).StructFunc(func(l *Group) {
+	s.Type().Id(t.cacheEntityStructName(signature)).StructFunc(func(l *Group) {
 		for _, field := range removeErrorIfLast(signature.Results) {
 			l.Add(structFieldName(&field)).Add(fieldType(ctx, field.Type, false))
 		}
@@ -30,14 +30,14 @@ func (t *errorLoggingTemplate) Render(ctx context.Context) write_strategy.Render
 	f.ImportAlias(t.info.SourcePackageImport, serviceAlias)
 	f.HeaderComment(t.info.FileHeader)
 
-	f.Comment("ErrorLoggingMiddleware writes to logger any error, if it is not nil.").
-		Line().Func().Id(ServiceErrorLoggingMiddlewareName).Params(Id(_logger_).Qual(PackagePathGoKitLog, "Logger")).Params(Id(MiddlewareTypeName)).
+	f.Comment(t.info.nsName(ServiceErrorLoggingMiddlewareName) + " writes to logger any error, if it is not nil.").
+		Line().Func().Id(t.info.nsName(ServiceErrorLoggingMiddlewareName)).Params(Id(_logger_).Qual(PackagePathGoKitLog, "Logger")).Params(Id(t.info.nsName(MiddlewareTypeName))).
 		Block(t.newRecoverBody(t.info.Iface))
 
 	f.Line()
 
 	// Render type logger
-	f.Type().Id(serviceErrorLoggingStructName).Struct(
+	f.Type().Id(t.info.nsPrivateName(serviceErrorLoggingStructName)).Struct(
 		Id(_logger_).Qual(PackagePathGoKitLog, "Logger"),
 		Id(_next_).Qual(t.info.SourcePackageImport, t.info.Iface.Name),
 	)
@@ -51,8 +51,8 @@ func (t *errorLoggingTemplate) Render(ctx context.Context) write_strategy.Render
 	return f
 }
 
-func (errorLoggingTemplate) DefaultPath() string {
-	return filenameBuilder(PathService, "error_logging")
+func (t *errorLoggingTemplate) DefaultPath() string {
+	return filenameBuilder(PathService, t.info.nsFile("error_logging"))
 }
 
 func (t *errorLoggingTemplate) Prepare(ctx context.Context) error {
@@ -69,7 +69,7 @@ func (t *errorLoggingTemplate) newRecoverBody(i *types.Interface) *Statement {
 	).Params(
 		Qual(t.info.SourcePackageImport, i.Name),
 	).BlockFunc(func(g *Group) {
-		g.Return(Op("&").Id(serviceErrorLoggingStructName).Values(
+		g.Return(Op("&").Id(t.info.nsPrivateName(serviceErrorLoggingStructName)).Values(
 			Dict{
 				Id(_logger_): Id(_logger_),
 				Id(_next_):   Id(_next_),
@@ -79,7 +79,7 @@ func (t *errorLoggingTemplate) newRecoverBody(i *types.Interface) *Statement {
 }
 
 func (t *errorLoggingTemplate) recoverFunc(ctx context.Context, signature *types.Function) *Statement {
-	return methodDefinition(ctx, serviceErrorLoggingStructName, signature).
+	return methodDefinition(ctx, t.info.nsPrivateName(serviceErrorLoggingStructName), signature).
 		BlockFunc(t.recoverFuncBody(signature))
 }
 
@@ -90,19 +90,19 @@ func (t *errorLoggingTemplate) recoverFuncBody(signature *types.Function) func(g
 			if len(signature.Results) > 0 {
 				s.Return()
 			}
-			s.Id(rec(serviceErrorLoggingStructName)).Dot(_next_).Dot(signature.Name).Call(paramNames(signature.Args))
+			s.Id(rec(t.info.nsPrivateName(serviceErrorLoggingStructName))).Dot(_next_).Dot(signature.Name).Call(paramNames(signature.Args))
 			g.Add(s)
 			return
 		}
 		g.Defer().Func().Params().Block(
 			If(Id(nameOfLastResultError(signature)).Op("!=").Nil()).Block(
-				Id(rec(serviceErrorLoggingStructName)).Dot(_logger_).Dot("Log").Call(
+				Id(rec(t.info.nsPrivateName(serviceErrorLoggingStructName))).Dot(_logger_).Dot("Log").Call(
 					Lit("method"), Lit(signature.Name),
 					Lit("message"), Id(nameOfLastResultError(signature)),
 				),
 			),
 		).Call()
 
-		g.Return().Id(rec(serviceErrorLoggingStructName)).Dot(_next_).Dot(signature.Name).Call(paramNames(signature.Args))
+		g.Return().Id(rec(t.info.nsPrivateName(serviceErrorLoggingStructName))).Dot(_next_).Dot(signature.Name).Call(paramNames(signature.Args))
 	}
 }
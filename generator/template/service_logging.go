@@ -76,14 +76,14 @@ func (t *loggingTemplate) Render(ctx context.Context) write_strategy.Renderer {
 	f.ImportAlias(t.info.SourcePackageImport, serviceAlias)
 	f.HeaderComment(t.info.FileHeader)
 
-	f.Comment(ServiceLoggingMiddlewareName + " writes params, results and working time of method call to provided logger after its execution.").
-		Line().Func().Id(ServiceLoggingMiddlewareName).Params(Id(_logger_).Qual(PackagePathGoKitLog, "Logger")).Params(Id(MiddlewareTypeName)).
+	f.Comment(t.info.nsName(ServiceLoggingMiddlewareName) + " writes params, results and working time of method call to provided logger after its execution.").
+		Line().Func().Id(t.info.nsName(ServiceLoggingMiddlewareName)).Params(Id(_logger_).Qual(PackagePathGoKitLog, "Logger")).Params(Id(t.info.nsName(MiddlewareTypeName))).
 		Block(t.newLoggingBody(t.info.Iface))
 
 	f.Line()
 
 	// Render type logger
-	f.Type().Id(serviceLoggingStructName).Struct(
+	f.Type().Id(t.info.nsPrivateName(serviceLoggingStructName)).Struct(
 		Id(_logger_).Qual(PackagePathGoKitLog, "Logger"),
 		Id(_next_).Qual(t.info.SourcePackageImport, t.info.Iface.Name),
 	)
@@ -98,10 +98,10 @@ func (t *loggingTemplate) Render(ctx context.Context) write_strategy.Renderer {
 	}
 	for _, signature := range t.info.Iface.Methods {
 		if params := RemoveContextIfFirst(signature.Args); t.calcParamAmount(signature.Name, params) > 0 {
-			f.Add(t.loggingEntity(ctx, "log"+requestStructName(signature), signature, params))
+			f.Add(t.loggingEntity(ctx, "log"+t.info.requestStructName(signature), signature, params))
 		}
 		if params := removeErrorIfLast(signature.Results); t.calcParamAmount(signature.Name, params) > 0 {
-			f.Add(t.loggingEntity(ctx, "log"+responseStructName(signature), signature, params))
+			f.Add(t.loggingEntity(ctx, "log"+t.info.responseStructName(signature), signature, params))
 		}
 	}
 	if len(t.info.Iface.Methods) > 0 {
@@ -111,8 +111,8 @@ func (t *loggingTemplate) Render(ctx context.Context) write_strategy.Renderer {
 	return f
 }
 
-func (loggingTemplate) DefaultPath() string {
-	return filenameBuilder(PathService, "logging")
+func (t *loggingTemplate) DefaultPath() string {
+	return filenameBuilder(PathService, t.info.nsFile("logging"))
 }
 
 func (t *loggingTemplate) Prepare(ctx context.Context) error {
@@ -144,7 +144,7 @@ func (t *loggingTemplate) newLoggingBody(i *types.Interface) *Statement {
 	).Params(
 		Qual(t.info.SourcePackageImport, i.Name),
 	).BlockFunc(func(g *Group) {
-		g.Return(Op("&").Id(serviceLoggingStructName).Values(
+		g.Return(Op("&").Id(t.info.nsPrivateName(serviceLoggingStructName)).Values(
 			Dict{
 				Id(_logger_): Id(_logger_),
 				Id(_next_):   Id(_next_),
@@ -189,7 +189,7 @@ func (t *loggingTemplate) loggingEntity(ctx context.Context, name string, fn *ty
 //
 func (t *loggingTemplate) loggingFunc(ctx context.Context, signature *types.Function) *Statement {
 	normal := normalizeFunction(signature)
-	return methodDefinition(ctx, serviceLoggingStructName, &normal.Function).
+	return methodDefinition(ctx, t.info.nsPrivateName(serviceLoggingStructName), &normal.Function).
 		BlockFunc(t.loggingFuncBody(signature))
 }
 
@@ -212,12 +212,12 @@ func (t *loggingTemplate) loggingFuncBody(signature *types.Function) func(g *Gro
 			if len(normal.Results) > 0 {
 				s.Return()
 			}
-			s.Id(rec(serviceLoggingStructName)).Dot(_next_).Dot(signature.Name).Call(paramNames(normal.Args))
+			s.Id(rec(t.info.nsPrivateName(serviceLoggingStructName))).Dot(_next_).Dot(signature.Name).Call(paramNames(normal.Args))
 			g.Add(s)
 			return
 		}
 		g.Defer().Func().Params(Id("begin").Qual(PackagePathTime, "Time")).Block(
-			Id(rec(serviceLoggingStructName)).Dot(_logger_).Dot("Log").CallFunc(func(g *Group) {
+			Id(rec(t.info.nsPrivateName(serviceLoggingStructName))).Dot(_logger_).Dot("Log").CallFunc(func(g *Group) {
 				g.Line().Lit("method")
 				g.Lit(signature.Name)
 				g.Line().Lit("message")
@@ -237,7 +237,7 @@ func (t *loggingTemplate) loggingFuncBody(signature *types.Function) func(g *Gro
 				g.Qual(PackagePathTime, "Since").Call(Id("begin"))
 			}),
 		).Call(Qual(PackagePathTime, "Now").Call())
-		g.Return().Id(rec(serviceLoggingStructName)).Dot(_next_).Dot(signature.Name).Call(paramNames(normal.Args))
+		g.Return().Id(rec(t.info.nsPrivateName(serviceLoggingStructName))).Dot(_next_).Dot(signature.Name).Call(paramNames(normal.Args))
 	}
 }
 
@@ -282,7 +282,7 @@ func (t *loggingTemplate) logRequest(fn *normalizedFunction) *Statement {
 	if paramAmount <= 0 {
 		return Lit("")
 	}
-	return Id("log" + requestStructName(fn.parent)).Add(t.fillMap(fn.parent, RemoveContextIfFirst(fn.parent.Args), RemoveContextIfFirst(fn.Args)))
+	return Id("log" + t.info.requestStructName(fn.parent)).Add(t.fillMap(fn.parent, RemoveContextIfFirst(fn.parent.Args), RemoveContextIfFirst(fn.Args)))
 }
 
 func (t *loggingTemplate) logResponse(fn *normalizedFunction) *Statement {
@@ -290,7 +290,7 @@ func (t *loggingTemplate) logResponse(fn *normalizedFunction) *Statement {
 	if paramAmount <= 0 {
 		return Lit("")
 	}
-	return Id("log" + responseStructName(fn.parent)).Add(t.fillMap(fn.parent, removeErrorIfLast(fn.parent.Results), RemoveContextIfFirst(fn.Results)))
+	return Id("log" + t.info.responseStructName(fn.parent)).Add(t.fillMap(fn.parent, removeErrorIfLast(fn.parent.Results), RemoveContextIfFirst(fn.Results)))
 }
 
 func (t *loggingTemplate) calcParamAmount(name string, params []types.Variable) int {
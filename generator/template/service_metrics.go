@@ -0,0 +1,199 @@
+package template
+
+import (
+	"context"
+
+	. "github.com/dave/jennifer/jen"
+	mstrings "github.com/recolabs/microgen/generator/strings"
+	"github.com/recolabs/microgen/generator/write_strategy"
+	"github.com/vetcher/go-astra/types"
+)
+
+const (
+	serviceMetricsStructName = "metricsMiddleware"
+
+	_requestCount_   = "requestCount"
+	_errorCount_     = "errorCount"
+	_requestLatency_ = "requestLatency"
+)
+
+var (
+	ServiceMetricsMiddlewareName           = mstrings.ToUpperFirst(serviceMetricsStructName)
+	ServicePrometheusMetricsMiddlewareName = "Prometheus" + ServiceMetricsMiddlewareName
+)
+
+type metricsTemplate struct {
+	info *GenerationInfo
+}
+
+func NewMetricsTemplate(info *GenerationInfo) Template {
+	return &metricsTemplate{
+		info: info,
+	}
+}
+
+// Render metrics middleware.
+//
+//		// MetricsMiddleware collects count of requests, count of errors and latency of every method call.
+//		func MetricsMiddleware(requestCount metrics.Counter, errorCount metrics.Counter, requestLatency metrics.Histogram) Middleware {
+//			return func(next service.StringService) service.StringService {
+//				return &metricsMiddleware{
+//					errorCount:     errorCount,
+//					next:           next,
+//					requestCount:   requestCount,
+//					requestLatency: requestLatency,
+//				}
+//			}
+//		}
+//
+//		func (M metricsMiddleware) Count(ctx context.Context, text string, symbol string) (count int, positions []int, err error) {
+//			defer func(begin time.Time) {
+//				M.requestCount.With("method", "Count").Add(1)
+//				if err != nil {
+//					M.errorCount.With("method", "Count").Add(1)
+//				}
+//				M.requestLatency.With("method", "Count").Observe(time.Since(begin).Seconds())
+//			}(time.Now())
+//			return M.next.Count(ctx, text, symbol)
+//		}
+//
+func (t *metricsTemplate) Render(ctx context.Context) write_strategy.Renderer {
+	f := NewFile("service")
+	f.ImportAlias(t.info.SourcePackageImport, serviceAlias)
+	f.ImportAlias(PackagePathGoKitMetricsPrometheus, "kitprometheus")
+	f.ImportAlias(PackagePathPrometheus, "prometheus")
+	f.HeaderComment(t.info.FileHeader)
+
+	f.Comment(t.info.nsName(ServiceMetricsMiddlewareName) + " collects count of requests, count of errors and latency of every method call.").
+		Line().Func().Id(t.info.nsName(ServiceMetricsMiddlewareName)).Params(
+		Id(_requestCount_).Qual(PackagePathGoKitMetrics, "Counter"),
+		Id(_errorCount_).Qual(PackagePathGoKitMetrics, "Counter"),
+		Id(_requestLatency_).Qual(PackagePathGoKitMetrics, "Histogram"),
+	).Params(Id(t.info.nsName(MiddlewareTypeName))).
+		Block(t.newMetricsBody(t.info.Iface))
+
+	f.Line()
+	f.Add(t.prometheusConstructor())
+	f.Line()
+
+	// Render type metrics
+	f.Type().Id(t.info.nsPrivateName(serviceMetricsStructName)).Struct(
+		Id(_requestCount_).Qual(PackagePathGoKitMetrics, "Counter"),
+		Id(_errorCount_).Qual(PackagePathGoKitMetrics, "Counter"),
+		Id(_requestLatency_).Qual(PackagePathGoKitMetrics, "Histogram"),
+		Id(_next_).Qual(t.info.SourcePackageImport, t.info.Iface.Name),
+	)
+
+	// Render functions
+	for _, signature := range t.info.Iface.Methods {
+		f.Line()
+		f.Add(t.metricsFunc(ctx, signature)).Line()
+	}
+
+	return f
+}
+
+func (t *metricsTemplate) DefaultPath() string {
+	return filenameBuilder(PathService, t.info.nsFile("metrics"))
+}
+
+func (t *metricsTemplate) Prepare(ctx context.Context) error {
+	return nil
+}
+
+func (t *metricsTemplate) ChooseStrategy(ctx context.Context) (write_strategy.Strategy, error) {
+	return write_strategy.NewCreateFileStrategy(t.info.OutputFilePath, t.DefaultPath()), nil
+}
+
+func (t *metricsTemplate) newMetricsBody(i *types.Interface) *Statement {
+	return Return(Func().Params(
+		Id(_next_).Qual(t.info.SourcePackageImport, i.Name),
+	).Params(
+		Qual(t.info.SourcePackageImport, i.Name),
+	).BlockFunc(func(g *Group) {
+		g.Return(Op("&").Id(t.info.nsPrivateName(serviceMetricsStructName)).Values(
+			Dict{
+				Id(_requestCount_):   Id(_requestCount_),
+				Id(_errorCount_):     Id(_errorCount_),
+				Id(_requestLatency_): Id(_requestLatency_),
+				Id(_next_):           Id(_next_),
+			},
+		))
+	}))
+}
+
+// Renders constructor, which registers prometheus collectors in default registry.
+//
+//		// PrometheusMetricsMiddleware creates MetricsMiddleware with collectors, registered in default prometheus registry.
+//		func PrometheusMetricsMiddleware(namespace string) Middleware {
+//			return MetricsMiddleware(
+//				kitprometheus.NewCounterFrom(prometheus.CounterOpts{
+//					Help:      "Number of requests received.",
+//					Name:      "request_count",
+//					Namespace: namespace,
+//				}, []string{"method"}),
+//				...
+//			)
+//		}
+//
+func (t *metricsTemplate) prometheusConstructor() *Statement {
+	labels := Index().String().Values(Lit("method"))
+	opts := func(name, help string) Dict {
+		return Dict{
+			Id("Namespace"): Id("namespace"),
+			Id("Name"):      Lit(name),
+			Id("Help"):      Lit(help),
+		}
+	}
+	return Comment(t.info.nsName(ServicePrometheusMetricsMiddlewareName)+" creates "+t.info.nsName(ServiceMetricsMiddlewareName)+" with collectors, registered in default prometheus registry.").
+		Line().Func().Id(t.info.nsName(ServicePrometheusMetricsMiddlewareName)).Params(Id("namespace").String()).Params(Id(t.info.nsName(MiddlewareTypeName))).Block(
+		Return().Id(t.info.nsName(ServiceMetricsMiddlewareName)).Call(
+			Line().Qual(PackagePathGoKitMetricsPrometheus, "NewCounterFrom").Call(
+				Qual(PackagePathPrometheus, "CounterOpts").Values(opts("request_count", "Number of requests received.")),
+				labels,
+			),
+			Line().Qual(PackagePathGoKitMetricsPrometheus, "NewCounterFrom").Call(
+				Qual(PackagePathPrometheus, "CounterOpts").Values(opts("error_count", "Number of requests, that returned an error.")),
+				labels,
+			),
+			Line().Qual(PackagePathGoKitMetricsPrometheus, "NewHistogramFrom").Call(
+				Qual(PackagePathPrometheus, "HistogramOpts").Values(opts("request_latency_seconds", "Duration of requests in seconds.")),
+				labels,
+			),
+			Line(),
+		),
+	)
+}
+
+func (t *metricsTemplate) metricsFunc(ctx context.Context, signature *types.Function) *Statement {
+	return methodDefinition(ctx, t.info.nsPrivateName(serviceMetricsStructName), signature).
+		BlockFunc(t.metricsFuncBody(signature))
+}
+
+func (t *metricsTemplate) metricsFuncBody(signature *types.Function) func(g *Group) {
+	return func(g *Group) {
+		if !t.info.AllowedMethods[signature.Name] {
+			s := &Statement{}
+			if len(signature.Results) > 0 {
+				s.Return()
+			}
+			s.Id(rec(t.info.nsPrivateName(serviceMetricsStructName))).Dot(_next_).Dot(signature.Name).Call(paramNames(signature.Args))
+			g.Add(s)
+			return
+		}
+		method := func(field string) *Statement {
+			return Id(rec(t.info.nsPrivateName(serviceMetricsStructName))).Dot(field).Dot("With").Call(Lit("method"), Lit(signature.Name))
+		}
+		g.Defer().Func().Params(Id("begin").Qual(PackagePathTime, "Time")).Block(
+			method(_requestCount_).Dot("Add").Call(Lit(1)),
+			If(Id(nameOfLastResultError(signature)).Op("!=").Nil()).Block(
+				method(_errorCount_).Dot("Add").Call(Lit(1)),
+			),
+			method(_requestLatency_).Dot("Observe").Call(
+				Qual(PackagePathTime, "Since").Call(Id("begin")).Dot("Seconds").Call(),
+			),
+		).Call(Qual(PackagePathTime, "Now").Call())
+
+		g.Return().Id(rec(t.info.nsPrivateName(serviceMetricsStructName))).Dot(_next_).Dot(signature.Name).Call(paramNames(signature.Args))
+	}
+}
@@ -36,12 +36,12 @@ func (t *middlewareTemplate) Render(ctx context.Context) write_strategy.Renderer
 	f.ImportAlias(t.info.SourcePackageImport, serviceAlias)
 	f.HeaderComment(t.info.FileHeader)
 	f.Comment("Service middleware (closure).").
-		Line().Type().Id(MiddlewareTypeName).Func().Call(Qual(t.info.SourcePackageImport, t.info.Iface.Name)).Qual(t.info.SourcePackageImport, t.info.Iface.Name)
+		Line().Type().Id(t.info.nsName(MiddlewareTypeName)).Func().Call(Qual(t.info.SourcePackageImport, t.info.Iface.Name)).Qual(t.info.SourcePackageImport, t.info.Iface.Name)
 	return f
 }
 
-func (middlewareTemplate) DefaultPath() string {
-	return filenameBuilder(PathService, "middleware")
+func (t *middlewareTemplate) DefaultPath() string {
+	return filenameBuilder(PathService, t.info.nsFile("middleware"))
 }
 
 func (middlewareTemplate) Prepare(ctx context.Context) error {
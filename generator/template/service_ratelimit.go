@@ -0,0 +1,385 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/dave/jennifer/jen"
+	mstrings "github.com/recolabs/microgen/generator/strings"
+	"github.com/recolabs/microgen/generator/write_strategy"
+	"github.com/vetcher/go-astra/types"
+)
+
+const (
+	rateLimitTag    = "rate-limit"
+	rateLimitKeyTag = "rate-limit-key"
+
+	serviceRateLimitingStructName = "rateLimitingMiddleware"
+	rateLimitErrorName            = "RateLimitError"
+	rateLimiterStructName         = "rateLimiter"
+	rateLimitKeyStructName        = "rateLimitKey"
+	tokenBucketStructName         = "tokenBucket"
+)
+
+var ServiceRateLimitingMiddlewareName = mstrings.ToUpperFirst(serviceRateLimitingStructName)
+
+// rateLimit is a token bucket of method from @rate-limit tag.
+type rateLimit struct {
+	// rate is a count of tokens, which are added to bucket every second.
+	rate  float64
+	burst int
+	// key is an expression from @rate-limit-key tag. Calls with different keys have different buckets.
+	key string
+}
+
+type rateLimitTemplate struct {
+	info   *GenerationInfo
+	limits map[string]rateLimit
+}
+
+func NewRateLimitTemplate(info *GenerationInfo) Template {
+	return &rateLimitTemplate{
+		info: info,
+	}
+}
+
+// Render rate limiting middleware.
+//
+//		// RateLimitError is returned, when calls of method exceed its @rate-limit.
+//		// Transports return it with HTTP status 429 and gRPC code RESOURCE_EXHAUSTED.
+//		type RateLimitError struct {
+//			Method string
+//		}
+//
+//		// RateLimitingMiddleware rejects calls of methods, which exceed limits of @rate-limit tags, with RateLimitError.
+//		func RateLimitingMiddleware() Middleware {
+//			return func(next service.StringService) service.StringService {
+//				return &rateLimitingMiddleware{
+//					limiter: &rateLimiter{buckets: make(map[rateLimitKey]*tokenBucket)},
+//					next:    next,
+//				}
+//			}
+//		}
+//
+//		func (M rateLimitingMiddleware) Count(ctx context.Context, text string, symbol string) (count int, positions []int, err error) {
+//			if !M.limiter.allow(rateLimitKey{
+//				key:    fmt.Sprint(text),
+//				method: "Count",
+//			}, 100.0, 20) {
+//				err = &RateLimitError{Method: "Count"}
+//				return
+//			}
+//			return M.next.Count(ctx, text, symbol)
+//		}
+//
+func (t *rateLimitTemplate) Render(ctx context.Context) write_strategy.Renderer {
+	f := NewFile("service")
+	f.ImportAlias(t.info.SourcePackageImport, serviceAlias)
+	f.HeaderComment(t.info.FileHeader)
+
+	f.Add(t.rateLimitError(ctx))
+
+	f.Line().Comment(t.info.nsName(ServiceRateLimitingMiddlewareName)+" rejects calls of methods, which exceed limits of @"+rateLimitTag+" tags, with "+t.info.nsName(rateLimitErrorName)+".").
+		Line().Func().Id(t.info.nsName(ServiceRateLimitingMiddlewareName)).Params().Params(Id(t.info.nsName(MiddlewareTypeName))).
+		Block(t.newRateLimitBody(t.info.Iface))
+
+	f.Line()
+	f.Type().Id(t.info.nsPrivateName(serviceRateLimitingStructName)).Struct(
+		Id("limiter").Op("*").Id(t.info.nsPrivateName(rateLimiterStructName)),
+		Id(_next_).Qual(t.info.SourcePackageImport, t.info.Iface.Name),
+	)
+
+	for _, signature := range t.info.Iface.Methods {
+		f.Line()
+		f.Add(t.rateLimitFunc(ctx, signature)).Line()
+	}
+
+	f.Line().Add(t.rateLimiter())
+	return f
+}
+
+func (t *rateLimitTemplate) DefaultPath() string {
+	return filenameBuilder(PathService, t.info.nsFile("ratelimit"))
+}
+
+func (t *rateLimitTemplate) Prepare(ctx context.Context) error {
+	t.limits = make(map[string]rateLimit)
+	for _, fn := range t.info.Iface.Methods {
+		value, ok := fetchTagLine(fn.Docs, rateLimitTag)
+		if !ok {
+			continue
+		}
+		limit, err := parseRateLimit(value)
+		if err != nil {
+			return fmt.Errorf("%s: %v", fn.Name, err)
+		}
+		if !IsErrorLast(fn.Results) {
+			return fmt.Errorf("%s: @%s: method should return error as last result", fn.Name, rateLimitTag)
+		}
+		limit.key, _ = fetchTagLine(fn.Docs, rateLimitKeyTag)
+		t.limits[fn.Name] = limit
+	}
+	return nil
+}
+
+func (t *rateLimitTemplate) ChooseStrategy(ctx context.Context) (write_strategy.Strategy, error) {
+	return write_strategy.NewCreateFileStrategy(t.info.OutputFilePath, t.DefaultPath()), nil
+}
+
+// fetchTagLine returns value of tag with spaces, e.g. `100/s burst=20` of `// @rate-limit 100/s burst=20`.
+func fetchTagLine(docs []string, tag string) (string, bool) {
+	prefix := TagMark + tag + " "
+	for _, doc := range docs {
+		if strings.HasPrefix(doc, prefix) {
+			return strings.TrimSpace(doc[len(prefix):]), true
+		}
+	}
+	return "", false
+}
+
+// parseRateLimit parses value of @rate-limit tag. Period of rate is a unit or a duration.
+// Burst is a count of calls per period by default.
+//
+//		// @rate-limit 100/s burst=20
+//		// @rate-limit 10/m
+//		// @rate-limit 5/500ms burst=1
+//
+func parseRateLimit(value string) (rateLimit, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return rateLimit{}, fmt.Errorf("@%s: rate should be N/period, e.g. 100/s", rateLimitTag)
+	}
+	slash := strings.Index(fields[0], "/")
+	if slash < 0 {
+		return rateLimit{}, fmt.Errorf("@%s: %s should be N/period, e.g. 100/s", rateLimitTag, fields[0])
+	}
+	count, err := strconv.ParseFloat(fields[0][:slash], 64)
+	if err != nil || count <= 0 {
+		return rateLimit{}, fmt.Errorf("@%s: %s: count of calls should be positive number", rateLimitTag, fields[0])
+	}
+	period, err := time.ParseDuration(fields[0][slash+1:])
+	if err != nil {
+		period, err = time.ParseDuration("1" + fields[0][slash+1:])
+	}
+	if err != nil || period <= 0 {
+		return rateLimit{}, fmt.Errorf("@%s: %s: period should be unit or duration, e.g. s, m or 500ms", rateLimitTag, fields[0])
+	}
+	limit := rateLimit{
+		rate:  count / period.Seconds(),
+		burst: int(math.Ceil(count)),
+	}
+	for _, option := range fields[1:] {
+		if !strings.HasPrefix(option, "burst=") {
+			return rateLimit{}, fmt.Errorf("@%s: unknown option %s", rateLimitTag, option)
+		}
+		burst, err := strconv.Atoi(strings.TrimPrefix(option, "burst="))
+		if err != nil || burst <= 0 {
+			return rateLimit{}, fmt.Errorf("@%s: %s: burst should be positive integer", rateLimitTag, option)
+		}
+		limit.burst = burst
+	}
+	return limit, nil
+}
+
+// Render typed error of rejected calls. Transports take HTTP status from StatusCode method,
+// and gRPC server takes code from GRPCStatus method.
+//
+//		func (e *RateLimitError) Error() string {
+//			return "rate limit of " + e.Method + " is exceeded"
+//		}
+//
+//		func (e *RateLimitError) StatusCode() int {
+//			return http.StatusTooManyRequests
+//		}
+//
+//		func (e *RateLimitError) GRPCStatus() *status.Status {
+//			return status.New(codes.ResourceExhausted, e.Error())
+//		}
+//
+func (t *rateLimitTemplate) rateLimitError(ctx context.Context) *Statement {
+	name := t.info.nsName(rateLimitErrorName)
+	s := Comment(name + " is returned, when calls of method exceed its @" + rateLimitTag + ".").
+		Line().Comment("Transports return it with HTTP status 429 and gRPC code RESOURCE_EXHAUSTED.").
+		Line().Type().Id(name).Struct(
+		Id("Method").String(),
+	)
+	s.Line().Line().Func().Params(Id("e").Op("*").Id(name)).Id("Error").Params().String().Block(
+		Return(Lit("rate limit of ").Op("+").Id("e").Dot("Method").Op("+").Lit(" is exceeded")),
+	)
+	s.Line().Line().Func().Params(Id("e").Op("*").Id(name)).Id("StatusCode").Params().Int().Block(
+		Return(Qual(PackagePathHttp, "StatusTooManyRequests")),
+	)
+	if Tags(ctx).HasAny(GrpcTag, GrpcServerTag) {
+		s.Line().Line().Func().Params(Id("e").Op("*").Id(name)).Id("GRPCStatus").Params().Op("*").Qual(PackagePathGoogleGRPCStatus, "Status").Block(
+			Return(Qual(PackagePathGoogleGRPCStatus, "New").Call(Qual(PackagePathGoogleGRPCCodes, "ResourceExhausted"), Id("e").Dot("Error").Call())),
+		)
+	}
+	return s.Line()
+}
+
+func (t *rateLimitTemplate) newRateLimitBody(i *types.Interface) *Statement {
+	return Return(Func().Params(
+		Id(_next_).Qual(t.info.SourcePackageImport, i.Name),
+	).Params(
+		Qual(t.info.SourcePackageImport, i.Name),
+	).BlockFunc(func(g *Group) {
+		g.Return(Op("&").Id(t.info.nsPrivateName(serviceRateLimitingStructName)).Values(
+			Dict{
+				Id("limiter"): Op("&").Id(t.info.nsPrivateName(rateLimiterStructName)).Values(Dict{
+					Id("buckets"): Make(Map(Id(t.info.nsPrivateName(rateLimitKeyStructName))).Op("*").Id(t.info.nsPrivateName(tokenBucketStructName))),
+				}),
+				Id(_next_): Id(_next_),
+			},
+		))
+	}))
+}
+
+func (t *rateLimitTemplate) rateLimitFunc(ctx context.Context, signature *types.Function) *Statement {
+	return methodDefinition(ctx, t.info.nsPrivateName(serviceRateLimitingStructName), signature).
+		BlockFunc(t.rateLimitFuncBody(signature))
+}
+
+func (t *rateLimitTemplate) rateLimitFuncBody(signature *types.Function) func(g *Group) {
+	return func(g *Group) {
+		limit, ok := t.limits[signature.Name]
+		if ok && t.info.AllowedMethods[signature.Name] {
+			key := Dict{Id("method"): Lit(signature.Name)}
+			if limit.key != "" {
+				key[Id("key")] = Qual(PackagePathFmt, "Sprint").Call(Id(limit.key))
+			}
+			g.If(Op("!").Id(rec(t.info.nsPrivateName(serviceRateLimitingStructName))).Dot("limiter").Dot("allow").Call(
+				Id(t.info.nsPrivateName(rateLimitKeyStructName)).Values(key),
+				Lit(limit.rate),
+				Lit(limit.burst),
+			)).Block(
+				Id(nameOfLastResultError(signature)).Op("=").Op("&").Id(t.info.nsName(rateLimitErrorName)).Values(Dict{
+					Id("Method"): Lit(signature.Name),
+				}),
+				Return(),
+			)
+		}
+		s := &Statement{}
+		if len(signature.Results) > 0 {
+			s.Return()
+		}
+		s.Id(rec(t.info.nsPrivateName(serviceRateLimitingStructName))).Dot(_next_).Dot(signature.Name).Call(paramNames(signature.Args))
+		g.Add(s)
+	}
+}
+
+// Render token buckets, which are shared by all calls of middleware.
+// Buckets, which are refilled, are removed every minute, so keys of @rate-limit-key do not take memory forever.
+//
+//		type rateLimitKey struct {
+//			method string
+//			key    string
+//		}
+//
+//		type tokenBucket struct {
+//			tokens float64
+//			rate   float64
+//			burst  float64
+//			last   time.Time
+//		}
+//
+//		type rateLimiter struct {
+//			mu      sync.Mutex
+//			buckets map[rateLimitKey]*tokenBucket
+//			swept   time.Time
+//		}
+//
+//		// allow takes token from bucket of key. Bucket is refilled with rate tokens per second up to burst tokens.
+//		func (l *rateLimiter) allow(key rateLimitKey, rate float64, burst int) bool {
+//			l.mu.Lock()
+//			defer l.mu.Unlock()
+//			now := time.Now()
+//			if now.Sub(l.swept) > time.Minute {
+//				for k, b := range l.buckets {
+//					if b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.burst {
+//						delete(l.buckets, k)
+//					}
+//				}
+//				l.swept = now
+//			}
+//			b, ok := l.buckets[key]
+//			if !ok {
+//				b = &tokenBucket{
+//					burst:  float64(burst),
+//					last:   now,
+//					rate:   rate,
+//					tokens: float64(burst),
+//				}
+//				l.buckets[key] = b
+//			}
+//			b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+//			b.last = now
+//			if b.tokens < 1 {
+//				return false
+//			}
+//			b.tokens--
+//			return true
+//		}
+//
+func (t *rateLimitTemplate) rateLimiter() *Statement {
+	keyName := t.info.nsPrivateName(rateLimitKeyStructName)
+	bucketName := t.info.nsPrivateName(tokenBucketStructName)
+	limiterName := t.info.nsPrivateName(rateLimiterStructName)
+	refilled := func(b string) *Statement {
+		return Id(b).Dot("tokens").Op("+").Id("now").Dot("Sub").Call(Id(b).Dot("last")).Dot("Seconds").Call().Op("*").Id(b).Dot("rate")
+	}
+	s := Type().Id(keyName).Struct(
+		Id("method").String(),
+		Id("key").String(),
+	)
+	s.Line().Line().Type().Id(bucketName).Struct(
+		Id("tokens").Float64(),
+		Id("rate").Float64(),
+		Id("burst").Float64(),
+		Id("last").Qual(PackagePathTime, "Time"),
+	)
+	s.Line().Line().Type().Id(limiterName).Struct(
+		Id("mu").Qual(PackagePathSync, "Mutex"),
+		Id("buckets").Map(Id(keyName)).Op("*").Id(bucketName),
+		Id("swept").Qual(PackagePathTime, "Time"),
+	)
+	s.Line().Line().Comment("allow takes token from bucket of key. Bucket is refilled with rate tokens per second up to burst tokens.").
+		Line().Func().Params(Id("l").Op("*").Id(limiterName)).Id("allow").Params(
+		Id("key").Id(keyName),
+		Id("rate").Float64(),
+		Id("burst").Int(),
+	).Bool().Block(
+		Id("l").Dot("mu").Dot("Lock").Call(),
+		Defer().Id("l").Dot("mu").Dot("Unlock").Call(),
+		Id("now").Op(":=").Qual(PackagePathTime, "Now").Call(),
+		If(Id("now").Dot("Sub").Call(Id("l").Dot("swept")).Op(">").Qual(PackagePathTime, "Minute")).Block(
+			For(List(Id("k"), Id("b")).Op(":=").Range().Id("l").Dot("buckets")).Block(
+				If(refilled("b").Op(">=").Id("b").Dot("burst")).Block(
+					Delete(Id("l").Dot("buckets"), Id("k")),
+				),
+			),
+			Id("l").Dot("swept").Op("=").Id("now"),
+		),
+		List(Id("b"), Id("ok")).Op(":=").Id("l").Dot("buckets").Index(Id("key")),
+		If(Op("!").Id("ok")).Block(
+			Id("b").Op("=").Op("&").Id(bucketName).Values(Dict{
+				Id("tokens"): Float64().Call(Id("burst")),
+				Id("rate"):   Id("rate"),
+				Id("burst"):  Float64().Call(Id("burst")),
+				Id("last"):   Id("now"),
+			}),
+			Id("l").Dot("buckets").Index(Id("key")).Op("=").Id("b"),
+		),
+		Id("b").Dot("tokens").Op("=").Qual(PackagePathMath, "Min").Call(Id("b").Dot("burst"), refilled("b")),
+		Id("b").Dot("last").Op("=").Id("now"),
+		If(Id("b").Dot("tokens").Op("<").Lit(1)).Block(
+			Return(False()),
+		),
+		Id("b").Dot("tokens").Op("--"),
+		Return(True()),
+	)
+	return s
+}
@@ -0,0 +1,86 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetcher/go-astra"
+)
+
+const rateLimitTestSource = `package svc
+
+import "context"
+
+type UserService interface {
+	// @rate-limit 100/s burst=20
+	// @rate-limit-key name
+	Login(ctx context.Context, name string, password string) (token string, err error)
+	// @rate-limit 30/m
+	Ping(ctx context.Context) (ok bool, err error)
+	Version(ctx context.Context) (version string, err error)
+}
+`
+
+func TestRateLimit(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "svc.go")
+	if err := ioutil.WriteFile(source, []byte(rateLimitTestSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := astra.ParseFile(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &GenerationInfo{
+		Iface:               &file.Interfaces[0],
+		SourceFilePath:      source,
+		SourcePackageImport: "example.com/svc",
+		OutputPackageImport: "example.com/svc",
+		AllowedMethods:      map[string]bool{"Login": true, "Ping": true, "Version": true},
+	}
+	ctx := WithTags(context.Background(), TagsSet{RateLimitMiddlewareTag: {}, HttpTag: {}, GrpcTag: {}})
+	tmpl := NewRateLimitTemplate(info)
+	if err := tmpl.Prepare(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Render(ctx).Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	code := buf.String()
+	for _, s := range []string{
+		"func RateLimitingMiddleware() Middleware {",
+		"key:    fmt.Sprint(name),",
+		`}, 100.0, 20) {`,
+		`err = &RateLimitError{Method: "Login"}`,
+		`if !M.limiter.allow(rateLimitKey{method: "Ping"}, 0.5, 30) {`,
+		"return M.next.Version(ctx)",
+		"return http.StatusTooManyRequests",
+		"return status.New(codes.ResourceExhausted, e.Error())",
+	} {
+		assert.Contains(t, code, s)
+	}
+	assert.NotContains(t, code, `Method: "Version"`)
+}
+
+func TestParseRateLimit(t *testing.T) {
+	for value, want := range map[string]rateLimit{
+		"100/s burst=20":   {rate: 100, burst: 20},
+		"10/m":             {rate: 10.0 / 60, burst: 10},
+		"5/500ms burst=1":  {rate: 10, burst: 1},
+		"0.5/s":            {rate: 0.5, burst: 1},
+		"  2/h   burst=3 ": {rate: 2.0 / 3600, burst: 3},
+	} {
+		got, err := parseRateLimit(value)
+		if assert.NoError(t, err, value) {
+			assert.Equal(t, want, got, value)
+		}
+	}
+	for _, value := range []string{"", "100", "x/s", "0/s", "100/parsec", "100/s burst=0", "100/s key=name"} {
+		_, err := parseRateLimit(value)
+		assert.Error(t, err, value)
+	}
+}
@@ -30,14 +30,14 @@ func (t *recoverTemplate) Render(ctx context.Context) write_strategy.Renderer {
 	f.ImportAlias(t.info.SourcePackageImport, serviceAlias)
 	f.HeaderComment(t.info.FileHeader)
 
-	f.Comment(ServiceRecoveringMiddlewareName + " recovers panics from method calls, writes to provided logger and returns the error of panic as method error.").
-		Line().Func().Id(ServiceRecoveringMiddlewareName).Params(Id(_logger_).Qual(PackagePathGoKitLog, "Logger")).Params(Id(MiddlewareTypeName)).
+	f.Comment(t.info.nsName(ServiceRecoveringMiddlewareName) + " recovers panics from method calls, writes to provided logger and returns the error of panic as method error.").
+		Line().Func().Id(t.info.nsName(ServiceRecoveringMiddlewareName)).Params(Id(_logger_).Qual(PackagePathGoKitLog, "Logger")).Params(Id(t.info.nsName(MiddlewareTypeName))).
 		Block(t.newRecoverBody(t.info.Iface))
 
 	f.Line()
 
 	// Render type logger
-	f.Type().Id(serviceRecoveringStructName).Struct(
+	f.Type().Id(t.info.nsPrivateName(serviceRecoveringStructName)).Struct(
 		Id(_logger_).Qual(PackagePathGoKitLog, "Logger"),
 		Id(_next_).Qual(t.info.SourcePackageImport, t.info.Iface.Name),
 	)
@@ -51,8 +51,8 @@ func (t *recoverTemplate) Render(ctx context.Context) write_strategy.Renderer {
 	return f
 }
 
-func (recoverTemplate) DefaultPath() string {
-	return filenameBuilder(PathService, "recovering")
+func (t *recoverTemplate) DefaultPath() string {
+	return filenameBuilder(PathService, t.info.nsFile("recovering"))
 }
 
 func (t *recoverTemplate) Prepare(ctx context.Context) error {
@@ -69,7 +69,7 @@ func (t *recoverTemplate) newRecoverBody(i *types.Interface) *Statement {
 	).Params(
 		Qual(t.info.SourcePackageImport, i.Name),
 	).BlockFunc(func(g *Group) {
-		g.Return(Op("&").Id(serviceRecoveringStructName).Values(
+		g.Return(Op("&").Id(t.info.nsPrivateName(serviceRecoveringStructName)).Values(
 			Dict{
 				Id(_logger_): Id(_logger_),
 				Id(_next_):   Id(_next_),
@@ -79,7 +79,7 @@ func (t *recoverTemplate) newRecoverBody(i *types.Interface) *Statement {
 }
 
 func (t *recoverTemplate) recoverFunc(ctx context.Context, signature *types.Function) *Statement {
-	return methodDefinition(ctx, serviceRecoveringStructName, signature).
+	return methodDefinition(ctx, t.info.nsPrivateName(serviceRecoveringStructName), signature).
 		BlockFunc(t.recoverFuncBody(signature))
 }
 
@@ -90,19 +90,19 @@ func (t *recoverTemplate) recoverFuncBody(signature *types.Function) func(g *Gro
 			if len(signature.Results) > 0 {
 				s.Return()
 			}
-			s.Id(rec(serviceRecoveringStructName)).Dot(_next_).Dot(signature.Name).Call(paramNames(signature.Args))
+			s.Id(rec(t.info.nsPrivateName(serviceRecoveringStructName))).Dot(_next_).Dot(signature.Name).Call(paramNames(signature.Args))
 			g.Add(s)
 			return
 		}
 		g.Defer().Func().Params().Block(
 			If(Id("r").Op(":=").Recover(), Id("r").Op("!=").Nil()).Block(
-				Id(rec(serviceRecoveringStructName)).Dot(_logger_).Dot("Log").Call(
+				Id(rec(t.info.nsPrivateName(serviceRecoveringStructName))).Dot(_logger_).Dot("Log").Call(
 					Lit("method"), Lit(signature.Name),
 					Lit("message"), Id("r"),
 				),
 				Id(nameOfLastResultError(signature)).Op("=").Qual(PackagePathFmt, "Errorf").Call(Lit("%v"), Id("r")),
 			),
 		).Call()
-		g.Return().Id(rec(serviceRecoveringStructName)).Dot(_next_).Dot(signature.Name).Call(paramNames(signature.Args))
+		g.Return().Id(rec(t.info.nsPrivateName(serviceRecoveringStructName))).Dot(_next_).Dot(signature.Name).Call(paramNames(signature.Args))
 	}
 }
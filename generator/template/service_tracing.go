@@ -0,0 +1,309 @@
+package template
+
+import (
+	"context"
+
+	. "github.com/dave/jennifer/jen"
+	mstrings "github.com/recolabs/microgen/generator/strings"
+	"github.com/recolabs/microgen/generator/write_strategy"
+	"github.com/vetcher/go-astra/types"
+)
+
+const (
+	serviceTracingStructName = "tracingMiddleware"
+
+	_tracer_ = "tracer"
+	_span_   = "span"
+)
+
+var ServiceTracingMiddlewareName = mstrings.ToUpperFirst(serviceTracingStructName)
+
+// Constructors of span attributes for builtin types. Other types are converted to string with fmt.Sprint.
+var traceAttributeTypes = map[string]string{
+	"string":    "String",
+	"bool":      "Bool",
+	"int":       "Int",
+	"int64":     "Int64",
+	"float64":   "Float64",
+	"[]string":  "StringSlice",
+	"[]bool":    "BoolSlice",
+	"[]int":     "IntSlice",
+	"[]int64":   "Int64Slice",
+	"[]float64": "Float64Slice",
+}
+
+// Types, which are converted before passing to constructor of span attribute.
+var traceAttributeConversions = map[string][2]string{
+	"int8":    {"Int64", "int64"},
+	"int16":   {"Int64", "int64"},
+	"int32":   {"Int64", "int64"},
+	"uint8":   {"Int64", "int64"},
+	"uint16":  {"Int64", "int64"},
+	"uint32":  {"Int64", "int64"},
+	"float32": {"Float64", "float64"},
+}
+
+type tracingTemplate struct {
+	info         *GenerationInfo
+	ignoreParams map[string][]string
+}
+
+func NewTracingTemplate(info *GenerationInfo) Template {
+	return &tracingTemplate{
+		info: info,
+	}
+}
+
+// Render tracing middleware.
+//
+//		// TracingMiddleware starts span of OpenTelemetry for every method call.
+//		func TracingMiddleware(tracer trace.Tracer) Middleware {
+//			return func(next service.StringService) service.StringService {
+//				return &tracingMiddleware{
+//					next:   next,
+//					tracer: tracer,
+//				}
+//			}
+//		}
+//
+//		func (M tracingMiddleware) Count(ctx context.Context, text string, symbol string) (count int, positions []int, err error) {
+//			ctx, span := M.tracer.Start(ctx, "StringService.Count", trace.WithAttributes(
+//				attribute.String("text", text),
+//				attribute.String("symbol", symbol),
+//			))
+//			defer func() {
+//				if err != nil {
+//					span.RecordError(err)
+//					span.SetStatus(codes.Error, err.Error())
+//				}
+//				span.End()
+//			}()
+//			return M.next.Count(ctx, text, symbol)
+//		}
+//
+func (t *tracingTemplate) Render(ctx context.Context) write_strategy.Renderer {
+	f := NewFile("service")
+	f.ImportAlias(t.info.SourcePackageImport, serviceAlias)
+	f.HeaderComment(t.info.FileHeader)
+
+	f.Comment(t.info.nsName(ServiceTracingMiddlewareName) + " starts span of OpenTelemetry for every method call.").
+		Line().Func().Id(t.info.nsName(ServiceTracingMiddlewareName)).Params(
+		Id(_tracer_).Qual(PackagePathOTelTrace, "Tracer"),
+	).Params(Id(t.info.nsName(MiddlewareTypeName))).
+		Block(t.newTracingBody(t.info.Iface))
+
+	f.Line()
+
+	// Render type tracing
+	f.Type().Id(t.info.nsPrivateName(serviceTracingStructName)).Struct(
+		Id(_tracer_).Qual(PackagePathOTelTrace, "Tracer"),
+		Id(_next_).Qual(t.info.SourcePackageImport, t.info.Iface.Name),
+	)
+
+	// Render functions
+	for _, signature := range t.info.Iface.Methods {
+		f.Line()
+		f.Add(t.tracingFunc(ctx, signature)).Line()
+	}
+
+	return f
+}
+
+func (t *tracingTemplate) DefaultPath() string {
+	return filenameBuilder(PathService, t.info.nsFile("tracing"))
+}
+
+// Arguments, that are hidden from logs with `@logs-ignore`, are not added to spans too.
+func (t *tracingTemplate) Prepare(ctx context.Context) error {
+	t.ignoreParams = make(map[string][]string)
+	for _, fn := range t.info.Iface.Methods {
+		t.ignoreParams[fn.Name] = mstrings.FetchTags(fn.Docs, TagMark+logIgnoreTag)
+	}
+	return nil
+}
+
+func (t *tracingTemplate) ChooseStrategy(ctx context.Context) (write_strategy.Strategy, error) {
+	return write_strategy.NewCreateFileStrategy(t.info.OutputFilePath, t.DefaultPath()), nil
+}
+
+func (t *tracingTemplate) newTracingBody(i *types.Interface) *Statement {
+	return Return(Func().Params(
+		Id(_next_).Qual(t.info.SourcePackageImport, i.Name),
+	).Params(
+		Qual(t.info.SourcePackageImport, i.Name),
+	).BlockFunc(func(g *Group) {
+		g.Return(Op("&").Id(t.info.nsPrivateName(serviceTracingStructName)).Values(
+			Dict{
+				Id(_tracer_): Id(_tracer_),
+				Id(_next_):   Id(_next_),
+			},
+		))
+	}))
+}
+
+func (t *tracingTemplate) tracingFunc(ctx context.Context, signature *types.Function) *Statement {
+	return methodDefinition(ctx, t.info.nsPrivateName(serviceTracingStructName), signature).
+		BlockFunc(t.tracingFuncBody(signature))
+}
+
+// Methods without context can not carry span, so they are called as is.
+func (t *tracingTemplate) tracingFuncBody(signature *types.Function) func(g *Group) {
+	return func(g *Group) {
+		next := Id(rec(t.info.nsPrivateName(serviceTracingStructName))).Dot(_next_).Dot(signature.Name).Call(paramNames(signature.Args))
+		if !t.info.AllowedMethods[signature.Name] || !IsContextFirst(signature.Args) {
+			s := &Statement{}
+			if len(signature.Results) > 0 {
+				s.Return()
+			}
+			g.Add(s.Add(next))
+			return
+		}
+		g.List(Id(firstArgName(signature)), Id(_span_)).Op(":=").
+			Id(rec(t.info.nsPrivateName(serviceTracingStructName))).Dot(_tracer_).Dot("Start").CallFunc(func(g *Group) {
+			g.Id(firstArgName(signature))
+			g.Lit(t.info.Iface.Name + "." + signature.Name)
+			if attributes := t.spanAttributes(signature); len(attributes) > 0 {
+				g.Qual(PackagePathOTelTrace, "WithAttributes").Call(attributes...)
+			}
+		})
+		g.Defer().Func().Params().BlockFunc(func(d *Group) {
+			if IsErrorLast(signature.Results) {
+				errName := nameOfLastResultError(signature)
+				d.If(Id(errName).Op("!=").Nil()).Block(
+					Id(_span_).Dot("RecordError").Call(Id(errName)),
+					Id(_span_).Dot("SetStatus").Call(Qual(PackagePathOTelCodes, "Error"), Id(errName).Dot("Error").Call()),
+				)
+			}
+			d.Id(_span_).Dot("End").Call()
+		}).Call()
+		g.Return().Add(next)
+	}
+}
+
+// Renders attributes of span for arguments of method, except context and ignored arguments.
+//
+//		attribute.String("text", text),
+//		attribute.Int64("limit", int64(limit)),
+//		attribute.String("comment", fmt.Sprint(comment)),
+//
+func (t *tracingTemplate) spanAttributes(signature *types.Function) []Code {
+	var attributes []Code
+	for _, field := range RemoveContextIfFirst(signature.Args) {
+		if mstrings.IsInStringSlice(field.Name, t.ignoreParams[signature.Name]) {
+			continue
+		}
+		attributes = append(attributes, Line().Add(traceAttribute(field)))
+	}
+	if len(attributes) > 0 {
+		attributes = append(attributes, Line())
+	}
+	return attributes
+}
+
+func traceAttribute(field types.Variable) *Statement {
+	name := mstrings.ToLowerFirst(field.Name)
+	typ := field.Type.String()
+	if constructor, ok := traceAttributeTypes[typ]; ok {
+		return Qual(PackagePathOTelAttribute, constructor).Call(Lit(name), Id(name))
+	}
+	if conv, ok := traceAttributeConversions[typ]; ok {
+		return Qual(PackagePathOTelAttribute, conv[0]).Call(Lit(name), Id(conv[1]).Call(Id(name)))
+	}
+	return Qual(PackagePathOTelAttribute, "String").Call(Lit(name), Qual(PackagePathFmt, "Sprint").Call(Id(name)))
+}
+
+// Renders function, that extracts W3C trace context from headers of http request.
+//
+//		// extractTraceContext reads trace context of OpenTelemetry from headers of request.
+//		func extractTraceContext(propagator propagation.TextMapPropagator) http.RequestFunc {
+//			return func(ctx context.Context, r *http1.Request) context.Context {
+//				return propagator.Extract(ctx, propagation.HeaderCarrier(r.Header))
+//			}
+//		}
+//
+func httpExtractTraceContext(name string) *Statement {
+	return Comment(name+" reads trace context of OpenTelemetry from headers of request.").Line().
+		Func().Id(name).Params(Id("propagator").Qual(PackagePathOTelPropagation, "TextMapPropagator")).Qual(PackagePathGoKitTransportHTTP, "RequestFunc").Block(
+		Return().Func().Params(Id("ctx").Qual(PackagePathContext, "Context"), Id("r").Op("*").Qual(PackagePathHttp, "Request")).Qual(PackagePathContext, "Context").Block(
+			Return().Id("propagator").Dot("Extract").Call(Id("ctx"), Qual(PackagePathOTelPropagation, "HeaderCarrier").Call(Id("r").Dot("Header"))),
+		),
+	)
+}
+
+// Renders function, that injects W3C trace context to headers of http request.
+//
+//		// injectTraceContext writes trace context of OpenTelemetry to headers of request.
+//		func injectTraceContext(propagator propagation.TextMapPropagator) http.RequestFunc {
+//			return func(ctx context.Context, r *http1.Request) context.Context {
+//				propagator.Inject(ctx, propagation.HeaderCarrier(r.Header))
+//				return ctx
+//			}
+//		}
+//
+func httpInjectTraceContext(name string) *Statement {
+	return Comment(name+" writes trace context of OpenTelemetry to headers of request.").Line().
+		Func().Id(name).Params(Id("propagator").Qual(PackagePathOTelPropagation, "TextMapPropagator")).Qual(PackagePathGoKitTransportHTTP, "RequestFunc").Block(
+		Return().Func().Params(Id("ctx").Qual(PackagePathContext, "Context"), Id("r").Op("*").Qual(PackagePathHttp, "Request")).Qual(PackagePathContext, "Context").Block(
+			Id("propagator").Dot("Inject").Call(Id("ctx"), Qual(PackagePathOTelPropagation, "HeaderCarrier").Call(Id("r").Dot("Header"))),
+			Return(Id("ctx")),
+		),
+	)
+}
+
+// Renders function, that extracts W3C trace context from metadata of gRPC request.
+//
+//		// extractTraceContext reads trace context of OpenTelemetry from metadata of request.
+//		func extractTraceContext(propagator propagation.TextMapPropagator) grpc.ServerRequestFunc {
+//			return func(ctx context.Context, md metadata.MD) context.Context {
+//				carrier := propagation.MapCarrier{}
+//				for key, values := range md {
+//					if len(values) > 0 {
+//						carrier[key] = values[0]
+//					}
+//				}
+//				return propagator.Extract(ctx, carrier)
+//			}
+//		}
+//
+func grpcExtractTraceContext(name string) *Statement {
+	return Comment(name+" reads trace context of OpenTelemetry from metadata of request.").Line().
+		Func().Id(name).Params(Id("propagator").Qual(PackagePathOTelPropagation, "TextMapPropagator")).Qual(PackagePathGoKitTransportGRPC, "ServerRequestFunc").Block(
+		Return().Func().Params(Id("ctx").Qual(PackagePathNetContext, "Context"), Id("md").Qual(PackagePathGoogleGRPCMetadata, "MD")).Qual(PackagePathNetContext, "Context").Block(
+			Id("carrier").Op(":=").Qual(PackagePathOTelPropagation, "MapCarrier").Values(),
+			For(List(Id("key"), Id("values")).Op(":=").Range().Id("md")).Block(
+				If(Len(Id("values")).Op(">").Lit(0)).Block(
+					Id("carrier").Index(Id("key")).Op("=").Id("values").Index(Lit(0)),
+				),
+			),
+			Return().Id("propagator").Dot("Extract").Call(Id("ctx"), Id("carrier")),
+		),
+	)
+}
+
+// Renders function, that injects W3C trace context to metadata of gRPC request.
+//
+//		// injectTraceContext writes trace context of OpenTelemetry to metadata of request.
+//		func injectTraceContext(propagator propagation.TextMapPropagator) grpc.ClientRequestFunc {
+//			return func(ctx context.Context, md *metadata.MD) context.Context {
+//				carrier := propagation.MapCarrier{}
+//				propagator.Inject(ctx, carrier)
+//				for key, value := range carrier {
+//					md.Set(key, value)
+//				}
+//				return ctx
+//			}
+//		}
+//
+func grpcInjectTraceContext(name string) *Statement {
+	return Comment(name+" writes trace context of OpenTelemetry to metadata of request.").Line().
+		Func().Id(name).Params(Id("propagator").Qual(PackagePathOTelPropagation, "TextMapPropagator")).Qual(PackagePathGoKitTransportGRPC, "ClientRequestFunc").Block(
+		Return().Func().Params(Id("ctx").Qual(PackagePathContext, "Context"), Id("md").Op("*").Qual(PackagePathGoogleGRPCMetadata, "MD")).Qual(PackagePathContext, "Context").Block(
+			Id("carrier").Op(":=").Qual(PackagePathOTelPropagation, "MapCarrier").Values(),
+			Id("propagator").Dot("Inject").Call(Id("ctx"), Id("carrier")),
+			For(List(Id("key"), Id("value")).Op(":=").Range().Id("carrier")).Block(
+				Id("md").Dot("Set").Call(Id("key"), Id("value")),
+			),
+			Return(Id("ctx")),
+		),
+	)
+}
@@ -0,0 +1,82 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetcher/go-astra"
+)
+
+const tracingTestSource = `package svc
+
+import "context"
+
+type UserService interface {
+	// @logs-ignore password
+	Login(ctx context.Context, name string, password string, attempt int32, tags []string, meta map[string]string) (token string, err error)
+	Ping(ctx context.Context) (ok bool)
+	Version() (version string)
+}
+`
+
+func TestTracing(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "svc.go")
+	if err := ioutil.WriteFile(source, []byte(tracingTestSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := astra.ParseFile(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &GenerationInfo{
+		Iface:                 &file.Interfaces[0],
+		SourceFilePath:        source,
+		SourcePackageImport:   "example.com/svc",
+		OutputPackageImport:   "example.com/svc",
+		ProtobufPackageImport: "example.com/svc/pb",
+		AllowedMethods:        map[string]bool{"Login": true, "Ping": true, "Version": true},
+	}
+	ctx := WithTags(context.Background(), TagsSet{OpenTelemetryTag: {}, HttpTag: {}, GrpcTag: {}})
+	render := func(tmpl Template) string {
+		if err := tmpl.Prepare(ctx); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Render(ctx).Render(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	code := render(NewTracingTemplate(info))
+	for _, s := range []string{
+		"func TracingMiddleware(tracer trace.Tracer) Middleware {",
+		`ctx, span := M.tracer.Start(ctx, "UserService.Login", trace.WithAttributes(`,
+		`attribute.String("name", name),`,
+		`attribute.Int64("attempt", int64(attempt)),`,
+		`attribute.StringSlice("tags", tags),`,
+		`attribute.String("meta", fmt.Sprint(meta)),`,
+		"span.RecordError(err)",
+		"span.SetStatus(codes.Error, err.Error())",
+		`ctx, span := M.tracer.Start(ctx, "UserService.Ping")`,
+		"return M.next.Version()",
+	} {
+		assert.Contains(t, code, s)
+	}
+	assert.NotContains(t, code, `"password"`)
+	assert.NotContains(t, code, "span.RecordError(ok)")
+
+	code = render(NewHttpServerTemplate(info))
+	assert.Contains(t, code, "propagator propagation.TextMapPropagator, opts ...http.ServerOption")
+	assert.Contains(t, code, "return propagator.Extract(ctx, propagation.HeaderCarrier(r.Header))")
+	assert.NotContains(t, code, "opentracing")
+
+	code = render(NewGRPCClientTemplate(info))
+	assert.Contains(t, code, "func TracingGRPCClientOptions(propagator propagation.TextMapPropagator)")
+	assert.Contains(t, code, "propagator.Inject(ctx, carrier)")
+	assert.NotContains(t, code, "opentracing")
+}
@@ -0,0 +1,23 @@
+package template
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/vetcher/go-astra"
+	"github.com/vetcher/go-astra/types"
+)
+
+// parseTestSource parses go source of unit test. Generated code is tested by golden files of generator package.
+func parseTestSource(t *testing.T, source string) *types.File {
+	path := filepath.Join(t.TempDir(), "svc.go")
+	if err := ioutil.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := astra.ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
@@ -0,0 +1,90 @@
+package template
+
+import (
+	"context"
+
+	. "github.com/dave/jennifer/jen"
+	"github.com/recolabs/microgen/generator/write_strategy"
+)
+
+const circuitBreakerClientEndpointsName = "CircuitBreakerClientEndpoints"
+
+type circuitBreakerTemplate struct {
+	info *GenerationInfo
+}
+
+func NewCircuitBreakerTemplate(info *GenerationInfo) Template {
+	return &circuitBreakerTemplate{
+		info: info,
+	}
+}
+
+// Render wrapping of client endpoints with circuit breakers.
+//
+//		// CircuitBreakerClientEndpoints wraps every client endpoint with circuit breaker, which is returned by breaker for name of method.
+//		// Breakers of go-kit circuitbreaker package may be used, e.g. circuitbreaker.Gobreaker.
+//		func CircuitBreakerClientEndpoints(endpoints EndpointsSet, breaker func(method string) endpoint.Middleware) EndpointsSet {
+//			return EndpointsSet{
+//				CountEndpoint: breaker("StringService.Count")(endpoints.CountEndpoint),
+//				WatchEndpoint: endpoints.WatchEndpoint,
+//			}
+//		}
+//
+func (t *circuitBreakerTemplate) Render(ctx context.Context) write_strategy.Renderer {
+	f := NewFile("transport")
+	f.HeaderComment(t.info.FileHeader)
+
+	name := t.info.nsName(circuitBreakerClientEndpointsName)
+	f.Comment(name + " wraps every client endpoint with circuit breaker, which is returned by breaker for name of method.").
+		Line().Comment("Breakers of go-kit circuitbreaker package may be used, e.g. circuitbreaker.Gobreaker.").
+		Line().Func().Id(name).Params(
+		Id("endpoints").Id(t.info.endpointsSetName()),
+		Id("breaker").Func().Params(Id("method").String()).Qual(PackagePathGoKitEndpoint, "Middleware"),
+	).Id(t.info.endpointsSetName()).Block(
+		Return(Id(t.info.endpointsSetName()).Values(DictFunc(func(d Dict) {
+			for _, fn := range t.info.Iface.Methods {
+				// Endpoints of stream methods are not calls, which may fail, so they are passed as is.
+				if isStreamMethod(t.info, fn) {
+					d[Id(endpointsStructFieldName(fn.Name))] = Id("endpoints").Dot(endpointsStructFieldName(fn.Name))
+					continue
+				}
+				if !t.info.AllowedMethods[fn.Name] {
+					continue
+				}
+				d[Id(endpointsStructFieldName(fn.Name))] = Id("breaker").Call(Lit(t.info.Iface.Name + "." + fn.Name)).Call(Id("endpoints").Dot(endpointsStructFieldName(fn.Name)))
+			}
+		}))),
+	)
+	return f
+}
+
+func (t *circuitBreakerTemplate) DefaultPath() string {
+	return filenameBuilder(PathTransport, t.info.nsFile("breaker"))
+}
+
+func (t *circuitBreakerTemplate) Prepare(ctx context.Context) error {
+	return nil
+}
+
+func (t *circuitBreakerTemplate) ChooseStrategy(ctx context.Context) (write_strategy.Strategy, error) {
+	return write_strategy.NewCreateFileStrategy(t.info.OutputFilePath, t.DefaultPath()), nil
+}
+
+// Parameters of client constructors, which wrap endpoints with circuit breakers.
+func breakerClientParams(ctx context.Context) []Code {
+	if !Tags(ctx).Has(CircuitBreakerTag) {
+		return nil
+	}
+	return []Code{Id("breaker").Func().Params(Id("method").String()).Qual(PackagePathGoKitEndpoint, "Middleware")}
+}
+
+// Renders wrapping of client endpoints with circuit breakers, when tag is set.
+//
+//		transport.CircuitBreakerClientEndpoints(endpoints, breaker)
+//
+func breakerClientEndpoints(ctx context.Context, info *GenerationInfo, endpoints Code) *Statement {
+	if !Tags(ctx).Has(CircuitBreakerTag) {
+		return Add(endpoints)
+	}
+	return Qual(info.OutputPackageImport+"/transport", info.nsName(circuitBreakerClientEndpointsName)).Call(endpoints, Id("breaker"))
+}
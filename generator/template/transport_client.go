@@ -60,10 +60,16 @@ func NewEndpointsClientTemplate(info *GenerationInfo) Template {
 //
 func (t *endpointsClientTemplate) Render(ctx context.Context) write_strategy.Renderer {
 	f := NewFile("transport")
+	f.ImportAlias(PackagePathOTelCodes, "otelcodes")
 	f.HeaderComment(t.info.FileHeader)
 	if Tags(ctx).HasAny(TracingMiddlewareTag) {
-		f.Comment("TraceClientEndpoints is used for tracing endpoints on client side.")
+		f.Comment(t.info.nsName("TraceClientEndpoints") + " is used for tracing endpoints on client side.")
+		f.Add(t.clientOpentracingMiddleware()).Line()
+	}
+	if Tags(ctx).HasAny(OpenTelemetryTag) {
+		f.Comment(t.info.nsName("TraceClientEndpoints") + " starts client span of OpenTelemetry for every endpoint call.")
 		f.Add(t.clientTracingMiddleware()).Line()
+		f.Add(t.traceClientEndpoint()).Line()
 	}
 	for _, signature := range t.info.Iface.Methods {
 		if t.info.OneToManyStreamMethods[signature.Name] {
@@ -85,7 +91,7 @@ func (t *endpointsClientTemplate) Render(ctx context.Context) write_strategy.Ren
 
 func (t *endpointsClientTemplate) serviceOneToManyStreamEndpointMethod(ctx context.Context, signature *types.Function) *Statement {
 	normal := normalizeFunction(signature)
-	return methodDefinitionFull(ctx, EndpointsSetName, &normal.Function).
+	return methodDefinitionFull(ctx, t.info.endpointsSetName(), &normal.Function).
 		BlockFunc(t.serviceOneToManyStreamEndpointMethodBody(ctx, signature, &normal.Function))
 }
 func (t *endpointsClientTemplate) serviceOneToManyStreamEndpointMethodBody(ctx context.Context, fn *types.Function, normal *types.Function) func(g *Group) {
@@ -97,17 +103,17 @@ func (t *endpointsClientTemplate) serviceOneToManyStreamEndpointMethodBody(ctx c
 			return
 		}
 
-		g.Id(reqName).Op(":=").Id(requestStructName(fn)).Values(dictByNormalVariables(removeLastVar(fn.Args), removeLastVar(normal.Args)))
-		g.Add(List(Id(nameOfLastResultError(normal))).Op("=")).Id(strings.LastWordFromName(EndpointsSetName)).Dot(endpointsStructFieldName(fn.Name)).Call(Id(firstArgName(normal)), Op("&").Id(reqName))
+		g.Id(reqName).Op(":=").Id(t.info.requestStructName(fn)).Values(dictByNormalVariables(removeLastVar(fn.Args), removeLastVar(normal.Args)))
+		g.Add(List(Id(nameOfLastResultError(normal))).Op("=")).Id(strings.LastWordFromName(t.info.endpointsSetName())).Dot(endpointsStructFieldName(fn.Name)).Call(Id(firstArgName(normal)), Op("&").Id(reqName))
 		g.If(Id(nameOfLastResultError(normal)).Op("!=").Nil().BlockFunc(func(ifg *Group) {
 			if Tags(ctx).HasAny(GrpcTag, GrpcClientTag, GrpcServerTag) {
-				ifg.Add(checkGRPCError(normal))
+				ifg.Add(checkGRPCError(t.info, normal))
 			}
 			ifg.Return()
 		}))
 		g.ReturnFunc(func(group *Group) {
 			for _, field := range removeErrorIfLast(fn.Results) {
-				group.Id(respName).Assert(Op("*").Id(responseStructName(fn))).Op(".").Add(structFieldName(&field))
+				group.Id(respName).Assert(Op("*").Id(t.info.responseStructName(fn))).Op(".").Add(structFieldName(&field))
 			}
 			group.Id(nameOfLastResultError(normal))
 		})
@@ -115,7 +121,7 @@ func (t *endpointsClientTemplate) serviceOneToManyStreamEndpointMethodBody(ctx c
 }
 func (t *endpointsClientTemplate) serviceManyToManyStreamEndpointMethod(ctx context.Context, signature *types.Function) *Statement {
 	normal := normalizeFunction(signature)
-	return methodDefinitionFull(ctx, EndpointsSetName, &normal.Function).
+	return methodDefinitionFull(ctx, t.info.endpointsSetName(), &normal.Function).
 		BlockFunc(t.serviceManyToManyStreamEndpointMethodBody(ctx, signature, &normal.Function))
 }
 func (t *endpointsClientTemplate) serviceManyToManyStreamEndpointMethodBody(ctx context.Context, fn *types.Function, normal *types.Function) func(g *Group) {
@@ -126,16 +132,16 @@ func (t *endpointsClientTemplate) serviceManyToManyStreamEndpointMethodBody(ctx
 			return
 		}
 
-		g.Add(List(Id(nameOfLastResultError(normal))).Op("=")).Id(strings.LastWordFromName(EndpointsSetName)).Dot(endpointsStructFieldName(fn.Name)).Call(Id(firstArgName(normal)))
+		g.Add(List(Id(nameOfLastResultError(normal))).Op("=")).Id(strings.LastWordFromName(t.info.endpointsSetName())).Dot(endpointsStructFieldName(fn.Name)).Call(Id(firstArgName(normal)))
 		g.If(Id(nameOfLastResultError(normal)).Op("!=").Nil().BlockFunc(func(ifg *Group) {
 			if Tags(ctx).HasAny(GrpcTag, GrpcClientTag, GrpcServerTag) {
-				ifg.Add(checkGRPCError(normal))
+				ifg.Add(checkGRPCError(t.info, normal))
 			}
 			ifg.Return()
 		}))
 		g.ReturnFunc(func(group *Group) {
 			for _, field := range removeErrorIfLast(fn.Results) {
-				group.Id(respName).Assert(Op("*").Id(responseStructName(fn))).Op(".").Add(structFieldName(&field))
+				group.Id(respName).Assert(Op("*").Id(t.info.responseStructName(fn))).Op(".").Add(structFieldName(&field))
 			}
 			group.Id(nameOfLastResultError(normal))
 		})
@@ -143,7 +149,7 @@ func (t *endpointsClientTemplate) serviceManyToManyStreamEndpointMethodBody(ctx
 }
 func (t *endpointsClientTemplate) serviceManyToOneStreamEndpointMethod(ctx context.Context, signature *types.Function) *Statement {
 	normal := normalizeFunction(signature)
-	return methodDefinitionFull(ctx, EndpointsSetName, &normal.Function).
+	return methodDefinitionFull(ctx, t.info.endpointsSetName(), &normal.Function).
 		BlockFunc(t.serviceManyToOneStreamEndpointMethodBody(ctx, signature, &normal.Function))
 }
 func (t *endpointsClientTemplate) serviceManyToOneStreamEndpointMethodBody(ctx context.Context, fn *types.Function, normal *types.Function) func(g *Group) {
@@ -154,24 +160,24 @@ func (t *endpointsClientTemplate) serviceManyToOneStreamEndpointMethodBody(ctx c
 			return
 		}
 
-		g.Add(List(Id(nameOfLastResultError(normal))).Op("=")).Id(strings.LastWordFromName(EndpointsSetName)).Dot(endpointsStructFieldName(fn.Name)).Call(Id(firstArgName(normal)))
+		g.Add(List(Id(nameOfLastResultError(normal))).Op("=")).Id(strings.LastWordFromName(t.info.endpointsSetName())).Dot(endpointsStructFieldName(fn.Name)).Call(Id(firstArgName(normal)))
 		g.If(Id(nameOfLastResultError(normal)).Op("!=").Nil().BlockFunc(func(ifg *Group) {
 			if Tags(ctx).HasAny(GrpcTag, GrpcClientTag, GrpcServerTag) {
-				ifg.Add(checkGRPCError(normal))
+				ifg.Add(checkGRPCError(t.info, normal))
 			}
 			ifg.Return()
 		}))
 		g.ReturnFunc(func(group *Group) {
 			for _, field := range removeErrorIfLast(fn.Results) {
-				group.Id(respName).Assert(Op("*").Id(responseStructName(fn))).Op(".").Add(structFieldName(&field))
+				group.Id(respName).Assert(Op("*").Id(t.info.responseStructName(fn))).Op(".").Add(structFieldName(&field))
 			}
 			group.Id(nameOfLastResultError(normal))
 		})
 	}
 }
 
-func (endpointsClientTemplate) DefaultPath() string {
-	return filenameBuilder(PathTransport, "client")
+func (t *endpointsClientTemplate) DefaultPath() string {
+	return filenameBuilder(PathTransport, t.info.nsFile("client"))
 }
 
 func (t *endpointsClientTemplate) Prepare(ctx context.Context) error {
@@ -198,7 +204,7 @@ func (t *endpointsClientTemplate) ChooseStrategy(ctx context.Context) (write_str
 //
 func (t *endpointsClientTemplate) serviceEndpointMethod(ctx context.Context, signature *types.Function) *Statement {
 	normal := normalizeFunction(signature)
-	return methodDefinitionFull(ctx, EndpointsSetName, &normal.Function).
+	return methodDefinitionFull(ctx, t.info.endpointsSetName(), &normal.Function).
 		BlockFunc(t.serviceEndpointMethodBody(ctx, signature, &normal.Function))
 }
 
@@ -222,25 +228,32 @@ func (t *endpointsClientTemplate) serviceEndpointMethodBody(ctx context.Context,
 			g.Return()
 			return
 		}
-		g.Id(reqName).Op(":=").Id(requestStructName(fn)).Values(dictByNormalVariables(RemoveContextIfFirst(fn.Args), RemoveContextIfFirst(normal.Args)))
-		g.Add(endpointResponse(respName, normal)).Id(strings.LastWordFromName(EndpointsSetName)).Dot(endpointsStructFieldName(fn.Name)).Call(Id(firstArgName(normal)), Op("&").Id(reqName))
+		g.Id(reqName).Op(":=").Id(t.info.requestStructName(fn)).Values(dictByNormalVariables(RemoveContextIfFirst(fn.Args), RemoveContextIfFirst(normal.Args)))
+		g.Add(endpointResponse(respName, normal)).Id(strings.LastWordFromName(t.info.endpointsSetName())).Dot(endpointsStructFieldName(fn.Name)).Call(Id(firstArgName(normal)), Op("&").Id(reqName))
 		g.If(Id(nameOfLastResultError(normal)).Op("!=").Nil().BlockFunc(func(ifg *Group) {
 			if Tags(ctx).HasAny(GrpcTag, GrpcClientTag, GrpcServerTag) {
-				ifg.Add(checkGRPCError(normal))
+				ifg.Add(checkGRPCError(t.info, normal))
 			}
 			ifg.Return()
 		}))
 		g.ReturnFunc(func(group *Group) {
 			for _, field := range removeErrorIfLast(fn.Results) {
-				group.Id(respName).Assert(Op("*").Id(responseStructName(fn))).Op(".").Add(structFieldName(&field))
+				group.Id(respName).Assert(Op("*").Id(t.info.responseStructName(fn))).Op(".").Add(structFieldName(&field))
 			}
 			group.Id(nameOfLastResultError(normal))
 		})
 	}
 }
 
-func checkGRPCError(fn *types.Function) *Statement {
+// Services with @errors tag decode typed errors from gRPC status.
+//
+//		err = DecodeGRPCError(err)
+//
+func checkGRPCError(info *GenerationInfo, fn *types.Function) *Statement {
 	s := &Statement{}
+	if HasErrorsTag(info.Iface.Docs) {
+		return s.Id(nameOfLastResultError(fn)).Op("=").Id(info.nsName("DecodeGRPCError")).Call(Id(nameOfLastResultError(fn)))
+	}
 	s.If(List(Id("e"), Id("ok")).Op(":=").Qual(PackagePathGoogleGRPCStatus, "FromError").Call(Id(nameOfLastResultError(fn))),
 		Id("ok").Op("||").
 			Id("e").Dot("Code").Call().Op("==").Qual(PackagePathGoogleGRPCCodes, "Internal").Op("||").
@@ -264,10 +277,10 @@ func firstArgName(signature *types.Function) string {
 	return mstrings.ToLowerFirst(signature.Args[0].Name)
 }
 
-func (t *endpointsClientTemplate) clientTracingMiddleware() *Statement {
+func (t *endpointsClientTemplate) clientOpentracingMiddleware() *Statement {
 	s := &Statement{}
-	s.Func().Id("TraceClientEndpoints").Call(Id("endpoints").Id(EndpointsSetName), Id("tracer").Qual(PackagePathOpenTracingGo, "Tracer")).Id(EndpointsSetName).BlockFunc(func(g *Group) {
-		g.Return(Id(EndpointsSetName).Values(DictFunc(func(d Dict) {
+	s.Func().Id(t.info.nsName("TraceClientEndpoints")).Call(Id("endpoints").Id(t.info.endpointsSetName()), Id("tracer").Qual(PackagePathOpenTracingGo, "Tracer")).Id(t.info.endpointsSetName()).BlockFunc(func(g *Group) {
+		g.Return(Id(t.info.endpointsSetName()).Values(DictFunc(func(d Dict) {
 			for _, signature := range t.info.Iface.Methods {
 				if t.info.AllowedMethods[signature.Name] {
 					d[Id(endpointsStructFieldName(signature.Name))] = Qual(PackagePathGoKitTracing, "TraceClient").Call(Id("tracer"), Lit(signature.Name)).Call(Id("endpoints").Dot(endpointsStructFieldName(signature.Name)))
@@ -277,3 +290,68 @@ func (t *endpointsClientTemplate) clientTracingMiddleware() *Statement {
 	})
 	return s
 }
+
+// Renders
+//
+//		func TraceClientEndpoints(endpoints EndpointsSet, tracer trace.Tracer) EndpointsSet {
+//			return EndpointsSet{CountEndpoint: traceClientEndpoint(tracer, "StringService.Count", endpoints.CountEndpoint)}
+//		}
+//
+func (t *endpointsClientTemplate) clientTracingMiddleware() *Statement {
+	s := &Statement{}
+	s.Func().Id(t.info.nsName("TraceClientEndpoints")).Call(Id("endpoints").Id(t.info.endpointsSetName()), Id("tracer").Qual(PackagePathOTelTrace, "Tracer")).Id(t.info.endpointsSetName()).BlockFunc(func(g *Group) {
+		g.Return(Id(t.info.endpointsSetName()).Values(DictFunc(func(d Dict) {
+			for _, signature := range t.info.Iface.Methods {
+				if t.info.AllowedMethods[signature.Name] {
+					d[Id(endpointsStructFieldName(signature.Name))] = Id(t.info.nsPrivateName("traceClientEndpoint")).Call(
+						Id("tracer"),
+						Lit(t.info.Iface.Name+"."+signature.Name),
+						Id("endpoints").Dot(endpointsStructFieldName(signature.Name)),
+					)
+				}
+			}
+		})))
+	})
+	return s
+}
+
+// Renders
+//
+//		func traceClientEndpoint(tracer trace.Tracer, name string, next endpoint.Endpoint) endpoint.Endpoint {
+//			return func(ctx context.Context, request interface{}) (interface{}, error) {
+//				ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+//				defer span.End()
+//				response, err := next(ctx, request)
+//				if err != nil {
+//					span.RecordError(err)
+//					span.SetStatus(otelcodes.Error, err.Error())
+//				}
+//				return response, err
+//			}
+//		}
+//
+func (t *endpointsClientTemplate) traceClientEndpoint() *Statement {
+	return Func().Id(t.info.nsPrivateName("traceClientEndpoint")).Params(
+		Id("tracer").Qual(PackagePathOTelTrace, "Tracer"),
+		Id("name").String(),
+		Id("next").Qual(PackagePathGoKitEndpoint, "Endpoint"),
+	).Qual(PackagePathGoKitEndpoint, "Endpoint").Block(
+		Return().Func().Params(
+			Id("ctx").Qual(PackagePathContext, "Context"),
+			Id("request").Interface(),
+		).Params(Interface(), Error()).Block(
+			List(Id("ctx"), Id("span")).Op(":=").Id("tracer").Dot("Start").Call(
+				Id("ctx"),
+				Id("name"),
+				Qual(PackagePathOTelTrace, "WithSpanKind").Call(Qual(PackagePathOTelTrace, "SpanKindClient")),
+			),
+			Defer().Id("span").Dot("End").Call(),
+			List(Id("response"), Err()).Op(":=").Id("next").Call(Id("ctx"), Id("request")),
+			If(Err().Op("!=").Nil()).Block(
+				Id("span").Dot("RecordError").Call(Err()),
+				Id("span").Dot("SetStatus").Call(Qual(PackagePathOTelCodes, "Error"), Err().Dot("Error").Call()),
+			),
+			Return(Id("response"), Err()),
+		),
+	)
+}
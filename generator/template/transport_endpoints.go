@@ -30,6 +30,10 @@ func endpointsStructFieldName(str string) string {
 	return str + "Endpoint"
 }
 
+func (i *GenerationInfo) endpointsSetName() string {
+	return i.nsName(EndpointsSetName)
+}
+
 // Renders endpoints file.
 //
 //		// This file was automatically generated by "microgen" utility.
@@ -49,25 +53,25 @@ func (t *endpointsTemplate) Render(ctx context.Context) write_strategy.Renderer
 	f := NewFile("transport")
 	f.HeaderComment(t.info.FileHeader)
 
-	f.Comment(fmt.Sprintf("%s implements %s API and used for transport purposes.", EndpointsSetName, t.info.Iface.Name))
-	f.Type().Id(OneToManyStreamEndpoint).Func().Params(Id("req").Interface(), Id("stream").Interface()).
+	f.Comment(fmt.Sprintf("%s implements %s API and used for transport purposes.", t.info.endpointsSetName(), t.info.Iface.Name))
+	f.Type().Id(t.info.nsName(OneToManyStreamEndpoint)).Func().Params(Id("req").Interface(), Id("stream").Interface()).
 		Params(Error()).Line()
-	f.Type().Id(ManyToManyStreamEndpoint).Func().Params(Id("stream").Interface()).
+	f.Type().Id(t.info.nsName(ManyToManyStreamEndpoint)).Func().Params(Id("stream").Interface()).
 		Params(Error()).Line()
-	f.Type().Id(ManyToOneStreamEndpoint).Func().Params(Id("stream").Interface()).
+	f.Type().Id(t.info.nsName(ManyToOneStreamEndpoint)).Func().Params(Id("stream").Interface()).
 		Params(Error()).Line()
-	f.Type().Id(EndpointsSetName).StructFunc(func(g *Group) {
+	f.Type().Id(t.info.endpointsSetName()).StructFunc(func(g *Group) {
 		for _, signature := range t.info.Iface.Methods {
 			if t.info.OneToManyStreamMethods[signature.Name] {
-				g.Id(endpointsStructFieldName(signature.Name)).Id(OneToManyStreamEndpoint)
+				g.Id(endpointsStructFieldName(signature.Name)).Id(t.info.nsName(OneToManyStreamEndpoint))
 				continue
 			}
 			if t.info.ManyToManyStreamMethods[signature.Name] {
-				g.Id(endpointsStructFieldName(signature.Name)).Id(ManyToManyStreamEndpoint)
+				g.Id(endpointsStructFieldName(signature.Name)).Id(t.info.nsName(ManyToManyStreamEndpoint))
 				continue
 			}
 			if t.info.ManyToOneStreamMethods[signature.Name] {
-				g.Id(endpointsStructFieldName(signature.Name)).Id(ManyToOneStreamEndpoint)
+				g.Id(endpointsStructFieldName(signature.Name)).Id(t.info.nsName(ManyToOneStreamEndpoint))
 				continue
 			}
 			if t.info.AllowedMethods[signature.Name] {
@@ -79,8 +83,8 @@ func (t *endpointsTemplate) Render(ctx context.Context) write_strategy.Renderer
 	return f
 }
 
-func (endpointsTemplate) DefaultPath() string {
-	return filenameBuilder(PathTransport, "endpoints")
+func (t *endpointsTemplate) DefaultPath() string {
+	return filenameBuilder(PathTransport, t.info.nsFile("endpoints"))
 }
 
 func (t *endpointsTemplate) Prepare(ctx context.Context) error {
@@ -97,8 +101,8 @@ func (t *endpointsTemplate) serverMetrics(ctx context.Context) *Statement {
 		return s
 	}
 	const _name_ = "methodName"
-	s.Func().Id("InstrumentingEndpoints").Call(Id("endpoints").Id(EndpointsSetName), Id("tracer").Qual(PackagePathOpenTracingGo, "Tracer")).Id(EndpointsSetName).BlockFunc(func(g *Group) {
-		g.Return(Id(EndpointsSetName).Values(DictFunc(func(d Dict) {
+	s.Func().Id(t.info.nsName("InstrumentingEndpoints")).Call(Id("endpoints").Id(t.info.endpointsSetName()), Id("tracer").Qual(PackagePathOpenTracingGo, "Tracer")).Id(t.info.endpointsSetName()).BlockFunc(func(g *Group) {
+		g.Return(Id(t.info.endpointsSetName()).Values(DictFunc(func(d Dict) {
 			for _, signature := range t.info.Iface.Methods {
 				if t.info.AllowedMethods[signature.Name] {
 					d[Id(endpointsStructFieldName(signature.Name))] = Qual(PackagePathGoKitTracing, "TraceServer").Call(Id("tracer"), Lit(signature.Name)).Call(Id("endpoints").Dot(endpointsStructFieldName(signature.Name)))
@@ -107,7 +111,7 @@ func (t *endpointsTemplate) serverMetrics(ctx context.Context) *Statement {
 		})))
 	})
 	s.Line()
-	s.Line().Func().Id("LatencyMiddleware").Params(Id("dur").Qual(PackagePathGoKitMetrics, "Histogram"), Id(_name_).String()).Qual(PackagePathGoKitEndpoint, "Middleware").Block(
+	s.Line().Func().Id(t.info.nsName("LatencyMiddleware")).Params(Id("dur").Qual(PackagePathGoKitMetrics, "Histogram"), Id(_name_).String()).Qual(PackagePathGoKitEndpoint, "Middleware").Block(
 		Return().Func().Params(Id("next").Qual(PackagePathGoKitEndpoint, "Endpoint")).Qual(PackagePathGoKitEndpoint, "Endpoint").Block(
 			Id("dur").Op(":=").Id("dur").Dot("With").Call(Lit("method"), Id(_name_)),
 			Return().Func().Params(ctx_contextContext, Id("request").Interface()).Params(Id("response").Interface(), Err().Error()).Block(
@@ -120,7 +124,7 @@ func (t *endpointsTemplate) serverMetrics(ctx context.Context) *Statement {
 		),
 	)
 	s.Line()
-	s.Line().Func().Id("RequestFrequencyMiddleware").Params(Id("freq").Qual(PackagePathGoKitMetrics, "Gauge"), Id(_name_).String()).Qual(PackagePathGoKitEndpoint, "Middleware").Block(
+	s.Line().Func().Id(t.info.nsName("RequestFrequencyMiddleware")).Params(Id("freq").Qual(PackagePathGoKitMetrics, "Gauge"), Id(_name_).String()).Qual(PackagePathGoKitEndpoint, "Middleware").Block(
 		Return().Func().Params(Id("next").Qual(PackagePathGoKitEndpoint, "Endpoint")).Qual(PackagePathGoKitEndpoint, "Endpoint").Block(
 			Id("freq").Op(":=").Id("freq").Dot("With").Call(Lit("method"), Id(_name_)),
 			Return().Func().Params(ctx_contextContext, Id("request").Interface()).Params(Interface(), Error()).Block(
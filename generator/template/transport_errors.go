@@ -0,0 +1,562 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	. "github.com/dave/jennifer/jen"
+	mstrings "github.com/recolabs/microgen/generator/strings"
+	"github.com/recolabs/microgen/generator/write_strategy"
+	"github.com/vetcher/go-astra/types"
+)
+
+const (
+	ErrorsTag = "errors"
+)
+
+// Names of gRPC codes in @errors tag, as they are named in protobuf, and constants of codes package.
+var grpcCodeNames = map[string]string{
+	"CANCELLED":           "Canceled",
+	"UNKNOWN":             "Unknown",
+	"INVALID_ARGUMENT":    "InvalidArgument",
+	"DEADLINE_EXCEEDED":   "DeadlineExceeded",
+	"NOT_FOUND":           "NotFound",
+	"ALREADY_EXISTS":      "AlreadyExists",
+	"PERMISSION_DENIED":   "PermissionDenied",
+	"RESOURCE_EXHAUSTED":  "ResourceExhausted",
+	"FAILED_PRECONDITION": "FailedPrecondition",
+	"ABORTED":             "Aborted",
+	"OUT_OF_RANGE":        "OutOfRange",
+	"UNIMPLEMENTED":       "Unimplemented",
+	"INTERNAL":            "Internal",
+	"UNAVAILABLE":         "Unavailable",
+	"DATA_LOSS":           "DataLoss",
+	"UNAUTHENTICATED":     "Unauthenticated",
+}
+
+// gRPC codes for errors, which are not listed in @errors tag, but have StatusCode method.
+var httpStatusGRPCCodes = []struct {
+	status string
+	code   string
+}{
+	{"StatusBadRequest", "InvalidArgument"},
+	{"StatusUnauthorized", "Unauthenticated"},
+	{"StatusForbidden", "PermissionDenied"},
+	{"StatusNotFound", "NotFound"},
+	{"StatusConflict", "AlreadyExists"},
+	{"StatusPreconditionFailed", "FailedPrecondition"},
+	{"StatusTooManyRequests", "ResourceExhausted"},
+	{"StatusNotImplemented", "Unimplemented"},
+	{"StatusServiceUnavailable", "Unavailable"},
+	{"StatusGatewayTimeout", "DeadlineExceeded"},
+}
+
+// errorMapping is an error of service from @errors tag with its HTTP status and gRPC code.
+type errorMapping struct {
+	// name of sentinel variable or error type in service package.
+	name   string
+	status int
+	// code is a name of constant of codes package, e.g. NotFound.
+	code string
+	// sentinel is true for variables, which are compared with errors.Is, and false for error types.
+	sentinel bool
+	// pointer is true for error types, which implement error with pointer receiver.
+	pointer bool
+}
+
+// HasErrorsTag returns true, when service declares its typed errors with @errors tag.
+// Transports map errors to HTTP statuses and gRPC codes only for such services.
+func HasErrorsTag(docs []string) bool {
+	return mstrings.HasTag(docs, TagMark+ErrorsTag+" ")
+}
+
+// Parses values of @errors tag, which are `Name=STATUS/CODE`.
+//
+//		// @errors ErrNotFound=404/NOT_FOUND, ValidationError=400/INVALID_ARGUMENT
+//
+func fetchErrorMappings(docs []string) ([]errorMapping, error) {
+	var mappings []errorMapping
+	seen := make(map[string]bool)
+	for _, value := range fetchTagValues(docs, ErrorsTag) {
+		if value == "" {
+			continue
+		}
+		eq, slash := strings.Index(value, "="), strings.LastIndex(value, "/")
+		if eq <= 0 || slash < eq {
+			return nil, fmt.Errorf("@%s: %s should be Name=STATUS/CODE, e.g. NotFound=404/NOT_FOUND", ErrorsTag, value)
+		}
+		m := errorMapping{name: value[:eq]}
+		if seen[m.name] {
+			return nil, fmt.Errorf("@%s: %s is mapped twice", ErrorsTag, m.name)
+		}
+		seen[m.name] = true
+		status, err := strconv.Atoi(value[eq+1 : slash])
+		if err != nil || status < 400 || status > 599 {
+			return nil, fmt.Errorf("@%s: %s: HTTP status should be from 400 to 599", ErrorsTag, value)
+		}
+		m.status = status
+		code, ok := grpcCodeNames[value[slash+1:]]
+		if !ok {
+			return nil, fmt.Errorf("@%s: %s: unknown gRPC code %s", ErrorsTag, value, value[slash+1:])
+		}
+		m.code = code
+		mappings = append(mappings, m)
+	}
+	return mappings, nil
+}
+
+// Finds errors of mappings in service package: variables are sentinel errors, types should have Error method.
+func resolveErrorMappings(mappings []errorMapping, file *types.File) error {
+	for i := range mappings {
+		m := &mappings[i]
+		if findVariable(file.Vars, m.name) != nil {
+			m.sentinel = true
+			continue
+		}
+		found := false
+		for _, method := range file.Methods {
+			name := types.TypeName(method.Receiver.Type)
+			if method.Name != "Error" || name == nil || *name != m.name {
+				continue
+			}
+			_, m.pointer = method.Receiver.Type.(types.TPointer)
+			found = true
+			break
+		}
+		if !found {
+			return fmt.Errorf("@%s: %s is neither a variable nor a type with Error method in service package", ErrorsTag, m.name)
+		}
+	}
+	return nil
+}
+
+type errorsTemplate struct {
+	info     *GenerationInfo
+	mappings []errorMapping
+}
+
+func NewErrorsTemplate(info *GenerationInfo) Template {
+	return &errorsTemplate{
+		info: info,
+	}
+}
+
+func (t *errorsTemplate) DefaultPath() string {
+	return filenameBuilder(PathTransport, t.info.nsFile("errors"))
+}
+
+func (t *errorsTemplate) Prepare(ctx context.Context) error {
+	mappings, err := fetchErrorMappings(t.info.Iface.Docs)
+	if err != nil {
+		return err
+	}
+	file, err := parsePackage(t.info.SourceFilePath)
+	if err != nil {
+		return err
+	}
+	if err := resolveErrorMappings(mappings, file); err != nil {
+		return err
+	}
+	t.mappings = mappings
+	return nil
+}
+
+func (t *errorsTemplate) ChooseStrategy(ctx context.Context) (write_strategy.Strategy, error) {
+	return write_strategy.NewCreateFileStrategy(t.info.OutputFilePath, t.DefaultPath()), nil
+}
+
+// Render errors file.
+//
+//		// Error is an error of service, which is transferred by transport with mapped HTTP status and gRPC code.
+//		type Error struct {
+//			// Name of error from @errors tag. It is empty for errors, which are not listed there.
+//			Name    string          `json:"error,omitempty"`
+//			Message string          `json:"message"`
+//			// Details are JSON of value of error type.
+//			Details json.RawMessage `json:"details,omitempty"`
+//			// Status is an HTTP status of error.
+//			Status int `json:"-"`
+//		}
+//
+//		// EncodeError maps error of service to transport error.
+//		func EncodeError(err error) *Error {
+//			if e, ok := err.(*Error); ok {
+//				return e
+//			}
+//			if errors.Is(err, service.ErrNotFound) {
+//				return &Error{
+//					Message: err.Error(),
+//					Name:    "ErrNotFound",
+//					Status:  404,
+//				}
+//			}
+//			...
+//		}
+//
+func (t *errorsTemplate) Render(ctx context.Context) write_strategy.Renderer {
+	f := NewFile("transport")
+	f.ImportAlias(t.info.SourcePackageImport, serviceAlias)
+	f.HeaderComment(t.info.FileHeader)
+
+	errorName := t.info.nsName("Error")
+	grpc := Tags(ctx).HasAny(GrpcTag, GrpcClientTag, GrpcServerTag)
+	f.Comment(errorName + " is an error of service, which is transferred by transport with mapped HTTP status and gRPC code.")
+	f.Type().Id(errorName).StructFunc(func(g *Group) {
+		g.Comment("Name of error from @errors tag. It is empty for errors, which are not listed there.")
+		g.Id("Name").String().Tag(map[string]string{"json": "error,omitempty"})
+		g.Id("Message").String().Tag(map[string]string{"json": "message"})
+		g.Comment("Details are JSON of value of error type.")
+		g.Id("Details").Qual(PackagePathJson, "RawMessage").Tag(map[string]string{"json": "details,omitempty"})
+		g.Comment("Status is an HTTP status of error.")
+		g.Id("Status").Int().Tag(map[string]string{"json": "-"})
+		if grpc {
+			g.Comment("Code is a gRPC code of error, which had gRPC status. It is OK for other errors.")
+			g.Id("Code").Qual(PackagePathGoogleGRPCCodes, "Code").Tag(map[string]string{"json": "-"})
+		}
+	})
+	f.Line().Func().Params(Id("e").Op("*").Id(errorName)).Id("Error").Params().String().Block(
+		Return(Id("e").Dot("Message")),
+	)
+	f.Line().Func().Params(Id("e").Op("*").Id(errorName)).Id("StatusCode").Params().Int().Block(
+		Return(Id("e").Dot("Status")),
+	)
+	f.Line().Add(t.encodeError(grpc))
+	f.Line().Add(t.decodeError())
+	if grpc {
+		f.Line().Add(t.grpcStatus())
+		f.Line().Add(t.decodeGRPCError())
+		f.Line().Add(t.grpcCodeFromStatus())
+		f.Line().Add(t.statusFromGRPCCode())
+	}
+	return f
+}
+
+func (t *errorsTemplate) newError(values Dict) *Statement {
+	return Op("&").Id(t.info.nsName("Error")).Values(values)
+}
+
+// Render mapping of service errors.
+//
+//		// EncodeError maps error of service to transport error.
+//		// Errors, which are not listed in @errors tag, may choose HTTP status with StatusCode method.
+//		func EncodeError(err error) *Error {
+//			if e, ok := err.(*Error); ok {
+//				return e
+//			}
+//			{
+//				var e *service.ValidationError
+//				if errors.As(err, &e) {
+//					details, _ := json.Marshal(e)
+//					return &Error{
+//						Details: details,
+//						Message: err.Error(),
+//						Name:    "ValidationError",
+//						Status:  400,
+//					}
+//				}
+//			}
+//			var coder interface {
+//				StatusCode() int
+//			}
+//			if errors.As(err, &coder) && coder.StatusCode() >= http.StatusBadRequest {
+//				return &Error{
+//					Message: err.Error(),
+//					Status:  coder.StatusCode(),
+//				}
+//			}
+//			var grpcErr interface {
+//				GRPCStatus() *status.Status
+//			}
+//			if errors.As(err, &grpcErr) {
+//				st := grpcErr.GRPCStatus()
+//				return &Error{
+//					Code:    st.Code(),
+//					Message: st.Message(),
+//					Status:  statusFromGRPCCode(st.Code()),
+//				}
+//			}
+//			return &Error{
+//				Message: err.Error(),
+//				Status:  http.StatusInternalServerError,
+//			}
+//		}
+//
+func (t *errorsTemplate) encodeError(grpc bool) *Statement {
+	errorName := t.info.nsName("Error")
+	name := t.info.nsName("EncodeError")
+	doc := Comment(name+" maps error of service to transport error.").
+		Line().Comment("Errors, which are not listed in @errors tag, may choose HTTP status with StatusCode method.")
+	if grpc {
+		doc = doc.Line().Comment("Errors with gRPC status, e.g. errors of other gRPC services, keep their code.")
+	}
+	return doc.Line().Func().Id(name).Params(Err().Error()).Op("*").Id(errorName).BlockFunc(func(g *Group) {
+		g.If(List(Id("e"), Id("ok")).Op(":=").Err().Assert(Op("*").Id(errorName)), Id("ok")).Block(
+			Return(Id("e")),
+		)
+		for _, m := range t.mappings {
+			if m.sentinel {
+				g.If(Qual(PackagePathErrors, "Is").Call(Err(), Qual(t.info.SourcePackageImport, m.name))).Block(
+					Return(t.newError(Dict{
+						Id("Name"):    Lit(m.name),
+						Id("Message"): Err().Dot("Error").Call(),
+						Id("Status"):  Lit(m.status),
+					})),
+				)
+				continue
+			}
+			g.Block(
+				Var().Id("e").Add(t.errorType(m)),
+				If(Qual(PackagePathErrors, "As").Call(Err(), Op("&").Id("e"))).Block(
+					List(Id("details"), Id("_")).Op(":=").Qual(PackagePathJson, "Marshal").Call(Id("e")),
+					Return(t.newError(Dict{
+						Id("Name"):    Lit(m.name),
+						Id("Message"): Err().Dot("Error").Call(),
+						Id("Details"): Id("details"),
+						Id("Status"):  Lit(m.status),
+					})),
+				),
+			)
+		}
+		g.Var().Id("coder").Interface(Id("StatusCode").Params().Int())
+		g.If(
+			Qual(PackagePathErrors, "As").Call(Err(), Op("&").Id("coder")).Op("&&").
+				Id("coder").Dot("StatusCode").Call().Op(">=").Qual(PackagePathHttp, "StatusBadRequest"),
+		).Block(
+			Return(t.newError(Dict{
+				Id("Message"): Err().Dot("Error").Call(),
+				Id("Status"):  Id("coder").Dot("StatusCode").Call(),
+			})),
+		)
+		if grpc {
+			g.Var().Id("grpcErr").Interface(Id("GRPCStatus").Params().Op("*").Qual(PackagePathGoogleGRPCStatus, "Status"))
+			g.If(Qual(PackagePathErrors, "As").Call(Err(), Op("&").Id("grpcErr"))).Block(
+				Id("st").Op(":=").Id("grpcErr").Dot("GRPCStatus").Call(),
+				Return(t.newError(Dict{
+					Id("Code"):    Id("st").Dot("Code").Call(),
+					Id("Message"): Id("st").Dot("Message").Call(),
+					Id("Status"):  Id(t.info.nsPrivateName("statusFromGRPCCode")).Call(Id("st").Dot("Code").Call()),
+				})),
+			)
+		}
+		g.Return(t.newError(Dict{
+			Id("Message"): Err().Dot("Error").Call(),
+			Id("Status"):  Qual(PackagePathHttp, "StatusInternalServerError"),
+		}))
+	})
+}
+
+func (t *errorsTemplate) errorType(m errorMapping) *Statement {
+	if m.pointer {
+		return Op("*").Qual(t.info.SourcePackageImport, m.name)
+	}
+	return Qual(t.info.SourcePackageImport, m.name)
+}
+
+// Render decoding of typed errors on client side.
+//
+//		// DecodeError returns typed error of service by transport error.
+//		func DecodeError(e *Error) error {
+//			switch e.Name {
+//			case "ErrNotFound":
+//				return service.ErrNotFound
+//			case "ValidationError":
+//				var err *service.ValidationError
+//				if json.Unmarshal(e.Details, &err) == nil {
+//					return err
+//				}
+//			}
+//			return e
+//		}
+//
+func (t *errorsTemplate) decodeError() *Statement {
+	name := t.info.nsName("DecodeError")
+	return Comment(name+" returns typed error of service by transport error.").
+		Line().Comment("Errors, which are not listed in @errors tag, are returned as is.").
+		Line().Func().Id(name).Params(Id("e").Op("*").Id(t.info.nsName("Error"))).Error().BlockFunc(func(g *Group) {
+		if len(t.mappings) > 0 {
+			g.Switch(Id("e").Dot("Name")).BlockFunc(func(s *Group) {
+				for _, m := range t.mappings {
+					if m.sentinel {
+						s.Case(Lit(m.name)).Block(Return(Qual(t.info.SourcePackageImport, m.name)))
+						continue
+					}
+					s.Case(Lit(m.name)).Block(
+						Var().Id("err").Add(t.errorType(m)),
+						If(Qual(PackagePathJson, "Unmarshal").Call(Id("e").Dot("Details"), Op("&").Id("err")).Op("==").Nil()).Block(
+							Return(Err()),
+						),
+					)
+				}
+			})
+		}
+		g.Return(Id("e"))
+	})
+}
+
+// Render gRPC status of transport error, which is used by grpc package, when server returns error.
+//
+//		// GRPCStatus returns gRPC status with mapped code.
+//		// Name, HTTP status and details of error are added to status as ErrorInfo.
+//		func (e *Error) GRPCStatus() *status.Status {
+//			code := e.Code
+//			if code == codes.OK {
+//				code = grpcCodeFromStatus(e.Status)
+//			}
+//			switch e.Name {
+//			case "ErrNotFound":
+//				code = codes.NotFound
+//			}
+//			st := status.New(code, e.Message)
+//			info := &errdetails.ErrorInfo{
+//				Domain:   "StringService",
+//				Metadata: map[string]string{"status": strconv.Itoa(e.Status)},
+//				Reason:   e.Name,
+//			}
+//			if len(e.Details) > 0 {
+//				info.Metadata["details"] = string(e.Details)
+//			}
+//			if withDetails, err := st.WithDetails(info); err == nil {
+//				return withDetails
+//			}
+//			return st
+//		}
+//
+func (t *errorsTemplate) grpcStatus() *Statement {
+	return Comment("GRPCStatus returns gRPC status with mapped code.").
+		Line().Comment("Name, HTTP status and details of error are added to status as ErrorInfo.").
+		Line().Func().Params(Id("e").Op("*").Id(t.info.nsName("Error"))).Id("GRPCStatus").Params().Op("*").Qual(PackagePathGoogleGRPCStatus, "Status").BlockFunc(func(g *Group) {
+		g.Id("code").Op(":=").Id("e").Dot("Code")
+		g.If(Id("code").Op("==").Qual(PackagePathGoogleGRPCCodes, "OK")).Block(
+			Id("code").Op("=").Id(t.info.nsPrivateName("grpcCodeFromStatus")).Call(Id("e").Dot("Status")),
+		)
+		if len(t.mappings) > 0 {
+			g.Switch(Id("e").Dot("Name")).BlockFunc(func(s *Group) {
+				for _, m := range t.mappings {
+					s.Case(Lit(m.name)).Block(Id("code").Op("=").Qual(PackagePathGoogleGRPCCodes, m.code))
+				}
+			})
+		}
+		g.Id("st").Op(":=").Qual(PackagePathGoogleGRPCStatus, "New").Call(Id("code"), Id("e").Dot("Message"))
+		g.Id("info").Op(":=").Op("&").Qual(PackagePathGoogleErrDetails, "ErrorInfo").Values(Dict{
+			Id("Reason"):   Id("e").Dot("Name"),
+			Id("Domain"):   Lit(t.info.Iface.Name),
+			Id("Metadata"): Map(String()).String().Values(Dict{Lit("status"): Qual(PackagePathStrconv, "Itoa").Call(Id("e").Dot("Status"))}),
+		})
+		g.If(Len(Id("e").Dot("Details")).Op(">").Lit(0)).Block(
+			Id("info").Dot("Metadata").Index(Lit("details")).Op("=").String().Call(Id("e").Dot("Details")),
+		)
+		g.If(List(Id("withDetails"), Err()).Op(":=").Id("st").Dot("WithDetails").Call(Id("info")), Err().Op("==").Nil()).Block(
+			Return(Id("withDetails")),
+		)
+		g.Return(Id("st"))
+	})
+}
+
+// Render decoding of gRPC client errors.
+//
+//		// DecodeGRPCError returns typed error of service by error of gRPC client.
+//		func DecodeGRPCError(err error) error {
+//			st, ok := status.FromError(err)
+//			if !ok {
+//				return err
+//			}
+//			e := &Error{
+//				Code:    st.Code(),
+//				Message: st.Message(),
+//				Status:  statusFromGRPCCode(st.Code()),
+//			}
+//			for _, detail := range st.Details() {
+//				if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == "StringService" {
+//					e.Name = info.Reason
+//					e.Details = json.RawMessage(info.Metadata["details"])
+//					if s, err := strconv.Atoi(info.Metadata["status"]); err == nil {
+//						e.Status = s
+//					}
+//				}
+//			}
+//			return DecodeError(e)
+//		}
+//
+func (t *errorsTemplate) decodeGRPCError() *Statement {
+	name := t.info.nsName("DecodeGRPCError")
+	return Comment(name+" returns typed error of service by error of gRPC client.").
+		Line().Func().Id(name).Params(Err().Error()).Error().Block(
+		List(Id("st"), Id("ok")).Op(":=").Qual(PackagePathGoogleGRPCStatus, "FromError").Call(Err()),
+		If(Op("!").Id("ok")).Block(
+			Return(Err()),
+		),
+		Id("e").Op(":=").Add(t.newError(Dict{
+			Id("Code"):    Id("st").Dot("Code").Call(),
+			Id("Message"): Id("st").Dot("Message").Call(),
+			Id("Status"):  Id(t.info.nsPrivateName("statusFromGRPCCode")).Call(Id("st").Dot("Code").Call()),
+		})),
+		For(List(Id("_"), Id("detail")).Op(":=").Range().Id("st").Dot("Details").Call()).Block(
+			If(
+				List(Id("info"), Id("ok")).Op(":=").Id("detail").Assert(Op("*").Qual(PackagePathGoogleErrDetails, "ErrorInfo")),
+				Id("ok").Op("&&").Id("info").Dot("Domain").Op("==").Lit(t.info.Iface.Name),
+			).Block(
+				Id("e").Dot("Name").Op("=").Id("info").Dot("Reason"),
+				Id("e").Dot("Details").Op("=").Qual(PackagePathJson, "RawMessage").Call(Id("info").Dot("Metadata").Index(Lit("details"))),
+				If(
+					List(Id("s"), Err()).Op(":=").Qual(PackagePathStrconv, "Atoi").Call(Id("info").Dot("Metadata").Index(Lit("status"))),
+					Err().Op("==").Nil(),
+				).Block(
+					Id("e").Dot("Status").Op("=").Id("s"),
+				),
+			),
+		),
+		Return(Id(t.info.nsName("DecodeError")).Call(Id("e"))),
+	)
+}
+
+// Render gRPC code of errors, which are not listed in @errors tag.
+//
+//		// grpcCodeFromStatus returns gRPC code of errors, which are not listed in @errors tag.
+//		func grpcCodeFromStatus(httpStatus int) codes.Code {
+//			switch httpStatus {
+//			case http.StatusBadRequest:
+//				return codes.InvalidArgument
+//			...
+//			}
+//			return codes.Unknown
+//		}
+//
+func (t *errorsTemplate) grpcCodeFromStatus() *Statement {
+	name := t.info.nsPrivateName("grpcCodeFromStatus")
+	return Comment(name+" returns gRPC code of errors, which are not listed in @errors tag.").
+		Line().Func().Id(name).Params(Id("httpStatus").Int()).Qual(PackagePathGoogleGRPCCodes, "Code").Block(
+		Switch(Id("httpStatus")).BlockFunc(func(g *Group) {
+			for _, c := range httpStatusGRPCCodes {
+				g.Case(Qual(PackagePathHttp, c.status)).Block(Return(Qual(PackagePathGoogleGRPCCodes, c.code)))
+			}
+		}),
+		Return(Qual(PackagePathGoogleGRPCCodes, "Unknown")),
+	)
+}
+
+// Render HTTP status of errors with gRPC status, which are not listed in @errors tag.
+//
+//		// statusFromGRPCCode returns HTTP status of errors with gRPC code.
+//		func statusFromGRPCCode(code codes.Code) int {
+//			switch code {
+//			case codes.InvalidArgument:
+//				return http.StatusBadRequest
+//			...
+//			}
+//			return http.StatusInternalServerError
+//		}
+//
+func (t *errorsTemplate) statusFromGRPCCode() *Statement {
+	name := t.info.nsPrivateName("statusFromGRPCCode")
+	return Comment(name+" returns HTTP status of errors with gRPC code.").
+		Line().Func().Id(name).Params(Id("code").Qual(PackagePathGoogleGRPCCodes, "Code")).Int().Block(
+		Switch(Id("code")).BlockFunc(func(g *Group) {
+			for _, c := range httpStatusGRPCCodes {
+				g.Case(Qual(PackagePathGoogleGRPCCodes, c.code)).Block(Return(Qual(PackagePathHttp, c.status)))
+			}
+		}),
+		Return(Qual(PackagePathHttp, "StatusInternalServerError")),
+	)
+}
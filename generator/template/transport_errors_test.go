@@ -0,0 +1,113 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetcher/go-astra"
+)
+
+const errorsTestSource = `package svc
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrNotFound = errors.New("not found")
+
+type ValidationError struct {
+	Field string
+}
+
+func (e *ValidationError) Error() string { return e.Field }
+
+type Conflict string
+
+func (e Conflict) Error() string { return string(e) }
+
+// @microgen http, grpc
+// @errors ErrNotFound=404/NOT_FOUND, ValidationError=400/INVALID_ARGUMENT
+// @errors Conflict=409/ALREADY_EXISTS
+type UserService interface {
+	GetUser(ctx context.Context, id string) (err error)
+}
+`
+
+func TestErrorMappings(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "svc.go")
+	if err := ioutil.WriteFile(source, []byte(errorsTestSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := astra.ParseFile(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iface := &file.Interfaces[0]
+	assert.True(t, HasErrorsTag(iface.Docs))
+	mappings, err := fetchErrorMappings(iface.Docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.NoError(t, resolveErrorMappings(mappings, file)) {
+		assert.Equal(t, []errorMapping{
+			{name: "ErrNotFound", status: 404, code: "NotFound", sentinel: true},
+			{name: "ValidationError", status: 400, code: "InvalidArgument", pointer: true},
+			{name: "Conflict", status: 409, code: "AlreadyExists"},
+		}, mappings)
+	}
+	assert.Error(t, resolveErrorMappings([]errorMapping{{name: "UserService"}}, file))
+
+	ResetParsedCache()
+	info := &GenerationInfo{
+		Iface:               iface,
+		SourceFilePath:      source,
+		SourcePackageImport: "example.com/svc",
+		OutputPackageImport: "example.com/svc",
+		AllowedMethods:      map[string]bool{"GetUser": true},
+	}
+	ctx := WithTags(context.Background(), TagsSet{HttpTag: {}, GrpcTag: {}})
+	tmpl := NewErrorsTemplate(info)
+	if err := tmpl.Prepare(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Render(ctx).Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	code := buf.String()
+	assert.Contains(t, code, "errors.Is(err, service.ErrNotFound)")
+	assert.Contains(t, code, "var e *service.ValidationError")
+	assert.Contains(t, code, "var err service.Conflict")
+	assert.Contains(t, code, "code = codes.AlreadyExists")
+	assert.Contains(t, code, "func DecodeGRPCError(err error) error")
+	// Errors with gRPC status keep their code.
+	assert.Contains(t, code, "if errors.As(err, &grpcErr) {")
+	assert.Contains(t, code, "Code:    st.Code(),")
+	assert.Contains(t, code, "func statusFromGRPCCode(code codes.Code) int")
+
+	buf.Reset()
+	ctx = WithTags(context.Background(), TagsSet{HttpTag: {}})
+	if err := tmpl.Render(ctx).Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	assert.NotContains(t, buf.String(), "GRPCStatus")
+}
+
+func TestErrorMappingsErrors(t *testing.T) {
+	for name, value := range map[string]string{
+		"no status":    "NotFound=NOT_FOUND",
+		"no name":      "=404/NOT_FOUND",
+		"bad status":   "NotFound=200/NOT_FOUND",
+		"unknown code": "NotFound=404/MISSING",
+		"duplicate":    "NotFound=404/NOT_FOUND, NotFound=410/NOT_FOUND",
+	} {
+		_, err := fetchErrorMappings([]string{"// @errors " + value})
+		assert.Error(t, err, name)
+	}
+}
@@ -18,11 +18,21 @@ func NewExchangeTemplate(info *GenerationInfo) Template {
 	}
 }
 
-func requestStructName(signature *types.Function) string {
+func (i *GenerationInfo) requestStructName(signature *types.Function) string {
+	return i.nsName(signature.Name + "Request")
+}
+
+func (i *GenerationInfo) responseStructName(signature *types.Function) string {
+	return i.nsName(signature.Name + "Response")
+}
+
+// Name of protobuf message for method request. Protobuf messages are not namespaced,
+// because every service has its own protobuf package.
+func requestMessageName(signature *types.Function) string {
 	return signature.Name + "Request"
 }
 
-func responseStructName(signature *types.Function) string {
+func responseMessageName(signature *types.Function) string {
 	return signature.Name + "Response"
 }
 
@@ -52,13 +62,13 @@ func (t *exchangeTemplate) Render(ctx context.Context) write_strategy.Renderer {
 	}
 	for _, signature := range t.info.Iface.Methods {
 		if t.info.OneToManyStreamMethods[signature.Name] {
-			f.Add(exchange(ctx, requestStructName(signature), removeLastVar(RemoveContextIfFirst(signature.Args)))) //.Line()
-			f.Add(exchange(ctx, responseStructName(signature), removeErrorIfLast(signature.Results))).Line()
+			f.Add(exchange(ctx, t.info.requestStructName(signature), removeLastVar(RemoveContextIfFirst(signature.Args)))) //.Line()
+			f.Add(exchange(ctx, t.info.responseStructName(signature), removeErrorIfLast(signature.Results))).Line()
 			continue
 		}
 		if t.info.AllowedMethods[signature.Name] {
-			f.Add(exchange(ctx, requestStructName(signature), RemoveContextIfFirst(signature.Args))) //.Line()
-			f.Add(exchange(ctx, responseStructName(signature), removeErrorIfLast(signature.Results))).Line()
+			f.Add(exchange(ctx, t.info.requestStructName(signature), RemoveContextIfFirst(signature.Args))) //.Line()
+			f.Add(exchange(ctx, t.info.responseStructName(signature), removeErrorIfLast(signature.Results))).Line()
 		}
 	}
 	if len(t.info.Iface.Methods) > 0 {
@@ -68,8 +78,8 @@ func (t *exchangeTemplate) Render(ctx context.Context) write_strategy.Renderer {
 	return f
 }
 
-func (exchangeTemplate) DefaultPath() string {
-	return filenameBuilder(PathTransport, "exchanges")
+func (t *exchangeTemplate) DefaultPath() string {
+	return filenameBuilder(PathTransport, t.info.nsFile("exchanges"))
 }
 
 func (exchangeTemplate) Prepare(ctx context.Context) error {
@@ -56,41 +56,20 @@ func (t *gRPCClientTemplate) Render(ctx context.Context) write_strategy.Renderer
 	f.ImportAlias(PackagePathGoKitTransportGRPC, "grpckit")
 	f.HeaderComment(t.info.FileHeader)
 
-	f.Func().Id("NewGRPCClient").
-		ParamsFunc(func(p *Group) {
-			p.Id("conn").Op("*").Qual(PackagePathGoogleGRPC, "ClientConn")
-			p.Id("addr").Id("string")
-			p.Id("opts").Op("...").Qual(PackagePathGoKitTransportGRPC, "ClientOption")
-		}).Qual(t.info.OutputPackageImport+"/transport", EndpointsSetName).
-		BlockFunc(func(g *Group) {
-			if t.info.ProtobufClientAddr != "" {
-				g.If(Id("addr").Op("==").Lit("")).Block(
-					Id("addr").Op("=").Lit(t.info.ProtobufClientAddr),
-				)
-			}
-			g.Return().Qual(t.info.OutputPackageImport+"/transport", EndpointsSetName).Values(DictFunc(func(d Dict) {
-				for _, m := range t.info.Iface.Methods {
-					if !t.info.AllowedMethods[m.Name] ||
-						t.info.ManyToManyStreamMethods[m.Name] ||
-						t.info.ManyToOneStreamMethods[m.Name] ||
-						t.info.OneToManyStreamMethods[m.Name] {
-						continue
-					}
-					client := &Statement{}
-					client.Qual(PackagePathGoKitTransportGRPC, "NewClient").Call(
-						Line().Id("conn"), Id("addr"), Lit(m.Name),
-						Line().Id(encodeRequestName(m)),
-						Line().Id(decodeResponseName(m)),
-						Line().Add(t.replyType(m)),
-						Line().Add(t.clientOpts(m)).Op("...").Line(),
-					).Dot("Endpoint").Call()
-					d[Id(endpointsStructFieldName(m.Name))] = client
-				}
-			}))
-		})
+	if wrapsClientEndpoints(ctx) {
+		f.Add(t.wrappedClient(ctx))
+	} else {
+		f.Func().Id(t.info.nsNewName("GRPCClient")).
+			ParamsFunc(func(p *Group) {
+				p.Id("conn").Op("*").Qual(PackagePathGoogleGRPC, "ClientConn")
+				p.Id("addr").Id("string")
+				p.Id("opts").Op("...").Qual(PackagePathGoKitTransportGRPC, "ClientOption")
+			}).Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName()).
+			BlockFunc(t.clientBody(ctx))
+	}
 
 	if Tags(ctx).Has(TracingMiddlewareTag) {
-		f.Line().Func().Id("TracingGRPCClientOptions").Params(
+		f.Line().Func().Id(t.info.nsName("TracingGRPCClientOptions")).Params(
 			Id("tracer").Qual(PackagePathOpenTracingGo, "Tracer"),
 			Id("logger").Qual(PackagePathGoKitLog, "Logger"),
 		).Params(
@@ -103,48 +82,164 @@ func (t *gRPCClientTemplate) Render(ctx context.Context) write_strategy.Renderer
 			),
 		)
 	}
+	if Tags(ctx).Has(OpenTelemetryTag) {
+		f.Line().Func().Id(t.info.nsName("TracingGRPCClientOptions")).Params(
+			Id("propagator").Qual(PackagePathOTelPropagation, "TextMapPropagator"),
+		).Params(
+			Func().Params(Op("[]").Qual(PackagePathGoKitTransportGRPC, "ClientOption")).Params(Op("[]").Qual(PackagePathGoKitTransportGRPC, "ClientOption")),
+		).Block(
+			Return().Func().Params(Id("opts").Op("[]").Qual(PackagePathGoKitTransportGRPC, "ClientOption")).Params(Op("[]").Qual(PackagePathGoKitTransportGRPC, "ClientOption")).Block(
+				Return().Append(Id("opts"), Qual(PackagePathGoKitTransportGRPC, "ClientBefore").Call(
+					Line().Id(t.info.nsPrivateName("injectTraceContext")).Call(Id("propagator")).Op(",").Line(),
+				)),
+			),
+		)
+		f.Line().Add(grpcInjectTraceContext(t.info.nsPrivateName("injectTraceContext")))
+	}
+	if Tags(ctx).Has(ServiceDiscoveryTag) {
+		f.Line().Add(t.sdClient(ctx))
+	}
 
 	return f
 }
 
+// Renders body of client.
+//
+//		return transport.EndpointsSet{CountEndpoint: grpckit.NewClient(...).Endpoint()}
+//
+func (t *gRPCClientTemplate) clientBody(ctx context.Context) func(g *Group) {
+	return func(g *Group) {
+		if t.info.ProtobufClientAddr != "" {
+			g.If(Id("addr").Op("==").Lit("")).Block(
+				Id("addr").Op("=").Lit(t.info.ProtobufClientAddr),
+			)
+		}
+		g.Return().Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName()).Values(DictFunc(func(d Dict) {
+			for _, m := range t.info.Iface.Methods {
+				if !t.info.AllowedMethods[m.Name] ||
+					t.info.ManyToManyStreamMethods[m.Name] ||
+					t.info.ManyToOneStreamMethods[m.Name] ||
+					t.info.OneToManyStreamMethods[m.Name] {
+					continue
+				}
+				client := &Statement{}
+				client.Qual(PackagePathGoKitTransportGRPC, "NewClient").Call(
+					Line().Id("conn"), Id("addr"), Lit(m.Name),
+					Line().Id(t.info.encodeRequestName(m)),
+					Line().Id(t.info.decodeResponseName(m)),
+					Line().Add(t.replyType(ctx, m)),
+					Line().Add(t.clientOpts(m)).Op("...").Line(),
+				).Dot("Endpoint").Call()
+				d[Id(endpointsStructFieldName(m.Name))] = client
+			}
+		}))
+	}
+}
+
+// Renders client, which wraps endpoints with circuit breakers and retries.
+//
+//		// NewGRPCClient creates endpoints of StringService, which call server by conn.
+//		// Endpoints are wrapped with circuit breakers, which breaker returns for name of method.
+//		// Failed calls are retried by policies of @retry tags.
+//		func NewGRPCClient(conn *grpc.ClientConn, addr string, breaker func(method string) endpoint.Middleware, opts ...grpckit.ClientOption) transport.EndpointsSet {
+//			return transport.RetryClientEndpoints(transport.CircuitBreakerClientEndpoints(grpcClientEndpoints(conn, addr, opts...), breaker))
+//		}
+//
+//		func grpcClientEndpoints(conn *grpc.ClientConn, addr string, opts ...grpckit.ClientOption) transport.EndpointsSet {
+//			return transport.EndpointsSet{...}
+//		}
+//
+func (t *gRPCClientTemplate) wrappedClient(ctx context.Context) *Statement {
+	endpoints := t.info.nsPrivateName("grpcClientEndpoints")
+	return Comment(t.info.nsNewName("GRPCClient")+" creates endpoints of "+t.info.Iface.Name+", which call server by conn.").
+		Line().Add(wrappedClientComment(ctx, false)).
+		Func().Id(t.info.nsNewName("GRPCClient")).Params(append(append(
+		[]Code{
+			Id("conn").Op("*").Qual(PackagePathGoogleGRPC, "ClientConn"),
+			Id("addr").String(),
+		},
+		breakerClientParams(ctx)...),
+		Id("opts").Op("...").Qual(PackagePathGoKitTransportGRPC, "ClientOption"),
+	)...).Params(
+		Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName()),
+	).Block(
+		Return(retryClientEndpoints(ctx, t.info, breakerClientEndpoints(ctx, t.info, Id(endpoints).Call(Id("conn"), Id("addr"), Id("opts").Op("..."))))),
+	).
+		Line().
+		Line().Func().Id(endpoints).Params(
+		Id("conn").Op("*").Qual(PackagePathGoogleGRPC, "ClientConn"),
+		Id("addr").String(),
+		Id("opts").Op("...").Qual(PackagePathGoKitTransportGRPC, "ClientOption"),
+	).Params(
+		Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName()),
+	).BlockFunc(t.clientBody(ctx))
+}
+
 // Renders reply type argument
 // 		stringsvc.CountResponse{}
-func (t *gRPCClientTemplate) replyType(signature *types.Function) *Statement {
+// or wrapper for single pointer to scalar
+// 		wrappers.StringValue{}
+func (t *gRPCClientTemplate) replyType(ctx context.Context, signature *types.Function) *Statement {
 	results := removeErrorIfLast(signature.Results)
 	if len(results) == 0 {
-		return Qual(PackagePathEmptyProtobuf, "Empty").Values()
+		return Qual(protobufEmptyPackage(ctx), "Empty").Values()
 	}
 	if len(results) == 1 {
-		sp := specialReplyType(results[0].Type)
-		if sp != nil {
-			return sp
+		if wrapper, ok := pointerWrapperName(results[0].Type); ok {
+			return Qual(protobufWrappersPackage(ctx), wrapper).Values()
 		}
 	}
-	return Qual(t.info.ProtobufPackageImport, responseStructName(signature)).Values()
+	return Qual(t.info.ProtobufPackageImport, responseMessageName(signature)).Values()
 }
 
-func specialReplyType(p types.Type) *Statement {
-	name := types.TypeName(p)
-	imp := types.TypeImport(p)
-	// *string -> *wrappers.StringValue
-	if name != nil && *name == "string" && imp == nil {
-		ptr, ok := p.(types.TPointer)
-		if ok && ptr.NumberOfPointers == 1 {
-			return (&Statement{}).Qual(GolangProtobufWrappers, "StringValue").Values()
-		}
-	}
-	// *float64 -> *wrappers.DoubleValue
-	if name != nil && *name == "float64" && imp == nil {
-		ptr, ok := p.(types.TPointer)
-		if ok && ptr.NumberOfPointers == 1 {
-			return (&Statement{}).Qual(GolangProtobufWrappers, "DoubleValue").Values()
-		}
+// Renders constructor of client, which balances requests between instances of service.
+//
+//		// NewGRPCClientSD creates endpoints of StringService, which are balanced between instances of instancer.
+//		// Instance is address of server, which is dialed once with dialOpts. Connection is closed, when instance disappears
+//		// or closer is closed.
+//		// Failed calls of idempotent methods are tried on next instances up to retryMax times within retryTimeout.
+//		func NewGRPCClientSD(instancer sd.Instancer, logger log.Logger, retryMax int, retryTimeout time.Duration, addr string, dialOpts []grpc.DialOption, opts ...grpckit.ClientOption) (transport.EndpointsSet, io.Closer) {
+//			return transport.BalancedEndpoints(instancer, func(instance string) (transport.EndpointsSet, io.Closer, error) {
+//				conn, err := grpc.Dial(instance, dialOpts...)
+//				if err != nil {
+//					return transport.EndpointsSet{}, nil, err
+//				}
+//				return NewGRPCClient(conn, addr, opts...), conn, nil
+//			}, logger, retryMax, retryTimeout)
+//		}
+//
+func (t *gRPCClientTemplate) sdClient(ctx context.Context) *Statement {
+	client := Id(t.info.nsNewName("GRPCClient")).Call(Id("conn"), Id("addr"), Id("opts").Op("..."))
+	if wrapsClientEndpoints(ctx) {
+		client = breakerClientEndpoints(ctx, t.info, Id(t.info.nsPrivateName("grpcClientEndpoints")).Call(Id("conn"), Id("addr"), Id("opts").Op("...")))
 	}
-	return nil
+	return Comment(t.info.nsNewName("GRPCClientSD")+" creates endpoints of "+t.info.Iface.Name+", which are balanced between instances of instancer.").
+		Line().Comment("Instance is address of server, which is dialed once with dialOpts. Connection is closed, when instance disappears").
+		Line().Comment("or closer is closed.").
+		Line().Comment("Failed calls of idempotent methods are tried on next instances up to retryMax times within retryTimeout.").
+		Line().Add(wrappedClientComment(ctx, true)).
+		Func().Id(t.info.nsNewName("GRPCClientSD")).Params(sdClientParams(ctx,
+		Id("addr").String(),
+		Id("dialOpts").Index().Qual(PackagePathGoogleGRPC, "DialOption"),
+		Id("opts").Op("...").Qual(PackagePathGoKitTransportGRPC, "ClientOption"),
+	)...).Params(
+		Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName()),
+		Qual(PackagePathIO, "Closer"),
+	).Block(
+		balancedClient(ctx, t.info,
+			Func().Params(Id("instance").String()).Params(Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName()), Qual(PackagePathIO, "Closer"), Error()).Block(
+				List(Id("conn"), Err()).Op(":=").Qual(PackagePathGoogleGRPC, "Dial").Call(Id("instance"), Id("dialOpts").Op("...")),
+				If(Err().Op("!=").Nil()).Block(
+					Return(Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName()).Values(), Nil(), Err()),
+				),
+				Return(client, Id("conn"), Nil()),
+			),
+		),
+	)
 }
 
-func (gRPCClientTemplate) DefaultPath() string {
-	return filenameBuilder(PathTransport, "grpc", "client")
+func (t *gRPCClientTemplate) DefaultPath() string {
+	return filenameBuilder(PathTransport, "grpc", t.info.nsFile("client"))
 }
 
 func (t *gRPCClientTemplate) Prepare(ctx context.Context) error {
@@ -36,20 +36,27 @@ func NewGRPCEndpointConverterTemplate(info *GenerationInfo) Template {
 	}
 }
 
-func decodeRequestName(f *types.Function) string {
-	return "_Decode_" + f.Name + "_Request"
+func (i *GenerationInfo) decodeRequestName(f *types.Function) string {
+	return "_Decode_" + i.nsConverterName(f) + "_Request"
 }
 
-func decodeResponseName(f *types.Function) string {
-	return "_Decode_" + f.Name + "_Response"
+func (i *GenerationInfo) decodeResponseName(f *types.Function) string {
+	return "_Decode_" + i.nsConverterName(f) + "_Response"
 }
 
-func encodeRequestName(f *types.Function) string {
-	return "_Encode_" + f.Name + "_Request"
+func (i *GenerationInfo) encodeRequestName(f *types.Function) string {
+	return "_Encode_" + i.nsConverterName(f) + "_Request"
 }
 
-func encodeResponseName(f *types.Function) string {
-	return "_Encode_" + f.Name + "_Response"
+func (i *GenerationInfo) encodeResponseName(f *types.Function) string {
+	return "_Encode_" + i.nsConverterName(f) + "_Response"
+}
+
+func (i *GenerationInfo) nsConverterName(f *types.Function) string {
+	if i.Namespace == "" {
+		return f.Name
+	}
+	return i.Namespace + "_" + f.Name
 }
 
 // Renders converter file.
@@ -241,8 +248,8 @@ Loop:
 	return methodName
 }
 
-func (gRPCEndpointConverterTemplate) DefaultPath() string {
-	return filenameBuilder(PathTransport, "grpc", "protobuf_endpoint_converters")
+func (t *gRPCEndpointConverterTemplate) DefaultPath() string {
+	return filenameBuilder(PathTransport, "grpc", t.info.nsFile("protobuf_endpoint_converters"))
 }
 
 func (t *gRPCEndpointConverterTemplate) Prepare(ctx context.Context) error {
@@ -277,10 +284,10 @@ func (t *gRPCEndpointConverterTemplate) ChooseStrategy(ctx context.Context) (wri
 		return nil, err
 	}
 
-	removeAlreadyExistingFunctions(file.Functions, &t.requestEncoders, encodeRequestName)
-	removeAlreadyExistingFunctions(file.Functions, &t.requestDecoders, decodeRequestName)
-	removeAlreadyExistingFunctions(file.Functions, &t.responseEncoders, encodeResponseName)
-	removeAlreadyExistingFunctions(file.Functions, &t.responseDecoders, decodeResponseName)
+	removeAlreadyExistingFunctions(file.Functions, &t.requestEncoders, t.info.encodeRequestName)
+	removeAlreadyExistingFunctions(file.Functions, &t.requestDecoders, t.info.decodeRequestName)
+	removeAlreadyExistingFunctions(file.Functions, &t.responseEncoders, t.info.encodeResponseName)
+	removeAlreadyExistingFunctions(file.Functions, &t.responseDecoders, t.info.decodeResponseName)
 
 	t.state = AppendStrat
 	return write_strategy.NewAppendToFileStrategy(t.info.OutputFilePath, t.DefaultPath()), nil
@@ -384,11 +391,11 @@ func (t *gRPCEndpointConverterTemplate) encodeRequest(ctx context.Context, signa
 	methodParams := RemoveContextIfFirst(signature.Args)
 	fullName := "request"
 	shortName := "req"
-	return Line().Func().Id(encodeRequestName(signature)).Params(ctx_contextContext, Id(fullName).Interface()).
+	return Line().Func().Id(t.info.encodeRequestName(signature)).Params(ctx_contextContext, Id(fullName).Interface()).
 		Params(Interface(), Error()).BlockFunc(
 		func(group *Group) {
 			if len(methodParams) == 1 {
-				sp := specialEndpointConverterToProto(methodParams[0], signature, requestStructName, t.info.SourcePackageImport, fullName, shortName)
+				sp := specialEndpointConverterToProto(ctx, methodParams[0], signature, t.info.requestStructName, t.info.OutputPackageImport+"/transport", fullName, shortName)
 				if sp != nil {
 					group.Add(sp)
 					return
@@ -396,16 +403,16 @@ func (t *gRPCEndpointConverterTemplate) encodeRequest(ctx context.Context, signa
 			}
 			if len(methodParams) > 0 {
 				group.If(Id(fullName).Op("==").Nil()).Block(
-					Return(Nil(), Qual(PackagePathErrors, "New").Call(Lit("nil "+requestStructName(signature)))),
+					Return(Nil(), Qual(PackagePathErrors, "New").Call(Lit("nil "+t.info.requestStructName(signature)))),
 				)
-				group.Id(shortName).Op(":=").Id(fullName).Assert(Op("*").Qual(t.info.OutputPackageImport+"/transport", requestStructName(signature)))
+				group.Id(shortName).Op(":=").Id(fullName).Assert(Op("*").Qual(t.info.OutputPackageImport+"/transport", t.info.requestStructName(signature)))
 				for _, field := range methodParams {
 					if _, ok := golangTypeToProto(ctx, "", &field); !ok {
 						group.Add(convertCustomType(shortName, typeToProto(field.Type, 0), &field))
 					}
 				}
 			}
-			group.Return().List(t.grpcEndpointConvReturn(ctx, signature, methodParams, requestStructName, shortName, golangTypeToProto, t.info.ProtobufPackageImport), Nil())
+			group.Return().List(t.grpcEndpointConvReturn(ctx, signature, methodParams, requestMessageName, shortName, golangTypeToProto, t.info.ProtobufPackageImport), Nil())
 		},
 	).Line()
 }
@@ -421,7 +428,7 @@ func (t *gRPCEndpointConverterTemplate) grpcEndpointConvReturn(
 	pkg string,
 ) *Statement {
 	if len(methodParams) == 0 {
-		return Op("&").Qual(PackagePathEmptyProtobuf, "Empty").Values()
+		return Op("&").Qual(protobufEmptyPackage(ctx), "Empty").Values()
 	}
 	return Op("&").Qual(pkg, strNameFn(fn)).Values(DictFunc(func(dict Dict) {
 		for _, field := range methodParams {
@@ -449,10 +456,10 @@ func (t *gRPCEndpointConverterTemplate) encodeResponse(ctx context.Context, sign
 	methodResults := removeErrorIfLast(signature.Results)
 	fullName := "response"
 	shortName := "resp"
-	return Line().Func().Id(encodeResponseName(signature)).Call(ctx_contextContext, Id(fullName).Interface()).Params(Interface(), Error()).BlockFunc(
+	return Line().Func().Id(t.info.encodeResponseName(signature)).Call(ctx_contextContext, Id(fullName).Interface()).Params(Interface(), Error()).BlockFunc(
 		func(group *Group) {
 			if len(methodResults) == 1 {
-				sp := specialEndpointConverterToProto(methodResults[0], signature, responseStructName, t.info.SourcePackageImport+"/transport", fullName, shortName)
+				sp := specialEndpointConverterToProto(ctx, methodResults[0], signature, t.info.responseStructName, t.info.OutputPackageImport+"/transport", fullName, shortName)
 				if sp != nil {
 					group.Add(sp)
 					return
@@ -460,16 +467,16 @@ func (t *gRPCEndpointConverterTemplate) encodeResponse(ctx context.Context, sign
 			}
 			if len(methodResults) > 0 {
 				group.If(Id(fullName).Op("==").Nil()).Block(
-					Return(Nil(), Qual(PackagePathErrors, "New").Call(Lit("nil "+responseStructName(signature)))),
+					Return(Nil(), Qual(PackagePathErrors, "New").Call(Lit("nil "+t.info.responseStructName(signature)))),
 				)
-				group.Id(shortName).Op(":=").Id(fullName).Assert(Op("*").Qual(t.info.OutputPackageImport+"/transport", responseStructName(signature)))
+				group.Id(shortName).Op(":=").Id(fullName).Assert(Op("*").Qual(t.info.OutputPackageImport+"/transport", t.info.responseStructName(signature)))
 				for _, field := range methodResults {
 					if _, ok := golangTypeToProto(ctx, "", &field); !ok {
 						group.Add(convertCustomType(shortName, typeToProto(field.Type, 0), &field))
 					}
 				}
 			}
-			group.Return().List(t.grpcEndpointConvReturn(ctx, signature, methodResults, responseStructName, shortName, golangTypeToProto, t.info.ProtobufPackageImport), Nil())
+			group.Return().List(t.grpcEndpointConvReturn(ctx, signature, methodResults, responseMessageName, shortName, golangTypeToProto, t.info.ProtobufPackageImport), Nil())
 		},
 	).Line()
 }
@@ -488,10 +495,10 @@ func (t *gRPCEndpointConverterTemplate) decodeStreamRequest(ctx context.Context,
 	methodParams := removeLastVar(RemoveContextIfFirst(signature.Args))
 	fullName := "request"
 	shortName := "req"
-	return Line().Func().Id(decodeRequestName(signature)).Call(ctx_contextContext, Id(fullName).Interface()).Params(Interface(), Error()).BlockFunc(
+	return Line().Func().Id(t.info.decodeRequestName(signature)).Call(ctx_contextContext, Id(fullName).Interface()).Params(Interface(), Error()).BlockFunc(
 		func(group *Group) {
 			if len(methodParams) == 1 {
-				sp := specialEndpointConverterFromProto(methodParams[0], signature, requestStructName, t.info.SourcePackageImport, fullName, shortName)
+				sp := specialEndpointConverterFromProto(ctx, methodParams[0], signature, t.info.requestStructName, t.info.OutputPackageImport+"/transport", fullName, shortName)
 				if sp != nil {
 					group.Add(sp)
 					return
@@ -499,16 +506,16 @@ func (t *gRPCEndpointConverterTemplate) decodeStreamRequest(ctx context.Context,
 			}
 			if len(methodParams) > 0 {
 				group.If(Id(fullName).Op("==").Nil()).Block(
-					Return(Nil(), Qual(PackagePathErrors, "New").Call(Lit("nil "+requestStructName(signature)))),
+					Return(Nil(), Qual(PackagePathErrors, "New").Call(Lit("nil "+t.info.requestStructName(signature)))),
 				)
-				group.Id(shortName).Op(":=").Id(fullName).Assert(Op("*").Qual(t.info.ProtobufPackageImport, requestStructName(signature)))
+				group.Id(shortName).Op(":=").Id(fullName).Assert(Op("*").Qual(t.info.ProtobufPackageImport, requestMessageName(signature)))
 				for _, field := range methodParams {
 					if _, ok := protoTypeToGolang(ctx, "", &field); !ok {
 						group.Add(convertCustomType(shortName, protoToType(field.Type, 0), &field))
 					}
 				}
 			}
-			group.Return().List(t.grpcEndpointConvReturn(ctx, signature, methodParams, requestStructName, shortName, protoTypeToGolang, t.info.OutputPackageImport+"/transport"), Nil())
+			group.Return().List(t.grpcEndpointConvReturn(ctx, signature, methodParams, t.info.requestStructName, shortName, protoTypeToGolang, t.info.OutputPackageImport+"/transport"), Nil())
 		},
 	).Line()
 }
@@ -517,10 +524,10 @@ func (t *gRPCEndpointConverterTemplate) decodeRequest(ctx context.Context, signa
 	methodParams := RemoveContextIfFirst(signature.Args)
 	fullName := "request"
 	shortName := "req"
-	return Line().Func().Id(decodeRequestName(signature)).Call(ctx_contextContext, Id(fullName).Interface()).Params(Interface(), Error()).BlockFunc(
+	return Line().Func().Id(t.info.decodeRequestName(signature)).Call(ctx_contextContext, Id(fullName).Interface()).Params(Interface(), Error()).BlockFunc(
 		func(group *Group) {
 			if len(methodParams) == 1 {
-				sp := specialEndpointConverterFromProto(methodParams[0], signature, requestStructName, t.info.SourcePackageImport, fullName, shortName)
+				sp := specialEndpointConverterFromProto(ctx, methodParams[0], signature, t.info.requestStructName, t.info.OutputPackageImport+"/transport", fullName, shortName)
 				if sp != nil {
 					group.Add(sp)
 					return
@@ -528,16 +535,16 @@ func (t *gRPCEndpointConverterTemplate) decodeRequest(ctx context.Context, signa
 			}
 			if len(methodParams) > 0 {
 				group.If(Id(fullName).Op("==").Nil()).Block(
-					Return(Nil(), Qual(PackagePathErrors, "New").Call(Lit("nil "+requestStructName(signature)))),
+					Return(Nil(), Qual(PackagePathErrors, "New").Call(Lit("nil "+t.info.requestStructName(signature)))),
 				)
-				group.Id(shortName).Op(":=").Id(fullName).Assert(Op("*").Qual(t.info.ProtobufPackageImport, requestStructName(signature)))
+				group.Id(shortName).Op(":=").Id(fullName).Assert(Op("*").Qual(t.info.ProtobufPackageImport, requestMessageName(signature)))
 				for _, field := range methodParams {
 					if _, ok := protoTypeToGolang(ctx, "", &field); !ok {
 						group.Add(convertCustomType(shortName, protoToType(field.Type, 0), &field))
 					}
 				}
 			}
-			group.Return().List(t.grpcEndpointConvReturn(ctx, signature, methodParams, requestStructName, shortName, protoTypeToGolang, t.info.OutputPackageImport+"/transport"), Nil())
+			group.Return().List(t.grpcEndpointConvReturn(ctx, signature, methodParams, t.info.requestStructName, shortName, protoTypeToGolang, t.info.OutputPackageImport+"/transport"), Nil())
 		},
 	).Line()
 }
@@ -560,10 +567,10 @@ func (t *gRPCEndpointConverterTemplate) decodeResponse(ctx context.Context, sign
 	methodResults := removeErrorIfLast(signature.Results)
 	fullName := "response"
 	shortName := "resp"
-	return Line().Func().Id(decodeResponseName(signature)).Call(ctx_contextContext, Id(fullName).Interface()).Params(Interface(), Error()).BlockFunc(
+	return Line().Func().Id(t.info.decodeResponseName(signature)).Call(ctx_contextContext, Id(fullName).Interface()).Params(Interface(), Error()).BlockFunc(
 		func(group *Group) {
 			if len(methodResults) == 1 {
-				sp := specialEndpointConverterFromProto(methodResults[0], signature, responseStructName, t.info.SourcePackageImport+"/transport", fullName, shortName)
+				sp := specialEndpointConverterFromProto(ctx, methodResults[0], signature, t.info.responseStructName, t.info.OutputPackageImport+"/transport", fullName, shortName)
 				if sp != nil {
 					group.Add(sp)
 					return
@@ -571,21 +578,30 @@ func (t *gRPCEndpointConverterTemplate) decodeResponse(ctx context.Context, sign
 			}
 			if len(methodResults) > 0 {
 				group.If(Id(fullName).Op("==").Nil()).Block(
-					Return(Nil(), Qual(PackagePathErrors, "New").Call(Lit("nil "+responseStructName(signature)))),
+					Return(Nil(), Qual(PackagePathErrors, "New").Call(Lit("nil "+t.info.responseStructName(signature)))),
 				)
-				group.Id(shortName).Op(":=").Id(fullName).Assert(Op("*").Qual(t.info.ProtobufPackageImport, responseStructName(signature)))
+				group.Id(shortName).Op(":=").Id(fullName).Assert(Op("*").Qual(t.info.ProtobufPackageImport, responseMessageName(signature)))
 				for _, field := range methodResults {
 					if _, ok := protoTypeToGolang(ctx, "", &field); !ok {
 						group.Add(convertCustomType(shortName, protoToType(field.Type, 0), &field))
 					}
 				}
 			}
-			group.Return().List(t.grpcEndpointConvReturn(ctx, signature, methodResults, responseStructName, shortName, protoTypeToGolang, t.info.OutputPackageImport+"/transport"), Nil())
+			group.Return().List(t.grpcEndpointConvReturn(ctx, signature, methodResults, t.info.responseStructName, shortName, protoTypeToGolang, t.info.OutputPackageImport+"/transport"), Nil())
 		},
 	).Line()
 }
 
+// Renders conversion of single pointer to scalar to wrapper message.
+//
+//		if request == nil {
+//			return nil, nil
+//		}
+//		req := request.(*transport.GetRequest)
+//		return &wrappers.Int64Value{Value: *req.Id}, nil
+//
 func specialEndpointConverterToProto(
+	ctx context.Context,
 	v types.Variable,
 	fn *types.Function,
 	strNameFn func(*types.Function) string,
@@ -593,25 +609,29 @@ func specialEndpointConverterToProto(
 	fullName string,
 	shortName string,
 ) *Statement {
-	name := types.TypeName(v.Type)
-	imp := types.TypeImport(v.Type)
-	// *string -> *wrappers.StringValue
-	if name != nil && *name == "string" && imp == nil {
-		_, ok := v.Type.(types.TPointer)
-		if ok {
-			s := If(Id(fullName).Op("==").Nil()).Block(
-				Return(Nil(), Nil()),
-			)
-			sp := Op("*").Qual(pkg, strNameFn(fn))
-			s.Line().Id(shortName).Op(":=").Id(fullName).Assert(sp)
-			s.Line().Return(Op("&").Qual(GolangProtobufWrappers, "StringValue").Values(Dict{Id("Value"): Op("*").Id(shortName).Op(".").Add(structFieldName(&v))}), Nil())
-			return s
-		}
+	wrapper, ok := pointerWrapperName(v.Type)
+	if !ok {
+		return nil
 	}
-	return nil
+	s := If(Id(fullName).Op("==").Nil()).Block(
+		Return(Nil(), Nil()),
+	)
+	sp := Op("*").Qual(pkg, strNameFn(fn))
+	s.Line().Id(shortName).Op(":=").Id(fullName).Assert(sp)
+	s.Line().Return(Op("&").Qual(protobufWrappersPackage(ctx), wrapper).Values(Dict{Id("Value"): Op("*").Id(shortName).Op(".").Add(structFieldName(&v))}), Nil())
+	return s
 }
 
+// Renders conversion of wrapper message to single pointer to scalar.
+//
+//		if response == nil {
+//			return nil, nil
+//		}
+//		resp := response.(*wrappers.Int64Value)
+//		return &transport.GetResponse{Id: &resp.Value}, nil
+//
 func specialEndpointConverterFromProto(
+	ctx context.Context,
 	v types.Variable,
 	fn *types.Function,
 	strNameFn func(*types.Function) string,
@@ -619,20 +639,15 @@ func specialEndpointConverterFromProto(
 	fullName string,
 	shortName string,
 ) *Statement {
-	name := types.TypeName(v.Type)
-	imp := types.TypeImport(v.Type)
-	// *string <- *wrappers.StringValue
-	if name != nil && *name == "string" && imp == nil {
-		_, ok := v.Type.(types.TPointer)
-		if ok {
-			s := If(Id(fullName).Op("==").Nil()).Block(
-				Return(Nil(), Nil()),
-			)
-			sp := Op("*").Qual(GolangProtobufWrappers, "StringValue")
-			s.Line().Id(shortName).Op(":=").Id(fullName).Assert(sp)
-			s.Line().Return(Op("&").Qual(pkg, strNameFn(fn)).Values(Dict{structFieldName(&v): Op("&").Id(shortName).Dot("Value")}), Nil())
-			return s
-		}
+	wrapper, ok := pointerWrapperName(v.Type)
+	if !ok {
+		return nil
 	}
-	return nil
+	s := If(Id(fullName).Op("==").Nil()).Block(
+		Return(Nil(), Nil()),
+	)
+	sp := Op("*").Qual(protobufWrappersPackage(ctx), wrapper)
+	s.Line().Id(shortName).Op(":=").Id(fullName).Assert(sp)
+	s.Line().Return(Op("&").Qual(pkg, strNameFn(fn)).Values(Dict{structFieldName(&v): Op("&").Id(shortName).Dot("Value")}), Nil())
+	return s
 }
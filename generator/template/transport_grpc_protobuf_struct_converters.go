@@ -0,0 +1,743 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	. "github.com/dave/jennifer/jen"
+	mstrings "github.com/recolabs/microgen/generator/strings"
+	"github.com/vetcher/go-astra/types"
+)
+
+const (
+	GolangProtobufDuration        = "github.com/golang/protobuf/ptypes/duration"
+	GoogleProtobufTimestamppb     = "google.golang.org/protobuf/types/known/timestamppb"
+	GoogleProtobufDurationpb      = "google.golang.org/protobuf/types/known/durationpb"
+	GoogleProtobufWrapperspb      = "google.golang.org/protobuf/types/known/wrapperspb"
+	protoConverterLocalPrefix     = "conv"
+	protoConverterProtoNamePrefix = "proto"
+)
+
+var (
+	// Types of Value field of wrappers messages.
+	protoWrapperValueTypes = map[string]types.Type{
+		"DoubleValue": types.TName{TypeName: "float64"},
+		"FloatValue":  types.TName{TypeName: "float32"},
+		"Int64Value":  types.TName{TypeName: "int64"},
+		"UInt64Value": types.TName{TypeName: "uint64"},
+		"Int32Value":  types.TName{TypeName: "int32"},
+		"UInt32Value": types.TName{TypeName: "uint32"},
+		"BoolValue":   types.TName{TypeName: "bool"},
+		"StringValue": types.TName{TypeName: "string"},
+		"BytesValue":  types.TArray{IsSlice: true, Next: types.TName{TypeName: "byte"}},
+	}
+	basicNumberTypes = []string{
+		"int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64", "byte", "rune",
+	}
+)
+
+// Kinds of converter functions, which are rendered for pair of golang and protobuf types.
+const (
+	converterKindNone = iota
+	converterKindStruct
+	converterKindSlice
+	converterKindMap
+	converterKindPointer
+)
+
+// protoConverterFunc is a queued converter function between golang type and type of pb.go file.
+type protoConverterFunc struct {
+	name    string
+	toProto bool
+	golang  types.Type
+	proto   types.Type
+}
+
+// protoConversion converts one value: with expression or with call of function, that returns value and error.
+// Call of nilType is skipped for nil source message, result is zero value of nilType then.
+type protoConversion struct {
+	expr    func(src Code) *Statement
+	call    func(src Code) *Statement
+	nilType *Statement
+	nilZero *Statement
+}
+
+// Renders conversion of src. When conversion may fail, result is assigned to local variable
+// and error is checked.
+//
+//		convCreated, err := ptypes.TimestampProto(comment.Created)
+//		if err != nil {
+//			return nil, err
+//		}
+//
+// Source message of nil conversion is checked before call.
+//
+//		var convCreated time.Time
+//		if protoComment.Created != nil {
+//			var err error
+//			convCreated, err = ptypes.Timestamp(protoComment.Created)
+//			if err != nil {
+//				return nil, err
+//			}
+//		}
+//
+func (c *protoConversion) render(group *Group, local string, src Code, zero Code) Code {
+	if c.expr != nil {
+		return c.expr(src)
+	}
+	if c.nilType == nil {
+		c.check(group, local, ":=", src, zero)
+		return Id(local)
+	}
+	group.Var().Id(local).Add(c.nilType.Clone())
+	group.If(Add(src).Op("!=").Nil()).BlockFunc(func(notNil *Group) {
+		notNil.Var().Err().Error()
+		c.check(notNil, local, "=", src, zero)
+	})
+	return Id(local)
+}
+
+// Renders call and check of error.
+func (c *protoConversion) check(group *Group, local, op string, src Code, zero Code) {
+	group.List(Id(local), Err()).Op(op).Add(c.call(src))
+	group.If(Err().Op("!=").Nil()).Block(
+		Return(zero, Err()),
+	)
+}
+
+// Renders conversion of src, which result is assigned to new local variable. Source is not nil.
+func (c *protoConversion) assign(group *Group, local string, src Code, zero Code) {
+	if c.expr != nil {
+		group.Id(local).Op(":=").Add(c.expr(src))
+		return
+	}
+	c.check(group, local, ":=", src, zero)
+}
+
+func exprConversion(fn func(src Code) *Statement) *protoConversion {
+	return &protoConversion{expr: fn}
+}
+
+func callConversion(fn *Statement) *protoConversion {
+	return &protoConversion{call: func(src Code) *Statement { return fn.Clone().Call(src) }}
+}
+
+// Conversion from message, which may be nil, to value of typ.
+func nilCallConversion(fn, typ, zero *Statement) *protoConversion {
+	c := callConversion(fn)
+	c.nilType, c.nilZero = typ, zero
+	return c
+}
+
+// Returns name and import path of named type. Path is empty for types, declared in the same file.
+func namedType(t types.Type) (name string, pkg string, ok bool) {
+	switch tt := t.(type) {
+	case types.TName:
+		return tt.TypeName, "", true
+	case types.TImport:
+		next, ok := tt.Next.(types.TName)
+		if !ok {
+			return "", "", false
+		}
+		if tt.Import != nil {
+			pkg = tt.Import.Package
+		}
+		return next.TypeName, pkg, true
+	}
+	return "", "", false
+}
+
+// Returns type, that is pointed by single pointer.
+func pointerElem(t types.Type) (types.Type, bool) {
+	p, ok := t.(types.TPointer)
+	if !ok || p.NumberOfPointers != 1 {
+		return nil, false
+	}
+	return p.Next, true
+}
+
+func sliceElem(t types.Type) (types.Type, bool) {
+	a, ok := t.(types.TArray)
+	if !ok || !a.IsSlice {
+		return nil, false
+	}
+	return a.Next, true
+}
+
+func derefType(t types.Type) types.Type {
+	if elem, ok := pointerElem(t); ok {
+		return elem
+	}
+	return t
+}
+
+// Checks, that type is named type from one of packages.
+func isNamedTypeOf(t types.Type, name string, pkgs ...string) bool {
+	n, pkg, ok := namedType(t)
+	return ok && n == name && mstrings.IsInStringSlice(pkg, pkgs)
+}
+
+// Returns name of wrapper message, when type is pointer to it.
+func protoWrapperName(t types.Type) (string, bool) {
+	elem, ok := pointerElem(t)
+	if !ok {
+		return "", false
+	}
+	name, pkg, ok := namedType(elem)
+	if !ok || (pkg != GolangProtobufWrappers && pkg != GoogleProtobufWrapperspb) {
+		return "", false
+	}
+	_, ok = protoWrapperValueTypes[name]
+	return name, ok
+}
+
+// Returns string representation of type, where names without import are qualified with local package.
+func canonicalType(t types.Type, local string) string {
+	switch tt := t.(type) {
+	case types.TName:
+		if types.IsBuiltin(tt) || local == "" {
+			return tt.TypeName
+		}
+		return local + "." + tt.TypeName
+	case types.TImport:
+		if tt.Import == nil {
+			return canonicalType(tt.Next, local)
+		}
+		return tt.Import.Package + "." + canonicalType(tt.Next, "")
+	case types.TPointer:
+		return strings.Repeat("*", tt.NumberOfPointers) + canonicalType(tt.Next, local)
+	case types.TArray:
+		if tt.IsSlice {
+			return "[]" + canonicalType(tt.Next, local)
+		}
+		return "[" + strconv.Itoa(tt.ArrayLen) + "]" + canonicalType(tt.Next, local)
+	case types.TMap:
+		return "map[" + canonicalType(tt.Key, local) + "]" + canonicalType(tt.Value, local)
+	case nil:
+		return ""
+	}
+	return t.String()
+}
+
+// Returns builtin type of type or underlying builtin type of type, declared in file of package pkg.
+func basicType(t types.Type, file *types.File, pkg string) (string, bool) {
+	name, p, ok := namedType(t)
+	if !ok {
+		return "", false
+	}
+	if p == "" && types.IsBuiltinTypeString(name) {
+		return name, true
+	}
+	if file == nil || (p != "" && p != pkg) {
+		return "", false
+	}
+	for _, ft := range file.Types {
+		if ft.Name != name {
+			continue
+		}
+		if underlying, ok := ft.Type.(types.TName); ok && types.IsBuiltin(underlying) {
+			return underlying.TypeName, true
+		}
+	}
+	return "", false
+}
+
+func basicKind(name string) string {
+	switch {
+	case name == "string", name == "bool":
+		return name
+	case mstrings.IsInStringSlice(name, basicNumberTypes):
+		return "number"
+	}
+	return ""
+}
+
+func findStructInFile(t types.Type, file *types.File, pkg string) *types.Struct {
+	name, p, ok := namedType(t)
+	if !ok || file == nil || (p != "" && p != pkg) || types.IsBuiltinTypeString(name) {
+		return nil
+	}
+	for i := range file.Structures {
+		if file.Structures[i].Name == name {
+			return &file.Structures[i]
+		}
+	}
+	return nil
+}
+
+func hasVar(file *types.File, name string) bool {
+	for _, v := range file.Vars {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Finds field of generated protobuf message by name of golang field.
+// Protobuf field names are camel cased, so `ID` matches `Id`.
+func findProtoField(s *types.Struct, name string) *types.StructField {
+	var found *types.StructField
+	for i := range s.Fields {
+		f := &s.Fields[i]
+		if !isPublic(f.Name) || strings.HasPrefix(f.Name, "XXX_") {
+			continue
+		}
+		if f.Name == name {
+			return f
+		}
+		if found == nil && strings.EqualFold(f.Name, name) {
+			found = f
+		}
+	}
+	return found
+}
+
+func isPublic(name string) bool {
+	return name != "" && name == mstrings.ToUpperFirst(name)
+}
+
+// Returns base name for parameters of converter.
+func converterParamName(t types.Type) string {
+	name := "value"
+	if n := types.TypeName(t); n != nil && types.TypeImport(t) != nil {
+		name = mstrings.ToLowerFirst(types.TypeImport(t).Name + mstrings.ToUpperFirst(*n))
+	} else if n != nil && !types.IsBuiltinTypeString(*n) {
+		name = mstrings.ToLowerFirst(*n)
+	} else if n != nil {
+		name = *n + "Value"
+	}
+	if types.IsMap(t) {
+		name += "Map"
+	} else if types.IsArray(t) {
+		name += "List"
+	}
+	return name
+}
+
+func (t *stubGRPCTypeConverterTemplate) converterName(toProto bool, golang types.Type) string {
+	if toProto {
+		return typeToProto(golang, 0)
+	}
+	return protoToType(golang, 0)
+}
+
+func (t *stubGRPCTypeConverterTemplate) golangStruct(golang types.Type) *types.Struct {
+	return findStructInFile(golang, t.sourceFile, t.info.SourcePackageImport)
+}
+
+func (t *stubGRPCTypeConverterTemplate) protoStruct(proto types.Type) *types.Struct {
+	return findStructInFile(proto, t.info.ProtobufGoFile, t.info.ProtobufPackageImport)
+}
+
+// Finds type of field of request or response message for method argument or result.
+func (t *stubGRPCTypeConverterTemplate) protoMessageFieldType(messageName string, field *types.Variable) (types.Type, bool) {
+	s := findStructInFile(types.TName{TypeName: messageName}, t.info.ProtobufGoFile, t.info.ProtobufPackageImport)
+	if s == nil {
+		return nil, false
+	}
+	f := findProtoField(s, mstrings.ToUpperFirst(field.Name))
+	if f == nil {
+		return nil, false
+	}
+	return f.Type, true
+}
+
+// Render type of pb.go file, where names without import are qualified with protobuf package.
+//
+//		[]*pb.Comment
+//
+func (t *stubGRPCTypeConverterTemplate) protoType(proto types.Type) *Statement {
+	switch p := proto.(type) {
+	case types.TName:
+		if types.IsBuiltin(p) {
+			return Id(p.TypeName)
+		}
+		return Qual(t.info.ProtobufPackageImport, p.TypeName)
+	case types.TImport:
+		if p.Import == nil {
+			return t.protoType(p.Next)
+		}
+		return Qual(p.Import.Package, canonicalType(p.Next, ""))
+	case types.TPointer:
+		return Op(strings.Repeat("*", p.NumberOfPointers)).Add(t.protoType(p.Next))
+	case types.TArray:
+		if p.IsSlice {
+			return Index().Add(t.protoType(p.Next))
+		}
+		return Index(Lit(p.ArrayLen)).Add(t.protoType(p.Next))
+	case types.TMap:
+		return Map(t.protoType(p.Key)).Add(t.protoType(p.Value))
+	}
+	return Interface()
+}
+
+// Returns zero value of type, which is returned with error.
+func (t *stubGRPCTypeConverterTemplate) zeroValue(typ types.Type, rendered *Statement, file *types.File, pkg string) *Statement {
+	switch typ.(type) {
+	case types.TPointer, types.TArray, types.TMap, types.TInterface:
+		return Nil()
+	}
+	if findStructInFile(typ, file, pkg) != nil {
+		return rendered.Clone().Values()
+	}
+	if name, ok := basicType(typ, file, pkg); ok {
+		switch basicKind(name) {
+		case "string":
+			return Lit("")
+		case "bool":
+			return False()
+		}
+		return Lit(0)
+	}
+	return Nil()
+}
+
+func (t *stubGRPCTypeConverterTemplate) golangZero(ctx context.Context, golang types.Type) *Statement {
+	return t.zeroValue(golang, fieldType(ctx, golang, false), t.sourceFile, t.info.SourcePackageImport)
+}
+
+func (t *stubGRPCTypeConverterTemplate) protoZero(proto types.Type) *Statement {
+	return t.zeroValue(proto, t.protoType(proto), t.info.ProtobufGoFile, t.info.ProtobufPackageImport)
+}
+
+// Returns kind of converter function, which should be rendered for pair of types.
+func (t *stubGRPCTypeConverterTemplate) converterKind(golang, proto types.Type) int {
+	if canonicalType(golang, t.info.SourcePackageImport) == canonicalType(proto, t.info.ProtobufPackageImport) {
+		return converterKindNone
+	}
+	if t.golangStruct(derefType(golang)) != nil && t.protoStruct(derefType(proto)) != nil {
+		return converterKindStruct
+	}
+	if _, ok := sliceElem(golang); ok {
+		if _, ok := sliceElem(proto); ok {
+			return converterKindSlice
+		}
+	}
+	_, gMap := golang.(types.TMap)
+	_, pMap := proto.(types.TMap)
+	if gMap && pMap {
+		return converterKindMap
+	}
+	if _, ok := pointerElem(golang); ok {
+		return converterKindPointer
+	}
+	return converterKindNone
+}
+
+// Returns conversion between golang and protobuf types or nil, when types can not be converted.
+// Structures, slices, maps and pointers are converted by functions, which are queued for render.
+func (t *stubGRPCTypeConverterTemplate) conversion(ctx context.Context, toProto bool, golang, proto types.Type) *protoConversion {
+	if kind := t.converterKind(golang, proto); kind != converterKindNone {
+		if !t.canConvert(ctx, toProto, kind, golang, proto) {
+			return nil
+		}
+		name := t.converterName(toProto, golang)
+		if !mstrings.IsInStringSlice(name, t.alreadyRenderedConverters) {
+			t.alreadyRenderedConverters = append(t.alreadyRenderedConverters, name)
+			t.queue = append(t.queue, protoConverterFunc{name: name, toProto: toProto, golang: golang, proto: proto})
+		}
+		return callConversion(Id(name))
+	}
+	return t.valueConversion(ctx, toProto, golang, proto)
+}
+
+// Checks, that elements of slices, maps and pointers can be converted.
+func (t *stubGRPCTypeConverterTemplate) canConvert(ctx context.Context, toProto bool, kind int, golang, proto types.Type) bool {
+	switch kind {
+	case converterKindSlice:
+		gElem, _ := sliceElem(golang)
+		pElem, _ := sliceElem(proto)
+		return t.conversion(ctx, toProto, gElem, pElem) != nil
+	case converterKindMap:
+		gMap, pMap := golang.(types.TMap), proto.(types.TMap)
+		return t.conversion(ctx, toProto, gMap.Key, pMap.Key) != nil && t.conversion(ctx, toProto, gMap.Value, pMap.Value) != nil
+	case converterKindPointer:
+		gElem, _ := pointerElem(golang)
+		pElem, _ := t.pointerTarget(proto)
+		return t.valueConversion(ctx, toProto, gElem, pElem) != nil
+	}
+	return true
+}
+
+// Returns conversion of values, that do not need separate function:
+// equal types, time, durations, numbers, strings and enums.
+func (t *stubGRPCTypeConverterTemplate) valueConversion(ctx context.Context, toProto bool, golang, proto types.Type) *protoConversion {
+	if canonicalType(golang, t.info.SourcePackageImport) == canonicalType(proto, t.info.ProtobufPackageImport) {
+		return exprConversion(func(src Code) *Statement { return Add(src) })
+	}
+	protoElem, _ := pointerElem(proto)
+	switch {
+	// Well-known types of google.golang.org/protobuf are converted with their own methods.
+	case isNamedTypeOf(golang, "Time", PackagePathTime) && isNamedTypeOf(protoElem, "Timestamp", GoogleProtobufTimestamppb):
+		if toProto {
+			return exprConversion(func(src Code) *Statement { return Qual(GoogleProtobufTimestamppb, "New").Call(src) })
+		}
+		return exprConversion(func(src Code) *Statement { return Add(src).Dot("AsTime").Call() })
+	case isNamedTypeOf(golang, "Duration", PackagePathTime) && isNamedTypeOf(protoElem, "Duration", GoogleProtobufDurationpb):
+		if toProto {
+			return exprConversion(func(src Code) *Statement { return Qual(GoogleProtobufDurationpb, "New").Call(src) })
+		}
+		return exprConversion(func(src Code) *Statement { return Add(src).Dot("AsDuration").Call() })
+	case isStructMap(golang) && isNamedTypeOf(protoElem, "Struct", GoogleProtobufStructpb):
+		if toProto {
+			return callConversion(Qual(GoogleProtobufStructpb, "NewStruct"))
+		}
+		return exprConversion(func(src Code) *Statement { return Add(src).Dot("AsMap").Call() })
+	case golang.String() == "[]string" && isNamedTypeOf(protoElem, "FieldMask", GoogleProtobufFieldmaskpb):
+		if toProto {
+			return exprConversion(func(src Code) *Statement {
+				return Op("&").Qual(GoogleProtobufFieldmaskpb, "FieldMask").Values(Dict{Id("Paths"): src})
+			})
+		}
+		return exprConversion(func(src Code) *Statement { return Add(src).Dot("GetPaths").Call() })
+	case isNamedTypeOf(golang, "Time", PackagePathTime) && isNamedTypeOf(protoElem, "Timestamp", GolangProtobufPtypesTimestamp):
+		if toProto {
+			return callConversion(Qual(GolangProtobufPtypes, "TimestampProto"))
+		}
+		return nilCallConversion(Qual(GolangProtobufPtypes, "Timestamp"), Qual(PackagePathTime, "Time"), Qual(PackagePathTime, "Time").Values())
+	case isNamedTypeOf(golang, "Duration", PackagePathTime) && isNamedTypeOf(protoElem, "Duration", GolangProtobufDuration):
+		if toProto {
+			return exprConversion(func(src Code) *Statement { return Qual(GolangProtobufPtypes, "DurationProto").Call(src) })
+		}
+		return nilCallConversion(Qual(GolangProtobufPtypes, "Duration"), Qual(PackagePathTime, "Duration"), Lit(0))
+	}
+	golangBasic, ok := basicType(golang, t.sourceFile, t.info.SourcePackageImport)
+	if !ok {
+		return nil
+	}
+	protoBasic, ok := basicType(proto, t.info.ProtobufGoFile, t.info.ProtobufPackageImport)
+	if !ok {
+		return nil
+	}
+	if basicKind(golangBasic) != "" && basicKind(golangBasic) == basicKind(protoBasic) {
+		if toProto {
+			return exprConversion(func(src Code) *Statement { return t.protoType(proto).Call(src) })
+		}
+		return exprConversion(func(src Code) *Statement { return fieldType(ctx, golang, false).Call(src) })
+	}
+	// String golang enum to protobuf enum by names of values.
+	enum, _, _ := namedType(proto)
+	if basicKind(golangBasic) == "string" && basicKind(protoBasic) == "number" &&
+		hasVar(t.info.ProtobufGoFile, enum+"_value") && hasVar(t.info.ProtobufGoFile, enum+"_name") {
+		if toProto {
+			return exprConversion(func(src Code) *Statement {
+				return t.protoType(proto).Call(Qual(t.info.ProtobufPackageImport, enum+"_value").Index(Id("string").Call(src)))
+			})
+		}
+		return exprConversion(func(src Code) *Statement {
+			return fieldType(ctx, golang, false).Call(Qual(t.info.ProtobufPackageImport, enum+"_name").Index(Id(protoBasic).Call(src)))
+		})
+	}
+	return nil
+}
+
+// Ways to get value, pointed by golang pointer, from protobuf type.
+const (
+	pointerTargetValue = iota
+	pointerTargetDeref
+	pointerTargetWrapper
+)
+
+// Returns type, which is converted to value of golang pointer, and the way to get it.
+func (t *stubGRPCTypeConverterTemplate) pointerTarget(proto types.Type) (types.Type, int) {
+	if name, ok := protoWrapperName(proto); ok {
+		return protoWrapperValueTypes[name], pointerTargetWrapper
+	}
+	if elem, ok := pointerElem(proto); ok {
+		if _, ok := basicType(elem, t.info.ProtobufGoFile, t.info.ProtobufPackageImport); ok {
+			return elem, pointerTargetDeref
+		}
+	}
+	return proto, pointerTargetValue
+}
+
+// Render converter function, which name and parameters are based on name.
+//
+//		func PtrCommentToProto(comment *svc.Comment) (*pb.Comment, error) {
+//			...
+//		}
+//
+func (t *stubGRPCTypeConverterTemplate) protoConverterFunc(ctx context.Context, fn protoConverterFunc, name string) *Statement {
+	golangParam := Id(name).Add(fieldType(ctx, fn.golang, false))
+	protoParam := Id(protoConverterProtoNamePrefix + mstrings.ToUpperFirst(name)).Add(t.protoType(fn.proto))
+	body := func(group *Group) {
+		t.protoConverterBody(ctx, group, fn, name)
+	}
+	if fn.toProto {
+		return Func().Id(fn.name).
+			Params(golangParam).
+			Params(t.protoType(fn.proto), Error()).
+			BlockFunc(body)
+	}
+	return Func().Id(fn.name).
+		Params(protoParam).
+		Params(fieldType(ctx, fn.golang, false), Error()).
+		BlockFunc(body)
+}
+
+// Render body of converter function. Converted value has name of parameter of golang type,
+// value of protobuf type is prefixed with `proto`.
+func (t *stubGRPCTypeConverterTemplate) protoConverterBody(ctx context.Context, group *Group, fn protoConverterFunc, name string) {
+	golangName, protoName := name, protoConverterProtoNamePrefix+mstrings.ToUpperFirst(name)
+	src, dst := protoName, golangName
+	srcType, dstType := fn.proto, fn.golang
+	dstCode, dstZero := fieldType(ctx, fn.golang, false), t.golangZero(ctx, fn.golang)
+	if fn.toProto {
+		src, dst = golangName, protoName
+		srcType, dstType = fn.golang, fn.proto
+		dstCode, dstZero = t.protoType(fn.proto), t.protoZero(fn.proto)
+	}
+	switch t.converterKind(fn.golang, fn.proto) {
+	case converterKindStruct:
+		t.structConverterBody(ctx, group, fn, src, dstZero)
+	case converterKindSlice:
+		gElem, _ := sliceElem(fn.golang)
+		pElem, _ := sliceElem(fn.proto)
+		conv := t.conversion(ctx, fn.toProto, gElem, pElem)
+		group.If(Id(src).Op("==").Nil()).Block(Return(Nil(), Nil()))
+		group.Id(dst).Op(":=").Make(dstCode, Len(Id(src)))
+		group.For(Id("i").Op(":=").Range().Id(src)).BlockFunc(func(loop *Group) {
+			elem := conv.render(loop, "elem", Id(src).Index(Id("i")), Nil())
+			loop.Id(dst).Index(Id("i")).Op("=").Add(elem)
+		})
+		group.Return(Id(dst), Nil())
+	case converterKindMap:
+		gMap, pMap := fn.golang.(types.TMap), fn.proto.(types.TMap)
+		key := t.conversion(ctx, fn.toProto, gMap.Key, pMap.Key)
+		value := t.conversion(ctx, fn.toProto, gMap.Value, pMap.Value)
+		group.If(Id(src).Op("==").Nil()).Block(Return(Nil(), Nil()))
+		group.Id(dst).Op(":=").Make(dstCode, Len(Id(src)))
+		group.For(List(Id("k"), Id("v")).Op(":=").Range().Id(src)).BlockFunc(func(loop *Group) {
+			k := key.render(loop, protoConverterLocalPrefix+"Key", Id("k"), Nil())
+			v := value.render(loop, protoConverterLocalPrefix+"Value", Id("v"), Nil())
+			loop.Id(dst).Index(k).Op("=").Add(v)
+		})
+		group.Return(Id(dst), Nil())
+	case converterKindPointer:
+		t.pointerConverterBody(ctx, group, fn, src, dstZero)
+	default:
+		conv := t.valueConversion(ctx, fn.toProto, fn.golang, fn.proto)
+		if conv == nil {
+			group.Comment(fmt.Sprintf("TODO: can not convert %s to %s", srcType, dstType))
+			group.Return(Id(src), Nil())
+			return
+		}
+		if conv.expr != nil {
+			group.Return(conv.expr(Id(src)), Nil())
+			return
+		}
+		if conv.nilType != nil {
+			group.If(Id(src).Op("==").Nil()).Block(Return(conv.nilZero.Clone(), Nil()))
+		}
+		group.Return(conv.call(Id(src)))
+	}
+}
+
+// Render conversion of structure field by field. Fields, that can not be converted, are listed in TODO comments.
+//
+//		if comment == nil {
+//			return nil, nil
+//		}
+//		convCreated, err := ptypes.TimestampProto(comment.Created)
+//		if err != nil {
+//			return nil, err
+//		}
+//		// TODO: Author has no matching field in pb.Comment
+//		return &pb.Comment{
+//			Created: convCreated,
+//			Id:      comment.ID,
+//			Kind:    pb.Kind(comment.Kind),
+//		}, nil
+//
+func (t *stubGRPCTypeConverterTemplate) structConverterBody(ctx context.Context, group *Group, fn protoConverterFunc, src string, zero *Statement) {
+	gs, ps := t.golangStruct(derefType(fn.golang)), t.protoStruct(derefType(fn.proto))
+	dstType, dstIsPtr := fieldType(ctx, derefType(fn.golang), false), isPointer(fn.golang)
+	if fn.toProto {
+		dstType, dstIsPtr = t.protoType(derefType(fn.proto)), isPointer(fn.proto)
+	}
+	srcIsPtr := isPointer(fn.proto)
+	if fn.toProto {
+		srcIsPtr = isPointer(fn.golang)
+	}
+	if srcIsPtr {
+		group.If(Id(src).Op("==").Nil()).Block(Return(zero, Nil()))
+	}
+	var todos []string
+	dict := Dict{}
+	for _, gf := range gs.Fields {
+		if !isPublic(gf.Name) {
+			continue
+		}
+		pf := findProtoField(ps, gf.Name)
+		if pf == nil {
+			todos = append(todos, fmt.Sprintf("TODO: %s has no matching field in pb.%s", gf.Name, ps.Name))
+			continue
+		}
+		conv := t.conversion(ctx, fn.toProto, gf.Type, pf.Type)
+		if conv == nil {
+			todos = append(todos, fmt.Sprintf("TODO: can not convert %s of type %s to %s of type %s", gf.Name, gf.Type, pf.Name, pf.Type))
+			continue
+		}
+		srcField, dstField := pf.Name, gf.Name
+		if fn.toProto {
+			srcField, dstField = gf.Name, pf.Name
+		}
+		dict[Id(dstField)] = conv.render(group, protoConverterLocalPrefix+gf.Name, Id(src).Dot(srcField), zero)
+	}
+	for _, todo := range todos {
+		group.Comment(todo)
+	}
+	result := dstType.Values(dict)
+	if dstIsPtr {
+		result = Op("&").Add(result)
+	}
+	group.Return(result, Nil())
+}
+
+// Render conversion of golang pointer. Protobuf value may be a pointer, wrapper message or plain value.
+//
+//		if stringValue == nil {
+//			return nil, nil
+//		}
+//		conv := *stringValue
+//		return &wrappers.StringValue{Value: conv}, nil
+//
+func (t *stubGRPCTypeConverterTemplate) pointerConverterBody(ctx context.Context, group *Group, fn protoConverterFunc, src string, zero *Statement) {
+	gElem, _ := pointerElem(fn.golang)
+	pElem, target := t.pointerTarget(fn.proto)
+	conv := t.valueConversion(ctx, fn.toProto, gElem, pElem)
+	local := protoConverterLocalPrefix
+	if fn.toProto {
+		group.If(Id(src).Op("==").Nil()).Block(Return(zero, Nil()))
+		conv.assign(group, local, Op("*").Id(src), zero)
+		switch target {
+		case pointerTargetWrapper:
+			name, _ := protoWrapperName(fn.proto)
+			elem, _ := pointerElem(fn.proto)
+			_, pkg, _ := namedType(elem)
+			group.Return(Op("&").Qual(pkg, name).Values(Dict{Id("Value"): Id(local)}), Nil())
+		case pointerTargetDeref:
+			group.Return(Op("&").Id(local), Nil())
+		default:
+			group.Return(Id(local), Nil())
+		}
+		return
+	}
+	value := Code(Id(src))
+	switch target {
+	case pointerTargetWrapper:
+		value = Id(src).Dot("Value")
+	case pointerTargetDeref:
+		value = Op("*").Id(src)
+	}
+	if isPointer(fn.proto) {
+		group.If(Id(src).Op("==").Nil()).Block(Return(Nil(), Nil()))
+	}
+	conv.assign(group, local, value, Nil())
+	group.Return(Op("&").Id(local), Nil())
+}
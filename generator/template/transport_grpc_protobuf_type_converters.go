@@ -19,12 +19,32 @@ const (
 	JsonbPackage                  = "github.com/sas1024/gorm-jsonb/jsonb"
 	GolangProtobufPtypes          = "github.com/golang/protobuf/ptypes"
 	GolangProtobufWrappers        = "github.com/golang/protobuf/ptypes/wrappers"
+	GoogleProtobufEmptypb         = "google.golang.org/protobuf/types/known/emptypb"
+	GoogleProtobufStructpb        = "google.golang.org/protobuf/types/known/structpb"
+	GoogleProtobufFieldmaskpb     = "google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
+// Names of wrapper messages for pointers to scalars.
+var protoPointerWrappers = map[string]string{
+	"string":  "StringValue",
+	"bool":    "BoolValue",
+	"int64":   "Int64Value",
+	"int32":   "Int32Value",
+	"uint64":  "UInt64Value",
+	"uint32":  "UInt32Value",
+	"float64": "DoubleValue",
+	"float32": "FloatValue",
+}
+
 type stubGRPCTypeConverterTemplate struct {
 	info                      *GenerationInfo
 	alreadyRenderedConverters []string
 	state                     WriteStrategyState
+
+	// Parsed service package, where structures for conversion to messages of pb.go file are looked up.
+	sourceFile *types.File
+	// Converters of nested types, which should be rendered.
+	queue []protoConverterFunc
 }
 
 func NewStubGRPCTypeConverterTemplate(info *GenerationInfo) Template {
@@ -33,7 +53,49 @@ func NewStubGRPCTypeConverterTemplate(info *GenerationInfo) Template {
 	}
 }
 
-func specialTypeConverter(p types.Type) *Statement {
+// Converters use well-known types of google.golang.org/protobuf instead of
+// deprecated github.com/golang/protobuf/ptypes packages, when protobuf-apiv2 tag is set.
+func protobufAPIv2(ctx context.Context) bool {
+	tags, _ := ctx.Value(mainTagsContextKey).(TagsSet)
+	return tags.Has(ProtobufAPIv2Tag)
+}
+
+func protobufEmptyPackage(ctx context.Context) string {
+	if protobufAPIv2(ctx) {
+		return GoogleProtobufEmptypb
+	}
+	return PackagePathEmptyProtobuf
+}
+
+func protobufWrappersPackage(ctx context.Context) string {
+	if protobufAPIv2(ctx) {
+		return GoogleProtobufWrapperspb
+	}
+	return GolangProtobufWrappers
+}
+
+func protobufTimestampPackage(ctx context.Context) string {
+	if protobufAPIv2(ctx) {
+		return GoogleProtobufTimestamppb
+	}
+	return GolangProtobufPtypesTimestamp
+}
+
+// Returns name of wrapper message for single pointer to scalar, e.g. Int64Value for *int64.
+func pointerWrapperName(p types.Type) (string, bool) {
+	elem, ok := pointerElem(p)
+	if !ok {
+		return "", false
+	}
+	name, ok := elem.(types.TName)
+	if !ok {
+		return "", false
+	}
+	wrapper, ok := protoPointerWrappers[name.TypeName]
+	return wrapper, ok
+}
+
+func specialTypeConverter(ctx context.Context, p types.Type) *Statement {
 	name := types.TypeName(p)
 	imp := types.TypeImport(p)
 	// error -> string
@@ -43,86 +105,144 @@ func specialTypeConverter(p types.Type) *Statement {
 	// time.Time -> timestamp.Timestamp
 	if name != nil && *name == "Time" && imp != nil && imp.Package == "time" {
 		if types.TypeArray(p) == nil { // ignore []time.Time case
-			return Op("*").Qual(GolangProtobufPtypesTimestamp, "Timestamp")
+			return Op("*").Qual(protobufTimestampPackage(ctx), "Timestamp")
+		}
+	}
+	if protobufAPIv2(ctx) {
+		// time.Duration -> durationpb.Duration
+		if isNamedTypeOf(p, "Duration", PackagePathTime) {
+			return Op("*").Qual(GoogleProtobufDurationpb, "Duration")
+		}
+		// map[string]interface{} -> structpb.Struct
+		if isStructMap(p) {
+			return Op("*").Qual(GoogleProtobufStructpb, "Struct")
 		}
 	}
+	// Well-known types, e.g. *fieldmaskpb.FieldMask, are passed as is.
+	if _, ok := WellKnownProtoType(p); ok {
+		return fieldType(ctx, p, false)
+	}
 	// jsonb.JSONB -> string
 	if name != nil && *name == "JSONB" && imp != nil && imp.Package == JsonbPackage {
 		return Id("string")
 	}
-	// *string -> *wrappers.StringValue
-	if name != nil && *name == "string" && imp == nil {
-		_, ok := p.(types.TPointer)
-		if ok {
-			return Op("*").Qual(GolangProtobufWrappers, "StringValue")
-		}
+	// *string -> *wrappers.StringValue, *int64 -> *wrappers.Int64Value, etc.
+	if wrapper, ok := pointerWrapperName(p); ok {
+		return Op("*").Qual(protobufWrappersPackage(ctx), wrapper)
 	}
-	// *float64 -> *wrappers.DoubleValue
-	if name != nil && *name == "float64" && imp == nil {
-		ptr, ok := p.(types.TPointer)
-		if ok && ptr.NumberOfPointers == 1 {
-			return Op("*").Qual(GolangProtobufWrappers, "DoubleValue")
-		}
+	return nil
+}
+
+// Returns well-known message, which is sent instead of request or response message for single param,
+// as in service.proto: wrapper for pointer to scalar or timestamp for time.Time.
+func wellKnownMessageType(ctx context.Context, p types.Type) *Statement {
+	if wrapper, ok := pointerWrapperName(p); ok {
+		return Op("*").Qual(protobufWrappersPackage(ctx), wrapper)
+	}
+	if isNamedTypeOf(derefType(p), "Time", PackagePathTime) {
+		return Op("*").Qual(protobufTimestampPackage(ctx), "Timestamp")
 	}
 	return nil
 }
 
-func converterToProtoBody(field *types.Variable) Code {
+// Checks, that type is map[string]interface{}, which is represented by google.protobuf.Struct.
+func isStructMap(p types.Type) bool {
+	m, ok := p.(types.TMap)
+	if !ok {
+		return false
+	}
+	_, ok = m.Value.(types.TInterface)
+	return ok && m.Key.String() == "string"
+}
+
+func converterToProtoBody(ctx context.Context, field *types.Variable) Code {
 	s := &Statement{}
-	switch typeToProto(field.Type, 0) {
-	case "ErrorToProto":
-		s.If(Id(mstrings.ToLowerFirst(field.Name))).Op("==").Nil().Block(
+	name := mstrings.ToLowerFirst(field.Name)
+	v2 := protobufAPIv2(ctx)
+	switch converter := typeToProto(field.Type, 0); {
+	case converter == "ErrorToProto":
+		s.If(Id(name)).Op("==").Nil().Block(
 			Return().List(Lit(""), Nil()),
 		).Line()
-		s.Return().List(Id(mstrings.ToLowerFirst(field.Name)).Dot("Error").Call(), Nil())
-	case "ByteListToProto", "ListByteToProto":
-		s.Return().List(Id(mstrings.ToLowerFirst(field.Name)), Nil())
-	case "TimeTimeToProto":
-		s.Return().Qual(GolangProtobufPtypes, "TimestampProto").Call(Id(field.Name))
-	case "ListStringToProto", "SliceStringToProto":
-		s.Return().List(Id(mstrings.ToLowerFirst(field.Name)), Nil())
-	//	if str == "" {
-	//		return nil
+		s.Return().List(Id(name).Dot("Error").Call(), Nil())
+	case converter == "ByteListToProto", converter == "ListByteToProto":
+		s.Return().List(Id(name), Nil())
+	case converter == "TimeTimeToProto" && v2:
+		s.Return().List(Qual(GoogleProtobufTimestamppb, "New").Call(Id(name)), Nil())
+	case converter == "TimeTimeToProto":
+		s.Return().Qual(GolangProtobufPtypes, "TimestampProto").Call(Id(name))
+	case converter == "TimeDurationToProto" && v2:
+		s.Return().List(Qual(GoogleProtobufDurationpb, "New").Call(Id(name)), Nil())
+	//	if m == nil {
+	//		return nil, nil
 	//	}
-	//	return &wrappers.StringValue{Value: str}
-	case "PtrStringToProto":
-		s.If(Id(mstrings.ToLowerFirst(field.Name)).Op("==").Nil()).Block(
+	//	return structpb.NewStruct(m)
+	case converter == "MapStringInterfaceToProto" && v2:
+		s.If(Id(name).Op("==").Nil()).Block(
 			Return().List(Nil(), Nil()),
 		)
-		s.Line().Return().List(Op("&").Qual(GolangProtobufWrappers, "StringValue").Block(Dict{Id("Value"): Op("*").Id(mstrings.ToLowerFirst(field.Name)).Op(",")}), Nil())
+		s.Line().Return().Qual(GoogleProtobufStructpb, "NewStruct").Call(Id(name))
+	case converter == "ListStringToProto", converter == "SliceStringToProto":
+		s.Return().List(Id(name), Nil())
+	//	if str == nil {
+	//		return nil, nil
+	//	}
+	//	return &wrappers.StringValue{Value: *str}, nil
+	case isPointerWrapper(field.Type):
+		wrapper, _ := pointerWrapperName(field.Type)
+		s.If(Id(name).Op("==").Nil()).Block(
+			Return().List(Nil(), Nil()),
+		)
+		s.Line().Return().List(Op("&").Qual(protobufWrappersPackage(ctx), wrapper).Values(Dict{Id("Value"): Op("*").Id(name)}), Nil())
 	default:
-		s.Return().List(Id(mstrings.ToLowerFirst(field.Name)), Nil())
+		s.Return().List(Id(name), Nil())
 		//s.Panic(Lit("function not provided")).Comment("// TODO: provide converter")
 	}
 	return s
 }
 
-func converterProtoToBody(field *types.Variable) Code {
+func converterProtoToBody(ctx context.Context, field *types.Variable) Code {
 	s := &Statement{}
-	switch protoToType(field.Type, 0) {
-	case "ProtoToError":
-		s.If().Id("proto" + mstrings.ToUpperFirst(field.Name)).Op("==").Lit("").Block(
+	name := "proto" + mstrings.ToUpperFirst(field.Name)
+	v2 := protobufAPIv2(ctx)
+	switch converter := protoToType(field.Type, 0); {
+	case converter == "ProtoToError":
+		s.If().Id(name).Op("==").Lit("").Block(
 			Return().List(Nil(), Nil()),
 		).Line()
-		s.Return().List(Qual("errors", "New").Call(Id("proto"+mstrings.ToUpperFirst(field.Name))), Nil())
-	case "ProtoToByteList", "ProtoToListByte":
-		s.Return().List(Id("proto"+mstrings.ToUpperFirst(field.Name)), Nil())
-	case "ProtoToTimeTime":
-		s.Return().Qual(GolangProtobufPtypes, "Timestamp").Call(Id("proto" + mstrings.ToUpperFirst(field.Name)))
-	case "ProtoToListString", "ProtoToSliceString":
-		s.Return().List(Id("proto"+mstrings.ToUpperFirst(field.Name)), Nil())
-	case "ProtoToPtrString":
-		s.If(Id("proto" + mstrings.ToUpperFirst(field.Name)).Op("==").Nil()).Block(
+		s.Return().List(Qual("errors", "New").Call(Id(name)), Nil())
+	case converter == "ProtoToByteList", converter == "ProtoToListByte":
+		s.Return().List(Id(name), Nil())
+	case converter == "ProtoToTimeTime" && v2:
+		s.Return().List(Id(name).Dot("AsTime").Call(), Nil())
+	case converter == "ProtoToTimeTime":
+		s.Return().Qual(GolangProtobufPtypes, "Timestamp").Call(Id(name))
+	case converter == "ProtoToTimeDuration" && v2:
+		s.Return().List(Id(name).Dot("AsDuration").Call(), Nil())
+	case converter == "ProtoToMapStringInterface" && v2:
+		s.If(Id(name).Op("==").Nil()).Block(
 			Return().List(Nil(), Nil()),
 		)
-		s.Line().Return().List(Op("&").Id("proto"+mstrings.ToUpperFirst(field.Name)).Dot("Value"), Nil())
+		s.Line().Return().List(Id(name).Dot("AsMap").Call(), Nil())
+	case converter == "ProtoToListString", converter == "ProtoToSliceString":
+		s.Return().List(Id(name), Nil())
+	case isPointerWrapper(field.Type):
+		s.If(Id(name).Op("==").Nil()).Block(
+			Return().List(Nil(), Nil()),
+		)
+		s.Line().Return().List(Op("&").Id(name).Dot("Value"), Nil())
 	default:
-		s.Return().List(Id("proto"+mstrings.ToUpperFirst(field.Name)), Nil())
+		s.Return().List(Id(name), Nil())
 		//s.Panic(Lit("function not provided")).Comment("// TODO: provide converter")
 	}
 	return s
 }
 
+func isPointerWrapper(p types.Type) bool {
+	_, ok := pointerWrapperName(p)
+	return ok
+}
+
 // Render whole file with protobuf converters.
 //
 //		// This file was automatically generated by "microgen" utility.
@@ -143,18 +263,40 @@ func (t *stubGRPCTypeConverterTemplate) Render(ctx context.Context) write_strate
 		if !t.info.AllowedMethods[signature.Name] {
 			continue
 		}
-		args := append(RemoveContextIfFirst(signature.Args), removeErrorIfLast(signature.Results)...)
-		for _, field := range args {
+		args := RemoveContextIfFirst(signature.Args)
+		results := removeErrorIfLast(signature.Results)
+		for i, field := range append(args, results...) {
+			messageName := requestMessageName(signature)
+			if i >= len(args) {
+				messageName = responseMessageName(signature)
+			}
+			protoType, hasProto := t.protoMessageFieldType(messageName, &field)
 			if _, ok := golangTypeToProto(ctx, "", &field); !ok && !mstrings.IsInStringSlice(typeToProto(field.Type, 0), t.alreadyRenderedConverters) {
-				f.Line().Add(t.stubConverterToProto(ctx, &field)).Line()
 				t.alreadyRenderedConverters = append(t.alreadyRenderedConverters, typeToProto(field.Type, 0))
+				if hasProto {
+					fn := protoConverterFunc{name: typeToProto(field.Type, 0), toProto: true, golang: field.Type, proto: protoType}
+					f.Line().Add(t.protoConverterFunc(ctx, fn, mstrings.ToLowerFirst(field.Name))).Line()
+				} else {
+					f.Line().Add(t.stubConverterToProto(ctx, &field)).Line()
+				}
 			}
 			if _, ok := protoTypeToGolang(ctx, "", &field); !ok && !mstrings.IsInStringSlice(protoToType(field.Type, 0), t.alreadyRenderedConverters) {
-				f.Line().Add(t.stubConverterProtoTo(ctx, &field)).Line()
 				t.alreadyRenderedConverters = append(t.alreadyRenderedConverters, protoToType(field.Type, 0))
+				if hasProto {
+					fn := protoConverterFunc{name: protoToType(field.Type, 0), toProto: false, golang: field.Type, proto: protoType}
+					f.Line().Add(t.protoConverterFunc(ctx, fn, mstrings.ToLowerFirst(field.Name))).Line()
+				} else {
+					f.Line().Add(t.stubConverterProtoTo(ctx, &field)).Line()
+				}
 			}
 		}
 	}
+	// Converters of nested structures, slices, maps and pointers.
+	for len(t.queue) > 0 {
+		fn := t.queue[0]
+		t.queue = t.queue[1:]
+		f.Line().Add(t.protoConverterFunc(ctx, fn, converterParamName(fn.golang))).Line()
+	}
 
 	if t.state == AppendStrat {
 		return f
@@ -179,6 +321,14 @@ func (t *stubGRPCTypeConverterTemplate) Prepare(ctx context.Context) error {
 	if t.info.ProtobufPackageImport == "" {
 		return fmt.Errorf("protobuf package is empty")
 	}
+	if t.info.ProtobufGoFile != nil {
+		file, err := parsePackage(t.info.SourceFilePath)
+		if err != nil {
+			logger.Logger.Logln(1, "can't parse service package, structures will not be converted:", err)
+			return nil
+		}
+		t.sourceFile = file
+	}
 	return nil
 }
 
@@ -211,7 +361,7 @@ func (t *stubGRPCTypeConverterTemplate) stubConverterToProto(ctx context.Context
 	return Func().Id(typeToProto(field.Type, 0)).
 		Params(Id(mstrings.ToLowerFirst(field.Name)).Add(fieldType(ctx, field.Type, false))).
 		Params(Add(t.protoFieldType(ctx, field.Type)), Error()).
-		Block(converterToProtoBody(field))
+		Block(converterToProtoBody(ctx, field))
 }
 
 // Render stub method for protobuf to golang converter.
@@ -224,7 +374,7 @@ func (t *stubGRPCTypeConverterTemplate) stubConverterProtoTo(ctx context.Context
 	return Func().Id(protoToType(field.Type, 0)).
 		Params(Id("proto"+mstrings.ToUpperFirst(field.Name)).Add(t.protoFieldType(ctx, field.Type))).
 		Params(Add(fieldType(ctx, field.Type, false)), Error()).
-		Block(converterProtoToBody(field))
+		Block(converterProtoToBody(ctx, field))
 }
 
 // Render protobuf field type for given func field.
@@ -233,7 +383,7 @@ func (t *stubGRPCTypeConverterTemplate) stubConverterProtoTo(ctx context.Context
 //
 func (t *stubGRPCTypeConverterTemplate) protoFieldType(ctx context.Context, field types.Type) *Statement {
 	c := &Statement{}
-	if code := specialTypeConverter(field); code != nil {
+	if code := specialTypeConverter(ctx, field); code != nil {
 		return c.Add(code)
 	}
 	custom := false
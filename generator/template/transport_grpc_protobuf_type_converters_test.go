@@ -0,0 +1,207 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetcher/go-astra"
+)
+
+const typeConvertersTestSource = `package svc
+
+import (
+	"context"
+	"time"
+)
+
+type CommentService interface {
+	Create(ctx context.Context, comment *Comment) (created *Comment, err error)
+}
+
+type Status string
+
+type Comment struct {
+	ID      string
+	Status  Status
+	Created time.Time
+	Note    *string
+	Scores  []int
+	Author  *User
+	Replies []*Comment
+	Secret  string
+}
+
+type User struct {
+	Name string
+	Age  int
+}
+`
+
+const typeConvertersTestPbGo = `package pb
+
+import (
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+)
+
+type Status int32
+
+var Status_name = map[int32]string{0: "DRAFT"}
+
+var Status_value = map[string]int32{"DRAFT": 0}
+
+type Comment struct {
+	sizeCache int32
+	Id        string
+	Status    Status
+	Created   *timestamp.Timestamp
+	Note      *wrappers.StringValue
+	Scores    []int64
+	Author    *User
+	Replies   []*Comment
+}
+
+type User struct {
+	Name string
+	Age  int32
+}
+
+type CreateRequest struct {
+	Comment *Comment
+}
+
+type CreateResponse struct {
+	Created *Comment
+}
+`
+
+func TestProtobufStructConverters(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "svc.go")
+	pbGo := filepath.Join(dir, "svc.pb.go.txt")
+	if err := ioutil.WriteFile(source, []byte(typeConvertersTestSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(pbGo, []byte(typeConvertersTestPbGo), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := astra.ParseFile(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pbFile, err := astra.ParseFile(pbGo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ResetParsedCache()
+	info := &GenerationInfo{
+		Iface:                 &file.Interfaces[0],
+		SourceFilePath:        source,
+		SourcePackageImport:   "example.com/svc",
+		OutputPackageImport:   "example.com/svc",
+		ProtobufPackageImport: "example.com/svc/pb",
+		ProtobufGoFile:        pbFile,
+		AllowedMethods:        map[string]bool{"Create": true},
+	}
+	ctx := WithSourcePackageImport(context.Background(), info.SourcePackageImport)
+	tmpl := NewStubGRPCTypeConverterTemplate(info)
+	if err := tmpl.Prepare(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Render(ctx).Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	code := buf.String()
+	assert.Contains(t, code, "func PtrCommentToProto(comment *service.Comment) (*pb.Comment, error)")
+	assert.Contains(t, code, "func ProtoToPtrComment(protoComment *pb.Comment) (*service.Comment, error)")
+	assert.Contains(t, code, "convCreated, err := ptypes.TimestampProto(comment.Created)")
+	assert.Contains(t, code, "pb.Status(pb.Status_value[string(comment.Status)])")
+	assert.Contains(t, code, "service.Status(pb.Status_name[int32(protoComment.Status)])")
+	assert.Contains(t, code, "// TODO: Secret has no matching field in pb.Comment")
+	assert.Contains(t, code, "return &wrappers.StringValue{Value: conv}, nil")
+	assert.Contains(t, code, "protoIntValueList[i] = int64(intValueList[i])")
+	assert.Contains(t, code, "func ListPtrCommentToProto(commentList []*service.Comment) ([]*pb.Comment, error)")
+	assert.Contains(t, code, "elem, err := ProtoToPtrComment(protoCommentList[i])")
+	assert.Contains(t, code, "Age:  int(protoUser.Age),")
+}
+
+const protobufAPIv2TestSource = `package svc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+type EventService interface {
+	Update(ctx context.Context, at time.Time, ttl time.Duration, attrs map[string]interface{}, mask *fieldmaskpb.FieldMask) (err error)
+	Find(ctx context.Context, limit *int64) (found *bool, err error)
+}
+`
+
+func TestProtobufAPIv2Converters(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "svc.go")
+	if err := ioutil.WriteFile(source, []byte(protobufAPIv2TestSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := astra.ParseFile(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &GenerationInfo{
+		Iface:                 &file.Interfaces[0],
+		SourceFilePath:        source,
+		SourcePackageImport:   "example.com/svc",
+		OutputPackageImport:   "example.com/svc",
+		ProtobufPackageImport: "example.com/svc/pb",
+		AllowedMethods:        map[string]bool{"Update": true, "Find": true},
+	}
+	ctx := WithSourcePackageImport(context.Background(), info.SourcePackageImport)
+	ctx = WithTags(ctx, TagsSet{GrpcTag: {}, ProtobufAPIv2Tag: {}})
+	render := func(tmpl Template) string {
+		if err := tmpl.Prepare(ctx); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Render(ctx).Render(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	code := render(NewStubGRPCTypeConverterTemplate(info))
+	for _, s := range []string{
+		"func TimeTimeToProto(at time.Time) (*timestamppb.Timestamp, error) {\n\treturn timestamppb.New(at), nil\n}",
+		"func ProtoToTimeTime(protoAt *timestamppb.Timestamp) (time.Time, error) {\n\treturn protoAt.AsTime(), nil\n}",
+		"func TimeDurationToProto(ttl time.Duration) (*durationpb.Duration, error) {\n\treturn durationpb.New(ttl), nil\n}",
+		"func ProtoToTimeDuration(protoTtl *durationpb.Duration) (time.Duration, error) {\n\treturn protoTtl.AsDuration(), nil\n}",
+		"func MapStringInterfaceToProto(attrs map[string]interface{}) (*structpb.Struct, error) {",
+		"return structpb.NewStruct(attrs)",
+		"return protoAttrs.AsMap(), nil",
+		"func PtrFieldmaskpbFieldMaskToProto(mask *fieldmaskpb.FieldMask) (*fieldmaskpb.FieldMask, error) {\n\treturn mask, nil\n}",
+		"func PtrInt64ToProto(limit *int64) (*wrapperspb.Int64Value, error) {",
+		"return &wrapperspb.Int64Value{Value: *limit}, nil",
+		"func ProtoToPtrBool(protoFound *wrapperspb.BoolValue) (*bool, error) {",
+		"return &protoFound.Value, nil",
+	} {
+		assert.Contains(t, code, s)
+	}
+	assert.NotContains(t, code, "github.com/golang/protobuf")
+
+	code = render(NewGRPCEndpointConverterTemplate(info))
+	for _, s := range []string{
+		"return &emptypb.Empty{}, nil",
+		"req := request.(*transport.FindRequest)",
+		"return &wrapperspb.Int64Value{Value: *req.Limit}, nil",
+		"resp := response.(*wrapperspb.BoolValue)",
+	} {
+		assert.Contains(t, code, s)
+	}
+	assert.NotContains(t, code, "github.com/golang/protobuf")
+}
@@ -75,15 +75,15 @@ func (t *gRPCServerTemplate) Render(ctx context.Context) write_strategy.Renderer
 		g.Id(unimplementedServerEmbedString)
 		for _, method := range t.info.Iface.Methods {
 			if t.info.OneToManyStreamMethods[method.Name] {
-				g.Id(mstrings.ToLowerFirst(method.Name)).Qual(t.info.OutputPackageImport+"/transport", OneToManyStreamEndpoint)
+				g.Id(mstrings.ToLowerFirst(method.Name)).Qual(t.info.OutputPackageImport+"/transport", t.info.nsName(OneToManyStreamEndpoint))
 				continue
 			}
 			if t.info.ManyToManyStreamMethods[method.Name] {
-				g.Id(mstrings.ToLowerFirst(method.Name)).Qual(t.info.OutputPackageImport+"/transport", ManyToManyStreamEndpoint)
+				g.Id(mstrings.ToLowerFirst(method.Name)).Qual(t.info.OutputPackageImport+"/transport", t.info.nsName(ManyToManyStreamEndpoint))
 				continue
 			}
 			if t.info.ManyToOneStreamMethods[method.Name] {
-				g.Id(mstrings.ToLowerFirst(method.Name)).Qual(t.info.OutputPackageImport+"/transport", ManyToOneStreamEndpoint)
+				g.Id(mstrings.ToLowerFirst(method.Name)).Qual(t.info.OutputPackageImport+"/transport", t.info.nsName(ManyToOneStreamEndpoint))
 				continue
 			}
 			if !t.info.AllowedMethods[method.Name] {
@@ -93,15 +93,16 @@ func (t *gRPCServerTemplate) Render(ctx context.Context) write_strategy.Renderer
 		}
 	}).Line()
 
-	f.Func().Id("NewGRPCServer").
+	f.Func().Id(t.info.nsNewName("GRPCServer")).
 		ParamsFunc(func(p *Group) {
-			p.Id("endpoints").Op("*").Qual(t.info.OutputPackageImport+"/transport", EndpointsSetName)
+			p.Id("endpoints").Op("*").Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName())
 			if Tags(ctx).Has(TracingMiddlewareTag) {
 				p.Id("logger").Qual(PackagePathGoKitLog, "Logger")
-			}
-			if Tags(ctx).Has(TracingMiddlewareTag) {
 				p.Id("tracer").Qual(PackagePathOpenTracingGo, "Tracer")
 			}
+			if Tags(ctx).Has(OpenTelemetryTag) {
+				p.Id("propagator").Qual(PackagePathOTelPropagation, "TextMapPropagator")
+			}
 			p.Id("opts").Op("...").Qual(PackagePathGoKitTransportGRPC, "ServerOption")
 		}).Params(
 		Qual(t.info.ProtobufPackageImport, serverStructName(t.info.Iface)),
@@ -136,8 +137,8 @@ func (t *gRPCServerTemplate) Render(ctx context.Context) write_strategy.Renderer
 					g[(&Statement{}).Id(mstrings.ToLowerFirst(m.Name))] = Qual(PackagePathGoKitTransportGRPC, "NewServer").
 						Call(
 							Line().Id("endpoints").Dot(endpointsStructFieldName(m.Name)),
-							Line().Id(decodeRequestName(m)),
-							Line().Id(encodeResponseName(m)),
+							Line().Id(t.info.decodeRequestName(m)),
+							Line().Id(t.info.encodeResponseName(m)),
 							Line().Add(t.serverOpts(ctx, m)).Op("...").Line(),
 						)
 				}
@@ -149,10 +150,10 @@ func (t *gRPCServerTemplate) Render(ctx context.Context) write_strategy.Renderer
 	f.Func().
 		Id("newOneToManyStreamServer").
 		Params(
-			Id("endpoint").Qual(t.info.OutputPackageImport+"/transport", OneToManyStreamEndpoint),
+			Id("endpoint").Qual(t.info.OutputPackageImport+"/transport", t.info.nsName(OneToManyStreamEndpoint)),
 		).
 		Params(
-			Qual(t.info.OutputPackageImport+"/transport", OneToManyStreamEndpoint),
+			Qual(t.info.OutputPackageImport+"/transport", t.info.nsName(OneToManyStreamEndpoint)),
 		).
 		Block(
 			Return().Id("endpoint"),
@@ -163,10 +164,10 @@ func (t *gRPCServerTemplate) Render(ctx context.Context) write_strategy.Renderer
 	f.Func().
 		Id("newManyToOneStreamServer").
 		Params(
-			Id("endpoint").Qual(t.info.OutputPackageImport+"/transport", ManyToOneStreamEndpoint),
+			Id("endpoint").Qual(t.info.OutputPackageImport+"/transport", t.info.nsName(ManyToOneStreamEndpoint)),
 		).
 		Params(
-			Qual(t.info.OutputPackageImport+"/transport", ManyToOneStreamEndpoint),
+			Qual(t.info.OutputPackageImport+"/transport", t.info.nsName(ManyToOneStreamEndpoint)),
 		).
 		Block(
 			Return().Id("endpoint"),
@@ -177,10 +178,10 @@ func (t *gRPCServerTemplate) Render(ctx context.Context) write_strategy.Renderer
 	f.Func().
 		Id("newManyToManyStreamServer").
 		Params(
-			Id("endpoint").Qual(t.info.OutputPackageImport+"/transport", ManyToManyStreamEndpoint),
+			Id("endpoint").Qual(t.info.OutputPackageImport+"/transport", t.info.nsName(ManyToManyStreamEndpoint)),
 		).
 		Params(
-			Qual(t.info.OutputPackageImport+"/transport", ManyToManyStreamEndpoint),
+			Qual(t.info.OutputPackageImport+"/transport", t.info.nsName(ManyToManyStreamEndpoint)),
 		).
 		Block(
 			Return().Id("endpoint"),
@@ -189,7 +190,7 @@ func (t *gRPCServerTemplate) Render(ctx context.Context) write_strategy.Renderer
 
 	for _, signature := range t.info.Iface.Methods {
 		if t.info.OneToManyStreamMethods[signature.Name] {
-			f.Add(t.grpcOneToManyStreamServerFunc(signature, t.info)).Line()
+			f.Add(t.grpcOneToManyStreamServerFunc(ctx, signature, t.info)).Line()
 			continue
 		}
 		if t.info.ManyToManyStreamMethods[signature.Name] {
@@ -203,18 +204,22 @@ func (t *gRPCServerTemplate) Render(ctx context.Context) write_strategy.Renderer
 		if !t.info.AllowedMethods[signature.Name] {
 			continue
 		}
-		f.Add(t.grpcServerFunc(signature, t.info.Iface)).Line()
+		f.Add(t.grpcServerFunc(ctx, signature, t.info.Iface)).Line()
+	}
+
+	if Tags(ctx).Has(OpenTelemetryTag) {
+		f.Line().Add(grpcExtractTraceContext(t.info.nsPrivateName("extractTraceContext")))
 	}
 
 	return f
 }
 
-func (t *gRPCServerTemplate) grpcOneToManyStreamServerFunc(signature *types.Function, info *GenerationInfo) *Statement {
+func (t *gRPCServerTemplate) grpcOneToManyStreamServerFunc(ctx context.Context, signature *types.Function, info *GenerationInfo) *Statement {
 	return Func().
 		Params(Id(rec(privateServerStructName(info.Iface))).Op("*").Id(privateServerStructName(info.Iface))).
 		Id(signature.Name).
 		Call(
-			Id("req").Add(t.grpcServerReqStruct(signature)),
+			Id("req").Add(t.grpcServerReqStruct(ctx, signature)),
 			Id("stream").Qual(info.ProtobufPackageImport, streamStructName(info.Iface.Name, signature))).
 		Params(Error()).
 		BlockFunc(t.grpcOneToManyStreamServerFuncBody(signature, info.Iface))
@@ -223,7 +228,7 @@ func (t *gRPCServerTemplate) grpcOneToManyStreamServerFuncBody(signature *types.
 	return func(g *Group) {
 		g.List(Id("decoded_req"), Err()).
 			Op(":=").
-			Id(decodeRequestName(signature)).Call(
+			Id(t.info.decodeRequestName(signature)).Call(
 			Id("context.Background()"),
 			Id("req"))
 
@@ -255,8 +260,8 @@ func (t *gRPCServerTemplate) grpcManyToOneStreamServerFuncBody(signature *types.
 	}
 }
 
-func (gRPCServerTemplate) DefaultPath() string {
-	return filenameBuilder(PathTransport, "grpc", "server")
+func (t *gRPCServerTemplate) DefaultPath() string {
+	return filenameBuilder(PathTransport, "grpc", t.info.nsFile("server"))
 }
 
 func (t *gRPCServerTemplate) Prepare(ctx context.Context) error {
@@ -280,13 +285,13 @@ func (t *gRPCServerTemplate) ChooseStrategy(ctx context.Context) (write_strategy
 //			return resp.(*stringsvc.CountResponse), nil
 //		}
 //
-func (t *gRPCServerTemplate) grpcServerFunc(signature *types.Function, i *types.Interface) *Statement {
+func (t *gRPCServerTemplate) grpcServerFunc(ctx context.Context, signature *types.Function, i *types.Interface) *Statement {
 	return Func().
 		Params(Id(rec(privateServerStructName(i))).Op("*").Id(privateServerStructName(i))).
 		Id(signature.Name).
-		Call(Id("ctx").Qual(PackagePathNetContext, "Context"), Id("req").Add(t.grpcServerReqStruct(signature))).
-		Params(t.grpcServerRespStruct(signature), Error()).
-		BlockFunc(t.grpcServerFuncBody(signature, i))
+		Call(Id("ctx").Qual(PackagePathNetContext, "Context"), Id("req").Add(t.grpcServerReqStruct(ctx, signature))).
+		Params(t.grpcServerRespStruct(ctx, signature), Error()).
+		BlockFunc(t.grpcServerFuncBody(ctx, signature, i))
 }
 
 // Special case for empty request
@@ -294,18 +299,18 @@ func (t *gRPCServerTemplate) grpcServerFunc(signature *types.Function, i *types.
 //		*empty.Empty
 // or
 //		*stringsvc.CountRequest
-func (t *gRPCServerTemplate) grpcServerReqStruct(fn *types.Function) *Statement {
+func (t *gRPCServerTemplate) grpcServerReqStruct(ctx context.Context, fn *types.Function) *Statement {
 	args := RemoveContextIfFirst(fn.Args)
 	if len(args) == 0 {
-		return Op("*").Qual(PackagePathEmptyProtobuf, "Empty")
+		return Op("*").Qual(protobufEmptyPackage(ctx), "Empty")
 	}
 	if len(args) == 1 {
-		sp := specialTypeConverter(args[0].Type)
+		sp := wellKnownMessageType(ctx, args[0].Type)
 		if sp != nil {
 			return sp
 		}
 	}
-	return Op("*").Qual(t.info.ProtobufPackageImport, requestStructName(fn))
+	return Op("*").Qual(t.info.ProtobufPackageImport, requestMessageName(fn))
 }
 
 // Special case for empty response
@@ -313,18 +318,18 @@ func (t *gRPCServerTemplate) grpcServerReqStruct(fn *types.Function) *Statement
 //		*empty.Empty
 // or
 //		*stringsvc.CountResponse
-func (t *gRPCServerTemplate) grpcServerRespStruct(fn *types.Function) *Statement {
+func (t *gRPCServerTemplate) grpcServerRespStruct(ctx context.Context, fn *types.Function) *Statement {
 	results := removeErrorIfLast(fn.Results)
 	if len(results) == 0 {
-		return Op("*").Qual(PackagePathEmptyProtobuf, "Empty")
+		return Op("*").Qual(protobufEmptyPackage(ctx), "Empty")
 	}
 	if len(results) == 1 {
-		sp := specialTypeConverter(results[0].Type)
+		sp := wellKnownMessageType(ctx, results[0].Type)
 		if sp != nil {
 			return sp
 		}
 	}
-	return Op("*").Qual(t.info.ProtobufPackageImport, responseStructName(fn))
+	return Op("*").Qual(t.info.ProtobufPackageImport, responseMessageName(fn))
 }
 
 // Render service method body for grpc server.
@@ -335,23 +340,28 @@ func (t *gRPCServerTemplate) grpcServerRespStruct(fn *types.Function) *Statement
 //		}
 //		return resp.(*stringsvc.CountResponse), nil
 //
-func (t *gRPCServerTemplate) grpcServerFuncBody(signature *types.Function, i *types.Interface) func(g *Group) {
+// Services with @errors tag return transport.EncodeError(err), so error is sent with mapped code.
+func (t *gRPCServerTemplate) grpcServerFuncBody(ctx context.Context, signature *types.Function, i *types.Interface) func(g *Group) {
 	return func(g *Group) {
 		g.List(Id("_"), Id("resp"), Err()).
 			Op(":=").
 			Id(rec(privateServerStructName(i))).Dot(mstrings.ToLowerFirst(signature.Name)).Dot("ServeGRPC").Call(Id("ctx"), Id("req"))
 
-		g.If(Err().Op("!=").Nil()).Block(
-			Return().List(Nil(), Err()),
-		)
+		g.If(Err().Op("!=").Nil()).BlockFunc(func(ig *Group) {
+			if HasErrorsTag(t.info.Iface.Docs) {
+				ig.Return().List(Nil(), Qual(t.info.OutputPackageImport+"/transport", t.info.nsName("EncodeError")).Call(Err()))
+				return
+			}
+			ig.Return().List(Nil(), Err())
+		})
 
-		g.Return().List(Id("resp").Assert(t.grpcServerRespStruct(signature)), Nil())
+		g.Return().List(Id("resp").Assert(t.grpcServerRespStruct(ctx, signature)), Nil())
 	}
 }
 
 func (t *gRPCServerTemplate) serverOpts(ctx context.Context, fn *types.Function) *Statement {
 	s := &Statement{}
-	if Tags(ctx).Has(TracingMiddlewareTag) {
+	if Tags(ctx).HasAny(TracingMiddlewareTag, OpenTelemetryTag) {
 		s.Op("append(")
 		defer s.Op(")")
 	}
@@ -361,5 +371,10 @@ func (t *gRPCServerTemplate) serverOpts(ctx context.Context, fn *types.Function)
 			Line().Qual(PackagePathGoKitTracing, "GRPCToContext").Call(Id("tracer"), Lit(fn.Name), Id("logger")),
 		)
 	}
+	if Tags(ctx).Has(OpenTelemetryTag) {
+		s.Op(",").Qual(PackagePathGoKitTransportGRPC, "ServerBefore").Call(
+			Line().Id(t.info.nsPrivateName("extractTraceContext")).Call(Id("propagator")),
+		)
+	}
 	return s
 }
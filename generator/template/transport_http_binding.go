@@ -0,0 +1,453 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	. "github.com/dave/jennifer/jen"
+	mstrings "github.com/recolabs/microgen/generator/strings"
+	"github.com/vetcher/go-astra/types"
+)
+
+const (
+	HttpQueryTag     = "http-query"
+	HttpHeaderTag    = "http-header"
+	HttpPathParamTag = "http-path-param"
+
+	commonHTTPBadRequestErrorName = "HTTPBadRequestError"
+)
+
+// Places of request parameters, as they are named in OpenAPI.
+const (
+	httpInQuery  = "query"
+	httpInHeader = "header"
+	httpInPath   = "path"
+)
+
+// Types of arguments, which may be bound to query, header or path.
+var httpBindableTypes = []string{
+	"string", "bool",
+	"int", "int8", "int16", "int32", "int64",
+	"uint", "uint8", "uint16", "uint32", "uint64",
+	"float32", "float64",
+}
+
+// httpParam is an argument of method, which is transferred in query, header or path instead of body.
+type httpParam struct {
+	arg types.Variable
+	in  string
+	// key is a query key, header name or path variable.
+	key string
+	// slice is true for []T arguments, which are transferred as repeated query keys or headers.
+	slice bool
+	// typeName is a name of T for T and []T arguments.
+	typeName string
+}
+
+// httpBinding describes where arguments of method are placed in http request.
+type httpBinding struct {
+	params []httpParam
+	// body are arguments, which are encoded to JSON body.
+	body []types.Variable
+}
+
+// HasHTTPBinding returns true, when method has any of @http-query, @http-header or @http-path-param tags.
+// Methods without them use old rules: all arguments of GET method are path variables, other methods read JSON body.
+func HasHTTPBinding(fn *types.Function) bool {
+	return mstrings.HasTag(fn.Docs, TagMark+HttpQueryTag+" ") ||
+		mstrings.HasTag(fn.Docs, TagMark+HttpHeaderTag+" ") ||
+		mstrings.HasTag(fn.Docs, TagMark+HttpPathParamTag+" ")
+}
+
+// Returns values of tag, separated by comma. Unlike FetchTags, tag should be followed by space,
+// so @http-path does not match @http-path-param.
+func fetchTagValues(docs []string, tag string) []string {
+	return mstrings.FetchTags(docs, TagMark+tag+" ")
+}
+
+// newHTTPBinding parses binding tags of method. Tag values are `arg` or `key:arg`.
+//
+//		// @http-path-param id
+//		// @http-query limit,offset,tag
+//		// @http-header X-Tenant-ID:tenant
+//
+func newHTTPBinding(fn *types.Function) (*httpBinding, error) {
+	args := HTTPRequestArgs(fn)
+	b := &httpBinding{}
+	bound := make(map[string]string)
+	for _, place := range []struct{ tag, in string }{
+		{HttpPathParamTag, httpInPath},
+		{HttpQueryTag, httpInQuery},
+		{HttpHeaderTag, httpInHeader},
+	} {
+		for _, value := range fetchTagValues(fn.Docs, place.tag) {
+			if value == "" {
+				continue
+			}
+			key, name := value, value
+			if i := strings.Index(value, ":"); i >= 0 {
+				key, name = value[:i], value[i+1:]
+			}
+			if key == "" || name == "" {
+				return nil, fmt.Errorf("%s: @%s %s: expected `arg` or `key:arg`", fn.Name, place.tag, value)
+			}
+			arg := findVariable(args, name)
+			if arg == nil {
+				return nil, fmt.Errorf("%s: @%s: argument %s not found", fn.Name, place.tag, name)
+			}
+			if in, ok := bound[name]; ok {
+				return nil, fmt.Errorf("%s: @%s: argument %s is already bound to %s", fn.Name, place.tag, name, in)
+			}
+			bound[name] = place.in
+			p := httpParam{arg: *arg, in: place.in, key: key}
+			typ := arg.Type
+			if array, ok := typ.(types.TArray); ok && array.IsSlice && place.in != httpInPath {
+				p.slice = true
+				typ = array.Next
+			}
+			if n, ok := typ.(types.TName); ok && mstrings.IsInStringSlice(n.TypeName, httpBindableTypes) {
+				p.typeName = n.TypeName
+			} else {
+				return nil, fmt.Errorf("%s: @%s: argument %s of type %s can not be bound, allowed types are %s and slices of them for query and header",
+					fn.Name, place.tag, name, arg.Type.String(), strings.Join(httpBindableTypes, ", "))
+			}
+			b.params = append(b.params, p)
+		}
+	}
+	for _, arg := range args {
+		if _, ok := bound[arg.Name]; !ok {
+			b.body = append(b.body, arg)
+		}
+	}
+	if FetchHttpMethodTag(fn.Docs) == "GET" && len(b.body) > 0 {
+		return nil, fmt.Errorf("%s: GET request has no body, bind argument %s with @%s, @%s or @%s",
+			fn.Name, b.body[0].Name, HttpQueryTag, HttpHeaderTag, HttpPathParamTag)
+	}
+	segments := strings.Split(buildMethodPath(fn), "/")
+	for _, p := range b.pathParams() {
+		if !mstrings.IsInStringSlice(p.key, pathSegmentVars(segments)) {
+			return nil, fmt.Errorf("%s: @%s %s: path %s should contain {%s} segment", fn.Name, HttpPathParamTag, p.key, buildMethodPath(fn), p.key)
+		}
+	}
+	return b, nil
+}
+
+// ValidateHTTPBinding checks @http-query, @http-header and @http-path-param tags of method.
+func ValidateHTTPBinding(fn *types.Function) error {
+	if !HasHTTPBinding(fn) {
+		return nil
+	}
+	_, err := newHTTPBinding(fn)
+	return err
+}
+
+func (b *httpBinding) pathParams() []httpParam {
+	var params []httpParam
+	for _, p := range b.params {
+		if p.in == httpInPath {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+func findVariable(vars []types.Variable, name string) *types.Variable {
+	for i := range vars {
+		if vars[i].Name == name {
+			return &vars[i]
+		}
+	}
+	return nil
+}
+
+// Returns variable of path segment, which is exactly {name} or {name:pattern}.
+func pathSegmentVar(segment string) string {
+	if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+		return ""
+	}
+	name := segment[1 : len(segment)-1]
+	if i := strings.Index(name, ":"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+func pathSegmentVars(segments []string) []string {
+	var vars []string
+	for _, s := range segments {
+		if v := pathSegmentVar(s); v != "" {
+			vars = append(vars, v)
+		}
+	}
+	return vars
+}
+
+// Returns default path of method with path parameters.
+//
+//		GetUser, @http-path-param id -> get-user/{id}
+//
+func bindingDefaultMethodPath(fn *types.Function) string {
+	edges := []string{mstrings.ToURLSnakeCase(fn.Name)}
+	for _, value := range fetchTagValues(fn.Docs, HttpPathParamTag) {
+		if i := strings.Index(value, ":"); i >= 0 {
+			value = value[:i]
+		}
+		if value != "" {
+			edges = append(edges, "{"+value+"}")
+		}
+	}
+	return path.Join(edges...)
+}
+
+// Render request decoder of method with bound parameters.
+//
+//		func _Decode_ListUsers_Request(_ context.Context, r *http.Request) (interface{}, error) {
+//			var req transport.ListUsersRequest
+//			_query := r.URL.Query()
+//			if _param := _query.Get("limit"); _param != "" {
+//				_v, err := strconv.ParseInt(_param, 10, 0)
+//				if err != nil {
+//					return nil, &HTTPBadRequestError{Field: "limit", Err: err}
+//				}
+//				req.Limit = int(_v)
+//			}
+//			req.Tenant = r.Header.Get("X-Tenant-ID")
+//			return &req, nil
+//		}
+//
+func (t *httpConverterTemplate) decodeBoundHTTPRequest(g *Group, fn *types.Function, b *httpBinding) {
+	g.Var().Id("req").Qual(t.info.OutputPackageImport+"/transport", t.info.requestStructName(fn))
+	if len(b.body) > 0 {
+		g.If(
+			Err().Op(":=").Qual(PackagePathJson, "NewDecoder").Call(Id("r").Dot("Body")).Dot("Decode").Call(Op("&").Id("req")),
+			Err().Op("!=").Nil(),
+		).Block(
+			Return(Nil(), badRequestError("body", Err())),
+		)
+	}
+	hasIn := func(in string) bool {
+		for _, p := range b.params {
+			if p.in == in {
+				return true
+			}
+		}
+		return false
+	}
+	if hasIn(httpInQuery) {
+		g.Id("_query").Op(":=").Id("r").Dot("URL").Dot("Query").Call()
+	}
+	if hasIn(httpInPath) {
+		g.Id("_vars").Op(":=").Qual(PackagePathGorillaMux, "Vars").Call(Id("r"))
+	}
+	for _, p := range b.params {
+		field := Id("req").Dot(mstrings.ToUpperFirst(p.arg.Name))
+		if p.slice {
+			var values *Statement
+			if p.in == httpInQuery {
+				values = Id("_query").Index(Lit(p.key))
+			} else {
+				values = Id("r").Dot("Header").Dot("Values").Call(Lit(p.key))
+			}
+			g.For(List(Id("_"), Id("_param")).Op(":=").Range().Add(values)).BlockFunc(func(g *Group) {
+				parseHTTPParam(g, p, func(v Code) Code { return field.Clone().Op("=").Append(field.Clone(), v) })
+			})
+			continue
+		}
+		var value *Statement
+		switch p.in {
+		case httpInQuery:
+			value = Id("_query").Dot("Get").Call(Lit(p.key))
+		case httpInHeader:
+			value = Id("r").Dot("Header").Dot("Get").Call(Lit(p.key))
+		case httpInPath:
+			value = Id("_vars").Index(Lit(p.key))
+		}
+		if p.typeName == "string" {
+			g.Add(field).Op("=").Add(value)
+			continue
+		}
+		g.If(Id("_param").Op(":=").Add(value), Id("_param").Op("!=").Lit("")).BlockFunc(func(g *Group) {
+			parseHTTPParam(g, p, func(v Code) Code { return field.Clone().Op("=").Add(v) })
+		})
+	}
+	g.Return(Op("&").Id("req"), Nil())
+}
+
+// Renders parsing of _param to type of parameter and assignment of result.
+func parseHTTPParam(g *Group, p httpParam, assign func(v Code) Code) {
+	var parse *Statement
+	switch p.typeName {
+	case "string":
+		g.Add(assign(Id("_param")))
+		return
+	case "bool":
+		parse = Qual(PackagePathStrconv, "ParseBool").Call(Id("_param"))
+	case "int", "int8", "int16", "int32", "int64":
+		parse = Qual(PackagePathStrconv, "ParseInt").Call(Id("_param"), Lit(10), Lit(bitSize(p.typeName)))
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		parse = Qual(PackagePathStrconv, "ParseUint").Call(Id("_param"), Lit(10), Lit(bitSize(p.typeName)))
+	case "float32", "float64":
+		parse = Qual(PackagePathStrconv, "ParseFloat").Call(Id("_param"), Lit(bitSize(p.typeName)))
+	}
+	g.List(Id("_v"), Err()).Op(":=").Add(parse)
+	g.If(Err().Op("!=").Nil()).Block(
+		Return(Nil(), badRequestError(p.key, Err())),
+	)
+	if p.typeName == "bool" || p.typeName == "int64" || p.typeName == "uint64" || p.typeName == "float64" {
+		g.Add(assign(Id("_v")))
+		return
+	}
+	g.Add(assign(Id(p.typeName).Call(Id("_v"))))
+}
+
+// Returns bit size of number type for strconv functions. Zero is a size of int and uint.
+func bitSize(typeName string) int {
+	size, _ := strconv.Atoi(strings.TrimLeft(typeName, "abcdefghijklmnopqrstuvwxyz"))
+	return size
+}
+
+func badRequestError(field string, err Code) *Statement {
+	return Op("&").Id(commonHTTPBadRequestErrorName).Values(Dict{
+		Id("Field"): Lit(field),
+		Id("Err"):   err,
+	})
+}
+
+// Render request encoder of method with bound parameters.
+//
+//		func _Encode_ListUsers_Request(ctx context.Context, r *http.Request, request interface{}) error {
+//			req := request.(*transport.ListUsersRequest)
+//			r.URL.Path = path.Join(r.URL.Path, "list-users")
+//			_query := r.URL.Query()
+//			_query.Set("limit", strconv.FormatInt(int64(req.Limit), 10))
+//			r.URL.RawQuery = _query.Encode()
+//			r.Header.Set("X-Tenant-ID", req.Tenant)
+//			return nil
+//		}
+//
+func (t *httpConverterTemplate) encodeBoundHTTPRequest(ctx context.Context, g *Group, fn *types.Function, b *httpBinding) {
+	g.Id("req").Op(":=").Id("request").Assert(Op("*").Qual(t.info.OutputPackageImport+"/transport", t.info.requestStructName(fn)))
+	g.Id("r").Dot("URL").Dot("Path").Op("=").Qual(PackagePathPath, "Join").CallFunc(func(g *Group) {
+		g.Id("r").Dot("URL").Dot("Path")
+		for _, segment := range strings.Split(strings.TrimPrefix(buildMethodPath(fn), "/"), "/") {
+			if p := findHTTPParam(b, httpInPath, pathSegmentVar(segment)); p != nil {
+				g.Add(formatHTTPParam(p.typeName, Id("req").Dot(mstrings.ToUpperFirst(p.arg.Name))))
+				continue
+			}
+			g.Lit(segment)
+		}
+	})
+	hasQuery := false
+	for _, p := range b.params {
+		if p.in != httpInQuery {
+			continue
+		}
+		if !hasQuery {
+			g.Id("_query").Op(":=").Id("r").Dot("URL").Dot("Query").Call()
+			hasQuery = true
+		}
+		field := Id("req").Dot(mstrings.ToUpperFirst(p.arg.Name))
+		if p.slice {
+			g.For(List(Id("_"), Id("_v")).Op(":=").Range().Add(field)).Block(
+				Id("_query").Dot("Add").Call(Lit(p.key), formatHTTPParam(p.typeName, Id("_v"))),
+			)
+			continue
+		}
+		g.Id("_query").Dot("Set").Call(Lit(p.key), formatHTTPParam(p.typeName, field))
+	}
+	if hasQuery {
+		g.Id("r").Dot("URL").Dot("RawQuery").Op("=").Id("_query").Dot("Encode").Call()
+	}
+	for _, p := range b.params {
+		if p.in != httpInHeader {
+			continue
+		}
+		field := Id("req").Dot(mstrings.ToUpperFirst(p.arg.Name))
+		if p.slice {
+			g.For(List(Id("_"), Id("_v")).Op(":=").Range().Add(field)).Block(
+				Id("r").Dot("Header").Dot("Add").Call(Lit(p.key), formatHTTPParam(p.typeName, Id("_v"))),
+			)
+			continue
+		}
+		g.Id("r").Dot("Header").Dot("Set").Call(Lit(p.key), formatHTTPParam(p.typeName, field))
+	}
+	if len(b.body) == 0 {
+		g.Return(Nil())
+		return
+	}
+	// Only arguments, which are not bound, are sent in body.
+	g.Return(Id(commonHTTPRequestEncoderName).Call(Id("ctx"), Id("r"),
+		StructFunc(func(g *Group) {
+			for _, arg := range b.body {
+				g.Add(structField(ctx, &arg))
+			}
+		}).Values(DictFunc(func(d Dict) {
+			for _, arg := range b.body {
+				d[structFieldName(&arg)] = Id("req").Dot(mstrings.ToUpperFirst(arg.Name))
+			}
+		})),
+	))
+}
+
+func findHTTPParam(b *httpBinding, in, key string) *httpParam {
+	if key == "" {
+		return nil
+	}
+	for i := range b.params {
+		if b.params[i].in == in && b.params[i].key == key {
+			return &b.params[i]
+		}
+	}
+	return nil
+}
+
+// Renders conversion of value to string.
+func formatHTTPParam(typeName string, v *Statement) *Statement {
+	switch typeName {
+	case "bool":
+		return Qual(PackagePathStrconv, "FormatBool").Call(v)
+	case "int", "int8", "int16", "int32", "int64":
+		return Qual(PackagePathStrconv, "FormatInt").Call(Int64().Call(v), Lit(10))
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		return Qual(PackagePathStrconv, "FormatUint").Call(Uint64().Call(v), Lit(10))
+	case "float32", "float64":
+		return Qual(PackagePathStrconv, "FormatFloat").Call(Float64().Call(v), LitRune('g'), Lit(-1), Lit(bitSize(typeName)))
+	}
+	return v
+}
+
+// Render error of request decoders. Server of go-kit responds with status code of errors, which implement StatusCoder.
+//
+//		// HTTPBadRequestError is returned by request decoders, when parameter of request can not be parsed.
+//		type HTTPBadRequestError struct {
+//			Field string
+//			Err   error
+//		}
+//
+//		func (e *HTTPBadRequestError) Error() string {
+//			return "bad request: " + e.Field + ": " + e.Err.Error()
+//		}
+//
+//		func (e *HTTPBadRequestError) StatusCode() int {
+//			return http.StatusBadRequest
+//		}
+//
+func commonHTTPBadRequestError() *Statement {
+	s := &Statement{}
+	s.Comment(commonHTTPBadRequestErrorName + " is returned by request decoders, when parameter of request can not be parsed.").Line()
+	s.Comment("Server responds to it with 400 status code.").Line()
+	s.Type().Id(commonHTTPBadRequestErrorName).Struct(
+		Id("Field").String(),
+		Id("Err").Error(),
+	).Line().Line()
+	s.Func().Params(Id("e").Op("*").Id(commonHTTPBadRequestErrorName)).Id("Error").Params().String().Block(
+		Return(Lit("bad request: ").Op("+").Id("e").Dot("Field").Op("+").Lit(": ").Op("+").Id("e").Dot("Err").Dot("Error").Call()),
+	).Line().Line()
+	s.Func().Params(Id("e").Op("*").Id(commonHTTPBadRequestErrorName)).Id("StatusCode").Params().Int().Block(
+		Return(Qual(PackagePathHttp, "StatusBadRequest")),
+	)
+	return s
+}
@@ -0,0 +1,111 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetcher/go-astra/types"
+)
+
+const httpBindingTestSource = `package svc
+
+import "context"
+
+type UserService interface {
+	// @http-method GET
+	// @http-query limit,offset,tag:tags
+	// @http-header X-Tenant-ID:tenant
+	ListUsers(ctx context.Context, tenant string, limit int, offset uint32, tags []string) (err error)
+	// @http-method PUT
+	// @http-path users/{id}
+	// @http-path-param id
+	UpdateUser(ctx context.Context, id int64, name string) (err error)
+	// @http-method DELETE
+	// @http-path-param id
+	DeleteUser(ctx context.Context, id uint) (err error)
+
+	// @http-method GET
+	// @http-query limit
+	UnboundGet(ctx context.Context, limit int, text string) (err error)
+	// @http-query user
+	StructQuery(ctx context.Context, user User) (err error)
+	// @http-path-param id
+	// @http-path users
+	MissingSegment(ctx context.Context, id int) (err error)
+	// @http-query id,key:id
+	DoubleBound(ctx context.Context, id int) (err error)
+	// @http-header X-Id:id
+	UnknownArg(ctx context.Context, key int) (err error)
+	// @http-path-param ids
+	SlicePath(ctx context.Context, ids []int) (err error)
+}
+
+type User struct {
+	Name string
+}
+`
+
+func parseHTTPBindingTestSource(t *testing.T) map[string]*types.Function {
+	methods := make(map[string]*types.Function)
+	for _, fn := range parseTestSource(t, httpBindingTestSource).Interfaces[0].Methods {
+		methods[fn.Name] = fn
+	}
+	return methods
+}
+
+func TestHTTPBinding(t *testing.T) {
+	methods := parseHTTPBindingTestSource(t)
+	type param struct{ in, key, arg string }
+	for name, expected := range map[string]struct {
+		params []param
+		body   []string
+		path   string
+	}{
+		"ListUsers": {
+			params: []param{{"query", "limit", "limit"}, {"query", "offset", "offset"}, {"query", "tag", "tags"}, {"header", "X-Tenant-ID", "tenant"}},
+			path:   "list-users",
+		},
+		"UpdateUser": {
+			params: []param{{"path", "id", "id"}},
+			body:   []string{"name"},
+			path:   "users/{id}",
+		},
+		"DeleteUser": {
+			params: []param{{"path", "id", "id"}},
+			path:   "delete-user/{id}",
+		},
+	} {
+		fn := methods[name]
+		assert.True(t, HasHTTPBinding(fn), name)
+		b, err := newHTTPBinding(fn)
+		if !assert.NoError(t, err, name) {
+			continue
+		}
+		var params []param
+		for _, p := range b.params {
+			params = append(params, param{p.in, p.key, p.arg.Name})
+		}
+		var body []string
+		for _, arg := range b.body {
+			body = append(body, arg.Name)
+		}
+		assert.Equal(t, expected.params, params, name)
+		assert.Equal(t, expected.body, body, name)
+		assert.Equal(t, expected.path, buildMethodPath(fn), name)
+		assert.NoError(t, ValidateHTTPBinding(fn), name)
+	}
+}
+
+func TestHTTPBindingErrors(t *testing.T) {
+	methods := parseHTTPBindingTestSource(t)
+	for _, name := range []string{
+		"UnboundGet",
+		"StructQuery",
+		"MissingSegment",
+		"DoubleBound",
+		"UnknownArg",
+		"SlicePath",
+	} {
+		assert.Error(t, ValidateHTTPBinding(methods[name]), name)
+	}
+}
@@ -2,10 +2,8 @@ package template
 
 import (
 	"context"
-	"fmt"
 
 	. "github.com/dave/jennifer/jen"
-	"github.com/recolabs/microgen/generator/strings"
 	"github.com/recolabs/microgen/generator/write_strategy"
 	"github.com/vetcher/go-astra/types"
 )
@@ -21,7 +19,7 @@ func NewHttpClientTemplate(info *GenerationInfo) Template {
 }
 
 func (t *httpClientTemplate) DefaultPath() string {
-	return filenameBuilder(PathTransport, "http", "client")
+	return filenameBuilder(PathTransport, "http", t.info.nsFile("client"))
 }
 
 func (t *httpClientTemplate) ChooseStrategy(ctx context.Context) (write_strategy.Strategy, error) {
@@ -84,17 +82,21 @@ func (t *httpClientTemplate) Render(ctx context.Context) write_strategy.Renderer
 	src.ImportAlias(PackagePathGoKitTransportHTTP, "httpkit")
 	src.HeaderComment(t.info.FileHeader)
 
-	src.Func().Id("NewHTTPClient").ParamsFunc(func(p *Group) {
-		p.Id("u").Op("*").Qual(PackagePathUrl, "URL")
-		p.Id("opts").Op("...").Qual(PackagePathGoKitTransportHTTP, "ClientOption")
-	}).Params(
-		Qual(t.info.OutputPackageImport+"/transport", EndpointsSetName),
-	).Block(
-		t.clientBody(ctx),
-	)
+	if wrapsClientEndpoints(ctx) {
+		src.Add(t.wrappedClient(ctx))
+	} else {
+		src.Func().Id(t.info.nsNewName("HTTPClient")).ParamsFunc(func(p *Group) {
+			p.Id("u").Op("*").Qual(PackagePathUrl, "URL")
+			p.Id("opts").Op("...").Qual(PackagePathGoKitTransportHTTP, "ClientOption")
+		}).Params(
+			Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName()),
+		).Block(
+			t.clientBody(ctx),
+		)
+	}
 
 	if Tags(ctx).Has(TracingMiddlewareTag) {
-		src.Line().Func().Id("TracingHTTPClientOptions").Params(
+		src.Line().Func().Id(t.info.nsName("TracingHTTPClientOptions")).Params(
 			Id("tracer").Qual(PackagePathOpenTracingGo, "Tracer"),
 			Id("logger").Qual(PackagePathGoKitLog, "Logger"),
 		).Params(
@@ -107,56 +109,22 @@ func (t *httpClientTemplate) Render(ctx context.Context) write_strategy.Renderer
 			),
 		)
 	}
-	if Tags(ctx).Has(ServiceDiscoveryTag) {
-		src.Comment(fmt.Sprintf("NewHTTPClientSD is a http client for %s and uses service discovery inside.", t.info.Iface.Name)).
-			Line().Var().Id("NewHTTPClientSD").Op("=").Id("sdClientFactory").Call(Id("httpClientFactoryMaker"))
-		src.Comment("sdClientFactory is a factory to create constructors for HTTPClientSD").
-			Line().Func().Id("sdClientFactory").Params(
-			Line().Id("maker").Func().Add(factoryMakerSignature(t.info)),
-			Line(),
+	if Tags(ctx).Has(OpenTelemetryTag) {
+		src.Line().Func().Id(t.info.nsName("TracingHTTPClientOptions")).Params(
+			Id("propagator").Qual(PackagePathOTelPropagation, "TextMapPropagator"),
 		).Params(
-			Line().Func().Add(sdClientSignature(t.info, false)),
-			Line(),
+			Func().Params(Op("[]").Qual(PackagePathGoKitTransportHTTP, "ClientOption")).Params(Op("[]").Qual(PackagePathGoKitTransportHTTP, "ClientOption")),
 		).Block(
-			Return().Func().Add(sdClientSignature(t.info, true)).BlockFunc(func(g *Group) {
-				g.Var().Id("endpoints").Qual(t.info.OutputPackageImport+"/transport", EndpointsSetName)
-				for _, fn := range t.info.Iface.Methods {
-					if !t.info.AllowedMethods[fn.Name] {
-						continue
-					}
-					g.Block(
-						Id("endpointer").Op(":=").Qual(PackagePathGoKitSD, "NewEndpointer").Call(
-							Id("instancer"),
-							Id(serviceDiscoveryFactoryName(fn.Name)).Call(Id("maker").Call(Id("opts").Op("..."))),
-							Id(_logger_)),
-						List(Id("endpoints").Dot(endpointsStructFieldName(fn.Name)), Id("_")).
-							Op("=").
-							Qual(PackagePathGoKitLB, "NewRoundRobin").Call(Id("endpointer")).Dot("Endpoint").Call(),
-					)
-				}
-				g.Return(Id("endpoints"))
-			}),
-		)
-		src.Comment("httpClientFactoryMaker returns function, that describes what to do with `instance string` to create new instance of client.").
-			Line().Comment("Commonly, for http protocol it would be some sort of url, e.g. `host:port`.").
-			Line().Func().Id("httpClientFactoryMaker").Add(factoryMakerSignature(t.info)).Block(
-			Return().Func().Params(Id("instance").String()).Params(Qual(t.info.OutputPackageImport+"/transport", EndpointsSetName), Error()).Block(
-				List(Id("u"), Err()).Op(":=").Qual(PackagePathUrl, "Parse").Call(Id("instance")),
-				If(Err().Op("!=").Nil()).Block(
-					Return(Qual(t.info.OutputPackageImport+"/transport", EndpointsSetName).Values(), Err()),
-				),
-				Return(Id("NewHTTPClient").Call(Id("u"), Id("opts").Op("...")), Nil()),
+			Return().Func().Params(Id("opts").Op("[]").Qual(PackagePathGoKitTransportHTTP, "ClientOption")).Params(Op("[]").Qual(PackagePathGoKitTransportHTTP, "ClientOption")).Block(
+				Return().Append(Id("opts"), Qual(PackagePathGoKitTransportHTTP, "ClientBefore").Call(
+					Line().Id(t.info.nsPrivateName("injectTraceContext")).Call(Id("propagator")).Op(",").Line(),
+				)),
 			),
 		)
-		for _, signature := range t.info.Iface.Methods {
-			if !t.info.AllowedMethods[signature.Name] ||
-				t.info.ManyToManyStreamMethods[signature.Name] ||
-				t.info.ManyToOneStreamMethods[signature.Name] ||
-				t.info.OneToManyStreamMethods[signature.Name] {
-				continue
-			}
-			src.Add(t.serviceDiscoveryFactory(ctx, signature))
-		}
+		src.Line().Add(httpInjectTraceContext(t.info.nsPrivateName("injectTraceContext")))
+	}
+	if Tags(ctx).Has(ServiceDiscoveryTag) {
+		src.Line().Add(t.sdClient(ctx))
 	}
 	return src
 }
@@ -188,7 +156,7 @@ func (t *httpClientTemplate) Render(ctx context.Context) write_strategy.Renderer
 //
 func (t *httpClientTemplate) clientBody(ctx context.Context) *Statement {
 	g := &Statement{}
-	g.Return(Qual(t.info.OutputPackageImport+"/transport", EndpointsSetName).Values(DictFunc(
+	g.Return(Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName()).Values(DictFunc(
 		func(d Dict) {
 			for _, fn := range t.info.Iface.Methods {
 				if !t.info.AllowedMethods[fn.Name] ||
@@ -201,8 +169,8 @@ func (t *httpClientTemplate) clientBody(ctx context.Context) *Statement {
 				client := &Statement{}
 				client.Qual(PackagePathGoKitTransportHTTP, "NewClient").Call(
 					Line().Lit(method), Id("u"),
-					Line().Id(encodeRequestName(fn)),
-					Line().Id(decodeResponseName(fn)),
+					Line().Id(t.info.encodeRequestName(fn)),
+					Line().Id(t.info.decodeResponseName(fn)),
 					Line().Add(t.clientOpts(fn)).Op("...").Line(),
 				).Dot("Endpoint").Call()
 				d[Id(endpointsStructFieldName(fn.Name))] = client
@@ -212,54 +180,113 @@ func (t *httpClientTemplate) clientBody(ctx context.Context) *Statement {
 	return g
 }
 
+// Renders client, which wraps endpoints with circuit breakers and retries.
+//
+//		// NewHTTPClient creates endpoints of StringService, which call server at u.
+//		// Endpoints are wrapped with circuit breakers, which breaker returns for name of method.
+//		// Failed calls are retried by policies of @retry tags.
+//		func NewHTTPClient(u *url.URL, breaker func(method string) endpoint.Middleware, opts ...httpkit.ClientOption) transport.EndpointsSet {
+//			return transport.RetryClientEndpoints(transport.CircuitBreakerClientEndpoints(httpClientEndpoints(u, opts...), breaker))
+//		}
+//
+//		func httpClientEndpoints(u *url.URL, opts ...httpkit.ClientOption) transport.EndpointsSet {
+//			return transport.EndpointsSet{...}
+//		}
+//
+func (t *httpClientTemplate) wrappedClient(ctx context.Context) *Statement {
+	endpoints := t.info.nsPrivateName("httpClientEndpoints")
+	return Comment(t.info.nsNewName("HTTPClient")+" creates endpoints of "+t.info.Iface.Name+", which call server at u.").
+		Line().Add(wrappedClientComment(ctx, false)).
+		Func().Id(t.info.nsNewName("HTTPClient")).Params(append(append(
+		[]Code{Id("u").Op("*").Qual(PackagePathUrl, "URL")},
+		breakerClientParams(ctx)...),
+		Id("opts").Op("...").Qual(PackagePathGoKitTransportHTTP, "ClientOption"),
+	)...).Params(
+		Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName()),
+	).Block(
+		Return(retryClientEndpoints(ctx, t.info, breakerClientEndpoints(ctx, t.info, Id(endpoints).Call(Id("u"), Id("opts").Op("..."))))),
+	).
+		Line().
+		Line().Func().Id(endpoints).Params(
+		Id("u").Op("*").Qual(PackagePathUrl, "URL"),
+		Id("opts").Op("...").Qual(PackagePathGoKitTransportHTTP, "ClientOption"),
+	).Params(
+		Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName()),
+	).Block(
+		t.clientBody(ctx),
+	)
+}
+
 func (t *httpClientTemplate) clientOpts(fn *types.Function) *Statement {
 	s := &Statement{}
 	s.Id("opts")
 	return s
 }
 
-//		func createUserSDFactory(clientMaker func(string) transport.EndpointsSet) sd.Factory {
-//			return func(instance string) (endpoint.Endpoint, io.Closer, error) {
-//				return clientMaker(instance).CreateCommentEndpoint, nil, nil
-//			}
+// Renders constructor of client, which balances requests between instances of service.
+//
+//		// NewHTTPClientSD creates endpoints of StringService, which are balanced between instances of instancer.
+//		// Instance is address of server, e.g. `host:port` or `http://host:port`. Closer stops watching instancer.
+//		// Failed calls of idempotent methods are tried on next instances up to retryMax times within retryTimeout.
+//		func NewHTTPClientSD(instancer sd.Instancer, logger log.Logger, retryMax int, retryTimeout time.Duration, opts ...httpkit.ClientOption) (transport.EndpointsSet, io.Closer) {
+//			return transport.BalancedEndpoints(instancer, func(instance string) (transport.EndpointsSet, io.Closer, error) {
+//				if !strings.Contains(instance, "://") {
+//					instance = "http://" + instance
+//				}
+//				u, err := url.Parse(instance)
+//				if err != nil {
+//					return transport.EndpointsSet{}, nil, err
+//				}
+//				if u.Path == "" {
+//					u.Path = "/"
+//				}
+//				return NewHTTPClient(u, opts...), nil, nil
+//			}, logger, retryMax, retryTimeout)
 //		}
 //
-func (t *httpClientTemplate) serviceDiscoveryFactory(ctx context.Context, fn *types.Function) *Statement {
-	s := &Statement{}
-	const _clientMaker_ = "clientMaker"
-	s.Func().Id(serviceDiscoveryFactoryName(fn.Name)).Params(Id(_clientMaker_).Func().Params(String()).Params(Qual(t.info.OutputPackageImport+"/transport", EndpointsSetName), Error())).Params(Qual(PackagePathGoKitSD, "Factory")).Block(
-		Return(Func().Params(Id("instance").String()).Params(Qual(PackagePathGoKitEndpoint, "Endpoint"), Qual(PackagePathIO, "Closer"), Error()).Block(
-			List(Id("c"), Err()).Op(":=").Id(_clientMaker_).Call(Id("instance")),
-			Return(Id("c").Dot(endpointsStructFieldName(fn.Name)), Nil(), Err()),
-		)),
-	)
-	return s
-}
-
-func serviceDiscoveryFactoryName(str string) string {
-	return strings.ToLowerFirst(str) + "SDFactory"
-}
-
-func factoryMakerSignature(info *GenerationInfo) *Statement {
-	return Params(
+func (t *httpClientTemplate) sdClient(ctx context.Context) *Statement {
+	client := Id(t.info.nsNewName("HTTPClient")).Call(Id("u"), Id("opts").Op("..."))
+	if wrapsClientEndpoints(ctx) {
+		client = breakerClientEndpoints(ctx, t.info, Id(t.info.nsPrivateName("httpClientEndpoints")).Call(Id("u"), Id("opts").Op("...")))
+	}
+	return Comment(t.info.nsNewName("HTTPClientSD")+" creates endpoints of "+t.info.Iface.Name+", which are balanced between instances of instancer.").
+		Line().Comment("Instance is address of server, e.g. `host:port` or `http://host:port`. Closer stops watching instancer.").
+		Line().Comment("Failed calls of idempotent methods are tried on next instances up to retryMax times within retryTimeout.").
+		Line().Add(wrappedClientComment(ctx, true)).
+		Func().Id(t.info.nsNewName("HTTPClientSD")).Params(sdClientParams(ctx,
 		Id("opts").Op("...").Qual(PackagePathGoKitTransportHTTP, "ClientOption"),
-	).Params(
-		Func().Params(String()).Params(Qual(info.OutputPackageImport+"/transport", EndpointsSetName), Error()),
+	)...).Params(
+		Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName()),
+		Qual(PackagePathIO, "Closer"),
+	).Block(
+		balancedClient(ctx, t.info,
+			Func().Params(Id("instance").String()).Params(Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName()), Qual(PackagePathIO, "Closer"), Error()).Block(
+				If(Op("!").Qual(PackagePathStrings, "Contains").Call(Id("instance"), Lit("://"))).Block(
+					Id("instance").Op("=").Lit("http://").Op("+").Id("instance"),
+				),
+				List(Id("u"), Err()).Op(":=").Qual(PackagePathUrl, "Parse").Call(Id("instance")),
+				If(Err().Op("!=").Nil()).Block(
+					Return(Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName()).Values(), Nil(), Err()),
+				),
+				If(Id("u").Dot("Path").Op("==").Lit("")).Block(
+					Id("u").Dot("Path").Op("=").Lit("/"),
+				),
+				Return(client, Nil(), Nil()),
+			),
+		),
 	)
 }
 
-func sdClientSignature(info *GenerationInfo, names bool) *Statement {
-	_instancer_ := "instancer"
-	_lg_ := _logger_
-	_opts_ := "opts"
-	if !names {
-		_instancer_, _lg_, _opts_ = "", "", ""
+// Common parameters of clients with service discovery: instancer, logger and retries of balancer.
+// Breaker is added before the last of extra parameters, which are options.
+func sdClientParams(ctx context.Context, extra ...Code) []Code {
+	params := []Code{
+		Id("instancer").Qual(PackagePathGoKitSD, "Instancer"),
+		Id(_logger_).Qual(PackagePathGoKitLog, "Logger"),
+		Id("retryMax").Int(),
+		Id("retryTimeout").Qual(PackagePathTime, "Duration"),
 	}
-	return Params(
-		Id(_instancer_).Qual(PackagePathGoKitSD, "Instancer"),
-		Id(_lg_).Qual(PackagePathGoKitLog, "Logger"),
-		Id(_opts_).Op("...").Qual(PackagePathGoKitTransportHTTP, "ClientOption"),
-	).Params(
-		Qual(info.OutputPackageImport+"/transport", EndpointsSetName),
-	)
+	params = append(params, extra[:len(extra)-1]...)
+	params = append(params, breakerClientParams(ctx)...)
+	return append(params, extra[len(extra)-1])
 }
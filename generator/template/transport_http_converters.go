@@ -13,6 +13,8 @@ import (
 const (
 	commonHTTPResponseEncoderName = "CommonHTTPResponseEncoder"
 	commonHTTPRequestEncoderName  = "CommonHTTPRequestEncoder"
+	httpErrorEncoderName          = "HTTPErrorEncoder"
+	httpErrorDecoderName          = "HTTPErrorDecoder"
 )
 
 type httpConverterTemplate struct {
@@ -24,6 +26,10 @@ type httpConverterTemplate struct {
 	state                        WriteStrategyState
 	isCommonEncoderRequestExist  bool
 	isCommonEncoderResponseExist bool
+	isBadRequestErrorExist       bool
+	isErrorCodersExist           bool
+	// bindings of methods with @http-query, @http-header or @http-path-param tags.
+	bindings map[string]*httpBinding
 }
 
 func NewHttpConverterTemplate(info *GenerationInfo) Template {
@@ -33,12 +39,17 @@ func NewHttpConverterTemplate(info *GenerationInfo) Template {
 }
 
 func (t *httpConverterTemplate) DefaultPath() string {
-	return filenameBuilder(PathTransport, "http", "converters")
+	return filenameBuilder(PathTransport, "http", t.info.nsFile("converters"))
 }
 
 func (t *httpConverterTemplate) ChooseStrategy(ctx context.Context) (write_strategy.Strategy, error) {
 	if err := statFile(t.info.OutputFilePath, t.DefaultPath()); err != nil {
 		t.state = FileStrat
+		// Common encoders may be already rendered to converters of another service.
+		if file, err := parsePackage(filepath.Join(t.info.OutputFilePath, t.DefaultPath())); err == nil {
+			t.findCommonEncoders(file.Functions)
+			t.findBadRequestError(file.Structures)
+		}
 		return write_strategy.NewCreateFileStrategy(t.info.OutputFilePath, t.DefaultPath()), nil
 	}
 	file, err := parsePackage(filepath.Join(t.info.OutputFilePath, t.DefaultPath()))
@@ -46,34 +57,59 @@ func (t *httpConverterTemplate) ChooseStrategy(ctx context.Context) (write_strat
 		return nil, err
 	}
 
-	removeAlreadyExistingFunctions(file.Functions, &t.encodersRequest, encodeRequestName)
-	removeAlreadyExistingFunctions(file.Functions, &t.decodersRequest, decodeRequestName)
-	removeAlreadyExistingFunctions(file.Functions, &t.encodersResponse, encodeResponseName)
-	removeAlreadyExistingFunctions(file.Functions, &t.decodersResponse, decodeResponseName)
+	removeAlreadyExistingFunctions(file.Functions, &t.encodersRequest, t.info.encodeRequestName)
+	removeAlreadyExistingFunctions(file.Functions, &t.decodersRequest, t.info.decodeRequestName)
+	removeAlreadyExistingFunctions(file.Functions, &t.encodersResponse, t.info.encodeResponseName)
+	removeAlreadyExistingFunctions(file.Functions, &t.decodersResponse, t.info.decodeResponseName)
+	t.findCommonEncoders(file.Functions)
+	t.findBadRequestError(file.Structures)
+
+	t.state = AppendStrat
+	return write_strategy.NewAppendToFileStrategy(t.info.OutputFilePath, t.DefaultPath()), nil
+}
 
-	for i := range file.Functions {
-		if file.Functions[i].Name == commonHTTPResponseEncoderName {
+func (t *httpConverterTemplate) findCommonEncoders(fns []types.Function) {
+	for i := range fns {
+		if fns[i].Name == commonHTTPResponseEncoderName {
 			t.isCommonEncoderResponseExist = true
 			continue
 		}
-		if file.Functions[i].Name == commonHTTPRequestEncoderName {
+		if fns[i].Name == commonHTTPRequestEncoderName {
 			t.isCommonEncoderRequestExist = true
 			continue
 		}
+		if fns[i].Name == t.info.nsName(httpErrorEncoderName) {
+			t.isErrorCodersExist = true
+			continue
+		}
 		if t.isCommonEncoderRequestExist && t.isCommonEncoderResponseExist {
 			break
 		}
 	}
+}
 
-	t.state = AppendStrat
-	return write_strategy.NewAppendToFileStrategy(t.info.OutputFilePath, t.DefaultPath()), nil
+func (t *httpConverterTemplate) findBadRequestError(structs []types.Struct) {
+	for i := range structs {
+		if structs[i].Name == commonHTTPBadRequestErrorName {
+			t.isBadRequestErrorExist = true
+			return
+		}
+	}
 }
 
 func (t *httpConverterTemplate) Prepare(ctx context.Context) error {
+	t.bindings = make(map[string]*httpBinding)
 	for _, fn := range t.info.Iface.Methods {
 		if !t.info.AllowedMethods[fn.Name] {
 			continue
 		}
+		if HasHTTPBinding(fn) {
+			b, err := newHTTPBinding(fn)
+			if err != nil {
+				return err
+			}
+			t.bindings[fn.Name] = b
+		}
 		t.decodersRequest = append(t.decodersRequest, fn)
 		t.encodersRequest = append(t.encodersRequest, fn)
 		t.decodersResponse = append(t.decodersResponse, fn)
@@ -139,6 +175,13 @@ func (t *httpConverterTemplate) Render(ctx context.Context) write_strategy.Rende
 	if !t.isCommonEncoderResponseExist {
 		f.Line().Add(commonHTTPResponseEncoder()).Line()
 	}
+	if len(t.bindings) > 0 && !t.isBadRequestErrorExist {
+		f.Line().Add(commonHTTPBadRequestError()).Line()
+	}
+	if HasErrorsTag(t.info.Iface.Docs) && !t.isErrorCodersExist {
+		f.Line().Add(t.httpErrorEncoder()).Line()
+		f.Line().Add(t.httpErrorDecoder()).Line()
+	}
 
 	for _, fn := range t.decodersRequest {
 		f.Line().Add(t.decodeHTTPRequest(fn)).Line()
@@ -147,10 +190,10 @@ func (t *httpConverterTemplate) Render(ctx context.Context) write_strategy.Rende
 		f.Line().Add(t.decodeHTTPResponse(fn)).Line()
 	}
 	for _, fn := range t.encodersRequest {
-		f.Line().Add(t.encodeHTTPRequest(fn)).Line()
+		f.Line().Add(t.encodeHTTPRequest(ctx, fn)).Line()
 	}
 	for _, fn := range t.encodersResponse {
-		f.Line().Add(encodeHTTPResponse(fn)).Line()
+		f.Line().Add(t.encodeHTTPResponse(fn)).Line()
 	}
 
 	if t.state == AppendStrat {
@@ -211,7 +254,7 @@ func commonHTTPResponseEncoder() *Statement {
 //			return req, err
 //		}
 func (t *httpConverterTemplate) decodeHTTPRequest(fn *types.Function) *Statement {
-	return Func().Id(decodeRequestName(fn)).
+	return Func().Id(t.info.decodeRequestName(fn)).
 		Params(
 			Id("_").Qual(PackagePathContext, "Context"),
 			Id("r").Op("*").Qual(PackagePathHttp, "Request"),
@@ -219,7 +262,11 @@ func (t *httpConverterTemplate) decodeHTTPRequest(fn *types.Function) *Statement
 		Interface(),
 		Error(),
 	).BlockFunc(func(g *Group) {
-		arguments := RemoveContextIfFirst(fn.Args)
+		if b, ok := t.bindings[fn.Name]; ok {
+			t.decodeBoundHTTPRequest(g, fn, b)
+			return
+		}
+		arguments := HTTPRequestArgs(fn)
 		if FetchHttpMethodTag(fn.Docs) == "GET" {
 			if len(arguments) > 0 {
 				g.Var().Call(Id("_param").String())
@@ -233,7 +280,7 @@ func (t *httpConverterTemplate) decodeHTTPRequest(fn *types.Function) *Statement
 					g.Add(stringToTypeConverter(&arg))
 				}
 			}
-			g.Return(Op("&").Qual(t.info.OutputPackageImport+"/transport", requestStructName(fn)).Values(DictFunc(func(d Dict) {
+			g.Return(Op("&").Qual(t.info.OutputPackageImport+"/transport", t.info.requestStructName(fn)).Values(DictFunc(func(d Dict) {
 				for _, arg := range arguments {
 					typename := types.TypeName(arg.Type)
 					if typename == nil {
@@ -243,7 +290,7 @@ func (t *httpConverterTemplate) decodeHTTPRequest(fn *types.Function) *Statement
 				}
 			})), Nil())
 		} else {
-			g.Var().Id("req").Qual(t.info.OutputPackageImport+"/transport", requestStructName(fn))
+			g.Var().Id("req").Qual(t.info.OutputPackageImport+"/transport", t.info.requestStructName(fn))
 			g.Err().Op(":=").Qual(PackagePathJson, "NewDecoder").Call(Id("r").Dot("Body")).Dot("Decode").Call(Op("&").Id("req"))
 			g.Return(Op("&").Id("req"), Err())
 		}
@@ -287,8 +334,10 @@ func stringToTypeConverter(arg *types.Variable) *Statement {
 //			err := json.NewDecoder(r.Body).Decode(&resp)
 //			return resp, err
 //		}
+//
+// Services with @errors tag decode error of service from responses with error status.
 func (t *httpConverterTemplate) decodeHTTPResponse(fn *types.Function) *Statement {
-	return Func().Id(decodeResponseName(fn)).
+	return Func().Id(t.info.decodeResponseName(fn)).
 		Params(
 			Id("_").Qual(PackagePathContext, "Context"),
 			Id("r").Op("*").Qual(PackagePathHttp, "Response"),
@@ -297,19 +346,77 @@ func (t *httpConverterTemplate) decodeHTTPResponse(fn *types.Function) *Statemen
 		Error(),
 	).
 		BlockFunc(func(g *Group) {
-			g.Var().Id("resp").Qual(t.info.OutputPackageImport+"/transport", responseStructName(fn))
+			if HasErrorsTag(t.info.Iface.Docs) {
+				g.If(Id("r").Dot("StatusCode").Op(">=").Qual(PackagePathHttp, "StatusBadRequest")).Block(
+					Return(Nil(), Id(t.info.nsName(httpErrorDecoderName)).Call(Id("r"))),
+				)
+			}
+			g.Var().Id("resp").Qual(t.info.OutputPackageImport+"/transport", t.info.responseStructName(fn))
 			g.Err().Op(":=").Qual(PackagePathJson, "NewDecoder").Call(Id("r").Dot("Body")).Dot("Decode").Call(Op("&").Id("resp"))
 			g.Return(Op("&").Id("resp"), Err())
 		})
 }
 
+// Render error encoder of server, which writes transport error with mapped status.
+//
+//		// HTTPErrorEncoder writes error of service as JSON with mapped HTTP status.
+//		func HTTPErrorEncoder(_ context.Context, err error, w http.ResponseWriter) {
+//			e := transport.EncodeError(err)
+//			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+//			w.WriteHeader(e.Status)
+//			json.NewEncoder(w).Encode(e)
+//		}
+//
+func (t *httpConverterTemplate) httpErrorEncoder() *Statement {
+	name := t.info.nsName(httpErrorEncoderName)
+	return Comment(name+" writes error of service as JSON with mapped HTTP status.").
+		Line().Func().Id(name).Params(
+		Id("_").Qual(PackagePathContext, "Context"),
+		Err().Error(),
+		Id("w").Qual(PackagePathHttp, "ResponseWriter"),
+	).Block(
+		Id("e").Op(":=").Qual(t.info.OutputPackageImport+"/transport", t.info.nsName("EncodeError")).Call(Err()),
+		Id("w").Dot("Header").Call().Dot("Set").Call(Lit("Content-Type"), Lit("application/json; charset=utf-8")),
+		Id("w").Dot("WriteHeader").Call(Id("e").Dot("Status")),
+		Qual(PackagePathJson, "NewEncoder").Call(Id("w")).Dot("Encode").Call(Id("e")),
+	)
+}
+
+// Render error decoder of client, which returns typed error of service.
+//
+//		// HTTPErrorDecoder returns error of service from response with error status.
+//		func HTTPErrorDecoder(r *http.Response) error {
+//			e := transport.Error{Status: r.StatusCode}
+//			if err := json.NewDecoder(r.Body).Decode(&e); err != nil || e.Message == "" {
+//				e.Message = http.StatusText(r.StatusCode)
+//			}
+//			return transport.DecodeError(&e)
+//		}
+//
+func (t *httpConverterTemplate) httpErrorDecoder() *Statement {
+	name := t.info.nsName(httpErrorDecoderName)
+	return Comment(name+" returns error of service from response with error status.").
+		Line().Func().Id(name).Params(Id("r").Op("*").Qual(PackagePathHttp, "Response")).Error().Block(
+		Id("e").Op(":=").Qual(t.info.OutputPackageImport+"/transport", t.info.nsName("Error")).Values(Dict{
+			Id("Status"): Id("r").Dot("StatusCode"),
+		}),
+		If(
+			Err().Op(":=").Qual(PackagePathJson, "NewDecoder").Call(Id("r").Dot("Body")).Dot("Decode").Call(Op("&").Id("e")),
+			Err().Op("!=").Nil().Op("||").Id("e").Dot("Message").Op("==").Lit(""),
+		).Block(
+			Id("e").Dot("Message").Op("=").Qual(PackagePathHttp, "StatusText").Call(Id("r").Dot("StatusCode")),
+		),
+		Return(Qual(t.info.OutputPackageImport+"/transport", t.info.nsName("DecodeError")).Call(Op("&").Id("e"))),
+	)
+}
+
 // Render response encoder.
 //		func EncodeHTTPCountResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
 //			return DefaultResponseEncoder(ctx, w, response)
 //		}
 //
-func encodeHTTPResponse(fn *types.Function) *Statement {
-	return Func().Id(encodeResponseName(fn)).Params(
+func (t *httpConverterTemplate) encodeHTTPResponse(fn *types.Function) *Statement {
+	return Func().Id(t.info.encodeResponseName(fn)).Params(
 		Id("ctx").Qual(PackagePathContext, "Context"),
 		Id("w").Qual(PackagePathHttp, "ResponseWriter"),
 		Id("response").Interface(),
@@ -325,23 +432,27 @@ func encodeHTTPResponse(fn *types.Function) *Statement {
 //			return DefaultRequestEncoder(ctx, r, request)
 //		}
 //
-func (t *httpConverterTemplate) encodeHTTPRequest(fn *types.Function) *Statement {
-	return Func().Id(encodeRequestName(fn)).Params(
+func (t *httpConverterTemplate) encodeHTTPRequest(ctx context.Context, fn *types.Function) *Statement {
+	return Func().Id(t.info.encodeRequestName(fn)).Params(
 		Id("ctx").Qual(PackagePathContext, "Context"),
 		Id("r").Op("*").Qual(PackagePathHttp, "Request"),
 		Id("request").Interface(),
 	).Params(
 		Error(),
-	).Block(
-		Add(t.encodeHTTPRequestBody(fn)),
-	)
+	).BlockFunc(func(g *Group) {
+		if b, ok := t.bindings[fn.Name]; ok {
+			t.encodeBoundHTTPRequest(ctx, g, fn, b)
+			return
+		}
+		g.Add(t.encodeHTTPRequestBody(fn))
+	})
 }
 
 func (t *httpConverterTemplate) encodeHTTPRequestBody(fn *types.Function) *Statement {
 	s := &Statement{}
 	pathVars := Lit(mstrings.ToURLSnakeCase(fn.Name))
 	if FetchHttpMethodTag(fn.Docs) == "GET" {
-		s.Id("req").Op(":=").Id("request").Assert(Op("*").Qual(t.info.OutputPackageImport+"/transport", requestStructName(fn))).Line()
+		s.Id("req").Op(":=").Id("request").Assert(Op("*").Qual(t.info.OutputPackageImport+"/transport", t.info.requestStructName(fn))).Line()
 		pathVars.Add(t.pathConverters(fn))
 	}
 	s.Id("r").Dot("URL").Dot("Path").Op("=").
@@ -356,7 +467,7 @@ func (t *httpConverterTemplate) encodeHTTPRequestBody(fn *types.Function) *State
 
 func (t *httpConverterTemplate) pathConverters(fn *types.Function) *Statement {
 	converters := &Statement{}
-	for _, arg := range RemoveContextIfFirst(fn.Args) {
+	for _, arg := range HTTPRequestArgs(fn) {
 		typename := types.TypeName(arg.Type)
 		if typename == nil {
 			panic("need to check and update validation rules (3)")
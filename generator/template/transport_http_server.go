@@ -32,7 +32,7 @@ func NewHttpServerTemplate(info *GenerationInfo) Template {
 }
 
 func (t *httpServerTemplate) DefaultPath() string {
-	return filenameBuilder(PathTransport, "http", "server")
+	return filenameBuilder(PathTransport, "http", t.info.nsFile("server"))
 }
 
 func (t *httpServerTemplate) ChooseStrategy(ctx context.Context) (write_strategy.Strategy, error) {
@@ -44,6 +44,10 @@ func (t *httpServerTemplate) Prepare(ctx context.Context) error {
 	t.paths = make(map[string]string)
 	for _, fn := range t.info.Iface.Methods {
 		t.methods[fn.Name] = FetchHttpMethodTag(fn.Docs)
+		if t.info.ManyToOneStreamMethods[fn.Name] || t.info.ManyToManyStreamMethods[fn.Name] {
+			// WebSocket handshake is a GET request.
+			t.methods[fn.Name] = "GET"
+		}
 		t.paths[fn.Name] = buildMethodPath(fn)
 	}
 	return nil
@@ -58,7 +62,14 @@ func FetchHttpMethodTag(rawString []string) string {
 }
 
 func buildMethodPath(fn *types.Function) string {
-	url := strings.Replace(mstrings.FetchMetaInfo(TagMark+HttpMethodPath, fn.Docs), " ", "", -1)
+	url := ""
+	for _, doc := range fn.Docs {
+		// Tag should be followed by space, so @http-path-param is not matched.
+		if strings.HasPrefix(doc, TagMark+HttpMethodPath+" ") {
+			url = strings.Replace(doc[len(TagMark+HttpMethodPath):], " ", "", -1)
+			break
+		}
+	}
 	if url == "" {
 		return buildDefaultMethodPath(fn)
 	}
@@ -66,9 +77,12 @@ func buildMethodPath(fn *types.Function) string {
 }
 
 func buildDefaultMethodPath(fn *types.Function) string {
+	if HasHTTPBinding(fn) {
+		return bindingDefaultMethodPath(fn)
+	}
 	edges := []string{mstrings.ToURLSnakeCase(fn.Name)} // parts of full path
 	if FetchHttpMethodTag(fn.Docs) == "GET" {
-		edges = append(edges, gorillaMuxUrlTemplateVarList(RemoveContextIfFirst(fn.Args))...)
+		edges = append(edges, gorillaMuxUrlTemplateVarList(HTTPRequestArgs(fn))...)
 	}
 	return path.Join(edges...)
 }
@@ -118,44 +132,64 @@ func (t *httpServerTemplate) Render(ctx context.Context) write_strategy.Renderer
 	f.ImportAlias(t.info.SourcePackageImport, serviceAlias)
 	f.HeaderComment(t.info.FileHeader)
 
-	f.Func().Id("NewHTTPHandler").ParamsFunc(func(p *Group) {
-		p.Id("endpoints").Op("*").Qual(t.info.OutputPackageImport+"/transport", EndpointsSetName)
+	f.Func().Id(t.info.nsNewName("HTTPHandler")).ParamsFunc(func(p *Group) {
+		p.Id("endpoints").Op("*").Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName())
 		if Tags(ctx).Has(TracingMiddlewareTag) {
 			p.Id("logger").Qual(PackagePathGoKitLog, "Logger")
-		}
-		if Tags(ctx).Has(TracingMiddlewareTag) {
 			p.Id("tracer").Qual(PackagePathOpenTracingGo, "Tracer")
 		}
+		if Tags(ctx).Has(OpenTelemetryTag) {
+			p.Id("propagator").Qual(PackagePathOTelPropagation, "TextMapPropagator")
+		}
 		p.Id("opts").Op("...").Qual(PackagePathGoKitTransportHTTP, "ServerOption")
 	}).Params(
 		Qual(PackagePathHttp, "Handler"),
 	).BlockFunc(func(g *Group) {
+		if HasErrorsTag(t.info.Iface.Docs) {
+			// Error encoder goes first, so it may be replaced with options of caller.
+			g.Id("opts").Op("=").Append(
+				Index().Qual(PackagePathGoKitTransportHTTP, "ServerOption").Values(
+					Qual(PackagePathGoKitTransportHTTP, "ServerErrorEncoder").Call(Id(t.info.nsName(httpErrorEncoderName))),
+				),
+				Id("opts").Op("..."),
+			)
+		}
 		g.Id("mux").Op(":=").Qual(PackagePathGorillaMux, "NewRouter").Call()
 		for _, fn := range t.info.Iface.Methods {
-			if !t.info.AllowedMethods[fn.Name] ||
-				t.info.ManyToManyStreamMethods[fn.Name] ||
+			if !t.info.AllowedMethods[fn.Name] {
+				continue
+			}
+			if t.info.ManyToManyStreamMethods[fn.Name] ||
 				t.info.ManyToOneStreamMethods[fn.Name] ||
 				t.info.OneToManyStreamMethods[fn.Name] {
+				g.Id("mux").Dot("Methods").Call(Lit(t.methods[fn.Name])).Dot("Path").
+					Call(Lit("/" + t.paths[fn.Name])).Dot("Handler").Call(
+					Id(t.info.httpStreamHandlerName(fn)).Call(Id("endpoints").Dot(endpointsStructFieldName(fn.Name))),
+				)
 				continue
 			}
 			g.Id("mux").Dot("Methods").Call(Lit(t.methods[fn.Name])).Dot("Path").
 				Call(Lit("/" + t.paths[fn.Name])).Dot("Handler").Call(
 				Line().Qual(PackagePathGoKitTransportHTTP, "NewServer").Call(
 					Line().Id("endpoints").Dot(endpointsStructFieldName(fn.Name)),
-					Line().Id(decodeRequestName(fn)),
-					Line().Id(encodeResponseName(fn)),
+					Line().Id(t.info.decodeRequestName(fn)),
+					Line().Id(t.info.encodeResponseName(fn)),
 					Line().Add(t.serverOpts(ctx, fn)).Op("...")),
 			)
 		}
 		g.Return(Id("mux"))
 	})
 
+	if Tags(ctx).Has(OpenTelemetryTag) {
+		f.Line().Add(httpExtractTraceContext(t.info.nsPrivateName("extractTraceContext")))
+	}
+
 	return f
 }
 
 func (t *httpServerTemplate) serverOpts(ctx context.Context, fn *types.Function) *Statement {
 	s := &Statement{}
-	if Tags(ctx).Has(TracingMiddlewareTag) {
+	if Tags(ctx).HasAny(TracingMiddlewareTag, OpenTelemetryTag) {
 		s.Op("append(")
 		defer s.Op(")")
 	}
@@ -165,6 +199,11 @@ func (t *httpServerTemplate) serverOpts(ctx context.Context, fn *types.Function)
 			Line().Qual(PackagePathGoKitTracing, "HTTPToContext").Call(Id("tracer"), Lit(fn.Name), Id("logger")),
 		)
 	}
+	if Tags(ctx).Has(OpenTelemetryTag) {
+		s.Op(",").Qual(PackagePathGoKitTransportHTTP, "ServerBefore").Call(
+			Line().Id(t.info.nsPrivateName("extractTraceContext")).Call(Id("propagator")),
+		)
+	}
 	return s
 }
 
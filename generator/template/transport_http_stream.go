@@ -0,0 +1,593 @@
+package template
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/dave/jennifer/jen"
+	mstrings "github.com/recolabs/microgen/generator/strings"
+	"github.com/recolabs/microgen/generator/write_strategy"
+	"github.com/vetcher/go-astra/types"
+)
+
+const (
+	httpServerStreamName = "httpServerStream"
+	httpClientStreamName = "httpClientStream"
+	httpStreamClientName = "HTTPStreamClient"
+)
+
+// HasStreamMethods returns true, when service has methods with one-to-many, many-to-one or many-to-many tags.
+func HasStreamMethods(info *GenerationInfo) bool {
+	for _, fn := range info.Iface.Methods {
+		if isStreamMethod(info, fn) {
+			return true
+		}
+	}
+	return false
+}
+
+func isStreamMethod(info *GenerationInfo, fn *types.Function) bool {
+	return info.OneToManyStreamMethods[fn.Name] || info.ManyToOneStreamMethods[fn.Name] || info.ManyToManyStreamMethods[fn.Name]
+}
+
+// HTTPRequestArgs returns arguments of method, which are transferred in HTTP request.
+// Stream argument of one-to-many method is replaced by response,
+// and many-to-one and many-to-many methods send all messages over WebSocket.
+func HTTPRequestArgs(fn *types.Function) []types.Variable {
+	tags := mstrings.FetchTags(fn.Docs, TagMark+MicrogenMainTag)
+	switch {
+	case mstrings.ContainTag(tags, "one-to-many"):
+		return removeLastVar(RemoveContextIfFirst(fn.Args))
+	case mstrings.ContainTag(tags, "many-to-one"), mstrings.ContainTag(tags, "many-to-many"):
+		return nil
+	}
+	return RemoveContextIfFirst(fn.Args)
+}
+
+// Constructors of stream types.
+var streamConstructorNames = map[string]string{
+	httpServerStreamName: "newHTTPServerStream",
+	httpClientStreamName: "newHTTPClientStream",
+	wsServerStreamName:   "newWSServerStream",
+	wsClientStreamName:   "newWSClientStream",
+}
+
+type httpStreamTemplate struct {
+	info *GenerationInfo
+
+	oneToMany []*types.Function
+	webSocket []*types.Function
+}
+
+func NewHttpStreamTemplate(info *GenerationInfo) Template {
+	return &httpStreamTemplate{
+		info: info,
+	}
+}
+
+func (t *httpStreamTemplate) DefaultPath() string {
+	return filenameBuilder(PathTransport, "http", t.info.nsFile("stream"))
+}
+
+func (t *httpStreamTemplate) ChooseStrategy(ctx context.Context) (write_strategy.Strategy, error) {
+	return write_strategy.NewCreateFileStrategy(t.info.OutputFilePath, t.DefaultPath()), nil
+}
+
+func (t *httpStreamTemplate) Prepare(ctx context.Context) error {
+	if t.info.ProtobufPackageImport == "" {
+		return fmt.Errorf("protobuf package is empty, stream methods over http use protobuf messages")
+	}
+	t.oneToMany, t.webSocket = nil, nil
+	for _, fn := range t.info.Iface.Methods {
+		if !t.info.AllowedMethods[fn.Name] {
+			continue
+		}
+		if t.info.OneToManyStreamMethods[fn.Name] {
+			t.oneToMany = append(t.oneToMany, fn)
+		}
+		if t.info.ManyToOneStreamMethods[fn.Name] || t.info.ManyToManyStreamMethods[fn.Name] {
+			t.webSocket = append(t.webSocket, fn)
+		}
+	}
+	return nil
+}
+
+// Render stream methods over http.
+// One-to-many methods send protobuf messages as server-sent events or newline delimited JSON,
+// many-to-one and many-to-many methods exchange JSON messages over WebSocket.
+//
+//		type countHTTPServerStream struct {
+//			*httpServerStream
+//		}
+//
+//		func (s countHTTPServerStream) Send(m *pb.CountResponse) error {
+//			return s.SendMsg(m)
+//		}
+//
+//		func countHTTPHandler(endpoint transport.OneToManyStreamEndpoint) http.Handler {
+//			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//				stream := newHTTPServerStream(w, r)
+//				request, err := DecodeHTTPCountRequest(r.Context(), r)
+//				if err != nil {
+//					stream.close(err)
+//					return
+//				}
+//				stream.close(endpoint(request, countHTTPServerStream{stream}))
+//			})
+//		}
+//
+func (t *httpStreamTemplate) Render(ctx context.Context) write_strategy.Renderer {
+	f := NewFile("transporthttp")
+	f.ImportAlias(t.info.ProtobufPackageImport, "pb")
+	f.ImportAlias(PackagePathGoKitTransportHTTP, "httpkit")
+	f.HeaderComment(t.info.FileHeader)
+
+	server := Tags(ctx).HasAny(HttpTag, HttpServerTag)
+	client := Tags(ctx).HasAny(HttpTag, HttpClientTag)
+	if len(t.oneToMany) > 0 {
+		f.Const().Id(t.info.nsPrivateName("streamErrorTrailer")).Op("=").Lit(streamErrorTrailer)
+	}
+	if len(t.webSocket) > 0 && HasErrorsTag(t.info.Iface.Docs) {
+		f.Line().Comment("Errors of service close WebSocket with code 4000 + HTTP status.").
+			Line().Const().Id(t.info.nsPrivateName("wsErrorCodeBase")).Op("=").Lit(wsErrorCodeBase)
+	}
+	if server {
+		if len(t.oneToMany) > 0 {
+			f.Line().Add(t.httpServerStream())
+			f.Line().Add(t.encodeStreamError())
+		}
+		if len(t.webSocket) > 0 {
+			f.Line().Add(t.wsServerStream())
+			f.Line().Add(t.wsCloseMessage())
+		}
+		for _, fn := range t.oneToMany {
+			f.Line().Add(t.serverStreamWrapper(fn, t.info.nsPrivateName(httpServerStreamName)))
+			f.Line().Add(t.oneToManyHandler(fn))
+		}
+		for _, fn := range t.webSocket {
+			f.Line().Add(t.serverStreamWrapper(fn, t.info.nsPrivateName(wsServerStreamName)))
+			f.Line().Add(t.webSocketHandler(fn))
+		}
+	}
+	if client {
+		f.Line().Add(t.streamClient())
+		f.Line().Add(t.headerMetadata())
+		if len(t.oneToMany) > 0 {
+			f.Line().Add(t.httpClientStream())
+			f.Line().Add(t.decodeStreamError())
+		}
+		if len(t.webSocket) > 0 {
+			f.Line().Add(t.wsClientStream())
+			f.Line().Add(t.wsCloseError())
+		}
+		for _, fn := range t.oneToMany {
+			f.Line().Add(t.clientStreamWrapper(fn, t.info.nsPrivateName(httpClientStreamName)))
+			f.Line().Add(t.oneToManyClientMethod(ctx, fn))
+		}
+		for _, fn := range t.webSocket {
+			f.Line().Add(t.clientStreamWrapper(fn, t.info.nsPrivateName(wsClientStreamName)))
+			f.Line().Add(t.webSocketClientMethod(ctx, fn))
+		}
+	}
+	return f
+}
+
+func (t *httpStreamTemplate) transport(name string) *Statement {
+	return Qual(t.info.OutputPackageImport+"/transport", t.info.nsName(name))
+}
+
+func (t *httpStreamTemplate) constructor(stream string) string {
+	return t.info.nsPrivateName(streamConstructorNames[stream])
+}
+
+func (t *httpStreamTemplate) pb(name string) *Statement {
+	return Qual(t.info.ProtobufPackageImport, name)
+}
+
+// Returns statement, which writes every value of metadata to header.
+func addMetadataToHeader(header *Statement, key func(k Code) Code) *Statement {
+	return For(List(Id("k"), Id("vs")).Op(":=").Range().Id("md")).Block(
+		For(List(Id("_"), Id("v")).Op(":=").Range().Id("vs")).Block(
+			header.Dot("Add").Call(key(Id("k")), Id("v")),
+		),
+	)
+}
+
+// Render server stream of one-to-many methods. Framing is chosen by Accept header of request,
+// it is rendered by sse and ndjson functions.
+//
+//		// httpServerStream sends messages of one-to-many stream as server-sent events, when client accepts text/event-stream,
+//		// or as newline delimited JSON otherwise.
+//		type httpServerStream struct {
+//			ctx     context.Context
+//			w       http.ResponseWriter
+//			sse     bool
+//			started bool
+//		}
+//
+func (t *httpStreamTemplate) httpServerStream() *Statement {
+	name := t.info.nsPrivateName(httpServerStreamName)
+	recv := func() *Statement { return Params(Id("s").Op("*").Id(name)) }
+	s := &Statement{}
+	s.Comment(name+" sends messages of one-to-many stream as server-sent events, when client accepts text/event-stream,").
+		Line().Comment("or as newline delimited JSON otherwise.").
+		Line().Type().Id(name).Struct(
+		Id("ctx").Qual(PackagePathContext, "Context"),
+		Id("w").Qual(PackagePathHttp, "ResponseWriter"),
+		Id("sse").Bool(),
+		Id("started").Bool(),
+	).Line()
+
+	s.Line().Func().Id(t.constructor(httpServerStreamName)).Params(
+		Id("w").Qual(PackagePathHttp, "ResponseWriter"),
+		Id("r").Op("*").Qual(PackagePathHttp, "Request"),
+	).Op("*").Id(name).Block(
+		Return(Op("&").Id(name).Values(Dict{
+			Id("ctx"): Id("r").Dot("Context").Call(),
+			Id("w"):   Id("w"),
+			Id("sse"): sseAccepted(),
+		})),
+	).Line()
+
+	s.Line().Func().Add(recv()).Id("start").Params().Block(
+		If(Id("s").Dot("started")).Block(Return()),
+		Id("s").Dot("started").Op("=").True(),
+		If(Id("s").Dot("sse")).Block(
+			sseHeaders()...,
+		).Else().Block(
+			t.ndjsonHeaders()...,
+		),
+		Id("s").Dot("w").Dot("WriteHeader").Call(Qual(PackagePathHttp, "StatusOK")),
+	).Line()
+
+	s.Line().Func().Add(recv()).Id("Context").Params().Qual(PackagePathContext, "Context").Block(
+		Return(Id("s").Dot("ctx")),
+	).Line()
+
+	s.Line().Func().Add(recv()).Id("SetHeader").Params(Id("md").Qual(PackagePathGoogleGRPCMetadata, "MD")).Error().Block(
+		If(Id("s").Dot("started")).Block(
+			Return(Qual(PackagePathErrors, "New").Call(Lit("stream: headers are already sent"))),
+		),
+		addMetadataToHeader(httpServerStreamHeader(), func(k Code) Code { return k }),
+		Return(Nil()),
+	).Line()
+
+	s.Line().Func().Add(recv()).Id("SendHeader").Params(Id("md").Qual(PackagePathGoogleGRPCMetadata, "MD")).Error().Block(
+		If(Err().Op(":=").Id("s").Dot("SetHeader").Call(Id("md")), Err().Op("!=").Nil()).Block(
+			Return(Err()),
+		),
+		Id("s").Dot("start").Call(),
+		Return(Nil()),
+	).Line()
+
+	s.Line().Func().Add(recv()).Id("SetTrailer").Params(Id("md").Qual(PackagePathGoogleGRPCMetadata, "MD")).Block(
+		addMetadataToHeader(httpServerStreamHeader(), func(k Code) Code { return Qual(PackagePathHttp, "TrailerPrefix").Op("+").Add(k) }),
+	).Line()
+
+	s.Line().Func().Add(recv()).Id("SendMsg").Params(Id("m").Interface()).Error().Block(
+		List(Id("data"), Err()).Op(":=").Qual(PackagePathJson, "Marshal").Call(Id("m")),
+		If(Err().Op("!=").Nil()).Block(Return(Err())),
+		Id("s").Dot("start").Call(),
+		If(Id("s").Dot("sse")).Block(
+			sseWriteMessage(),
+		).Else().Block(
+			ndjsonWriteMessage(),
+		),
+		If(Err().Op("!=").Nil()).Block(Return(Err())),
+		If(List(Id("flusher"), Id("ok")).Op(":=").Id("s").Dot("w").Assert(Qual(PackagePathHttp, "Flusher")), Id("ok")).Block(
+			Id("flusher").Dot("Flush").Call(),
+		),
+		Return(Nil()),
+	).Line()
+
+	s.Line().Comment("RecvMsg returns io.EOF, because one-to-many stream receives only request.").
+		Line().Func().Add(recv()).Id("RecvMsg").Params(Interface()).Error().Block(
+		Return(Qual(PackagePathIO, "EOF")),
+	).Line()
+
+	var encoder *Statement
+	if HasErrorsTag(t.info.Iface.Docs) {
+		encoder = Id(t.info.nsName(httpErrorEncoderName))
+	} else {
+		encoder = Qual(PackagePathGoKitTransportHTTP, "DefaultErrorEncoder")
+	}
+	s.Line().Comment("close finishes stream with result of endpoint. Error before the first message is written with HTTP status,").
+		Line().Comment("later it is sent as error event or trailer.").
+		Line().Func().Add(recv()).Id("close").Params(Err().Error()).Block(
+		Switch().Block(
+			Case(Err().Op("==").Nil()).Block(
+				Id("s").Dot("start").Call(),
+			),
+			Case(Op("!").Id("s").Dot("started")).Block(
+				encoder.Call(Id("s").Dot("ctx"), Err(), Id("s").Dot("w")),
+			),
+			Case(Id("s").Dot("sse")).Block(
+				t.sseWriteError(),
+			),
+			Default().Block(
+				t.ndjsonWriteError(),
+			),
+		),
+	)
+	return s
+}
+
+// Returns header of response of one-to-many server stream.
+func httpServerStreamHeader() *Statement {
+	return Id("s").Dot("w").Dot("Header").Call()
+}
+
+// Render text of error, which is sent after messages of stream.
+//
+//		func encodeStreamError(err error) string {
+//			data, _ := json.Marshal(transport.EncodeError(err))
+//			return string(data)
+//		}
+//
+func (t *httpStreamTemplate) encodeStreamError() *Statement {
+	name := t.info.nsPrivateName("encodeStreamError")
+	return Comment(name + " returns text of error, which is sent after messages of stream.").
+		Line().Func().Id(name).Params(Err().Error()).String().BlockFunc(func(g *Group) {
+		if HasErrorsTag(t.info.Iface.Docs) {
+			g.List(Id("data"), Id("_")).Op(":=").Qual(PackagePathJson, "Marshal").Call(t.transport("EncodeError").Call(Err()))
+			g.Return(String().Call(Id("data")))
+			return
+		}
+		g.Return(Qual(PackagePathStrings, "Replace").Call(Err().Dot("Error").Call(), Lit("\n"), Lit(" "), Lit(-1)))
+	})
+}
+
+// Render server stream of method, which implements stream interface of protobuf.
+//
+//		type countHTTPServerStream struct {
+//			*httpServerStream
+//		}
+//
+//		func (s countHTTPServerStream) Send(m *pb.CountResponse) error {
+//			return s.SendMsg(m)
+//		}
+//
+func (t *httpStreamTemplate) serverStreamWrapper(fn *types.Function, stream string) *Statement {
+	name := t.info.nsPrivateName(mstrings.ToLowerFirst(fn.Name) + "HTTPServerStream")
+	recv := func() *Statement { return Params(Id("s").Id(name)) }
+	s := &Statement{}
+	s.Type().Id(name).Struct(Op("*").Id(stream)).Line()
+	send := "Send"
+	if t.info.ManyToOneStreamMethods[fn.Name] {
+		send = "SendAndClose"
+	}
+	s.Line().Func().Add(recv()).Id(send).Params(Id("m").Op("*").Add(t.pb(responseMessageName(fn)))).Error().Block(
+		Return(Id("s").Dot("SendMsg").Call(Id("m"))),
+	)
+	if !t.info.OneToManyStreamMethods[fn.Name] {
+		s.Line().Line().Func().Add(recv()).Id("Recv").Params().Params(Op("*").Add(t.pb(requestMessageName(fn))), Error()).Block(
+			recvMessage(t.pb(requestMessageName(fn)))...,
+		)
+	}
+	return s
+}
+
+// Renders body of Recv method of stream.
+//
+//		m := new(pb.CountRequest)
+//		if err := s.RecvMsg(m); err != nil {
+//			return nil, err
+//		}
+//		return m, nil
+//
+func recvMessage(message *Statement) []Code {
+	return []Code{
+		Id("m").Op(":=").New(message),
+		If(Err().Op(":=").Id("s").Dot("RecvMsg").Call(Id("m")), Err().Op("!=").Nil()).Block(
+			Return(Nil(), Err()),
+		),
+		Return(Id("m"), Nil()),
+	}
+}
+
+// Returns name of http handler of stream method, which is registered by NewHTTPHandler.
+func (i *GenerationInfo) httpStreamHandlerName(fn *types.Function) string {
+	return i.nsPrivateName(mstrings.ToLowerFirst(fn.Name) + "HTTPHandler")
+}
+
+// Render handler of one-to-many method.
+//
+//		func countHTTPHandler(endpoint transport.OneToManyStreamEndpoint) http.Handler {
+//			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//				stream := newHTTPServerStream(w, r)
+//				request, err := DecodeHTTPCountRequest(r.Context(), r)
+//				if err != nil {
+//					stream.close(err)
+//					return
+//				}
+//				stream.close(endpoint(request, countHTTPServerStream{stream}))
+//			})
+//		}
+//
+func (t *httpStreamTemplate) oneToManyHandler(fn *types.Function) *Statement {
+	return Func().Id(t.info.httpStreamHandlerName(fn)).Params(Id("endpoint").Add(t.transport(OneToManyStreamEndpoint))).Qual(PackagePathHttp, "Handler").Block(
+		Return(Qual(PackagePathHttp, "HandlerFunc").Call(Func().Params(
+			Id("w").Qual(PackagePathHttp, "ResponseWriter"),
+			Id("r").Op("*").Qual(PackagePathHttp, "Request"),
+		).Block(
+			Id("stream").Op(":=").Id(t.constructor(httpServerStreamName)).Call(Id("w"), Id("r")),
+			List(Id("request"), Err()).Op(":=").Id(t.info.decodeRequestName(fn)).Call(Id("r").Dot("Context").Call(), Id("r")),
+			If(Err().Op("!=").Nil()).Block(
+				Id("stream").Dot("close").Call(Err()),
+				Return(),
+			),
+			Id("stream").Dot("close").Call(Id("endpoint").Call(
+				Id("request"),
+				Id(t.info.nsPrivateName(mstrings.ToLowerFirst(fn.Name)+"HTTPServerStream")).Values(Id("stream")),
+			)),
+		))),
+	)
+}
+
+// Render client of stream methods.
+//
+//		// HTTPStreamClient calls stream methods of StringService over HTTP and WebSocket.
+//		type HTTPStreamClient struct {
+//			u      *url.URL
+//			client *http.Client
+//			dialer *websocket.Dialer
+//		}
+//
+//		// NewHTTPStreamClient returns client of stream methods. Nil client and dialer are replaced by defaults.
+//		func NewHTTPStreamClient(u *url.URL, client *http.Client, dialer *websocket.Dialer) *HTTPStreamClient {
+//			if client == nil {
+//				client = http.DefaultClient
+//			}
+//			if dialer == nil {
+//				dialer = websocket.DefaultDialer
+//			}
+//			return &HTTPStreamClient{
+//				client: client,
+//				dialer: dialer,
+//				u:      u,
+//			}
+//		}
+//
+func (t *httpStreamTemplate) streamClient() *Statement {
+	name := t.info.nsName(httpStreamClientName)
+	constructor := t.info.nsNewName(httpStreamClientName)
+	http, ws := len(t.oneToMany) > 0, len(t.webSocket) > 0
+	s := &Statement{}
+	s.Comment(fmt.Sprintf("%s calls stream methods of %s over HTTP and WebSocket.", name, t.info.Iface.Name)).
+		Line().Type().Id(name).StructFunc(func(g *Group) {
+		g.Id("u").Op("*").Qual(PackagePathUrl, "URL")
+		if http {
+			g.Id("client").Op("*").Qual(PackagePathHttp, "Client")
+		}
+		if ws {
+			g.Id("dialer").Op("*").Qual(PackagePathGorillaWebsocket, "Dialer")
+		}
+	}).Line()
+
+	var defaults []string
+	if http {
+		defaults = append(defaults, "client")
+	}
+	if ws {
+		defaults = append(defaults, "dialer")
+	}
+	nils := defaults[0] + " is"
+	if len(defaults) == 2 {
+		nils = "client and dialer are"
+	}
+	s.Line().Comment(fmt.Sprintf("%s returns client of stream methods. Nil %s replaced by defaults.", constructor, nils)).
+		Line().Func().Id(constructor).ParamsFunc(func(p *Group) {
+		p.Id("u").Op("*").Qual(PackagePathUrl, "URL")
+		if http {
+			p.Id("client").Op("*").Qual(PackagePathHttp, "Client")
+		}
+		if ws {
+			p.Id("dialer").Op("*").Qual(PackagePathGorillaWebsocket, "Dialer")
+		}
+	}).Op("*").Id(name).BlockFunc(func(g *Group) {
+		if http {
+			g.If(Id("client").Op("==").Nil()).Block(
+				Id("client").Op("=").Qual(PackagePathHttp, "DefaultClient"),
+			)
+		}
+		if ws {
+			g.If(Id("dialer").Op("==").Nil()).Block(
+				Id("dialer").Op("=").Qual(PackagePathGorillaWebsocket, "DefaultDialer"),
+			)
+		}
+		g.Return(Op("&").Id(name).Values(DictFunc(func(d Dict) {
+			d[Id("u")] = Id("u")
+			for _, field := range defaults {
+				d[Id(field)] = Id(field)
+			}
+		})))
+	})
+	return s
+}
+
+// Render conversion of HTTP headers to metadata of gRPC.
+//
+//		// headerMetadata returns metadata of HTTP headers or trailers.
+//		func headerMetadata(h http.Header) metadata.MD {
+//			md := metadata.MD{}
+//			for k, vs := range h {
+//				md.Append(k, vs...)
+//			}
+//			return md
+//		}
+//
+func (t *httpStreamTemplate) headerMetadata() *Statement {
+	name := t.info.nsPrivateName("headerMetadata")
+	return Comment(name+" returns metadata of HTTP headers or trailers.").
+		Line().Func().Id(name).Params(Id("h").Qual(PackagePathHttp, "Header")).Qual(PackagePathGoogleGRPCMetadata, "MD").Block(
+		Id("md").Op(":=").Qual(PackagePathGoogleGRPCMetadata, "MD").Values(),
+		For(List(Id("k"), Id("vs")).Op(":=").Range().Id("h")).Block(
+			Id("md").Dot("Append").Call(Id("k"), Id("vs").Op("...")),
+		),
+		Return(Id("md")),
+	)
+}
+
+// Render client stream of method, which implements stream interface of protobuf.
+//
+//		type chatHTTPClientStream struct {
+//			*wsClientStream
+//		}
+//
+//		func (s chatHTTPClientStream) Send(m *pb.ChatRequest) error {
+//			return s.SendMsg(m)
+//		}
+//
+//		func (s chatHTTPClientStream) Recv() (*pb.ChatResponse, error) {
+//			m := new(pb.ChatResponse)
+//			if err := s.RecvMsg(m); err != nil {
+//				return nil, err
+//			}
+//			return m, nil
+//		}
+//
+func (t *httpStreamTemplate) clientStreamWrapper(fn *types.Function, stream string) *Statement {
+	name := t.info.nsPrivateName(mstrings.ToLowerFirst(fn.Name) + "HTTPClientStream")
+	recv := func() *Statement { return Params(Id("s").Id(name)) }
+	response := t.pb(responseMessageName(fn))
+	s := &Statement{}
+	s.Type().Id(name).Struct(Op("*").Id(stream))
+	if !t.info.OneToManyStreamMethods[fn.Name] {
+		s.Line().Line().Func().Add(recv()).Id("Send").Params(Id("m").Op("*").Add(t.pb(requestMessageName(fn)))).Error().Block(
+			Return(Id("s").Dot("SendMsg").Call(Id("m"))),
+		)
+	}
+	if t.info.ManyToOneStreamMethods[fn.Name] {
+		s.Line().Line().Func().Add(recv()).Id("CloseAndRecv").Params().Params(Op("*").Add(response), Error()).Block(
+			append([]Code{
+				If(Err().Op(":=").Id("s").Dot("CloseSend").Call(), Err().Op("!=").Nil()).Block(
+					Return(Nil(), Err()),
+				),
+			}, recvMessage(response)...)...,
+		)
+		return s
+	}
+	s.Line().Line().Func().Add(recv()).Id("Recv").Params().Params(Op("*").Add(response), Error()).Block(
+		recvMessage(response)...,
+	)
+	return s
+}
+
+// Renders error of response with error status.
+func (t *httpStreamTemplate) responseError(g *Group) {
+	if HasErrorsTag(t.info.Iface.Docs) {
+		g.Return(Nil(), Id(t.info.nsName(httpErrorDecoderName)).Call(Id("resp")))
+		return
+	}
+	g.List(Id("body"), Id("_")).Op(":=").Qual(PackagePathIOUtil, "ReadAll").Call(Id("resp").Dot("Body"))
+	g.Return(Nil(), Qual(PackagePathErrors, "New").Call(Qual(PackagePathStrings, "TrimSpace").Call(String().Call(Id("body")))))
+}
+
+func (t *httpStreamTemplate) clientMethod(ctx context.Context, fn *types.Function, args []types.Variable) *Statement {
+	return Func().Params(Id("c").Op("*").Id(t.info.nsName(httpStreamClientName))).Id(fn.Name).ParamsFunc(func(p *Group) {
+		p.Id("ctx").Qual(PackagePathContext, "Context")
+		for _, arg := range args {
+			p.Id(mstrings.ToLowerFirst(arg.Name)).Add(fieldType(ctx, arg.Type, true))
+		}
+	}).Params(t.pb(t.info.Iface.Name+"_"+fn.Name+"Client"), Error())
+}
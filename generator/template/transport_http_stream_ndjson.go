@@ -0,0 +1,197 @@
+package template
+
+import (
+	"context"
+
+	. "github.com/dave/jennifer/jen"
+	mstrings "github.com/recolabs/microgen/generator/strings"
+	"github.com/vetcher/go-astra/types"
+)
+
+// One-to-many methods send messages as newline delimited JSON to clients, which do not accept server-sent events,
+// and to generated client. Error after the first message is sent in trailer.
+
+// Trailer of newline delimited JSON stream with error, which happened after the first message.
+const streamErrorTrailer = "Stream-Error"
+
+// Renders headers of newline delimited JSON stream.
+//
+//		s.w.Header().Set("Content-Type", "application/x-ndjson")
+//		s.w.Header().Set("Trailer", streamErrorTrailer)
+//
+func (t *httpStreamTemplate) ndjsonHeaders() []Code {
+	return []Code{
+		httpServerStreamHeader().Dot("Set").Call(Lit("Content-Type"), Lit("application/x-ndjson")),
+		httpServerStreamHeader().Dot("Set").Call(Lit("Trailer"), Id(t.info.nsPrivateName("streamErrorTrailer"))),
+	}
+}
+
+// Renders writing of message as line of JSON.
+//
+//		_, err = s.w.Write(append(data, '\n'))
+//
+func ndjsonWriteMessage() *Statement {
+	return List(Id("_"), Err()).Op("=").Id("s").Dot("w").Dot("Write").Call(Append(Id("data"), LitRune('\n')))
+}
+
+// Renders writing of error after messages to trailer.
+//
+//		s.w.Header().Set(streamErrorTrailer, encodeStreamError(err))
+//
+func (t *httpStreamTemplate) ndjsonWriteError() *Statement {
+	return httpServerStreamHeader().Dot("Set").Call(Id(t.info.nsPrivateName("streamErrorTrailer")), Id(t.info.nsPrivateName("encodeStreamError")).Call(Err()))
+}
+
+// Render client stream of one-to-many methods.
+//
+//		// httpClientStream receives messages of one-to-many stream as newline delimited JSON.
+//		type httpClientStream struct {
+//			ctx  context.Context
+//			resp *http.Response
+//			r    *bufio.Reader
+//		}
+//
+func (t *httpStreamTemplate) httpClientStream() *Statement {
+	name := t.info.nsPrivateName(httpClientStreamName)
+	recv := func() *Statement { return Params(Id("s").Op("*").Id(name)) }
+	closeBody := Id("s").Dot("resp").Dot("Body").Dot("Close").Call()
+	s := &Statement{}
+	s.Comment(name+" receives messages of one-to-many stream as newline delimited JSON.").
+		Line().Type().Id(name).Struct(
+		Id("ctx").Qual(PackagePathContext, "Context"),
+		Id("resp").Op("*").Qual(PackagePathHttp, "Response"),
+		Id("r").Op("*").Qual(PackagePathBufio, "Reader"),
+	).Line()
+
+	s.Line().Func().Id(t.constructor(httpClientStreamName)).Params(
+		Id("ctx").Qual(PackagePathContext, "Context"),
+		Id("resp").Op("*").Qual(PackagePathHttp, "Response"),
+	).Op("*").Id(name).Block(
+		Return(Op("&").Id(name).Values(Dict{
+			Id("ctx"):  Id("ctx"),
+			Id("resp"): Id("resp"),
+			Id("r"):    Qual(PackagePathBufio, "NewReader").Call(Id("resp").Dot("Body")),
+		})),
+	).Line()
+
+	s.Line().Func().Add(recv()).Id("Header").Params().Params(Qual(PackagePathGoogleGRPCMetadata, "MD"), Error()).Block(
+		Return(Id(t.info.nsPrivateName("headerMetadata")).Call(Id("s").Dot("resp").Dot("Header")), Nil()),
+	).Line()
+
+	s.Line().Comment("Trailer returns trailers of response, they are available after Recv returns error.").
+		Line().Func().Add(recv()).Id("Trailer").Params().Qual(PackagePathGoogleGRPCMetadata, "MD").Block(
+		Return(Id(t.info.nsPrivateName("headerMetadata")).Call(Id("s").Dot("resp").Dot("Trailer"))),
+	).Line()
+
+	s.Line().Func().Add(recv()).Id("CloseSend").Params().Error().Block(
+		Return(Nil()),
+	).Line()
+
+	s.Line().Func().Add(recv()).Id("Context").Params().Qual(PackagePathContext, "Context").Block(
+		Return(Id("s").Dot("ctx")),
+	).Line()
+
+	s.Line().Func().Add(recv()).Id("SendMsg").Params(Interface()).Error().Block(
+		Return(Qual(PackagePathErrors, "New").Call(Lit("stream: one-to-many stream does not send messages"))),
+	).Line()
+
+	s.Line().Func().Add(recv()).Id("RecvMsg").Params(Id("m").Interface()).Error().Block(
+		For().Block(
+			List(Id("line"), Err()).Op(":=").Id("s").Dot("r").Dot("ReadBytes").Call(LitRune('\n')),
+			If(Len(Qual(PackagePathBytes, "TrimSpace").Call(Id("line"))).Op(">").Lit(0)).Block(
+				Return(Qual(PackagePathJson, "Unmarshal").Call(Id("line"), Id("m"))),
+			),
+			If(Err().Op("==").Qual(PackagePathIO, "EOF")).Block(
+				closeBody.Clone(),
+				If(
+					Id("text").Op(":=").Id("s").Dot("resp").Dot("Trailer").Dot("Get").Call(Id(t.info.nsPrivateName("streamErrorTrailer"))),
+					Id("text").Op("!=").Lit(""),
+				).Block(
+					Return(Id(t.info.nsPrivateName("decodeStreamError")).Call(Id("text"))),
+				),
+				Return(Qual(PackagePathIO, "EOF")),
+			),
+			If(Err().Op("!=").Nil()).Block(
+				closeBody.Clone(),
+				Return(Err()),
+			),
+		),
+	)
+	return s
+}
+
+// Render decoding of error, which is sent after messages of stream.
+//
+//		func decodeStreamError(text string) error {
+//			e := transport.Error{Message: text}
+//			json.Unmarshal([]byte(text), &e)
+//			return transport.DecodeError(&e)
+//		}
+//
+func (t *httpStreamTemplate) decodeStreamError() *Statement {
+	name := t.info.nsPrivateName("decodeStreamError")
+	return Comment(name + " returns error, which is sent after messages of stream.").
+		Line().Func().Id(name).Params(Id("text").String()).Error().BlockFunc(func(g *Group) {
+		if HasErrorsTag(t.info.Iface.Docs) {
+			g.Id("e").Op(":=").Add(t.transport("Error")).Values(Dict{Id("Message"): Id("text")})
+			g.Qual(PackagePathJson, "Unmarshal").Call(Index().Byte().Parens(Id("text")), Op("&").Id("e"))
+			g.Return(t.transport("DecodeError").Call(Op("&").Id("e")))
+			return
+		}
+		g.Return(Qual(PackagePathErrors, "New").Call(Id("text")))
+	})
+}
+
+// Render client method of one-to-many method.
+//
+//		func (c *HTTPStreamClient) Count(ctx context.Context, text string, symbol string) (pb.StringService_CountClient, error) {
+//			r, err := http.NewRequestWithContext(ctx, "POST", c.u.String(), nil)
+//			if err != nil {
+//				return nil, err
+//			}
+//			err = EncodeHTTPCountRequest(ctx, r, &transport.CountRequest{
+//				Symbol: symbol,
+//				Text:   text,
+//			})
+//			if err != nil {
+//				return nil, err
+//			}
+//			r.Header.Set("Accept", "application/x-ndjson")
+//			resp, err := c.client.Do(r)
+//			if err != nil {
+//				return nil, err
+//			}
+//			if resp.StatusCode >= http.StatusBadRequest {
+//				defer resp.Body.Close()
+//				return nil, HTTPErrorDecoder(resp)
+//			}
+//			return countHTTPClientStream{newHTTPClientStream(ctx, resp)}, nil
+//		}
+//
+func (t *httpStreamTemplate) oneToManyClientMethod(ctx context.Context, fn *types.Function) *Statement {
+	args := HTTPRequestArgs(fn)
+	return t.clientMethod(ctx, fn, args).BlockFunc(func(g *Group) {
+		g.List(Id("r"), Err()).Op(":=").Qual(PackagePathHttp, "NewRequestWithContext").Call(
+			Id("ctx"), Lit(FetchHttpMethodTag(fn.Docs)), Id("c").Dot("u").Dot("String").Call(), Nil(),
+		)
+		g.If(Err().Op("!=").Nil()).Block(Return(Nil(), Err()))
+		g.Err().Op("=").Id(t.info.encodeRequestName(fn)).Call(
+			Id("ctx"), Id("r"),
+			Op("&").Add(Qual(t.info.OutputPackageImport+"/transport", t.info.requestStructName(fn))).Values(dictByVariables(args)),
+		)
+		g.If(Err().Op("!=").Nil()).Block(Return(Nil(), Err()))
+		g.Id("r").Dot("Header").Dot("Set").Call(Lit("Accept"), Lit("application/x-ndjson"))
+		g.List(Id("resp"), Err()).Op(":=").Id("c").Dot("client").Dot("Do").Call(Id("r"))
+		g.If(Err().Op("!=").Nil()).Block(Return(Nil(), Err()))
+		g.If(Id("resp").Dot("StatusCode").Op(">=").Qual(PackagePathHttp, "StatusBadRequest")).BlockFunc(func(g *Group) {
+			g.Defer().Id("resp").Dot("Body").Dot("Close").Call()
+			t.responseError(g)
+		})
+		g.Return(
+			Id(t.info.nsPrivateName(mstrings.ToLowerFirst(fn.Name)+"HTTPClientStream")).Values(
+				Id(t.constructor(httpClientStreamName)).Call(Id("ctx"), Id("resp")),
+			),
+			Nil(),
+		)
+	})
+}
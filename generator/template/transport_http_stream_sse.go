@@ -0,0 +1,44 @@
+package template
+
+import (
+	. "github.com/dave/jennifer/jen"
+)
+
+// One-to-many methods send messages as server-sent events to clients, which accept text/event-stream, e.g. EventSource of browsers.
+// Every message is an event with JSON data, error after the first message is an event of type error.
+
+// Renders check of request, which accepts server-sent events.
+//
+//		strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+//
+func sseAccepted() *Statement {
+	return Qual(PackagePathStrings, "Contains").Call(Id("r").Dot("Header").Dot("Get").Call(Lit("Accept")), Lit("text/event-stream"))
+}
+
+// Renders headers of server-sent events.
+//
+//		s.w.Header().Set("Content-Type", "text/event-stream")
+//		s.w.Header().Set("Cache-Control", "no-cache")
+//
+func sseHeaders() []Code {
+	return []Code{
+		httpServerStreamHeader().Dot("Set").Call(Lit("Content-Type"), Lit("text/event-stream")),
+		httpServerStreamHeader().Dot("Set").Call(Lit("Cache-Control"), Lit("no-cache")),
+	}
+}
+
+// Renders writing of message as event.
+//
+//		_, err = fmt.Fprintf(s.w, "data: %s\n\n", data)
+//
+func sseWriteMessage() *Statement {
+	return List(Id("_"), Err()).Op("=").Qual(PackagePathFmt, "Fprintf").Call(Id("s").Dot("w"), Lit("data: %s\n\n"), Id("data"))
+}
+
+// Renders writing of error after messages as event of type error.
+//
+//		fmt.Fprintf(s.w, "event: error\ndata: %s\n\n", encodeStreamError(err))
+//
+func (t *httpStreamTemplate) sseWriteError() *Statement {
+	return Qual(PackagePathFmt, "Fprintf").Call(Id("s").Dot("w"), Lit("event: error\ndata: %s\n\n"), Id(t.info.nsPrivateName("encodeStreamError")).Call(Err()))
+}
@@ -0,0 +1,78 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetcher/go-astra"
+)
+
+const httpStreamTestSource = `package svc
+
+import "example.com/svc/pb"
+
+type StreamService interface {
+	// @microgen one-to-many
+	// @http-method GET
+	// @http-query text
+	Count(text string, stream pb.StreamService_CountServer) (err error)
+	// @microgen many-to-many
+	Chat(stream pb.StreamService_ChatServer) (err error)
+	// @microgen many-to-one
+	Sum(stream pb.StreamService_SumServer) (err error)
+}
+`
+
+func TestHTTPStream(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "svc.go")
+	if err := ioutil.WriteFile(source, []byte(httpStreamTestSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := astra.ParseFile(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iface := &file.Interfaces[0]
+	info := &GenerationInfo{
+		Iface:                   iface,
+		SourceFilePath:          source,
+		SourcePackageImport:     "example.com/svc",
+		OutputPackageImport:     "example.com/svc",
+		ProtobufPackageImport:   "example.com/svc/pb",
+		AllowedMethods:          map[string]bool{"Count": true, "Chat": true, "Sum": true},
+		OneToManyStreamMethods:  map[string]bool{"Count": true},
+		ManyToManyStreamMethods: map[string]bool{"Chat": true},
+		ManyToOneStreamMethods:  map[string]bool{"Sum": true},
+	}
+	assert.True(t, HasStreamMethods(info))
+	count := iface.Methods[0]
+	if args := HTTPRequestArgs(count); assert.Len(t, args, 1) {
+		assert.Equal(t, "text", args[0].Name)
+	}
+	assert.Empty(t, HTTPRequestArgs(iface.Methods[1]))
+	assert.Equal(t, "count", buildMethodPath(count))
+	assert.NoError(t, ValidateHTTPBinding(count))
+
+	ctx := WithTags(context.Background(), TagsSet{HttpTag: {}})
+	tmpl := NewHttpStreamTemplate(info)
+	if err := tmpl.Prepare(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Render(ctx).Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	code := buf.String()
+	assert.Contains(t, code, "request, err := _Decode_Count_Request(r.Context(), r)")
+	assert.Contains(t, code, "func (s sumHTTPServerStream) SendAndClose(m *pb.SumResponse) error")
+	assert.Contains(t, code, "func (s chatHTTPServerStream) Recv() (*pb.ChatRequest, error)")
+	assert.Contains(t, code, "func (c *HTTPStreamClient) Count(ctx context.Context, text string) (pb.StreamService_CountClient, error)")
+	assert.Contains(t, code, `http.NewRequestWithContext(ctx, "GET", c.u.String(), nil)`)
+	assert.Contains(t, code, "func (s sumHTTPClientStream) CloseAndRecv() (*pb.SumResponse, error)")
+	assert.Contains(t, code, "httpkit.DefaultErrorEncoder(s.ctx, err, s.w)")
+	assert.NotContains(t, code, "wsErrorCodeBase")
+}
@@ -0,0 +1,368 @@
+package template
+
+import (
+	"context"
+
+	. "github.com/dave/jennifer/jen"
+	mstrings "github.com/recolabs/microgen/generator/strings"
+	"github.com/vetcher/go-astra/types"
+)
+
+// Many-to-one and many-to-many methods exchange JSON messages over WebSocket.
+// Result of endpoint is sent in close frame of server.
+
+const (
+	wsServerStreamName = "wsServerStream"
+	wsClientStreamName = "wsClientStream"
+	webSocketUpgrader  = "WebSocketUpgrader"
+
+	// Errors of services with @errors tag close WebSocket with code 4000 + HTTP status.
+	wsErrorCodeBase = 4000
+	// Reason of WebSocket close frame is limited to 123 bytes.
+	wsMaxCloseReason = 123
+)
+
+// Render server stream of many-to-one and many-to-many methods.
+//
+//		// WebSocketUpgrader upgrades requests of many-to-one and many-to-many stream methods to WebSocket.
+//		// It rejects cross-origin requests by default, set CheckOrigin to allow browsers from other origins.
+//		var WebSocketUpgrader = websocket.Upgrader{}
+//
+//		// wsServerStream receives and sends messages of stream as JSON messages of WebSocket.
+//		type wsServerStream struct {
+//			ctx  context.Context
+//			conn *websocket.Conn
+//		}
+//
+func (t *httpStreamTemplate) wsServerStream() *Statement {
+	name := t.info.nsPrivateName(wsServerStreamName)
+	upgrader := t.info.nsName(webSocketUpgrader)
+	recv := func() *Statement { return Params(Id("s").Op("*").Id(name)) }
+	s := &Statement{}
+	s.Comment(upgrader+" upgrades requests of many-to-one and many-to-many stream methods to WebSocket.").
+		Line().Comment("It rejects cross-origin requests by default, set CheckOrigin to allow browsers from other origins.").
+		Line().Var().Id(upgrader).Op("=").Qual(PackagePathGorillaWebsocket, "Upgrader").Values().Line()
+
+	s.Line().Comment(name+" receives and sends messages of stream as JSON messages of WebSocket.").
+		Line().Type().Id(name).Struct(
+		Id("ctx").Qual(PackagePathContext, "Context"),
+		Id("conn").Op("*").Qual(PackagePathGorillaWebsocket, "Conn"),
+	).Line()
+
+	s.Line().Func().Id(t.constructor(wsServerStreamName)).Params(
+		Id("ctx").Qual(PackagePathContext, "Context"),
+		Id("conn").Op("*").Qual(PackagePathGorillaWebsocket, "Conn"),
+	).Op("*").Id(name).Block(
+		Comment("Close frame of client is not echoed, stream is closed by server with result of endpoint."),
+		Id("conn").Dot("SetCloseHandler").Call(Func().Params(Int(), String()).Error().Block(Return(Nil()))),
+		Return(Op("&").Id(name).Values(Dict{
+			Id("ctx"):  Id("ctx"),
+			Id("conn"): Id("conn"),
+		})),
+	).Line()
+
+	s.Line().Func().Add(recv()).Id("Context").Params().Qual(PackagePathContext, "Context").Block(
+		Return(Id("s").Dot("ctx")),
+	).Line()
+
+	s.Line().Comment("SetHeader does nothing, because headers are sent by upgrade, before endpoint is called.").
+		Line().Func().Add(recv()).Id("SetHeader").Params(Qual(PackagePathGoogleGRPCMetadata, "MD")).Error().Block(
+		Return(Nil()),
+	).Line()
+
+	s.Line().Comment("SendHeader does nothing, because headers are sent by upgrade, before endpoint is called.").
+		Line().Func().Add(recv()).Id("SendHeader").Params(Qual(PackagePathGoogleGRPCMetadata, "MD")).Error().Block(
+		Return(Nil()),
+	).Line()
+
+	s.Line().Comment("SetTrailer does nothing, because WebSocket has no trailers.").
+		Line().Func().Add(recv()).Id("SetTrailer").Params(Qual(PackagePathGoogleGRPCMetadata, "MD")).Block().Line()
+
+	s.Line().Func().Add(recv()).Id("SendMsg").Params(Id("m").Interface()).Error().Block(
+		Return(Id("s").Dot("conn").Dot("WriteJSON").Call(Id("m"))),
+	).Line()
+
+	s.Line().Func().Add(recv()).Id("RecvMsg").Params(Id("m").Interface()).Error().Block(
+		Err().Op(":=").Id("s").Dot("conn").Dot("ReadJSON").Call(Id("m")),
+		If(Qual(PackagePathGorillaWebsocket, "IsCloseError").Call(
+			Err(),
+			Qual(PackagePathGorillaWebsocket, "CloseNormalClosure"),
+			Qual(PackagePathGorillaWebsocket, "CloseNoStatusReceived"),
+		)).Block(
+			Return(Qual(PackagePathIO, "EOF")),
+		),
+		Return(Err()),
+	).Line()
+
+	s.Line().Comment("close finishes stream with close frame, which contains result of endpoint.").
+		Line().Func().Add(recv()).Id("close").Params(Err().Error()).Block(
+		Id("s").Dot("conn").Dot("WriteControl").Call(
+			Qual(PackagePathGorillaWebsocket, "CloseMessage"),
+			Id(t.info.nsPrivateName("wsCloseMessage")).Call(Err()),
+			Qual(PackagePathTime, "Now").Call().Dot("Add").Call(Qual(PackagePathTime, "Second")),
+		),
+		Id("s").Dot("conn").Dot("Close").Call(),
+	)
+	return s
+}
+
+// Render close frame of server with result of endpoint.
+//
+//		// wsCloseMessage returns close frame with result of endpoint.
+//		// Reason of close frame is limited to 123 bytes, so details of errors are not sent and message may be truncated.
+//		func wsCloseMessage(err error) []byte {
+//			if err == nil {
+//				return websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+//			}
+//			e := transport.EncodeError(err)
+//			reason := transport.Error{
+//				Message: e.Message,
+//				Name:    e.Name,
+//			}
+//			data, _ := json.Marshal(reason)
+//			for len(data) > 123 && reason.Message != "" {
+//				cut := len(data) - 123
+//				if cut > len(reason.Message) {
+//					cut = len(reason.Message)
+//				}
+//				reason.Message = reason.Message[:len(reason.Message)-cut]
+//				data, _ = json.Marshal(reason)
+//			}
+//			return websocket.FormatCloseMessage(wsErrorCodeBase+e.Status, string(data))
+//		}
+//
+func (t *httpStreamTemplate) wsCloseMessage() *Statement {
+	name := t.info.nsPrivateName("wsCloseMessage")
+	s := Comment(name + " returns close frame with result of endpoint.")
+	if HasErrorsTag(t.info.Iface.Docs) {
+		s.Line().Comment("Reason of close frame is limited to 123 bytes, so details of errors are not sent and message may be truncated.")
+	} else {
+		s.Line().Comment("Reason of close frame is limited to 123 bytes, so message of error may be truncated.")
+	}
+	s.Line().Func().Id(name).Params(Err().Error()).Index().Byte().BlockFunc(func(g *Group) {
+		g.If(Err().Op("==").Nil()).Block(
+			Return(Qual(PackagePathGorillaWebsocket, "FormatCloseMessage").Call(Qual(PackagePathGorillaWebsocket, "CloseNormalClosure"), Lit(""))),
+		)
+		if !HasErrorsTag(t.info.Iface.Docs) {
+			g.Id("reason").Op(":=").Err().Dot("Error").Call()
+			g.If(Len(Id("reason")).Op(">").Lit(wsMaxCloseReason)).Block(
+				Id("reason").Op("=").Id("reason").Index(Empty(), Lit(wsMaxCloseReason)),
+			)
+			g.Return(Qual(PackagePathGorillaWebsocket, "FormatCloseMessage").Call(Qual(PackagePathGorillaWebsocket, "CloseInternalServerErr"), Id("reason")))
+			return
+		}
+		message := Id("reason").Dot("Message")
+		g.Id("e").Op(":=").Add(t.transport("EncodeError")).Call(Err())
+		g.Id("reason").Op(":=").Add(t.transport("Error")).Values(Dict{
+			Id("Name"):    Id("e").Dot("Name"),
+			Id("Message"): Id("e").Dot("Message"),
+		})
+		g.List(Id("data"), Id("_")).Op(":=").Qual(PackagePathJson, "Marshal").Call(Id("reason"))
+		g.For(Len(Id("data")).Op(">").Lit(wsMaxCloseReason).Op("&&").Add(message).Op("!=").Lit("")).Block(
+			Id("cut").Op(":=").Len(Id("data")).Op("-").Lit(wsMaxCloseReason),
+			If(Id("cut").Op(">").Len(message)).Block(
+				Id("cut").Op("=").Len(message),
+			),
+			message.Clone().Op("=").Add(message).Index(Empty(), Len(message).Op("-").Id("cut")),
+			List(Id("data"), Id("_")).Op("=").Qual(PackagePathJson, "Marshal").Call(Id("reason")),
+		)
+		g.Return(Qual(PackagePathGorillaWebsocket, "FormatCloseMessage").Call(
+			Id(t.info.nsPrivateName("wsErrorCodeBase")).Op("+").Id("e").Dot("Status"),
+			String().Call(Id("data")),
+		))
+	})
+	return s
+}
+
+// Render handler of many-to-one or many-to-many method.
+//
+//		func chatHTTPHandler(endpoint transport.ManyToManyStreamEndpoint) http.Handler {
+//			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//				conn, err := WebSocketUpgrader.Upgrade(w, r, nil)
+//				if err != nil {
+//					// Upgrader has already replied with error status.
+//					return
+//				}
+//				stream := newWSServerStream(r.Context(), conn)
+//				stream.close(endpoint(chatHTTPServerStream{stream}))
+//			})
+//		}
+//
+func (t *httpStreamTemplate) webSocketHandler(fn *types.Function) *Statement {
+	endpoint := ManyToManyStreamEndpoint
+	if t.info.ManyToOneStreamMethods[fn.Name] {
+		endpoint = ManyToOneStreamEndpoint
+	}
+	return Func().Id(t.info.httpStreamHandlerName(fn)).Params(Id("endpoint").Add(t.transport(endpoint))).Qual(PackagePathHttp, "Handler").Block(
+		Return(Qual(PackagePathHttp, "HandlerFunc").Call(Func().Params(
+			Id("w").Qual(PackagePathHttp, "ResponseWriter"),
+			Id("r").Op("*").Qual(PackagePathHttp, "Request"),
+		).Block(
+			List(Id("conn"), Err()).Op(":=").Id(t.info.nsName(webSocketUpgrader)).Dot("Upgrade").Call(Id("w"), Id("r"), Nil()),
+			If(Err().Op("!=").Nil()).Block(
+				Comment("Upgrader has already replied with error status."),
+				Return(),
+			),
+			Id("stream").Op(":=").Id(t.constructor(wsServerStreamName)).Call(Id("r").Dot("Context").Call(), Id("conn")),
+			Id("stream").Dot("close").Call(Id("endpoint").Call(
+				Id(t.info.nsPrivateName(mstrings.ToLowerFirst(fn.Name)+"HTTPServerStream")).Values(Id("stream")),
+			)),
+		))),
+	)
+}
+
+// Render client stream of many-to-one and many-to-many methods.
+//
+//		// wsClientStream sends and receives messages of stream as JSON messages of WebSocket.
+//		type wsClientStream struct {
+//			ctx    context.Context
+//			conn   *websocket.Conn
+//			header http.Header
+//		}
+//
+func (t *httpStreamTemplate) wsClientStream() *Statement {
+	name := t.info.nsPrivateName(wsClientStreamName)
+	recv := func() *Statement { return Params(Id("s").Op("*").Id(name)) }
+	s := &Statement{}
+	s.Comment(name+" sends and receives messages of stream as JSON messages of WebSocket.").
+		Line().Type().Id(name).Struct(
+		Id("ctx").Qual(PackagePathContext, "Context"),
+		Id("conn").Op("*").Qual(PackagePathGorillaWebsocket, "Conn"),
+		Id("header").Qual(PackagePathHttp, "Header"),
+	).Line()
+
+	s.Line().Func().Id(t.constructor(wsClientStreamName)).Params(
+		Id("ctx").Qual(PackagePathContext, "Context"),
+		Id("conn").Op("*").Qual(PackagePathGorillaWebsocket, "Conn"),
+		Id("resp").Op("*").Qual(PackagePathHttp, "Response"),
+	).Op("*").Id(name).Block(
+		Comment("Close frame of server is not echoed, connection is closed, when it is received."),
+		Id("conn").Dot("SetCloseHandler").Call(Func().Params(Int(), String()).Error().Block(Return(Nil()))),
+		Return(Op("&").Id(name).Values(Dict{
+			Id("ctx"):    Id("ctx"),
+			Id("conn"):   Id("conn"),
+			Id("header"): Id("resp").Dot("Header"),
+		})),
+	).Line()
+
+	s.Line().Func().Add(recv()).Id("Header").Params().Params(Qual(PackagePathGoogleGRPCMetadata, "MD"), Error()).Block(
+		Return(Id(t.info.nsPrivateName("headerMetadata")).Call(Id("s").Dot("header")), Nil()),
+	).Line()
+
+	s.Line().Comment("Trailer returns nil, because WebSocket has no trailers.").
+		Line().Func().Add(recv()).Id("Trailer").Params().Qual(PackagePathGoogleGRPCMetadata, "MD").Block(
+		Return(Nil()),
+	).Line()
+
+	s.Line().Func().Add(recv()).Id("CloseSend").Params().Error().Block(
+		Return(Id("s").Dot("conn").Dot("WriteControl").Call(
+			Qual(PackagePathGorillaWebsocket, "CloseMessage"),
+			Qual(PackagePathGorillaWebsocket, "FormatCloseMessage").Call(Qual(PackagePathGorillaWebsocket, "CloseNormalClosure"), Lit("")),
+			Qual(PackagePathTime, "Now").Call().Dot("Add").Call(Qual(PackagePathTime, "Second")),
+		)),
+	).Line()
+
+	s.Line().Func().Add(recv()).Id("Context").Params().Qual(PackagePathContext, "Context").Block(
+		Return(Id("s").Dot("ctx")),
+	).Line()
+
+	s.Line().Func().Add(recv()).Id("SendMsg").Params(Id("m").Interface()).Error().Block(
+		Return(Id("s").Dot("conn").Dot("WriteJSON").Call(Id("m"))),
+	).Line()
+
+	s.Line().Func().Add(recv()).Id("RecvMsg").Params(Id("m").Interface()).Error().Block(
+		Err().Op(":=").Id("s").Dot("conn").Dot("ReadJSON").Call(Id("m")),
+		If(List(Id("ce"), Id("ok")).Op(":=").Err().Assert(Op("*").Qual(PackagePathGorillaWebsocket, "CloseError")), Id("ok")).Block(
+			Id("s").Dot("conn").Dot("Close").Call(),
+			Return(Id(t.info.nsPrivateName("wsCloseError")).Call(Id("ce"))),
+		),
+		Return(Err()),
+	)
+	return s
+}
+
+// Render error of stream from close frame of server.
+//
+//		// wsCloseError returns error of stream from close frame of server.
+//		func wsCloseError(ce *websocket.CloseError) error {
+//			switch {
+//			case ce.Code == websocket.CloseNormalClosure:
+//				return io.EOF
+//			case ce.Code >= wsErrorCodeBase+400 && ce.Code < wsErrorCodeBase+600:
+//				e := transport.Error{
+//					Message: ce.Text,
+//					Status:  ce.Code - wsErrorCodeBase,
+//				}
+//				json.Unmarshal([]byte(ce.Text), &e)
+//				return transport.DecodeError(&e)
+//			case ce.Text != "":
+//				return errors.New(ce.Text)
+//			}
+//			return ce
+//		}
+//
+func (t *httpStreamTemplate) wsCloseError() *Statement {
+	name := t.info.nsPrivateName("wsCloseError")
+	base := t.info.nsPrivateName("wsErrorCodeBase")
+	return Comment(name+" returns error of stream from close frame of server.").
+		Line().Func().Id(name).Params(Id("ce").Op("*").Qual(PackagePathGorillaWebsocket, "CloseError")).Error().Block(
+		Switch().BlockFunc(func(g *Group) {
+			g.Case(Id("ce").Dot("Code").Op("==").Qual(PackagePathGorillaWebsocket, "CloseNormalClosure")).Block(
+				Return(Qual(PackagePathIO, "EOF")),
+			)
+			if HasErrorsTag(t.info.Iface.Docs) {
+				g.Case(Id("ce").Dot("Code").Op(">=").Id(base).Op("+").Lit(400).Op("&&").Id("ce").Dot("Code").Op("<").Id(base).Op("+").Lit(600)).Block(
+					Id("e").Op(":=").Add(t.transport("Error")).Values(Dict{
+						Id("Message"): Id("ce").Dot("Text"),
+						Id("Status"):  Id("ce").Dot("Code").Op("-").Id(base),
+					}),
+					Qual(PackagePathJson, "Unmarshal").Call(Index().Byte().Parens(Id("ce").Dot("Text")), Op("&").Id("e")),
+					Return(t.transport("DecodeError").Call(Op("&").Id("e"))),
+				)
+			}
+			g.Case(Id("ce").Dot("Text").Op("!=").Lit("")).Block(
+				Return(Qual(PackagePathErrors, "New").Call(Id("ce").Dot("Text"))),
+			)
+		}),
+		Return(Id("ce")),
+	)
+}
+
+// Render client method of many-to-one or many-to-many method.
+//
+//		func (c *HTTPStreamClient) Chat(ctx context.Context) (pb.StringService_ChatClient, error) {
+//			u := *c.u
+//			u.Scheme = strings.Replace(u.Scheme, "http", "ws", 1)
+//			u.Path = path.Join(u.Path, "chat")
+//			conn, resp, err := c.dialer.DialContext(ctx, u.String(), nil)
+//			if err != nil {
+//				if resp != nil && resp.StatusCode >= http.StatusBadRequest {
+//					return nil, HTTPErrorDecoder(resp)
+//				}
+//				return nil, err
+//			}
+//			return chatHTTPClientStream{newWSClientStream(ctx, conn, resp)}, nil
+//		}
+//
+func (t *httpStreamTemplate) webSocketClientMethod(ctx context.Context, fn *types.Function) *Statement {
+	return t.clientMethod(ctx, fn, nil).BlockFunc(func(g *Group) {
+		g.Id("u").Op(":=").Op("*").Id("c").Dot("u")
+		g.Id("u").Dot("Scheme").Op("=").Qual(PackagePathStrings, "Replace").Call(Id("u").Dot("Scheme"), Lit("http"), Lit("ws"), Lit(1))
+		g.Id("u").Dot("Path").Op("=").Qual(PackagePathPath, "Join").Call(Id("u").Dot("Path"), Lit(buildMethodPath(fn)))
+		g.List(Id("conn"), Id("resp"), Err()).Op(":=").Id("c").Dot("dialer").Dot("DialContext").Call(Id("ctx"), Id("u").Dot("String").Call(), Nil())
+		g.If(Err().Op("!=").Nil()).BlockFunc(func(g *Group) {
+			if HasErrorsTag(t.info.Iface.Docs) {
+				g.If(Id("resp").Op("!=").Nil().Op("&&").Id("resp").Dot("StatusCode").Op(">=").Qual(PackagePathHttp, "StatusBadRequest")).Block(
+					Return(Nil(), Id(t.info.nsName(httpErrorDecoderName)).Call(Id("resp"))),
+				)
+			}
+			g.Return(Nil(), Err())
+		})
+		g.Return(
+			Id(t.info.nsPrivateName(mstrings.ToLowerFirst(fn.Name)+"HTTPClientStream")).Values(
+				Id(t.constructor(wsClientStreamName)).Call(Id("ctx"), Id("conn"), Id("resp")),
+			),
+			Nil(),
+		)
+	})
+}
@@ -0,0 +1,167 @@
+package template
+
+import (
+	"context"
+
+	. "github.com/dave/jennifer/jen"
+	"github.com/recolabs/microgen/generator/write_strategy"
+)
+
+type jsonrpcClientTemplate struct {
+	info *GenerationInfo
+}
+
+func NewJSONRPCClientTemplate(info *GenerationInfo) Template {
+	return &jsonrpcClientTemplate{
+		info: info,
+	}
+}
+
+func (t *jsonrpcClientTemplate) DefaultPath() string {
+	return filenameBuilder(PathTransport, "jsonrpc", t.info.nsFile("client"))
+}
+
+func (t *jsonrpcClientTemplate) ChooseStrategy(ctx context.Context) (write_strategy.Strategy, error) {
+	return write_strategy.NewCreateFileStrategy(t.info.OutputFilePath, t.DefaultPath()), nil
+}
+
+func (t *jsonrpcClientTemplate) Prepare(ctx context.Context) error {
+	return nil
+}
+
+// Render json-rpc client.
+//
+//		// Code generated by microgen. DO NOT EDIT.
+//
+//		package transportjsonrpc
+//
+//		import (
+//			jsonrpc "github.com/go-kit/kit/transport/http/jsonrpc"
+//			transport "github.com/recolabs/microgen/examples/generated/transport"
+//			url "net/url"
+//		)
+//
+//		func NewJSONRPCClient(u *url.URL, opts ...jsonrpc.ClientOption) transport.EndpointsSet {
+//			return transport.EndpointsSet{
+//				CountEndpoint: jsonrpc.NewClient(
+//					u, "v1.count",
+//					append(opts,
+//						jsonrpc.ClientRequestEncoder(_Encode_Count_Request),
+//						jsonrpc.ClientResponseDecoder(_Decode_Count_Response),
+//					)...,
+//				).Endpoint(),
+//			}
+//		}
+//
+func (t *jsonrpcClientTemplate) Render(ctx context.Context) write_strategy.Renderer {
+	f := NewFile("transportjsonrpc")
+	f.ImportAlias(t.info.SourcePackageImport, serviceAlias)
+	f.HeaderComment(t.info.FileHeader)
+
+	if wrapsClientEndpoints(ctx) {
+		f.Add(t.wrappedClient(ctx))
+	} else {
+		f.Func().Id(t.info.nsNewName("JSONRPCClient")).ParamsFunc(func(p *Group) {
+			p.Id("u").Op("*").Qual(PackagePathUrl, "URL")
+			p.Id("opts").Op("...").Qual(PackagePathGoKitTransportJSONRPC, "ClientOption")
+		}).Params(
+			Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName()),
+		).Block(
+			t.clientBody(),
+		)
+	}
+
+	if Tags(ctx).Has(TracingMiddlewareTag) {
+		f.Line().Func().Id(t.info.nsName("TracingJSONRPCClientOptions")).Params(
+			Id("tracer").Qual(PackagePathOpenTracingGo, "Tracer"),
+			Id("logger").Qual(PackagePathGoKitLog, "Logger"),
+		).Params(
+			Func().Params(Op("[]").Qual(PackagePathGoKitTransportJSONRPC, "ClientOption")).Params(Op("[]").Qual(PackagePathGoKitTransportJSONRPC, "ClientOption")),
+		).Block(
+			Return().Func().Params(Id("opts").Op("[]").Qual(PackagePathGoKitTransportJSONRPC, "ClientOption")).Params(Op("[]").Qual(PackagePathGoKitTransportJSONRPC, "ClientOption")).Block(
+				Return().Append(Id("opts"), Qual(PackagePathGoKitTransportJSONRPC, "ClientBefore").Call(
+					Line().Qual(PackagePathGoKitTracing, "ContextToHTTP").Call(Id("tracer"), Id("logger")).Op(",").Line(),
+				)),
+			),
+		)
+	}
+	if Tags(ctx).Has(OpenTelemetryTag) {
+		f.Line().Func().Id(t.info.nsName("TracingJSONRPCClientOptions")).Params(
+			Id("propagator").Qual(PackagePathOTelPropagation, "TextMapPropagator"),
+		).Params(
+			Func().Params(Op("[]").Qual(PackagePathGoKitTransportJSONRPC, "ClientOption")).Params(Op("[]").Qual(PackagePathGoKitTransportJSONRPC, "ClientOption")),
+		).Block(
+			Return().Func().Params(Id("opts").Op("[]").Qual(PackagePathGoKitTransportJSONRPC, "ClientOption")).Params(Op("[]").Qual(PackagePathGoKitTransportJSONRPC, "ClientOption")).Block(
+				Return().Append(Id("opts"), Qual(PackagePathGoKitTransportJSONRPC, "ClientBefore").Call(
+					Line().Id(t.info.nsPrivateName("injectTraceContext")).Call(Id("propagator")).Op(",").Line(),
+				)),
+			),
+		)
+		f.Line().Add(httpInjectTraceContext(t.info.nsPrivateName("injectTraceContext")))
+	}
+
+	return f
+}
+
+// Renders body of client.
+//
+//		return transport.EndpointsSet{CountEndpoint: jsonrpc.NewClient(...).Endpoint()}
+//
+func (t *jsonrpcClientTemplate) clientBody() *Statement {
+	return Return(Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName()).Values(DictFunc(func(d Dict) {
+		for _, fn := range t.info.Iface.Methods {
+			if !t.info.AllowedMethods[fn.Name] ||
+				t.info.ManyToManyStreamMethods[fn.Name] ||
+				t.info.ManyToOneStreamMethods[fn.Name] ||
+				t.info.OneToManyStreamMethods[fn.Name] {
+				continue
+			}
+			d[Id(endpointsStructFieldName(fn.Name))] = Qual(PackagePathGoKitTransportJSONRPC, "NewClient").Call(
+				Line().Id("u"), Lit(jsonrpcMethodName(fn)),
+				Line().Append(
+					Id("opts"),
+					Line().Qual(PackagePathGoKitTransportJSONRPC, "ClientRequestEncoder").Call(Id(t.info.encodeRequestName(fn))),
+					Line().Qual(PackagePathGoKitTransportJSONRPC, "ClientResponseDecoder").Call(Id(t.info.decodeResponseName(fn))),
+					Line(),
+				).Op("...").Line(),
+			).Dot("Endpoint").Call()
+		}
+	})))
+}
+
+// Renders client, which wraps endpoints with circuit breakers and retries.
+//
+//		// NewJSONRPCClient creates endpoints of StringService, which call server at u.
+//		// Endpoints are wrapped with circuit breakers, which breaker returns for name of method.
+//		// Failed calls are retried by policies of @retry tags.
+//		func NewJSONRPCClient(u *url.URL, breaker func(method string) endpoint.Middleware, opts ...jsonrpc.ClientOption) transport.EndpointsSet {
+//			return transport.RetryClientEndpoints(transport.CircuitBreakerClientEndpoints(jsonrpcClientEndpoints(u, opts...), breaker))
+//		}
+//
+//		func jsonrpcClientEndpoints(u *url.URL, opts ...jsonrpc.ClientOption) transport.EndpointsSet {
+//			return transport.EndpointsSet{...}
+//		}
+//
+func (t *jsonrpcClientTemplate) wrappedClient(ctx context.Context) *Statement {
+	endpoints := t.info.nsPrivateName("jsonrpcClientEndpoints")
+	return Comment(t.info.nsNewName("JSONRPCClient")+" creates endpoints of "+t.info.Iface.Name+", which call server at u.").
+		Line().Add(wrappedClientComment(ctx, false)).
+		Func().Id(t.info.nsNewName("JSONRPCClient")).Params(append(append(
+		[]Code{Id("u").Op("*").Qual(PackagePathUrl, "URL")},
+		breakerClientParams(ctx)...),
+		Id("opts").Op("...").Qual(PackagePathGoKitTransportJSONRPC, "ClientOption"),
+	)...).Params(
+		Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName()),
+	).Block(
+		Return(retryClientEndpoints(ctx, t.info, breakerClientEndpoints(ctx, t.info, Id(endpoints).Call(Id("u"), Id("opts").Op("..."))))),
+	).
+		Line().
+		Line().Func().Id(endpoints).Params(
+		Id("u").Op("*").Qual(PackagePathUrl, "URL"),
+		Id("opts").Op("...").Qual(PackagePathGoKitTransportJSONRPC, "ClientOption"),
+	).Params(
+		Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName()),
+	).Block(
+		t.clientBody(),
+	)
+}
@@ -0,0 +1,132 @@
+package template
+
+import (
+	"context"
+
+	. "github.com/dave/jennifer/jen"
+	mstrings "github.com/recolabs/microgen/generator/strings"
+	"github.com/recolabs/microgen/generator/write_strategy"
+	"github.com/vetcher/go-astra/types"
+)
+
+const (
+	JSONRPCMethodPrefixTag = "json-rpc-prefix"
+)
+
+type jsonrpcServerTemplate struct {
+	info *GenerationInfo
+}
+
+func NewJSONRPCServerTemplate(info *GenerationInfo) Template {
+	return &jsonrpcServerTemplate{
+		info: info,
+	}
+}
+
+func (t *jsonrpcServerTemplate) DefaultPath() string {
+	return filenameBuilder(PathTransport, "jsonrpc", t.info.nsFile("server"))
+}
+
+func (t *jsonrpcServerTemplate) ChooseStrategy(ctx context.Context) (write_strategy.Strategy, error) {
+	return write_strategy.NewCreateFileStrategy(t.info.OutputFilePath, t.DefaultPath()), nil
+}
+
+func (t *jsonrpcServerTemplate) Prepare(ctx context.Context) error {
+	return nil
+}
+
+// Name of json-rpc method, that used by server and client.
+//
+//		// @json-rpc-prefix v1.
+//		Count(...) -> v1.count
+//
+func jsonrpcMethodName(fn *types.Function) string {
+	return mstrings.FetchMetaInfo(TagMark+JSONRPCMethodPrefixTag, fn.Docs) + mstrings.ToLowerFirst(fn.Name)
+}
+
+// Render json-rpc server constructor.
+//
+//		// Code generated by microgen. DO NOT EDIT.
+//
+//		package transportjsonrpc
+//
+//		import (
+//			jsonrpc "github.com/go-kit/kit/transport/http/jsonrpc"
+//			transport "github.com/recolabs/microgen/examples/generated/transport"
+//			http "net/http"
+//		)
+//
+//		func NewJSONRPCHandler(endpoints *transport.EndpointsSet, opts ...jsonrpc.ServerOption) http.Handler {
+//			return jsonrpc.NewServer(jsonrpc.EndpointCodecMap{
+//				"v1.count": jsonrpc.EndpointCodec{
+//					Decode:   _Decode_Count_Request,
+//					Encode:   _Encode_Count_Response,
+//					Endpoint: endpoints.CountEndpoint,
+//				},
+//			}, opts...)
+//		}
+//
+func (t *jsonrpcServerTemplate) Render(ctx context.Context) write_strategy.Renderer {
+	f := NewFile("transportjsonrpc")
+	f.ImportAlias(t.info.SourcePackageImport, serviceAlias)
+	f.HeaderComment(t.info.FileHeader)
+
+	f.Func().Id(t.info.nsNewName("JSONRPCHandler")).ParamsFunc(func(p *Group) {
+		p.Id("endpoints").Op("*").Qual(t.info.OutputPackageImport+"/transport", t.info.endpointsSetName())
+		if Tags(ctx).Has(TracingMiddlewareTag) {
+			p.Id("logger").Qual(PackagePathGoKitLog, "Logger")
+			p.Id("tracer").Qual(PackagePathOpenTracingGo, "Tracer")
+		}
+		if Tags(ctx).Has(OpenTelemetryTag) {
+			p.Id("propagator").Qual(PackagePathOTelPropagation, "TextMapPropagator")
+		}
+		p.Id("opts").Op("...").Qual(PackagePathGoKitTransportJSONRPC, "ServerOption")
+	}).Params(
+		Qual(PackagePathHttp, "Handler"),
+	).BlockFunc(func(g *Group) {
+		g.Return().Qual(PackagePathGoKitTransportJSONRPC, "NewServer").Call(
+			Line().Qual(PackagePathGoKitTransportJSONRPC, "EndpointCodecMap").Values(DictFunc(func(d Dict) {
+				for _, fn := range t.info.Iface.Methods {
+					if !t.info.AllowedMethods[fn.Name] ||
+						t.info.ManyToManyStreamMethods[fn.Name] ||
+						t.info.ManyToOneStreamMethods[fn.Name] ||
+						t.info.OneToManyStreamMethods[fn.Name] {
+						continue
+					}
+					d[Lit(jsonrpcMethodName(fn))] = Qual(PackagePathGoKitTransportJSONRPC, "EndpointCodec").Values(Dict{
+						Id("Endpoint"): Id("endpoints").Dot(endpointsStructFieldName(fn.Name)),
+						Id("Decode"):   Id(t.info.decodeRequestName(fn)),
+						Id("Encode"):   Id(t.info.encodeResponseName(fn)),
+					})
+				}
+			})),
+			Line().Add(t.serverOpts(ctx)).Op("..."),
+		)
+	})
+
+	if Tags(ctx).Has(OpenTelemetryTag) {
+		f.Line().Add(httpExtractTraceContext(t.info.nsPrivateName("extractTraceContext")))
+	}
+
+	return f
+}
+
+func (t *jsonrpcServerTemplate) serverOpts(ctx context.Context) *Statement {
+	s := &Statement{}
+	if Tags(ctx).HasAny(TracingMiddlewareTag, OpenTelemetryTag) {
+		s.Op("append(")
+		defer s.Op(")")
+	}
+	s.Id("opts")
+	if Tags(ctx).Has(TracingMiddlewareTag) {
+		s.Op(",").Qual(PackagePathGoKitTransportJSONRPC, "ServerBefore").Call(
+			Line().Qual(PackagePathGoKitTracing, "HTTPToContext").Call(Id("tracer"), Lit("JSONRPC"), Id("logger")),
+		)
+	}
+	if Tags(ctx).Has(OpenTelemetryTag) {
+		s.Op(",").Qual(PackagePathGoKitTransportJSONRPC, "ServerBefore").Call(
+			Line().Id(t.info.nsPrivateName("extractTraceContext")).Call(Id("propagator")),
+		)
+	}
+	return s
+}
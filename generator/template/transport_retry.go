@@ -0,0 +1,325 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/dave/jennifer/jen"
+	"github.com/recolabs/microgen/generator/write_strategy"
+	"github.com/vetcher/go-astra/types"
+)
+
+const (
+	retryClientEndpointsName = "RetryClientEndpoints"
+
+	retryIdempotentOption = "idempotent"
+	defaultRetryAttempts  = 3
+	defaultRetryBackoff   = 100 * time.Millisecond
+)
+
+// retryPolicy of method from @retry tag.
+type retryPolicy struct {
+	// attempts is a count of calls including first one.
+	attempts int
+	// backoff is a pause before second call, which is doubled before every next call.
+	backoff time.Duration
+}
+
+type retryTemplate struct {
+	info     *GenerationInfo
+	policies map[string]retryPolicy
+}
+
+func NewRetryTemplate(info *GenerationInfo) Template {
+	return &retryTemplate{
+		info: info,
+	}
+}
+
+// Render retries of client endpoints of idempotent methods.
+//
+//		// RetryClientEndpoints retries failed calls of idempotent methods by policies of @retry tags.
+//		// Other endpoints are not changed.
+//		func RetryClientEndpoints(endpoints EndpointsSet) EndpointsSet {
+//			return EndpointsSet{
+//				CountEndpoint: retryClientEndpoint(endpoints.CountEndpoint, 3, 100*time.Millisecond),
+//				DummyEndpoint: endpoints.DummyEndpoint,
+//			}
+//		}
+//
+func (t *retryTemplate) Render(ctx context.Context) write_strategy.Renderer {
+	f := NewFile("transport")
+	f.HeaderComment(t.info.FileHeader)
+
+	name := t.info.nsName(retryClientEndpointsName)
+	f.Comment(name + " retries failed calls of idempotent methods by policies of @" + RetryTag + " tags.").
+		Line().Comment("Other endpoints are not changed.").
+		Line().Func().Id(name).Params(Id("endpoints").Id(t.info.endpointsSetName())).Id(t.info.endpointsSetName()).Block(
+		Return(Id(t.info.endpointsSetName()).Values(DictFunc(func(d Dict) {
+			for _, fn := range t.info.Iface.Methods {
+				policy, ok := t.policies[fn.Name]
+				if ok && t.info.AllowedMethods[fn.Name] && !isStreamMethod(t.info, fn) {
+					d[Id(endpointsStructFieldName(fn.Name))] = Id(t.info.nsPrivateName("retryClientEndpoint")).Call(
+						Id("endpoints").Dot(endpointsStructFieldName(fn.Name)),
+						Lit(policy.attempts),
+						durationCode(policy.backoff),
+					)
+					continue
+				}
+				if t.info.AllowedMethods[fn.Name] || isStreamMethod(t.info, fn) {
+					d[Id(endpointsStructFieldName(fn.Name))] = Id("endpoints").Dot(endpointsStructFieldName(fn.Name))
+				}
+			}
+		}))),
+	)
+	f.Line().Add(t.retryClientEndpoint())
+	f.Line().Add(t.retryable(ctx))
+	return f
+}
+
+func (t *retryTemplate) DefaultPath() string {
+	return filenameBuilder(PathTransport, t.info.nsFile("retry"))
+}
+
+func (t *retryTemplate) Prepare(ctx context.Context) error {
+	t.policies = make(map[string]retryPolicy)
+	for _, fn := range t.info.Iface.Methods {
+		value, ok := fetchTagLine(fn.Docs, RetryTag)
+		if !ok {
+			continue
+		}
+		policy, idempotent, err := parseRetryPolicy(value)
+		if err != nil {
+			return fmt.Errorf("%s: %v", fn.Name, err)
+		}
+		if !idempotent {
+			return fmt.Errorf("%s: @%s: only idempotent methods are retried, add %s option, when repeated calls of method are safe", fn.Name, RetryTag, retryIdempotentOption)
+		}
+		t.policies[fn.Name] = policy
+	}
+	return nil
+}
+
+func (t *retryTemplate) ChooseStrategy(ctx context.Context) (write_strategy.Strategy, error) {
+	return write_strategy.NewCreateFileStrategy(t.info.OutputFilePath, t.DefaultPath()), nil
+}
+
+// parseRetryPolicy parses value of @retry tag. Attempts and backoff are optional.
+//
+//		// @retry attempts=3 backoff=100ms idempotent
+//
+func parseRetryPolicy(value string) (policy retryPolicy, idempotent bool, err error) {
+	policy = retryPolicy{
+		attempts: defaultRetryAttempts,
+		backoff:  defaultRetryBackoff,
+	}
+	for _, option := range strings.Fields(value) {
+		switch {
+		case option == retryIdempotentOption:
+			idempotent = true
+		case strings.HasPrefix(option, "attempts="):
+			policy.attempts, err = strconv.Atoi(strings.TrimPrefix(option, "attempts="))
+			if err != nil || policy.attempts < 1 {
+				return policy, false, fmt.Errorf("@%s: %s: attempts should be positive integer", RetryTag, option)
+			}
+		case strings.HasPrefix(option, "backoff="):
+			policy.backoff, err = time.ParseDuration(strings.TrimPrefix(option, "backoff="))
+			if err != nil || policy.backoff < 0 {
+				return policy, false, fmt.Errorf("@%s: %s: backoff should be duration, e.g. 100ms", RetryTag, option)
+			}
+		default:
+			return policy, false, fmt.Errorf("@%s: unknown option %s", RetryTag, option)
+		}
+	}
+	return policy, idempotent, nil
+}
+
+// isIdempotent reports, whether @retry tag of method has idempotent option.
+func isIdempotent(fn *types.Function) bool {
+	value, ok := fetchTagLine(fn.Docs, RetryTag)
+	if !ok {
+		return false
+	}
+	_, idempotent, err := parseRetryPolicy(value)
+	return err == nil && idempotent
+}
+
+// durationCode renders duration as expression of time package, e.g. `100 * time.Millisecond`.
+func durationCode(d time.Duration) *Statement {
+	for _, unit := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"Hour", time.Hour},
+		{"Minute", time.Minute},
+		{"Second", time.Second},
+		{"Millisecond", time.Millisecond},
+		{"Microsecond", time.Microsecond},
+	} {
+		if d >= unit.value && d%unit.value == 0 {
+			return Lit(int(d / unit.value)).Op("*").Qual(PackagePathTime, unit.name)
+		}
+	}
+	return Lit(int(d))
+}
+
+// Render retrying endpoint.
+//
+//		// retryClientEndpoint calls next up to attempts times, while it returns errors, which may be retried.
+//		// Pause between calls starts from backoff and is doubled after every call.
+//		func retryClientEndpoint(next endpoint.Endpoint, attempts int, backoff time.Duration) endpoint.Endpoint {
+//			return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+//				for i := 1; ; i++ {
+//					response, err = next(ctx, request)
+//					if err == nil || i >= attempts || !retryable(err) {
+//						return response, err
+//					}
+//					select {
+//					case <-ctx.Done():
+//						return nil, ctx.Err()
+//					case <-time.After(backoff):
+//					}
+//					backoff *= 2
+//				}
+//			}
+//		}
+//
+func (t *retryTemplate) retryClientEndpoint() *Statement {
+	name := t.info.nsPrivateName("retryClientEndpoint")
+	return Comment(name+" calls next up to attempts times, while it returns errors, which may be retried.").
+		Line().Comment("Pause between calls starts from backoff and is doubled after every call.").
+		Line().Func().Id(name).Params(
+		Id("next").Qual(PackagePathGoKitEndpoint, "Endpoint"),
+		Id("attempts").Int(),
+		Id("backoff").Qual(PackagePathTime, "Duration"),
+	).Qual(PackagePathGoKitEndpoint, "Endpoint").Block(
+		Return().Func().Params(
+			Id("ctx").Qual(PackagePathContext, "Context"),
+			Id("request").Interface(),
+		).Params(Id("response").Interface(), Err().Error()).Block(
+			For(Id("i").Op(":=").Lit(1), Empty(), Id("i").Op("++")).Block(
+				List(Id("response"), Err()).Op("=").Id("next").Call(Id("ctx"), Id("request")),
+				If(Err().Op("==").Nil().Op("||").Id("i").Op(">=").Id("attempts").Op("||").Op("!").Id(t.info.nsPrivateName("retryable")).Call(Err())).Block(
+					Return(Id("response"), Err()),
+				),
+				Select().Block(
+					Case(Op("<-").Id("ctx").Dot("Done").Call()).Block(
+						Return(Nil(), Id("ctx").Dot("Err").Call()),
+					),
+					Case(Op("<-").Qual(PackagePathTime, "After").Call(Id("backoff"))),
+				),
+				Id("backoff").Op("*=").Lit(2),
+			),
+		),
+	)
+}
+
+// Render check of errors, which may pass on repeated call. Errors are classified by transport status,
+// which typed errors of service, decoded by @errors tag, do not have, so they are not retried as every unknown error.
+//
+//		// retryable returns true for errors, which may pass on repeated call: failures of connection,
+//		// HTTP statuses 429, 502, 503, 504 and gRPC codes Unavailable, ResourceExhausted, Aborted, DeadlineExceeded.
+//		// Other errors, e.g. errors of service from @errors tag, are not retried.
+//		func retryable(err error) bool {
+//			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+//				return false
+//			}
+//			var coder interface {
+//				StatusCode() int
+//			}
+//			if errors.As(err, &coder) {
+//				switch coder.StatusCode() {
+//				case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+//					return true
+//				}
+//				return false
+//			}
+//			if st, ok := status.FromError(err); ok {
+//				switch st.Code() {
+//				case codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.DeadlineExceeded:
+//					return true
+//				}
+//				return false
+//			}
+//			var netErr net.Error
+//			return errors.As(err, &netErr)
+//		}
+//
+func (t *retryTemplate) retryable(ctx context.Context) *Statement {
+	name := t.info.nsPrivateName("retryable")
+	return Comment(name+" returns true for errors, which may pass on repeated call: failures of connection,").
+		Line().Comment("HTTP statuses 429, 502, 503, 504 and gRPC codes Unavailable, ResourceExhausted, Aborted, DeadlineExceeded.").
+		Line().Comment("Other errors, e.g. errors of service from @errors tag, are not retried.").
+		Line().Func().Id(name).Params(Err().Error()).Bool().BlockFunc(func(g *Group) {
+		g.If(
+			Qual(PackagePathErrors, "Is").Call(Err(), Qual(PackagePathContext, "Canceled")).Op("||").
+				Qual(PackagePathErrors, "Is").Call(Err(), Qual(PackagePathContext, "DeadlineExceeded")),
+		).Block(
+			Return(False()),
+		)
+		g.Var().Id("coder").Interface(Id("StatusCode").Params().Int())
+		g.If(Qual(PackagePathErrors, "As").Call(Err(), Op("&").Id("coder"))).Block(
+			Switch(Id("coder").Dot("StatusCode").Call()).Block(
+				Case(ListFunc(func(l *Group) {
+					for _, code := range []string{"StatusTooManyRequests", "StatusBadGateway", "StatusServiceUnavailable", "StatusGatewayTimeout"} {
+						l.Qual(PackagePathHttp, code)
+					}
+				})).Block(Return(True())),
+			),
+			Return(False()),
+		)
+		if Tags(ctx).HasAny(GrpcTag, GrpcClientTag) {
+			g.If(List(Id("st"), Id("ok")).Op(":=").Qual(PackagePathGoogleGRPCStatus, "FromError").Call(Err()), Id("ok")).Block(
+				Switch(Id("st").Dot("Code").Call()).Block(
+					Case(ListFunc(func(l *Group) {
+						for _, code := range []string{"Unavailable", "ResourceExhausted", "Aborted", "DeadlineExceeded"} {
+							l.Qual(PackagePathGoogleGRPCCodes, code)
+						}
+					})).Block(Return(True())),
+				),
+				Return(False()),
+			)
+		}
+		g.Var().Id("netErr").Qual(PackagePathNet, "Error")
+		g.Return(Qual(PackagePathErrors, "As").Call(Err(), Op("&").Id("netErr")))
+	})
+}
+
+// Renders wrapping of client endpoints with retries, when tag is set.
+//
+//		transport.RetryClientEndpoints(endpoints)
+//
+func retryClientEndpoints(ctx context.Context, info *GenerationInfo, endpoints Code) *Statement {
+	if !Tags(ctx).Has(RetryTag) {
+		return Add(endpoints)
+	}
+	return Qual(info.OutputPackageImport+"/transport", info.nsName(retryClientEndpointsName)).Call(endpoints)
+}
+
+// wrapsClientEndpoints reports, whether client constructors wrap endpoints with circuit breakers or retries.
+// Then endpoints of transport are created by private function, which is shared with clients of service discovery.
+func wrapsClientEndpoints(ctx context.Context) bool {
+	return Tags(ctx).HasAny(CircuitBreakerTag, RetryTag)
+}
+
+// Renders comment of client constructors, which wrap endpoints with circuit breakers and retries.
+// Clients of service discovery wrap endpoints of every instance with breakers and retry calls on next instances.
+func wrappedClientComment(ctx context.Context, sd bool) *Statement {
+	s := &Statement{}
+	switch {
+	case Tags(ctx).Has(CircuitBreakerTag) && sd:
+		s.Comment("Endpoints of every instance are wrapped with circuit breakers, which breaker returns for name of method.").Line()
+	case Tags(ctx).Has(CircuitBreakerTag):
+		s.Comment("Endpoints are wrapped with circuit breakers, which breaker returns for name of method.").Line()
+	}
+	switch {
+	case Tags(ctx).Has(RetryTag) && sd:
+		s.Comment("Failed calls are retried on next instances by policies of @" + RetryTag + " tags.").Line()
+	case Tags(ctx).Has(RetryTag):
+		s.Comment("Failed calls are retried by policies of @" + RetryTag + " tags.").Line()
+	}
+	return s
+}
@@ -0,0 +1,108 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetcher/go-astra"
+)
+
+const retryTestSource = `package svc
+
+import (
+	"context"
+
+	"example.com/svc/pb"
+)
+
+type EchoService interface {
+	// @retry attempts=5 backoff=250ms idempotent
+	Get(ctx context.Context, id string) (value string, err error)
+	// @retry idempotent
+	List(ctx context.Context) (values []string, err error)
+	Put(ctx context.Context, id string, value string) (err error)
+	// @microgen one-to-many
+	Watch(id string, stream pb.EchoService_WatchServer) (err error)
+}
+`
+
+func TestRetryAndCircuitBreaker(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "svc.go")
+	if err := ioutil.WriteFile(source, []byte(retryTestSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := astra.ParseFile(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &GenerationInfo{
+		Iface:                  &file.Interfaces[0],
+		SourceFilePath:         source,
+		SourcePackageImport:    "example.com/svc",
+		OutputPackageImport:    "example.com/svc",
+		ProtobufPackageImport:  "example.com/svc/pb",
+		AllowedMethods:         map[string]bool{"Get": true, "List": true, "Put": true, "Watch": true},
+		OneToManyStreamMethods: map[string]bool{"Watch": true},
+	}
+	ctx := WithTags(context.Background(), TagsSet{RetryTag: {}, CircuitBreakerTag: {}, GrpcTag: {}})
+	render := func(tmpl Template) string {
+		if err := tmpl.Prepare(ctx); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Render(ctx).Render(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	code := render(NewRetryTemplate(info))
+	for _, s := range []string{
+		"func RetryClientEndpoints(endpoints EndpointsSet) EndpointsSet {",
+		"GetEndpoint:   retryClientEndpoint(endpoints.GetEndpoint, 5, 250*time.Millisecond),",
+		"ListEndpoint:  retryClientEndpoint(endpoints.ListEndpoint, 3, 100*time.Millisecond),",
+		"PutEndpoint:   endpoints.PutEndpoint,",
+		"WatchEndpoint: endpoints.WatchEndpoint,",
+		"if err == nil || i >= attempts || !retryable(err) {",
+		"case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:",
+		"case codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.DeadlineExceeded:",
+		"return errors.As(err, &netErr)",
+	} {
+		assert.Contains(t, code, s)
+	}
+
+	code = render(NewCircuitBreakerTemplate(info))
+	for _, s := range []string{
+		"func CircuitBreakerClientEndpoints(endpoints EndpointsSet, breaker func(method string) endpoint.Middleware) EndpointsSet {",
+		`GetEndpoint:   breaker("EchoService.Get")(endpoints.GetEndpoint),`,
+		`PutEndpoint:   breaker("EchoService.Put")(endpoints.PutEndpoint),`,
+		"WatchEndpoint: endpoints.WatchEndpoint,",
+	} {
+		assert.Contains(t, code, s)
+	}
+}
+
+func TestParseRetryPolicy(t *testing.T) {
+	policy, idempotent, err := parseRetryPolicy("attempts=3 backoff=100ms idempotent")
+	assert.NoError(t, err)
+	assert.True(t, idempotent)
+	assert.Equal(t, retryPolicy{attempts: 3, backoff: 100 * time.Millisecond}, policy)
+
+	_, idempotent, err = parseRetryPolicy("attempts=2")
+	assert.NoError(t, err)
+	assert.False(t, idempotent)
+
+	for _, value := range []string{"attempts=0", "attempts=x", "backoff=100", "backoff=-1s", "jitter"} {
+		_, _, err := parseRetryPolicy(value)
+		assert.Error(t, err, value)
+	}
+
+	assert.Equal(t, "250 * time.Millisecond", durationCode(250*time.Millisecond).GoString())
+	assert.Equal(t, "2 * time.Second", durationCode(2*time.Second).GoString())
+	assert.Equal(t, "1500 * time.Millisecond", durationCode(1500*time.Millisecond).GoString())
+}
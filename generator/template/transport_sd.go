@@ -0,0 +1,412 @@
+package template
+
+import (
+	"context"
+
+	. "github.com/dave/jennifer/jen"
+	mstrings "github.com/recolabs/microgen/generator/strings"
+	"github.com/recolabs/microgen/generator/write_strategy"
+	"github.com/vetcher/go-astra/types"
+)
+
+const (
+	endpointsFactoryName  = "EndpointsFactory"
+	balancedEndpointsName = "BalancedEndpoints"
+)
+
+type serviceDiscoveryTemplate struct {
+	info *GenerationInfo
+	// Idempotent methods, which calls may be retried on next instances.
+	idempotent map[string]bool
+}
+
+func NewServiceDiscoveryTemplate(info *GenerationInfo) Template {
+	return &serviceDiscoveryTemplate{
+		info: info,
+	}
+}
+
+// Render instancers and load balancing of endpoints for clients.
+//
+//		// EndpointsFactory creates endpoints for instance of service. Closer is closed, when instance disappears.
+//		type EndpointsFactory func(instance string) (EndpointsSet, io.Closer, error)
+//
+//		// BalancedEndpoints returns endpoints, which call instances of instancer by round robin.
+//		// Factory is called once for every instance, endpoints of instance are shared by all methods.
+//		// Failed calls of idempotent methods are tried on next instances up to retryMax times,
+//		// other methods are called once. All attempts of call take no more than retryTimeout.
+//		// Closer stops watching instancer and closes endpoints of all instances.
+//		func BalancedEndpoints(instancer sd.Instancer, factory EndpointsFactory, logger log.Logger, retryMax int, retryTimeout time.Duration) (EndpointsSet, io.Closer) {
+//			instances := &instanceEndpoints{factory: factory, sets: make(map[string]*instanceEndpointsSet)}
+//			var endpointers []*sd.DefaultEndpointer
+//			balance := func(factory sd.Factory, max int) endpoint.Endpoint {
+//				endpointer := sd.NewEndpointer(instancer, factory, logger)
+//				endpointers = append(endpointers, endpointer)
+//				return retryBalanced(max, retryTimeout, lb.NewRoundRobin(endpointer))
+//			}
+//			endpoints := EndpointsSet{
+//				CountEndpoint:     balance(countSDFactory(instances), 1),
+//				UppercaseEndpoint: balance(uppercaseSDFactory(instances), retryMax),
+//			}
+//			return endpoints, closerFunc(func() error {
+//				for _, endpointer := range endpointers {
+//					endpointer.Close()
+//				}
+//				return instances.close()
+//			})
+//		}
+//
+//		func countSDFactory(instances *instanceEndpoints) sd.Factory {
+//			return func(instance string) (endpoint.Endpoint, io.Closer, error) {
+//				endpoints, closer, err := instances.acquire(instance)
+//				if err != nil {
+//					return nil, nil, err
+//				}
+//				return endpoints.CountEndpoint, closer, nil
+//			}
+//		}
+//
+//		// StaticInstancer returns instancer with fixed list of instances, e.g. addresses of local servers.
+//		func StaticInstancer(instances ...string) sd.Instancer {
+//			return sd.FixedInstancer(instances)
+//		}
+//
+//		// DNSSRVInstancer returns instancer, which resolves instances from SRV records of name every ttl.
+//		func DNSSRVInstancer(name string, ttl time.Duration, logger log.Logger) sd.Instancer {
+//			return dnssrv.NewInstancer(name, ttl, logger)
+//		}
+//
+func (t *serviceDiscoveryTemplate) Render(ctx context.Context) write_strategy.Renderer {
+	f := NewFile("transport")
+	f.HeaderComment(t.info.FileHeader)
+
+	f.Comment(t.info.nsName(endpointsFactoryName) + " creates endpoints for instance of service. Closer is closed, when instance disappears.").
+		Line().Type().Id(t.info.nsName(endpointsFactoryName)).Func().Params(Id("instance").String()).Params(Id(t.info.endpointsSetName()), Qual(PackagePathIO, "Closer"), Error())
+
+	f.Line().Comment(t.info.nsName(balancedEndpointsName) + " returns endpoints, which call instances of instancer by round robin.").
+		Line().Comment("Factory is called once for every instance, endpoints of instance are shared by all methods.").
+		Line().Comment("Failed calls of idempotent methods are tried on next instances up to retryMax times,").
+		Line().Comment("other methods are called once. All attempts of call take no more than retryTimeout.").
+		Line().Comment("Closer stops watching instancer and closes endpoints of all instances.").
+		Line().Func().Id(t.info.nsName(balancedEndpointsName)).Params(
+		Id("instancer").Qual(PackagePathGoKitSD, "Instancer"),
+		Id("factory").Id(t.info.nsName(endpointsFactoryName)),
+		Id(_logger_).Qual(PackagePathGoKitLog, "Logger"),
+		Id("retryMax").Int(),
+		Id("retryTimeout").Qual(PackagePathTime, "Duration"),
+	).Params(Id(t.info.endpointsSetName()), Qual(PackagePathIO, "Closer")).Block(
+		Id("instances").Op(":=").Op("&").Id(t.info.nsPrivateName("instanceEndpoints")).Values(Dict{
+			Id("factory"): Id("factory"),
+			Id("sets"):    Make(Map(String()).Op("*").Id(t.info.nsPrivateName("instanceEndpointsSet"))),
+		}),
+		Var().Id("endpointers").Index().Op("*").Qual(PackagePathGoKitSD, "DefaultEndpointer"),
+		Id("balance").Op(":=").Func().Params(Id("factory").Qual(PackagePathGoKitSD, "Factory"), Id("max").Int()).Qual(PackagePathGoKitEndpoint, "Endpoint").Block(
+			Id("endpointer").Op(":=").Qual(PackagePathGoKitSD, "NewEndpointer").Call(Id("instancer"), Id("factory"), Id(_logger_)),
+			Id("endpointers").Op("=").Append(Id("endpointers"), Id("endpointer")),
+			Return(Id(t.info.nsPrivateName("retryBalanced")).Call(Id("max"), Id("retryTimeout"), Qual(PackagePathGoKitLB, "NewRoundRobin").Call(Id("endpointer")))),
+		),
+		Id("endpoints").Op(":=").Id(t.info.endpointsSetName()).Values(DictFunc(func(d Dict) {
+			for _, fn := range t.info.Iface.Methods {
+				if !t.sdMethod(fn) {
+					continue
+				}
+				max := Lit(1)
+				if t.idempotent[fn.Name] {
+					max = Id("retryMax")
+				}
+				d[Id(endpointsStructFieldName(fn.Name))] = Id("balance").Call(Id(t.info.nsPrivateName(serviceDiscoveryFactoryName(fn.Name))).Call(Id("instances")), max)
+			}
+		})),
+		Return(Id("endpoints"), Id(t.info.nsPrivateName("closerFunc")).Call(Func().Params().Error().Block(
+			For(List(Id("_"), Id("endpointer")).Op(":=").Range().Id("endpointers")).Block(
+				Id("endpointer").Dot("Close").Call(),
+			),
+			Return(Id("instances").Dot("close").Call()),
+		))),
+	)
+
+	for _, fn := range t.info.Iface.Methods {
+		if !t.sdMethod(fn) {
+			continue
+		}
+		f.Line().Add(t.serviceDiscoveryFactory(fn))
+	}
+
+	f.Line().Add(t.retryBalanced())
+	f.Line().Add(t.instanceEndpoints())
+
+	f.Line().Comment(t.info.nsName("StaticInstancer") + " returns instancer with fixed list of instances, e.g. addresses of local servers.").
+		Line().Func().Id(t.info.nsName("StaticInstancer")).Params(Id("instances").Op("...").String()).Qual(PackagePathGoKitSD, "Instancer").Block(
+		Return(Qual(PackagePathGoKitSD, "FixedInstancer").Call(Id("instances"))),
+	)
+
+	f.Line().Comment(t.info.nsName("DNSSRVInstancer") + " returns instancer, which resolves instances from SRV records of name every ttl.").
+		Line().Func().Id(t.info.nsName("DNSSRVInstancer")).Params(
+		Id("name").String(),
+		Id("ttl").Qual(PackagePathTime, "Duration"),
+		Id(_logger_).Qual(PackagePathGoKitLog, "Logger"),
+	).Qual(PackagePathGoKitSD, "Instancer").Block(
+		Return(Qual(PackagePathGoKitSDDNSSRV, "NewInstancer").Call(Id("name"), Id("ttl"), Id(_logger_))),
+	)
+
+	return f
+}
+
+func (t *serviceDiscoveryTemplate) DefaultPath() string {
+	return filenameBuilder(PathTransport, t.info.nsFile("sd"))
+}
+
+func (t *serviceDiscoveryTemplate) Prepare(ctx context.Context) error {
+	t.idempotent = make(map[string]bool)
+	for _, fn := range t.info.Iface.Methods {
+		t.idempotent[fn.Name] = isIdempotent(fn)
+	}
+	return nil
+}
+
+func (t *serviceDiscoveryTemplate) ChooseStrategy(ctx context.Context) (write_strategy.Strategy, error) {
+	return write_strategy.NewCreateFileStrategy(t.info.OutputFilePath, t.DefaultPath()), nil
+}
+
+// Stream methods have no endpoints, that may be balanced.
+func (t *serviceDiscoveryTemplate) sdMethod(fn *types.Function) bool {
+	return t.info.AllowedMethods[fn.Name] &&
+		!t.info.ManyToManyStreamMethods[fn.Name] &&
+		!t.info.ManyToOneStreamMethods[fn.Name] &&
+		!t.info.OneToManyStreamMethods[fn.Name]
+}
+
+//		func countSDFactory(instances *instanceEndpoints) sd.Factory {
+//			return func(instance string) (endpoint.Endpoint, io.Closer, error) {
+//				endpoints, closer, err := instances.acquire(instance)
+//				if err != nil {
+//					return nil, nil, err
+//				}
+//				return endpoints.CountEndpoint, closer, nil
+//			}
+//		}
+//
+func (t *serviceDiscoveryTemplate) serviceDiscoveryFactory(fn *types.Function) *Statement {
+	return Func().Id(t.info.nsPrivateName(serviceDiscoveryFactoryName(fn.Name))).Params(Id("instances").Op("*").Id(t.info.nsPrivateName("instanceEndpoints"))).Qual(PackagePathGoKitSD, "Factory").Block(
+		Return(Func().Params(Id("instance").String()).Params(Qual(PackagePathGoKitEndpoint, "Endpoint"), Qual(PackagePathIO, "Closer"), Error()).Block(
+			List(Id("endpoints"), Id("closer"), Err()).Op(":=").Id("instances").Dot("acquire").Call(Id("instance")),
+			If(Err().Op("!=").Nil()).Block(
+				Return(Nil(), Nil(), Err()),
+			),
+			Return(Id("endpoints").Dot(endpointsStructFieldName(fn.Name)), Id("closer"), Nil()),
+		)),
+	)
+}
+
+//		// retryBalanced calls endpoints, which balancer chooses, up to max times within timeout.
+//		// Error of the last call is returned as is, so errors of service are not hidden by lb.RetryError.
+//		func retryBalanced(max int, timeout time.Duration, balancer lb.Balancer) endpoint.Endpoint {
+//			retry := lb.Retry(max, timeout, balancer)
+//			return func(ctx context.Context, request interface{}) (interface{}, error) {
+//				response, err := retry(ctx, request)
+//				var retryErr lb.RetryError
+//				if errors.As(err, &retryErr) {
+//					return nil, retryErr.Final
+//				}
+//				return response, err
+//			}
+//		}
+//
+func (t *serviceDiscoveryTemplate) retryBalanced() *Statement {
+	name := t.info.nsPrivateName("retryBalanced")
+	return Comment(name+" calls endpoints, which balancer chooses, up to max times within timeout.").
+		Line().Comment("Error of the last call is returned as is, so errors of service are not hidden by lb.RetryError.").
+		Line().Func().Id(name).Params(
+		Id("max").Int(),
+		Id("timeout").Qual(PackagePathTime, "Duration"),
+		Id("balancer").Qual(PackagePathGoKitLB, "Balancer"),
+	).Qual(PackagePathGoKitEndpoint, "Endpoint").Block(
+		Id("retry").Op(":=").Qual(PackagePathGoKitLB, "Retry").Call(Id("max"), Id("timeout"), Id("balancer")),
+		Return(Func().Params(Id(_ctx_).Qual(PackagePathContext, "Context"), Id("request").Interface()).Params(Interface(), Error()).Block(
+			List(Id("response"), Err()).Op(":=").Id("retry").Call(Id(_ctx_), Id("request")),
+			Var().Id("retryErr").Qual(PackagePathGoKitLB, "RetryError"),
+			If(Qual(PackagePathErrors, "As").Call(Err(), Op("&").Id("retryErr"))).Block(
+				Return(Nil(), Id("retryErr").Dot("Final")),
+			),
+			Return(Id("response"), Err()),
+		)),
+	)
+}
+
+// Renders cache of endpoints of instances, which counts endpointers, that use instance.
+//
+//		// instanceEndpoints creates endpoints of instance once for endpointers of all methods
+//		// and closes them, when the last endpointer releases instance.
+//		type instanceEndpoints struct {
+//			factory EndpointsFactory
+//			mtx     sync.Mutex
+//			sets    map[string]*instanceEndpointsSet
+//		}
+//
+//		type instanceEndpointsSet struct {
+//			endpoints EndpointsSet
+//			closer    io.Closer
+//			refs      int
+//		}
+//
+//		func (c *instanceEndpoints) acquire(instance string) (EndpointsSet, io.Closer, error) {
+//			c.mtx.Lock()
+//			defer c.mtx.Unlock()
+//			set, ok := c.sets[instance]
+//			if !ok {
+//				endpoints, closer, err := c.factory(instance)
+//				if err != nil {
+//					return EndpointsSet{}, nil, err
+//				}
+//				set = &instanceEndpointsSet{endpoints: endpoints, closer: closer}
+//				c.sets[instance] = set
+//			}
+//			set.refs++
+//			var once sync.Once
+//			return set.endpoints, closerFunc(func() (err error) {
+//				once.Do(func() { err = c.release(instance, set) })
+//				return err
+//			}), nil
+//		}
+//
+//		func (c *instanceEndpoints) release(instance string, set *instanceEndpointsSet) error {
+//			c.mtx.Lock()
+//			defer c.mtx.Unlock()
+//			set.refs--
+//			if set.refs > 0 || c.sets[instance] != set {
+//				return nil
+//			}
+//			delete(c.sets, instance)
+//			if set.closer == nil {
+//				return nil
+//			}
+//			return set.closer.Close()
+//		}
+//
+//		func (c *instanceEndpoints) close() error {
+//			c.mtx.Lock()
+//			defer c.mtx.Unlock()
+//			var err error
+//			for instance, set := range c.sets {
+//				delete(c.sets, instance)
+//				if set.closer == nil {
+//					continue
+//				}
+//				if e := set.closer.Close(); e != nil && err == nil {
+//					err = e
+//				}
+//			}
+//			return err
+//		}
+//
+//		type closerFunc func() error
+//
+//		func (f closerFunc) Close() error {
+//			return f()
+//		}
+//
+func (t *serviceDiscoveryTemplate) instanceEndpoints() *Statement {
+	cacheName := t.info.nsPrivateName("instanceEndpoints")
+	setName := t.info.nsPrivateName("instanceEndpointsSet")
+	closerName := t.info.nsPrivateName("closerFunc")
+	s := &Statement{}
+	s.Comment(cacheName + " creates endpoints of instance once for endpointers of all methods").
+		Line().Comment("and closes them, when the last endpointer releases instance.").
+		Line().Type().Id(cacheName).Struct(
+		Id("factory").Id(t.info.nsName(endpointsFactoryName)),
+		Id("mtx").Qual(PackagePathSync, "Mutex"),
+		Id("sets").Map(String()).Op("*").Id(setName),
+	).Line()
+
+	s.Line().Type().Id(setName).Struct(
+		Id("endpoints").Id(t.info.endpointsSetName()),
+		Id("closer").Qual(PackagePathIO, "Closer"),
+		Id("refs").Int(),
+	).Line()
+
+	s.Line().Func().Params(Id("c").Op("*").Id(cacheName)).Id("acquire").Params(Id("instance").String()).Params(Id(t.info.endpointsSetName()), Qual(PackagePathIO, "Closer"), Error()).Block(
+		Id("c").Dot("mtx").Dot("Lock").Call(),
+		Defer().Id("c").Dot("mtx").Dot("Unlock").Call(),
+		List(Id("set"), Id("ok")).Op(":=").Id("c").Dot("sets").Index(Id("instance")),
+		If(Op("!").Id("ok")).Block(
+			List(Id("endpoints"), Id("closer"), Err()).Op(":=").Id("c").Dot("factory").Call(Id("instance")),
+			If(Err().Op("!=").Nil()).Block(
+				Return(Id(t.info.endpointsSetName()).Values(), Nil(), Err()),
+			),
+			Id("set").Op("=").Op("&").Id(setName).Values(Dict{
+				Id("endpoints"): Id("endpoints"),
+				Id("closer"):    Id("closer"),
+			}),
+			Id("c").Dot("sets").Index(Id("instance")).Op("=").Id("set"),
+		),
+		Id("set").Dot("refs").Op("++"),
+		Var().Id("once").Qual(PackagePathSync, "Once"),
+		Return(Id("set").Dot("endpoints"), Id(closerName).Call(Func().Params().Params(Err().Error()).Block(
+			Id("once").Dot("Do").Call(Func().Params().Block(
+				Err().Op("=").Id("c").Dot("release").Call(Id("instance"), Id("set")),
+			)),
+			Return(Err()),
+		)), Nil()),
+	).Line()
+
+	s.Line().Func().Params(Id("c").Op("*").Id(cacheName)).Id("release").Params(Id("instance").String(), Id("set").Op("*").Id(setName)).Error().Block(
+		Id("c").Dot("mtx").Dot("Lock").Call(),
+		Defer().Id("c").Dot("mtx").Dot("Unlock").Call(),
+		Id("set").Dot("refs").Op("--"),
+		If(Id("set").Dot("refs").Op(">").Lit(0).Op("||").Id("c").Dot("sets").Index(Id("instance")).Op("!=").Id("set")).Block(
+			Return(Nil()),
+		),
+		Delete(Id("c").Dot("sets"), Id("instance")),
+		If(Id("set").Dot("closer").Op("==").Nil()).Block(
+			Return(Nil()),
+		),
+		Return(Id("set").Dot("closer").Dot("Close").Call()),
+	).Line()
+
+	s.Line().Func().Params(Id("c").Op("*").Id(cacheName)).Id("close").Params().Error().Block(
+		Id("c").Dot("mtx").Dot("Lock").Call(),
+		Defer().Id("c").Dot("mtx").Dot("Unlock").Call(),
+		Var().Err().Error(),
+		For(List(Id("instance"), Id("set")).Op(":=").Range().Id("c").Dot("sets")).Block(
+			Delete(Id("c").Dot("sets"), Id("instance")),
+			If(Id("set").Dot("closer").Op("==").Nil()).Block(
+				Continue(),
+			),
+			If(Id("e").Op(":=").Id("set").Dot("closer").Dot("Close").Call(), Id("e").Op("!=").Nil().Op("&&").Err().Op("==").Nil()).Block(
+				Err().Op("=").Id("e"),
+			),
+		),
+		Return(Err()),
+	).Line()
+
+	s.Line().Type().Id(closerName).Func().Params().Error().Line()
+	s.Line().Func().Params(Id("f").Id(closerName)).Id("Close").Params().Error().Block(
+		Return(Id("f").Call()),
+	)
+	return s
+}
+
+func serviceDiscoveryFactoryName(str string) string {
+	return mstrings.ToLowerFirst(str) + "SDFactory"
+}
+
+// Renders body of client with service discovery, which balances endpoints of factory.
+// Balanced endpoints are retried, so failed call is repeated on next instance.
+//
+//		endpoints, closer := transport.BalancedEndpoints(instancer, factory, logger, retryMax, retryTimeout)
+//		return transport.RetryClientEndpoints(endpoints), closer
+//
+func balancedClient(ctx context.Context, info *GenerationInfo, factory Code) *Statement {
+	balanced := Qual(info.OutputPackageImport+"/transport", info.nsName(balancedEndpointsName)).Call(
+		Id("instancer"),
+		factory,
+		Id(_logger_),
+		Id("retryMax"),
+		Id("retryTimeout"),
+	)
+	if !Tags(ctx).Has(RetryTag) {
+		return Return(balanced)
+	}
+	return List(Id("endpoints"), Id("closer")).Op(":=").Add(balanced).
+		Line().Return(retryClientEndpoints(ctx, info, Id("endpoints")), Id("closer"))
+}
@@ -0,0 +1,90 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetcher/go-astra"
+)
+
+const serviceDiscoveryTestSource = `package svc
+
+import (
+	"context"
+
+	"example.com/svc/pb"
+)
+
+type EchoService interface {
+	// @retry idempotent
+	Echo(ctx context.Context, text string) (out string, err error)
+	Send(ctx context.Context, text string) (err error)
+	// @microgen one-to-many
+	Watch(text string, stream pb.EchoService_WatchServer) (err error)
+}
+`
+
+func TestServiceDiscovery(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "svc.go")
+	if err := ioutil.WriteFile(source, []byte(serviceDiscoveryTestSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := astra.ParseFile(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &GenerationInfo{
+		Iface:                  &file.Interfaces[0],
+		SourceFilePath:         source,
+		SourcePackageImport:    "example.com/svc",
+		OutputPackageImport:    "example.com/svc",
+		ProtobufPackageImport:  "example.com/svc/pb",
+		AllowedMethods:         map[string]bool{"Echo": true, "Send": true, "Watch": true},
+		OneToManyStreamMethods: map[string]bool{"Watch": true},
+	}
+	ctx := WithTags(context.Background(), TagsSet{ServiceDiscoveryTag: {}, HttpTag: {}, GrpcTag: {}})
+	render := func(tmpl Template) string {
+		if err := tmpl.Prepare(ctx); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Render(ctx).Render(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	code := render(NewServiceDiscoveryTemplate(info))
+	for _, s := range []string{
+		"type EndpointsFactory func(instance string) (EndpointsSet, io.Closer, error)",
+		"func BalancedEndpoints(instancer sd.Instancer, factory EndpointsFactory, logger log.Logger, retryMax int, retryTimeout time.Duration) (EndpointsSet, io.Closer) {",
+		// Only idempotent methods are tried on next instances.
+		"EchoEndpoint: balance(echoSDFactory(instances), retryMax),",
+		"SendEndpoint: balance(sendSDFactory(instances), 1),",
+		"return retryBalanced(max, retryTimeout, lb.NewRoundRobin(endpointer))",
+		"retry := lb.Retry(max, timeout, balancer)",
+		"return nil, retryErr.Final",
+		"endpoints, closer, err := instances.acquire(instance)",
+		"return endpoints.EchoEndpoint, closer, nil",
+		"return sd.FixedInstancer(instances)",
+		"return dnssrv.NewInstancer(name, ttl, logger)",
+	} {
+		assert.Contains(t, code, s)
+	}
+	assert.NotContains(t, code, "watchSDFactory")
+
+	code = render(NewHttpClientTemplate(info))
+	assert.Contains(t, code, "func NewHTTPClientSD(instancer sd.Instancer, logger log.Logger, retryMax int, retryTimeout time.Duration, opts ...httpkit.ClientOption) (transport.EndpointsSet, io.Closer) {")
+	assert.Contains(t, code, `if !strings.Contains(instance, "://") {`)
+	assert.Contains(t, code, "return transport.BalancedEndpoints(instancer, func(instance string) (transport.EndpointsSet, io.Closer, error) {")
+	assert.Contains(t, code, "}, logger, retryMax, retryTimeout)")
+
+	code = render(NewGRPCClientTemplate(info))
+	assert.Contains(t, code, "addr string, dialOpts []grpc.DialOption, opts ...grpckit.ClientOption) (transport.EndpointsSet, io.Closer) {")
+	assert.Contains(t, code, "conn, err := grpc.Dial(instance, dialOpts...)")
+	assert.Contains(t, code, "return NewGRPCClient(conn, addr, opts...), conn, nil")
+}
@@ -48,7 +48,7 @@ func (t *endpointsServerTemplate) Render(ctx context.Context) write_strategy.Ren
 
 	f.Add(t.allEndpoints()).Line()
 	if Tags(ctx).HasAny(TracingMiddlewareTag) {
-		f.Comment("TraceServerEndpoints is used for tracing endpoints on server side.")
+		f.Comment(t.info.nsName("TraceServerEndpoints") + " is used for tracing endpoints on server side.")
 		f.Add(t.serverTracingMiddleware()).Line()
 	}
 	for _, signature := range t.info.Iface.Methods {
@@ -71,8 +71,8 @@ func (t *endpointsServerTemplate) Render(ctx context.Context) write_strategy.Ren
 	return f
 }
 
-func (endpointsServerTemplate) DefaultPath() string {
-	return filenameBuilder(PathTransport, "server")
+func (t *endpointsServerTemplate) DefaultPath() string {
+	return filenameBuilder(PathTransport, t.info.nsFile("server"))
 }
 
 func (t *endpointsServerTemplate) Prepare(ctx context.Context) error {
@@ -94,7 +94,7 @@ func (t *endpointsServerTemplate) ChooseStrategy(ctx context.Context) (write_str
 //			}, nil
 //		}
 //
-func createEndpointBody(signature *normalizedFunction) *Statement {
+func createEndpointBody(info *GenerationInfo, signature *normalizedFunction) *Statement {
 	return Return(Func().Params(
 		Id(firstArgName(&signature.Function)).Qual("context", "Context"),
 		Id("request").Interface(),
@@ -104,7 +104,7 @@ func createEndpointBody(signature *normalizedFunction) *Statement {
 	).BlockFunc(func(g *Group) {
 		methodParams := RemoveContextIfFirst(signature.parent.Args)
 		if len(methodParams) > 0 {
-			g.Id("req").Op(":=").Id("request").Assert(Op("*").Id(requestStructName(signature.parent)))
+			g.Id("req").Op(":=").Id("request").Assert(Op("*").Id(info.requestStructName(signature.parent)))
 		}
 
 		g.Add(paramNames(signature.Results).
@@ -123,7 +123,7 @@ func createEndpointBody(signature *normalizedFunction) *Statement {
 			}))
 
 		g.Return(
-			Op("&").Id(responseStructName(signature.parent)).Values(dictByNormalVariables(
+			Op("&").Id(info.responseStructName(signature.parent)).Values(dictByNormalVariables(
 				removeErrorIfLast(signature.parent.Results),
 				removeErrorIfLast(signature.Results),
 			)),
@@ -155,8 +155,8 @@ func removeLastVar(slice []types.Variable) []types.Variable {
 func createOneToManyStreamEndpoint(signature *types.Function, info *GenerationInfo) *Statement {
 	normal := normalizeFunction(signature)
 	return Func().
-		Id(endpointsStructFieldName(signature.Name)).Params(Id("svc").Qual(info.SourcePackageImport, info.Iface.Name)).
-		Params(Id(OneToManyStreamEndpoint)).
+		Id(info.nsName(endpointsStructFieldName(signature.Name))).Params(Id("svc").Qual(info.SourcePackageImport, info.Iface.Name)).
+		Params(Id(info.nsName(OneToManyStreamEndpoint))).
 		Block(createOneToManyStreamEndpointBody(info, normal))
 }
 
@@ -171,7 +171,7 @@ func createOneToManyStreamEndpointBody(info *GenerationInfo, signature *normaliz
 	).Params(
 		Error(),
 	).BlockFunc(func(g *Group) {
-		g.Id("req").Op(":=").Id("request").Assert(Op("*").Id(requestStructName(signature.parent)))
+		g.Id("req").Op(":=").Id("request").Assert(Op("*").Id(info.requestStructName(signature.parent)))
 		g.Id("st").Op(":=").Id("stream").Assert(Qual(info.ProtobufPackageImport, streamStructName(info.Iface.Name, signature.parent)))
 
 		g.Add(paramNames(signature.Results).
@@ -198,8 +198,8 @@ func createOneToManyStreamEndpointBody(info *GenerationInfo, signature *normaliz
 func createManyToManyStreamEndpoint(signature *types.Function, info *GenerationInfo) *Statement {
 	normal := normalizeFunction(signature)
 	return Func().
-		Id(endpointsStructFieldName(signature.Name)).Params(Id("svc").Qual(info.SourcePackageImport, info.Iface.Name)).
-		Params(Id(ManyToManyStreamEndpoint)).
+		Id(info.nsName(endpointsStructFieldName(signature.Name))).Params(Id("svc").Qual(info.SourcePackageImport, info.Iface.Name)).
+		Params(Id(info.nsName(ManyToManyStreamEndpoint))).
 		Block(createManyToManyStreamEndpointBody(info, normal))
 }
 
@@ -228,8 +228,8 @@ func createManyToManyStreamEndpointBody(info *GenerationInfo, signature *normali
 func createManyToOneStreamEndpoint(signature *types.Function, info *GenerationInfo) *Statement {
 	normal := normalizeFunction(signature)
 	return Func().
-		Id(endpointsStructFieldName(signature.Name)).Params(Id("svc").Qual(info.SourcePackageImport, info.Iface.Name)).
-		Params(Id(ManyToOneStreamEndpoint)).
+		Id(info.nsName(endpointsStructFieldName(signature.Name))).Params(Id("svc").Qual(info.SourcePackageImport, info.Iface.Name)).
+		Params(Id(info.nsName(ManyToOneStreamEndpoint))).
 		Block(createManyToOneStreamEndpointBody(info, normal))
 }
 
@@ -258,17 +258,17 @@ func createManyToOneStreamEndpointBody(info *GenerationInfo, signature *normaliz
 func createEndpoint(signature *types.Function, info *GenerationInfo) *Statement {
 	normal := normalizeFunction(signature)
 	return Func().
-		Id(endpointsStructFieldName(signature.Name)).Params(Id("svc").Qual(info.SourcePackageImport, info.Iface.Name)).Params(Qual(PackagePathGoKitEndpoint, "Endpoint")).
-		Block(createEndpointBody(normal))
+		Id(info.nsName(endpointsStructFieldName(signature.Name))).Params(Id("svc").Qual(info.SourcePackageImport, info.Iface.Name)).Params(Qual(PackagePathGoKitEndpoint, "Endpoint")).
+		Block(createEndpointBody(info, normal))
 }
 
 func (t *endpointsServerTemplate) allEndpoints() *Statement {
 	s := &Statement{}
-	s.Func().Id("Endpoints").Call(Id("svc").Qual(t.info.SourcePackageImport, t.info.Iface.Name)).Id(EndpointsSetName).BlockFunc(func(g *Group) {
-		g.Return(Id(EndpointsSetName).Values(DictFunc(func(d Dict) {
+	s.Func().Id(t.info.nsName("Endpoints")).Call(Id("svc").Qual(t.info.SourcePackageImport, t.info.Iface.Name)).Id(t.info.endpointsSetName()).BlockFunc(func(g *Group) {
+		g.Return(Id(t.info.endpointsSetName()).Values(DictFunc(func(d Dict) {
 			for _, signature := range t.info.Iface.Methods {
 				if t.info.AllowedMethods[signature.Name] {
-					d[Id(endpointsStructFieldName(signature.Name))] = Id(endpointsStructFieldName(signature.Name)).Params(Id("svc"))
+					d[Id(endpointsStructFieldName(signature.Name))] = Id(t.info.nsName(endpointsStructFieldName(signature.Name))).Params(Id("svc"))
 				}
 			}
 		})))
@@ -278,8 +278,8 @@ func (t *endpointsServerTemplate) allEndpoints() *Statement {
 
 func (t *endpointsServerTemplate) serverTracingMiddleware() *Statement {
 	s := &Statement{}
-	s.Func().Id("TraceServerEndpoints").Call(Id("endpoints").Id(EndpointsSetName), Id("tracer").Qual(PackagePathOpenTracingGo, "Tracer")).Id(EndpointsSetName).BlockFunc(func(g *Group) {
-		g.Return(Id(EndpointsSetName).Values(DictFunc(func(d Dict) {
+	s.Func().Id(t.info.nsName("TraceServerEndpoints")).Call(Id("endpoints").Id(t.info.endpointsSetName()), Id("tracer").Qual(PackagePathOpenTracingGo, "Tracer")).Id(t.info.endpointsSetName()).BlockFunc(func(g *Group) {
+		g.Return(Id(t.info.endpointsSetName()).Values(DictFunc(func(d Dict) {
 			for _, signature := range t.info.Iface.Methods {
 				if t.info.AllowedMethods[signature.Name] {
 					d[Id(endpointsStructFieldName(signature.Name))] = Qual(PackagePathGoKitTracing, "TraceServer").Call(Id("tracer"), Lit(signature.Name)).Call(Id("endpoints").Dot(endpointsStructFieldName(signature.Name)))
@@ -0,0 +1,372 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	mstrings "github.com/recolabs/microgen/generator/strings"
+	"github.com/recolabs/microgen/generator/write_strategy"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// UserTemplateExt is an extension of user template files.
+	UserTemplateExt = ".tmpl"
+
+	frontMatterDelim = "---"
+)
+
+// Write strategies of user templates.
+const (
+	// UserStrategyCreate overwrites file on every generation.
+	UserStrategyCreate = "create"
+	// UserStrategyOnce creates file only when it does not exist, so user may edit it.
+	UserStrategyOnce = "once"
+	// UserStrategyAppend appends rendered code to the end of file.
+	// Declarations, which already exist in file, are not appended again.
+	UserStrategyAppend = "append"
+)
+
+// UserTemplate is a text/template file from user templates directory.
+// It starts with YAML front-matter, which declares tag, output path and write strategy:
+//
+//		---
+//		tag: audit
+//		path: service/{{.File "audit"}}.microgen.go
+//		strategy: create
+//		---
+//		// {{.FileHeader}}
+//		package service
+//		...
+//
+// Path is a template too, both are executed with View of service.
+// Go files are formatted after rendering.
+type UserTemplate struct {
+	// Name is a path of template file, relative to templates directory.
+	Name string
+	// Tag turns template on, when it is in service tags.
+	Tag string
+	// Strategy is one of create (default), once or append.
+	Strategy string
+
+	path *template.Template
+	body *template.Template
+}
+
+type userTemplateFrontMatter struct {
+	Tag      string `yaml:"tag"`
+	Path     string `yaml:"path"`
+	Strategy string `yaml:"strategy"`
+}
+
+// UserTemplateFuncs are functions, which are available in user templates in addition to builtin ones.
+var UserTemplateFuncs = template.FuncMap{
+	"lowerFirst": mstrings.ToLowerFirst,
+	"upperFirst": mstrings.ToUpperFirst,
+	"snake":      mstrings.ToSnakeCase,
+	"kebab":      mstrings.ToURLSnakeCase,
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"join":       strings.Join,
+}
+
+// LoadUserTemplates parses all *.tmpl files of directory and its subdirectories.
+func LoadUserTemplates(dir string) ([]*UserTemplate, error) {
+	var tmpls []*UserTemplate
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != UserTemplateExt {
+			return nil
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		buffer, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		t, err := ParseUserTemplate(filepath.ToSlash(name), buffer)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		tmpls = append(tmpls, t)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(tmpls, func(i, j int) bool { return tmpls[i].Name < tmpls[j].Name })
+	return tmpls, nil
+}
+
+// ParseUserTemplate parses content of user template file with front-matter.
+func ParseUserTemplate(name string, content []byte) (*UserTemplate, error) {
+	head, body, err := splitFrontMatter(content)
+	if err != nil {
+		return nil, err
+	}
+	var fm userTemplateFrontMatter
+	if err := yaml.UnmarshalStrict(head, &fm); err != nil {
+		return nil, fmt.Errorf("front-matter: %v", err)
+	}
+	if fm.Tag == "" {
+		return nil, fmt.Errorf("front-matter: tag is required")
+	}
+	if fm.Path == "" {
+		return nil, fmt.Errorf("front-matter: path is required")
+	}
+	switch fm.Strategy {
+	case "":
+		fm.Strategy = UserStrategyCreate
+	case UserStrategyCreate, UserStrategyOnce, UserStrategyAppend:
+	default:
+		return nil, fmt.Errorf("front-matter: unknown strategy %q, expected %s, %s or %s", fm.Strategy, UserStrategyCreate, UserStrategyOnce, UserStrategyAppend)
+	}
+	t := &UserTemplate{
+		Name:     name,
+		Tag:      fm.Tag,
+		Strategy: fm.Strategy,
+	}
+	t.path, err = template.New(name + ":path").Funcs(UserTemplateFuncs).Option("missingkey=error").Parse(fm.Path)
+	if err != nil {
+		return nil, err
+	}
+	t.body, err = template.New(name).Funcs(UserTemplateFuncs).Option("missingkey=error").Parse(string(body))
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Splits content to front-matter between `---` lines and template body.
+func splitFrontMatter(content []byte) (head, body []byte, err error) {
+	content = bytes.TrimPrefix(content, []byte("\ufeff"))
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	if len(lines) == 0 || string(bytes.TrimSpace(lines[0])) != frontMatterDelim {
+		return nil, nil, fmt.Errorf("template should start with %s front-matter", frontMatterDelim)
+	}
+	for i := 1; i < len(lines); i++ {
+		if string(bytes.TrimSpace(lines[i])) == frontMatterDelim {
+			return bytes.Join(lines[1:i], nil), bytes.Join(lines[i+1:], nil), nil
+		}
+	}
+	return nil, nil, fmt.Errorf("front-matter is not closed with %s", frontMatterDelim)
+}
+
+// For returns template, which renders user template for service.
+func (t *UserTemplate) For(info *GenerationInfo) Template {
+	return &userTemplate{
+		tmpl: t,
+		info: info,
+	}
+}
+
+type userTemplate struct {
+	tmpl *UserTemplate
+	info *GenerationInfo
+
+	view *View
+	// Path is resolved in Prepare, DefaultPath is called before it only to deduplicate templates.
+	path string
+	// Names of declarations of file, which append strategy skips.
+	existing map[string]bool
+}
+
+func (t *userTemplate) Prepare(ctx context.Context) error {
+	t.view = NewView(t.info, Tags(ctx))
+	path, err := t.execute(t.tmpl.path)
+	if err != nil {
+		return err
+	}
+	t.path = filepath.Clean(filepath.FromSlash(strings.TrimSpace(string(path))))
+	if filepath.IsAbs(t.path) || strings.HasPrefix(t.path, "..") {
+		return fmt.Errorf("%s: path %s should be inside output directory", t.tmpl.Name, t.path)
+	}
+	if t.tmpl.Strategy == UserStrategyAppend && filepath.Ext(t.path) != ".go" {
+		return fmt.Errorf("%s: strategy %s is allowed only for go files", t.tmpl.Name, UserStrategyAppend)
+	}
+	return nil
+}
+
+func (t *userTemplate) DefaultPath() string {
+	if t.path != "" {
+		return t.path
+	}
+	// Unique placeholder, until path is executed.
+	return t.tmpl.Name
+}
+
+func (t *userTemplate) ChooseStrategy(ctx context.Context) (write_strategy.Strategy, error) {
+	switch t.tmpl.Strategy {
+	case UserStrategyOnce:
+		if err := statFile(t.info.OutputFilePath, t.DefaultPath()); err == nil {
+			return write_strategy.NewNopStrategy(t.info.OutputFilePath, t.DefaultPath()), nil
+		}
+	case UserStrategyAppend:
+		if err := statFile(t.info.OutputFilePath, t.DefaultPath()); err == nil {
+			existing, err := fileDeclarations(filepath.Join(t.info.OutputFilePath, t.DefaultPath()))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", t.tmpl.Name, err)
+			}
+			t.existing = existing
+		}
+		return write_strategy.NewAppendToFileStrategy(t.info.OutputFilePath, t.DefaultPath()), nil
+	}
+	if filepath.Ext(t.DefaultPath()) == ".go" {
+		return write_strategy.NewCreateFileStrategy(t.info.OutputFilePath, t.DefaultPath()), nil
+	}
+	return write_strategy.NewCreateRawFileStrategy(t.info.OutputFilePath, t.DefaultPath()), nil
+}
+
+func (t *userTemplate) Render(ctx context.Context) write_strategy.Renderer {
+	return userTemplateRenderer{t}
+}
+
+func (t *userTemplate) execute(tmpl *template.Template) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, t.view); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type userTemplateRenderer struct {
+	t *userTemplate
+}
+
+func (r userTemplateRenderer) Render(w io.Writer) error {
+	content, err := r.t.execute(r.t.tmpl.body)
+	if err != nil {
+		return err
+	}
+	if r.t.tmpl.Strategy == UserStrategyAppend {
+		content, err = removeExistingDeclarations(content, r.t.existing)
+		if err != nil {
+			return fmt.Errorf("%s: %v", r.t.tmpl.Name, err)
+		}
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// Returns names of top-level declarations of file, rendered or on disk. Methods are named as Type.Method.
+func fileDeclarations(path string) (map[string]bool, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	var src interface{}
+	if pendingFiles != nil {
+		if content, ok := pendingFiles.Get(path); ok {
+			src = content
+		}
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), path, src, 0)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	for _, decl := range file.Decls {
+		for _, name := range declNames(decl) {
+			names[name] = true
+		}
+	}
+	return names, nil
+}
+
+// Removes declarations, which already exist, from rendered code, so it may be appended to file again.
+// Declaration of several names, e.g. var block, is removed, when all of them exist.
+// Returns nil, when nothing is left.
+func removeExistingDeclarations(content []byte, existing map[string]bool) ([]byte, error) {
+	if len(existing) == 0 {
+		return content, nil
+	}
+	const header = "package T\n"
+	src := append([]byte(header), content...)
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("parse rendered code: %v", err)
+	}
+	var (
+		result bytes.Buffer
+		last   = len(header)
+	)
+	for _, decl := range file.Decls {
+		if !allExist(declNames(decl), existing) {
+			continue
+		}
+		start, end := int(decl.Pos())-1, int(decl.End())-1
+		if doc := declDoc(decl); doc != nil {
+			start = int(doc.Pos()) - 1
+		}
+		result.Write(src[last:start])
+		last = end
+	}
+	result.Write(src[last:])
+	if len(bytes.TrimSpace(result.Bytes())) == 0 {
+		return nil, nil
+	}
+	return result.Bytes(), nil
+}
+
+func declNames(decl ast.Decl) []string {
+	var names []string
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Recv == nil || len(d.Recv.List) == 0 {
+			return []string{d.Name.Name}
+		}
+		recv := d.Recv.List[0].Type
+		if star, ok := recv.(*ast.StarExpr); ok {
+			recv = star.X
+		}
+		if ident, ok := recv.(*ast.Ident); ok {
+			names = append(names, ident.Name+"."+d.Name.Name)
+		}
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *ast.ValueSpec:
+				for _, name := range s.Names {
+					names = append(names, name.Name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+func declDoc(decl ast.Decl) *ast.CommentGroup {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		return d.Doc
+	case *ast.GenDecl:
+		return d.Doc
+	}
+	return nil
+}
+
+func allExist(names []string, existing map[string]bool) bool {
+	for _, name := range names {
+		if !existing[name] {
+			return false
+		}
+	}
+	return len(names) > 0
+}
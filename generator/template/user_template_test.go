@@ -0,0 +1,70 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vetcher/go-astra/types"
+)
+
+const testUserTemplate = `---
+tag: audit
+path: service/{{.File "audit"}}.go
+---
+{{range .Methods}}{{.Name}} {{.HTTPMethod}} /{{.HTTPPath}}{{range .Args}} {{.Name}}:{{.Type}}{{end}}
+{{end}}`
+
+func TestUserTemplate(t *testing.T) {
+	tmpl, err := ParseUserTemplate("audit.tmpl", []byte(testUserTemplate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "audit", tmpl.Tag)
+	assert.Equal(t, UserStrategyCreate, tmpl.Strategy)
+
+	stringType := types.TName{TypeName: "string"}
+	info := &GenerationInfo{
+		Iface: &types.Interface{
+			Base: types.Base{Name: "StringService"},
+			Methods: []*types.Function{
+				{
+					Base: types.Base{Name: "Count", Docs: []string{"// @http-method GET"}},
+					Args: []types.Variable{
+						{Base: types.Base{Name: "ctx"}, Type: types.TImport{Import: &types.Import{Package: "context"}, Next: types.TName{TypeName: "Context"}}},
+						{Base: types.Base{Name: "text"}, Type: stringType},
+					},
+				},
+				{Base: types.Base{Name: "Hidden"}},
+			},
+		},
+		AllowedMethods: map[string]bool{"Count": true},
+		Namespace:      "StringService",
+	}
+	ctx := WithTags(context.Background(), TagsSet{"audit": {}})
+	ut := tmpl.For(info)
+	if err := ut.Prepare(ctx); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "service/string_service_audit.go", ut.DefaultPath())
+
+	var buf bytes.Buffer
+	if err := ut.Render(ctx).Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Count GET /count/{text} text:string\n", buf.String())
+}
+
+func TestParseUserTemplateErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"no front-matter":  "package a\n",
+		"not closed":       "---\ntag: a\npath: a.go\n",
+		"no tag":           "---\npath: a.go\n---\n",
+		"unknown strategy": "---\ntag: a\npath: a.go\nstrategy: replace\n---\n",
+		"unknown field":    "---\ntag: a\npath: a.go\nout: b\n---\n",
+	} {
+		_, err := ParseUserTemplate(name, []byte(content))
+		assert.Error(t, err, name)
+	}
+}
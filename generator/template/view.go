@@ -0,0 +1,135 @@
+package template
+
+import (
+	"strings"
+
+	mstrings "github.com/recolabs/microgen/generator/strings"
+	"github.com/vetcher/go-astra/types"
+)
+
+// View is a stable description of generated service, which is passed to user templates as data.
+// It does not expose parser types, so user templates do not depend on microgen internals.
+//
+//		{{range .Methods}}
+//		// {{.Name}} is exposed as {{.HTTPMethod}} /{{.HTTPPath}}
+//		{{end}}
+//
+type View struct {
+	// Name of service interface, e.g. StringService.
+	Name string
+	// Docs are comments of service interface, including microgen tags.
+	Docs []string
+	// Namespace is not empty, when several services are generated into one tree. It equals to Name then.
+	Namespace string
+	// SourcePackage is an import path of package with service interface.
+	SourcePackage string
+	// SourcePath is an absolute path of file with service interface.
+	SourcePath string
+	// OutputPackage is an import path of package, where code is generated.
+	OutputPackage string
+	// OutputDir is an absolute path of output directory.
+	OutputDir string
+	// ProtobufPackage is an import path of package with protobuf types from `// @protobuf` tag.
+	ProtobufPackage string
+	// FileHeader is a comment, which should be the first line of generated go files.
+	FileHeader string
+	// Tags are sorted generation tags of service.
+	Tags []string
+	// Methods of service, except methods, excluded from generation with `// @microgen -`.
+	Methods []MethodView
+}
+
+// MethodView describes method of service interface.
+type MethodView struct {
+	Name string
+	Docs []string
+	// Context is a name of the first context.Context argument.
+	Context string
+	// Args are arguments of method without the first context.Context.
+	Args []VarView
+	// Results are results of method without the last error.
+	Results []VarView
+	// Error is a name of the last error result.
+	Error string
+	// HTTPMethod is a method from `// @http-method` tag or default POST.
+	// It is GET for many-to-one and many-to-many methods, which are served over WebSocket.
+	HTTPMethod string
+	// HTTPPath is a path from `// @http-path` tag or default path, built from method name, without leading slash.
+	HTTPPath string
+	// Stream is `one-to-many`, `many-to-one` or `many-to-many` for stream methods and empty string otherwise.
+	Stream string
+}
+
+// VarView is an argument or result of method.
+type VarView struct {
+	Name string
+	// Type is a type as it is written in source file, e.g. `*User` or `[]string`.
+	Type string
+}
+
+// NewView returns view of service from generation info.
+func NewView(info *GenerationInfo, tags TagsSet) *View {
+	v := &View{
+		Name:            info.Iface.Name,
+		Docs:            info.Iface.Docs,
+		Namespace:       info.Namespace,
+		SourcePackage:   info.SourcePackageImport,
+		SourcePath:      info.SourceFilePath,
+		OutputPackage:   info.OutputPackageImport,
+		OutputDir:       info.OutputFilePath,
+		ProtobufPackage: info.ProtobufPackageImport,
+		FileHeader:      info.FileHeader,
+		Tags:            tags.List(),
+	}
+	for _, fn := range info.Iface.Methods {
+		if !info.AllowedMethods[fn.Name] {
+			continue
+		}
+		m := MethodView{
+			Name:       fn.Name,
+			Docs:       fn.Docs,
+			Context:    firstArgName(fn),
+			Args:       varViews(RemoveContextIfFirst(fn.Args)),
+			Results:    varViews(removeErrorIfLast(fn.Results)),
+			Error:      nameOfLastResultError(fn),
+			HTTPMethod: FetchHttpMethodTag(fn.Docs),
+			HTTPPath:   strings.TrimPrefix(buildMethodPath(fn), "/"),
+		}
+		switch {
+		case info.OneToManyStreamMethods[fn.Name]:
+			m.Stream = "one-to-many"
+		case info.ManyToOneStreamMethods[fn.Name]:
+			m.Stream = "many-to-one"
+			m.HTTPMethod = "GET"
+		case info.ManyToManyStreamMethods[fn.Name]:
+			m.Stream = "many-to-many"
+			m.HTTPMethod = "GET"
+		}
+		v.Methods = append(v.Methods, m)
+	}
+	return v
+}
+
+// HasTag reports whether service is generated with tag.
+func (v *View) HasTag(tag string) bool {
+	return mstrings.IsInStringSlice(tag, v.Tags)
+}
+
+// File returns file name with service namespace, as built-in templates do.
+//
+//		audit -> string_service_audit
+//
+func (v *View) File(name string) string {
+	if v.Namespace == "" {
+		return name
+	}
+	return mstrings.ToSnakeCase(v.Namespace) + "_" + name
+}
+
+func varViews(vars []types.Variable) []VarView {
+	views := make([]VarView, len(vars))
+	for i := range vars {
+		views[i] = VarView{Name: vars[i].Name, Type: vars[i].Type.String()}
+	}
+	return views
+}
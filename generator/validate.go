@@ -36,6 +36,12 @@ func validateFunction(fn *types.Function, pbGoFile *types.File) (errs []error) {
 				errs = append(errs, fmt.Errorf("%s: unnamed parameter of type %s", fn.Name, param.Type.String()))
 			}
 		}
+		if err := validateHTTPRequest(fn); err != nil {
+			errs = append(errs, err)
+		}
+		if _, err := template.ProtoFieldNumbers(fn); err != nil {
+			errs = append(errs, err)
+		}
 		return
 	}
 	if mstrings.ContainTag(mstrings.FetchTags(fn.Docs, TagMark+MicrogenMainTag), "many-to-many") {
@@ -74,8 +80,11 @@ func validateFunction(fn *types.Function, pbGoFile *types.File) (errs []error) {
 			errs = append(errs, fmt.Errorf("%s: raw function %s is not allowed, declare it outside", fn.Name, param.Name))
 		}
 	}
-	if template.FetchHttpMethodTag(fn.Docs) == "GET" && !isArgumentsAllowSmartPath(fn) {
-		errs = append(errs, fmt.Errorf("%s: can't use GET method with provided arguments", fn.Name))
+	if err := validateHTTPRequest(fn); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := template.ProtoFieldNumbers(fn); err != nil {
+		errs = append(errs, err)
 	}
 	if pbGoFile != nil {
 		errs = append(errs, validateFuncionInPbGoFile(fn, pbGoFile)...)
@@ -202,8 +211,24 @@ func validateFuncionInPbGoFile(fn *types.Function, pbGoFile *types.File) (errs [
 	return
 }
 
+// Arguments of GET method without @http-query, @http-header and @http-path-param tags are path variables.
+// Bound arguments are checked by template.ValidateHTTPBinding.
+// Checks, that arguments of method can be transferred in HTTP request.
+func validateHTTPRequest(fn *types.Function) error {
+	if err := template.ValidateHTTPBinding(fn); err != nil {
+		return err
+	}
+	if template.FetchHttpMethodTag(fn.Docs) == "GET" && !isArgumentsAllowSmartPath(fn) {
+		return fmt.Errorf("%s: can't use GET method with provided arguments", fn.Name)
+	}
+	return nil
+}
+
 func isArgumentsAllowSmartPath(fn *types.Function) bool {
-	for _, arg := range template.RemoveContextIfFirst(fn.Args) {
+	if template.HasHTTPBinding(fn) {
+		return true
+	}
+	for _, arg := range template.HTTPRequestArgs(fn) {
 		if !canInsertToPath(&arg) {
 			return false
 		}
@@ -0,0 +1,341 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"github.com/recolabs/microgen/generator/protofile"
+	mstrings "github.com/recolabs/microgen/generator/strings"
+	"github.com/recolabs/microgen/generator/template"
+	"github.com/vetcher/go-astra/types"
+)
+
+// Go types, which may be used for protobuf scalar types.
+var goTypesOfProtoScalar = map[string][]string{
+	"int32":   {"int32", "int16", "int8"},
+	"int64":   {"int64", "int", "int32", "int16", "int8"},
+	"uint32":  {"uint32", "uint16", "uint8"},
+	"uint64":  {"uint64", "uint", "uint32", "uint16", "uint8"},
+	"float32": {"float32"},
+	"float64": {"float64", "float32"},
+	"bool":    {"bool"},
+	"string":  {"string"},
+}
+
+// ValidateInterfaceProto checks, that interface, declared in goFile, matches service of protobuf file:
+// names of service, rpcs and messages, streaming of methods, names and types of fields.
+// Errors point to declarations in both files.
+func ValidateInterfaceProto(iface *types.Interface, goFile string, protoFile *protofile.File) error {
+	v := &protoValidator{
+		proto: protoFile,
+		pos:   findGoPositions(goFile, iface.Name),
+	}
+	svc := protoFile.Service(iface.Name)
+	if svc == nil {
+		return fmt.Errorf("%s: service %s is not declared in %s", v.pos.iface, iface.Name, protoFile.Name)
+	}
+	var errs []error
+	methods := make(map[string]bool)
+	for _, fn := range iface.Methods {
+		methods[fn.Name] = true
+		if mstrings.ContainTag(mstrings.FetchTags(fn.Docs, TagMark+MicrogenMainTag), "-") {
+			continue
+		}
+		errs = append(errs, v.validateMethod(svc, fn)...)
+	}
+	for _, rpc := range svc.RPCs {
+		if !methods[rpc.Name] {
+			errs = append(errs, fmt.Errorf("%s: rpc %s is not a method of %s (%s)", rpc.Pos, rpc.Name, iface.Name, v.pos.iface))
+		}
+	}
+	return composeErrors(errs...)
+}
+
+type protoValidator struct {
+	proto *protofile.File
+	pos   *goPositions
+}
+
+func (v *protoValidator) errorf(goPos token.Position, protoPos protofile.Pos, format string, args ...interface{}) error {
+	return fmt.Errorf("%s: %s (%s)", goPos, fmt.Sprintf(format, args...), protoPos)
+}
+
+func (v *protoValidator) validateMethod(svc *protofile.Service, fn *types.Function) []error {
+	var rpc *protofile.RPC
+	for _, r := range svc.RPCs {
+		if r.Name == fn.Name {
+			rpc = r
+		}
+	}
+	pos := v.pos.method(fn.Name)
+	if rpc == nil {
+		return []error{v.errorf(pos, svc.Pos, "%s: rpc %s is not declared in service %s", fn.Name, fn.Name, svc.Name)}
+	}
+	tags := mstrings.FetchTags(fn.Docs, TagMark+MicrogenMainTag)
+	var (
+		oneToMany  = mstrings.ContainTag(tags, "one-to-many")
+		manyToOne  = mstrings.ContainTag(tags, "many-to-one")
+		manyToMany = mstrings.ContainTag(tags, "many-to-many")
+	)
+	clientStreaming, serverStreaming := manyToOne || manyToMany, oneToMany || manyToMany
+	if clientStreaming != rpc.ClientStreaming || serverStreaming != rpc.ServerStreaming {
+		return []error{v.errorf(pos, rpc.Pos, "%s: method is %s, but rpc is %s", fn.Name,
+			streamingKind(clientStreaming, serverStreaming), streamingKind(rpc.ClientStreaming, rpc.ServerStreaming))}
+	}
+
+	var errs []error
+	args := template.RemoveContextIfFirst(fn.Args)
+	if oneToMany && len(args) > 0 {
+		args = args[:len(args)-1]
+	}
+	if clientStreaming {
+		errs = append(errs, v.validateStreamedMessage(fn, rpc, rpc.Request)...)
+	} else {
+		errs = append(errs, v.validateMessage(fn, rpc, rpc.Request, fn.Name+"Request", args, "argument")...)
+	}
+	if serverStreaming || clientStreaming {
+		errs = append(errs, v.validateStreamedMessage(fn, rpc, rpc.Response)...)
+	} else {
+		results := fn.Results
+		if template.IsErrorLast(results) {
+			results = results[:len(results)-1]
+		}
+		errs = append(errs, v.validateMessage(fn, rpc, rpc.Response, fn.Name+"Response", results, "result")...)
+	}
+	return errs
+}
+
+// Fields of streamed messages are declared by hand, so only declaration of message is checked.
+// Messages of imported files can not be checked.
+func (v *protoValidator) validateStreamedMessage(fn *types.Function, rpc *protofile.RPC, typeName string) []error {
+	name := v.messageName(typeName)
+	if v.proto.Message(name) == nil && !strings.Contains(name, ".") {
+		return []error{v.errorf(v.pos.method(fn.Name), rpc.Pos, "%s: streamed message %s is not declared in %s", fn.Name, name, v.proto.Name)}
+	}
+	return nil
+}
+
+func (v *protoValidator) validateMessage(fn *types.Function, rpc *protofile.RPC, typeName, def string, params []types.Variable, kind string) []error {
+	expected := template.ProtoMessageName(params, def)
+	name := v.messageName(typeName)
+	if name != expected {
+		return []error{v.errorf(v.pos.method(fn.Name), rpc.Pos, "%s: message of %ss should be %s, but rpc uses %s", fn.Name, kind, expected, name)}
+	}
+	if expected != def {
+		// Empty, wrapper or Timestamp is chosen by type of the single parameter.
+		return nil
+	}
+	m := v.proto.Message(name)
+	if m == nil {
+		return []error{v.errorf(v.pos.method(fn.Name), rpc.Pos, "%s: message %s is not declared in %s", fn.Name, name, v.proto.Name)}
+	}
+	var errs []error
+	matched := make(map[*protofile.Field]bool)
+	for _, param := range params {
+		pos := v.pos.param(fn.Name, param.Name)
+		field := findProtoField(m, param.Name)
+		if field == nil {
+			errs = append(errs, v.errorf(pos, m.Pos, "%s: %s %s has no field %s in message %s", fn.Name, kind, param.Name, mstrings.ToSnakeCase(param.Name), m.Name))
+			continue
+		}
+		matched[field] = true
+		if !v.compatible(param.Type, field) {
+			errs = append(errs, v.errorf(pos, field.Pos, "%s: %s %s of type %s is not compatible with field %s %s of message %s",
+				fn.Name, kind, param.Name, param.Type, protoFieldTypeString(field), field.Name, m.Name))
+		}
+	}
+	for _, field := range m.Fields {
+		if !matched[field] {
+			errs = append(errs, v.errorf(v.pos.method(fn.Name), field.Pos, "%s: field %s of message %s has no %s", fn.Name, field.Name, m.Name, kind))
+		}
+	}
+	return errs
+}
+
+// Name of message without package of file.
+func (v *protoValidator) messageName(typeName string) string {
+	name := strings.TrimPrefix(typeName, ".")
+	if v.proto.Package != "" {
+		name = strings.TrimPrefix(name, v.proto.Package+".")
+	}
+	return name
+}
+
+// Field is named as snake_case of parameter or as parameter in other case.
+// Trailing `_` of parameter, which is added to keywords and used names, is ignored.
+func findProtoField(m *protofile.Message, name string) *protofile.Field {
+	name = strings.TrimRight(name, "_")
+	snake := mstrings.ToSnakeCase(name)
+	for _, field := range m.Fields {
+		if field.Name == snake {
+			return field
+		}
+	}
+	for _, field := range m.Fields {
+		if strings.EqualFold(strings.Replace(field.Name, "_", "", -1), name) {
+			return field
+		}
+	}
+	return nil
+}
+
+func protoFieldTypeString(field *protofile.Field) string {
+	switch {
+	case field.IsMap():
+		return fmt.Sprintf("map<%s, %s>", field.KeyType, field.Type)
+	case field.Label != "":
+		return field.Label + " " + field.Type
+	}
+	return field.Type
+}
+
+func (v *protoValidator) compatible(t types.Type, field *protofile.Field) bool {
+	switch {
+	case field.IsMap():
+		m, ok := t.(types.TMap)
+		return ok && v.compatibleType(m.Key, field.KeyType, false) && v.compatibleType(m.Value, field.Type, false)
+	case field.IsRepeated():
+		a, ok := t.(types.TArray)
+		return ok && a.IsSlice && v.compatibleType(a.Next, field.Type, false)
+	}
+	return v.compatibleType(t, field.Type, field.Label == protofile.LabelOptional)
+}
+
+// Checks go type against protobuf type. Named types of other packages and types, which are declared
+// in imported protobuf files, can not be checked, so they are compatible.
+func (v *protoValidator) compatibleType(t types.Type, protoType string, optional bool) bool {
+	name := strings.TrimPrefix(protoType, ".")
+	elem := t
+	ptr, isPtr := t.(types.TPointer)
+	if isPtr && ptr.NumberOfPointers == 1 {
+		elem = ptr.Next
+	}
+	if wkt, ok := template.WellKnownProtoType(t); ok {
+		return wkt == name
+	}
+	switch name {
+	case "bytes", "google.protobuf.BytesValue":
+		return t.String() == "[]byte"
+	case "google.protobuf.Timestamp":
+		return elem.String() == "time.Time"
+	case "google.protobuf.Duration":
+		return t.String() == "time.Duration"
+	case "google.protobuf.Struct":
+		return t.String() == "map[string]interface{}"
+	case "google.protobuf.Value", "google.protobuf.Any":
+		return t.String() == "interface{}"
+	case "google.protobuf.ListValue":
+		return t.String() == "[]interface{}"
+	case "google.protobuf.FieldMask":
+		return t.String() == "[]string"
+	}
+	if scalar, ok := protoWrapperTypes[name]; ok {
+		return isPtr && compatibleScalar(ptr.Next, scalar)
+	}
+	if scalar, ok := protoScalarTypes[name]; ok {
+		if isPtr && !optional {
+			return false
+		}
+		return compatibleScalar(elem, scalar)
+	}
+	if m := v.proto.Message(name); m != nil {
+		typeName := types.TypeName(elem)
+		return typeName != nil && (*typeName == m.Name || *typeName == goTypeName(m.FullName))
+	}
+	if v.proto.Enum(name) != nil {
+		// Enums of service may be named integer or string types.
+		return compatibleScalar(elem, "int64")
+	}
+	return true
+}
+
+// Named types, which are not builtin, may have any underlying type.
+func compatibleScalar(t types.Type, scalar string) bool {
+	if !types.IsBuiltin(t) {
+		_, named := t.(types.TName)
+		_, imported := t.(types.TImport)
+		return named || imported
+	}
+	return mstrings.IsInStringSlice(t.String(), goTypesOfProtoScalar[scalar])
+}
+
+func streamingKind(client, server bool) string {
+	switch {
+	case client && server:
+		return "many-to-many (bidirectional streaming)"
+	case client:
+		return "many-to-one (client streaming)"
+	case server:
+		return "one-to-many (server streaming)"
+	}
+	return "unary"
+}
+
+// goPositions are positions of interface, its methods and their parameters in go file.
+type goPositions struct {
+	iface   token.Position
+	methods map[string]token.Position
+	// Positions of parameters by method and parameter names, separated by dot.
+	params map[string]token.Position
+}
+
+// Positions of methods, which are not found (e.g. methods of embedded interfaces), are positions of interface.
+func (p *goPositions) method(name string) token.Position {
+	if pos, ok := p.methods[name]; ok {
+		return pos
+	}
+	return p.iface
+}
+
+func (p *goPositions) param(method, name string) token.Position {
+	if pos, ok := p.params[method+"."+name]; ok {
+		return pos
+	}
+	return p.method(method)
+}
+
+func findGoPositions(filename, ifaceName string) *goPositions {
+	p := &goPositions{
+		iface:   token.Position{Filename: filename},
+		methods: make(map[string]token.Position),
+		params:  make(map[string]token.Position),
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, nil, 0)
+	if err != nil {
+		return p
+	}
+	ast.Inspect(file, func(node ast.Node) bool {
+		spec, ok := node.(*ast.TypeSpec)
+		if !ok || spec.Name.Name != ifaceName {
+			return true
+		}
+		iface, ok := spec.Type.(*ast.InterfaceType)
+		if !ok {
+			return false
+		}
+		p.iface = fset.Position(spec.Pos())
+		for _, method := range iface.Methods.List {
+			fn, ok := method.Type.(*ast.FuncType)
+			if !ok || len(method.Names) == 0 {
+				continue
+			}
+			name := method.Names[0].Name
+			p.methods[name] = fset.Position(method.Pos())
+			fields := append([]*ast.Field(nil), fn.Params.List...)
+			if fn.Results != nil {
+				fields = append(fields, fn.Results.List...)
+			}
+			for _, field := range fields {
+				for _, param := range field.Names {
+					p.params[name+"."+param.Name] = fset.Position(param.Pos())
+				}
+			}
+		}
+		return false
+	})
+	return p
+}
@@ -0,0 +1,108 @@
+package generator
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/recolabs/microgen/generator/protofile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vetcher/go-astra"
+)
+
+const validateProtoTestSource = `package svc
+
+import (
+	"context"
+	"time"
+
+	"example.com/svc/pb"
+)
+
+// @microgen grpc
+type StringService interface {
+	Count(ctx context.Context, text string, userID int64, created time.Time, tags []string) (count int, err error)
+	Get(ctx context.Context, value *string) (kind Kind, err error)
+	// @microgen one-to-many
+	Watch(text string, stream pb.StringService_WatchServer) (err error)
+	// @microgen many-to-one
+	Upload(stream pb.StringService_UploadServer) (err error)
+	Missing(ctx context.Context) (err error)
+	// @microgen -
+	Ignored()
+}
+
+type Kind int
+`
+
+const validateProtoTestFile = `syntax = "proto3";
+
+package svc;
+
+import "google/protobuf/wrappers.proto";
+import "google/protobuf/timestamp.proto";
+
+service StringService {
+    rpc Count (CountRequest) returns (CountResponse);
+    rpc Get (google.protobuf.StringValue) returns (GetResponse);
+    rpc Watch (WatchRequest) returns (WatchResponse);
+    rpc Upload (stream Chunk) returns (UploadResult);
+    rpc Extra (ExtraRequest) returns (ExtraResponse);
+}
+
+message CountRequest {
+    string text = 1;
+    int64 user_id = 2;
+    google.protobuf.Timestamp created = 3;
+    string tags = 4;
+    string symbol = 5;
+}
+
+message CountResponse {
+    int32 count = 1;
+}
+
+message GetResponse {
+    Kind kind = 1;
+}
+
+enum Kind {
+    KIND_UNSPECIFIED = 0;
+}
+
+message Chunk {
+    bytes data = 1;
+}
+`
+
+func TestValidateInterfaceProto(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "svc.go")
+	require.NoError(t, ioutil.WriteFile(source, []byte(validateProtoTestSource), 0644))
+	file, err := astra.ParseFile(source)
+	require.NoError(t, err)
+	protoFile, err := protofile.Parse("api.proto", []byte(validateProtoTestFile))
+	require.NoError(t, err)
+
+	err = ValidateInterfaceProto(&file.Interfaces[0], source, protoFile)
+	require.Error(t, err)
+	lines := strings.Split(err.Error(), "\n")[1:]
+	for i := range lines {
+		lines[i] = strings.Replace(lines[i], dir+string(filepath.Separator), "", -1)
+	}
+	assert.Equal(t, []string{
+		"svc.go:12:75: Count: argument tags of type []string is not compatible with field string tags of message CountRequest (api.proto:20:5)",
+		"svc.go:12:2: Count: field symbol of message CountRequest has no argument (api.proto:21:5)",
+		"svc.go:12:91: Count: result count of type int is not compatible with field int32 count of message CountResponse (api.proto:25:5)",
+		"svc.go:15:2: Watch: method is one-to-many (server streaming), but rpc is unary (api.proto:11:5)",
+		"svc.go:17:2: Upload: streamed message UploadResult is not declared in api.proto (api.proto:12:5)",
+		"svc.go:18:2: Missing: rpc Missing is not declared in service StringService (api.proto:8:1)",
+		"api.proto:13:5: rpc Extra is not a method of StringService (svc.go:11:6)",
+	}, lines)
+
+	protoFile.Services[0].Name = "Other"
+	err = ValidateInterfaceProto(&file.Interfaces[0], source, protoFile)
+	assert.EqualError(t, err, source+":11:6: service StringService is not declared in api.proto")
+}
@@ -58,23 +58,14 @@ func (s createFileStrategy) Write(renderer Renderer) error {
 
 // Copied from original github.com/dave/jennifer/jen.go func Save()
 func (s createFileStrategy) Save(f Renderer, filename string) error {
-	buf := &bytes.Buffer{}
-	if err := f.Render(buf); err != nil {
+	formatted, err := s.content(f)
+	if err != nil {
 		return err
 	}
 	// Stop saving because nothing to save
-	if len(buf.Bytes()) == 0 {
+	if len(formatted) == 0 {
 		return nil
 	}
-	var err error
-	formatted := buf.Bytes()
-	if s.formatOn {
-		formatted, err = format.Source(formatted)
-		if err != nil {
-			fmt.Println(buf.String())
-			return fmt.Errorf("error when format source: %v", err)
-		}
-	}
 	if err := ioutil.WriteFile(filename, formatted, 0644); err != nil {
 		return err
 	}
@@ -82,6 +73,26 @@ func (s createFileStrategy) Save(f Renderer, filename string) error {
 	return nil
 }
 
+// Renders and formats content of file. Returns nil, when renderer returns nothing.
+func (s createFileStrategy) content(f Renderer) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := f.Render(buf); err != nil {
+		return nil, err
+	}
+	if len(buf.Bytes()) == 0 {
+		return nil, nil
+	}
+	if !s.formatOn {
+		return buf.Bytes(), nil
+	}
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		fmt.Println(buf.String())
+		return nil, fmt.Errorf("error when format source: %v", err)
+	}
+	return formatted, nil
+}
+
 func NewCreateFileStrategy(absPath, relPath string) Strategy {
 	return createFileStrategy{
 		absPath:  absPath,
@@ -144,29 +155,40 @@ func (s appendFileStrategy) Write(renderer Renderer) error {
 }
 
 func (s appendFileStrategy) Save(renderer Renderer, filename string) error {
-	buf := &bytes.Buffer{}
-	if err := renderer.Render(buf); err != nil {
+	formatted, err := s.content(renderer)
+	if err != nil {
 		return err
 	}
-
 	// Stop saving because nothing
-	if len(buf.Bytes()) == 0 {
+	if len(formatted) == 0 {
 		return nil
 	}
-	// Use trick for top-level formatting.
-	formatted, err := format.Source(append([]byte(formatTrick), buf.Bytes()...))
-	if err != nil {
-		fmt.Println(buf.String())
-		return fmt.Errorf("error when format source: %v", err)
-	}
 
 	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
 		return err
 	}
-	if _, err = f.Write(formatted[len(formatTrick):]); err != nil {
+	if _, err = f.Write(formatted); err != nil {
 		return err
 	}
 	lg.Logger.Logln(2, AppendFileMark, filepath.Join(s.absPath, s.relPath))
 	return nil
 }
+
+// Renders and formats code, that should be appended to file. Returns nil, when renderer returns nothing.
+func (s appendFileStrategy) content(renderer Renderer) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := renderer.Render(buf); err != nil {
+		return nil, err
+	}
+	if len(buf.Bytes()) == 0 {
+		return nil, nil
+	}
+	// Use trick for top-level formatting.
+	formatted, err := format.Source(append([]byte(formatTrick), buf.Bytes()...))
+	if err != nil {
+		fmt.Println(buf.String())
+		return nil, fmt.Errorf("error when format source: %v", err)
+	}
+	return formatted[len(formatTrick):], nil
+}
@@ -1,28 +1,268 @@
 package write_strategy
 
-/*
 import (
 	"fmt"
-	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
 
-	"github.com/recolabs/microgen/generator/template"
+	lg "github.com/recolabs/microgen/logger"
 )
 
-type writerStrategy struct {
-	writer io.Writer
+// Action is a change of file on disk, that strategy would make.
+type Action string
+
+const (
+	CreateAction    Action = "create"
+	OverwriteAction Action = "overwrite"
+	AppendAction    Action = "append"
+)
+
+// Files is an in-memory set of generated files, keyed by absolute path.
+// It is filled by memory strategies instead of file system and records actions, that strategies would make.
+type Files struct {
+	files   map[string][]byte
+	actions map[string]Action
+}
+
+func NewFiles() *Files {
+	return &Files{
+		files:   make(map[string][]byte),
+		actions: make(map[string]Action),
+	}
+}
+
+// Paths returns sorted absolute paths of all files.
+func (f *Files) Paths() []string {
+	paths := make([]string, 0, len(f.files))
+	for p := range f.files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Get returns content of file by absolute path.
+func (f *Files) Get(path string) ([]byte, bool) {
+	content, ok := f.files[path]
+	return content, ok
+}
+
+// Action returns the first action, that was made with file.
+func (f *Files) Action(path string) Action {
+	return f.actions[path]
+}
+
+func (f *Files) put(path string, content []byte, action Action) {
+	f.files[path] = content
+	if _, ok := f.actions[path]; ok {
+		return
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		action = CreateAction
+	}
+	f.actions[path] = action
 }
 
-func (s writerStrategy) Write(f template.Renderer, t template.Template) error {
-	err := f.Render(s.writer)
+// ActionOf returns action, that strategy makes with file on disk, and absolute path of the file.
+// Returns empty action for strategies, that do not write files.
+func ActionOf(s Strategy) (Action, string) {
+	var absPath, relPath string
+	var action Action
+	switch st := s.(type) {
+	case createFileStrategy:
+		absPath, relPath, action = st.absPath, st.relPath, OverwriteAction
+	case appendFileStrategy:
+		absPath, relPath, action = st.absPath, st.relPath, AppendAction
+	default:
+		return "", ""
+	}
+	outpath, err := filepath.Abs(filepath.Join(absPath, relPath))
 	if err != nil {
-		return fmt.Errorf("render error: %v", err)
+		return "", ""
+	}
+	return action, outpath
+}
+
+type memoryStrategy struct {
+	files    *Files
+	strategy Strategy
+}
+
+// NewMemoryStrategy returns strategy, which renders code in the same way, as given strategy,
+// but puts the result to files instead of writing it to disk.
+// Appended code is added to the file from files or, when it is not there yet, to the file from disk.
+func NewMemoryStrategy(files *Files, strategy Strategy) Strategy {
+	return memoryStrategy{
+		files:    files,
+		strategy: strategy,
+	}
+}
+
+func (s memoryStrategy) Write(renderer Renderer) error {
+	switch st := s.strategy.(type) {
+	case createFileStrategy:
+		content, err := st.content(renderer)
+		if err != nil || content == nil {
+			return err
+		}
+		outpath, err := filepath.Abs(filepath.Join(st.absPath, st.relPath))
+		if err != nil {
+			return fmt.Errorf("unable to resolve path: %v", err)
+		}
+		s.files.put(outpath, content, OverwriteAction)
+	case appendFileStrategy:
+		content, err := st.content(renderer)
+		if err != nil || content == nil {
+			return err
+		}
+		outpath, err := filepath.Abs(filepath.Join(st.absPath, st.relPath))
+		if err != nil {
+			return fmt.Errorf("unable to resolve path: %v", err)
+		}
+		base, ok := s.files.files[outpath]
+		if !ok {
+			base, err = ioutil.ReadFile(outpath)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		s.files.put(outpath, append(append([]byte{}, base...), content...), AppendAction)
+	case nopStrategy:
+	default:
+		return fmt.Errorf("strategy %T can not write to memory", s.strategy)
+	}
+	return nil
+}
+
+// rename is replaced in tests to fail in the middle of commit.
+var rename = os.Rename
+
+// Commit writes files to disk. Every file is written to temporary file in its directory first,
+// and temporary files are renamed, when all of them are written. Existing files are moved to backup files
+// before they are replaced. When writing or renaming fails, replaced files are restored from backups,
+// created files, temporary files and created directories are removed, so files on disk stay untouched.
+func (f *Files) Commit() error {
+	var (
+		temps    = make(map[string]string, len(f.files))
+		backups  = make(map[string]string)
+		created  []string
+		replaced []string
+	)
+	cleanup := func() {
+		for i := len(replaced) - 1; i >= 0; i-- {
+			path := replaced[i]
+			if backup, ok := backups[path]; ok {
+				rename(backup, path)
+				delete(backups, path)
+			} else {
+				os.Remove(path)
+			}
+		}
+		// File is moved to backup, but is not replaced.
+		for path, backup := range backups {
+			rename(backup, path)
+		}
+		for _, tmp := range temps {
+			os.Remove(tmp)
+		}
+		for i := len(created) - 1; i >= 0; i-- {
+			os.RemoveAll(created[i])
+		}
+	}
+	for _, path := range f.Paths() {
+		dir := filepath.Dir(path)
+		if missing := firstMissingDir(dir); missing != "" {
+			if err := os.MkdirAll(dir, MkdirPermissions); err != nil {
+				cleanup()
+				return fmt.Errorf("unable to create directory %s: %v", dir, err)
+			}
+			created = append(created, missing)
+		}
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			cleanup()
+			return fmt.Errorf("%s is dir", path)
+		}
+		tmp, err := writeTempFile(dir, filepath.Base(path), f.files[path])
+		if err != nil {
+			cleanup()
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		temps[path] = tmp
+	}
+	for _, path := range f.Paths() {
+		if _, err := os.Stat(path); err == nil {
+			backup, err := backupFile(path)
+			if err != nil {
+				cleanup()
+				return fmt.Errorf("unable to backup %s: %v", path, err)
+			}
+			backups[path] = backup
+		}
+		if err := rename(temps[path], path); err != nil {
+			cleanup()
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		delete(temps, path)
+		replaced = append(replaced, path)
+	}
+	for _, backup := range backups {
+		os.Remove(backup)
+	}
+	for _, path := range replaced {
+		if f.actions[path] == AppendAction {
+			lg.Logger.Logln(2, AppendFileMark, path)
+		} else {
+			lg.Logger.Logln(2, NewFileMark, path)
+		}
 	}
 	return nil
 }
 
-func NewWriterStrategy(writer io.Writer) template.Strategy {
-	return writerStrategy{
-		writer: writer,
+// backupFile moves file to new backup file in its directory and returns path of backup.
+func backupFile(path string) (string, error) {
+	backup, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*.bak")
+	if err != nil {
+		return "", err
+	}
+	backup.Close()
+	if err := rename(path, backup.Name()); err != nil {
+		os.Remove(backup.Name())
+		return "", err
+	}
+	return backup.Name(), nil
+}
+
+func writeTempFile(dir, name string, content []byte) (string, error) {
+	tmp, err := ioutil.TempFile(dir, "."+name+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// Returns the topmost directory of dir path, which does not exist, or empty string, when dir exists.
+func firstMissingDir(dir string) string {
+	missing := ""
+	for ; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil {
+			return missing
+		}
+		missing = dir
+		if filepath.Dir(dir) == dir {
+			return missing
+		}
 	}
 }
-*/
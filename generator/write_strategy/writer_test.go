@@ -0,0 +1,108 @@
+package write_strategy
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stringRenderer string
+
+func (r stringRenderer) Render(w io.Writer) error {
+	_, err := io.WriteString(w, string(r))
+	return err
+}
+
+func listDir(t *testing.T, dir string) []string {
+	var paths []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		paths = append(paths, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return paths
+}
+
+func TestFilesCommit(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	files := NewFiles()
+	for path, code := range map[string]string{"a.go": "func A() {}\n", "sub/b.go": "package b\n"} {
+		strategy := NewCreateFileStrategy(dir, path)
+		if path == "a.go" {
+			strategy = NewAppendToFileStrategy(dir, path)
+		}
+		if err := NewMemoryStrategy(files, strategy).Write(stringRenderer(code)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := files.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{".", "a.go", "sub", "sub/b.go"}, listDir(t, dir))
+	content, _ := ioutil.ReadFile(filepath.Join(dir, "a.go"))
+	assert.Equal(t, "package a\n\nfunc A() {}\n", string(content))
+	info, err := os.Stat(filepath.Join(dir, "sub", "b.go"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+	}
+}
+
+func TestFilesCommitFailure(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "z.go"), MkdirPermissions); err != nil {
+		t.Fatal(err)
+	}
+	files := NewFiles()
+	for _, path := range []string{"a.go", "new/b.go", "z.go"} {
+		if err := NewMemoryStrategy(files, NewCreateFileStrategy(dir, path)).Write(stringRenderer("package a\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assert.Error(t, files.Commit())
+	assert.Equal(t, []string{".", "z.go"}, listDir(t, dir))
+}
+
+func TestFilesCommitRenameFailure(t *testing.T) {
+	dir := t.TempDir()
+	for _, path := range []string{"a.go", "c.go"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, path), []byte("package old\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := NewFiles()
+	for _, path := range []string{"a.go", "new/b.go", "c.go"} {
+		if err := NewMemoryStrategy(files, NewCreateFileStrategy(dir, path)).Write(stringRenderer("package a\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Rename of the last file fails after a.go and new/b.go are replaced.
+	defer func() { rename = os.Rename }()
+	rename = func(from, to string) error {
+		if to == filepath.Join(dir, "c.go") && filepath.Ext(from) == ".tmp" {
+			return os.ErrPermission
+		}
+		return os.Rename(from, to)
+	}
+
+	assert.Error(t, files.Commit())
+	assert.Equal(t, []string{".", "a.go", "c.go"}, listDir(t, dir))
+	for _, path := range []string{"a.go", "c.go"} {
+		content, _ := ioutil.ReadFile(filepath.Join(dir, path))
+		assert.Equal(t, "package old\n", string(content), path)
+	}
+}
@@ -0,0 +1,108 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	generated "github.com/recolabs/microgen/examples/generated"
+	"github.com/recolabs/microgen/examples/generated/transport"
+	transportjsonrpc "github.com/recolabs/microgen/examples/generated/transport/jsonrpc"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+var errEmptyText = errors.New("empty text")
+
+type stringService struct{}
+
+func (stringService) Uppercase(_ context.Context, stringsMap map[string]string) (string, error) {
+	return strings.ToUpper(stringsMap["text"]), nil
+}
+
+func (stringService) Count(_ context.Context, text string, symbol string) (int, []int, error) {
+	if text == "" {
+		return 0, nil, errEmptyText
+	}
+	var positions []int
+	for i := 0; i < len(text); i++ {
+		if strings.HasPrefix(text[i:], symbol) {
+			positions = append(positions, i)
+		}
+	}
+	return len(positions), positions, nil
+}
+
+func (stringService) TestCase(_ context.Context, comments []*generated.Comment) (map[string]int, error) {
+	tree := make(map[string]int)
+	for _, c := range comments {
+		tree[c.Text]++
+	}
+	return tree, nil
+}
+
+func (stringService) DummyMethod(_ context.Context) error { return nil }
+
+func (stringService) IgnoredMethod() {}
+
+func (stringService) IgnoredErrorMethod() error { return nil }
+
+func newJSONRPCClient(t *testing.T) (transport.EndpointsSet, func()) {
+	endpoints := transport.Endpoints(stringService{})
+	srv := httptest.NewServer(transportjsonrpc.NewJSONRPCHandler(&endpoints, propagation.TraceContext{}))
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		srv.Close()
+		t.Fatal(err)
+	}
+	return transportjsonrpc.NewJSONRPCClient(u, noBreaker), srv.Close
+}
+
+func TestJSONRPCRoundTrip(t *testing.T) {
+	client, closeFn := newJSONRPCClient(t)
+	defer closeFn()
+	ctx := context.Background()
+
+	ans, err := client.Uppercase(ctx, map[string]string{"text": "microgen"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ans != "MICROGEN" {
+		t.Errorf("Uppercase: want %q, got %q", "MICROGEN", ans)
+	}
+
+	count, positions, err := client.Count(ctx, "abcabc", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || len(positions) != 2 || positions[0] != 1 || positions[1] != 4 {
+		t.Errorf("Count: want 2 [1 4], got %d %v", count, positions)
+	}
+
+	tree, err := client.TestCase(ctx, []*generated.Comment{{Text: "a"}, {Text: "a"}, {Text: "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree["a"] != 2 || tree["b"] != 1 {
+		t.Errorf("TestCase: unexpected tree %v", tree)
+	}
+
+	if err := client.DummyMethod(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJSONRPCError(t *testing.T) {
+	client, closeFn := newJSONRPCClient(t)
+	defer closeFn()
+
+	_, _, err := client.Count(context.Background(), "", "a")
+	if err == nil {
+		t.Fatal("Count: want error, got nil")
+	}
+	if err.Error() != errEmptyText.Error() {
+		t.Errorf("Count: want error %q, got %q", errEmptyText, err)
+	}
+}
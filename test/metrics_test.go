@@ -0,0 +1,67 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/recolabs/microgen/examples/generated/service"
+)
+
+// methodCounter counts values per method label.
+type methodCounter struct {
+	method string
+	values map[string]float64
+}
+
+func newMethodCounter() *methodCounter {
+	return &methodCounter{values: make(map[string]float64)}
+}
+
+func (c *methodCounter) With(labelValues ...string) metrics.Counter {
+	return &methodCounter{method: labelValues[1], values: c.values}
+}
+
+func (c *methodCounter) Add(delta float64) { c.values[c.method] += delta }
+
+func (c *methodCounter) Observe(value float64) { c.values[c.method]++ }
+
+type methodHistogram struct{ *methodCounter }
+
+func (h methodHistogram) With(labelValues ...string) metrics.Histogram {
+	return methodHistogram{h.methodCounter.With(labelValues...).(*methodCounter)}
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	requestCount := newMethodCounter()
+	errorCount := newMethodCounter()
+	requestLatency := methodHistogram{newMethodCounter()}
+	svc := service.MetricsMiddleware(requestCount, errorCount, requestLatency)(stringService{})
+	ctx := context.Background()
+
+	if _, _, err := svc.Count(ctx, "abc", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := svc.Count(ctx, "", "b"); err == nil {
+		t.Fatal("Count: want error, got nil")
+	}
+	if err := svc.DummyMethod(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := requestCount.values["Count"]; v != 2 {
+		t.Errorf("Count request count: want 2, got %v", v)
+	}
+	if v := requestCount.values["DummyMethod"]; v != 1 {
+		t.Errorf("DummyMethod request count: want 1, got %v", v)
+	}
+	if v := errorCount.values["Count"]; v != 1 {
+		t.Errorf("Count error count: want 1, got %v", v)
+	}
+	if v := errorCount.values["DummyMethod"]; v != 0 {
+		t.Errorf("DummyMethod error count: want 0, got %v", v)
+	}
+	if v := requestLatency.values["Count"]; v != 2 {
+		t.Errorf("Count latency observations: want 2, got %v", v)
+	}
+}
@@ -0,0 +1,104 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/recolabs/microgen/examples/generated/service"
+	"github.com/recolabs/microgen/examples/generated/transport"
+	transportgrpc "github.com/recolabs/microgen/examples/generated/transport/grpc"
+	transporthttp "github.com/recolabs/microgen/examples/generated/transport/http"
+	pb "github.com/recolabs/microgen/examples/protobuf"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Count of example service is limited by `@rate-limit 20/s burst=2` with `@rate-limit-key text`.
+func countRateLimited(t *testing.T, err error) bool {
+	t.Helper()
+	if err == nil {
+		return false
+	}
+	var e *service.RateLimitError
+	if !errors.As(err, &e) {
+		t.Fatalf("want RateLimitError, got %v", err)
+	}
+	return true
+}
+
+func TestRateLimitBurstAndRefill(t *testing.T) {
+	svc := service.RateLimitingMiddleware()(stringService{})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := svc.Count(ctx, "abc", "b"); countRateLimited(t, err) {
+			t.Fatalf("call %d of burst is rejected", i+1)
+		}
+	}
+	if _, _, err := svc.Count(ctx, "abc", "b"); !countRateLimited(t, err) {
+		t.Fatal("call after burst is allowed")
+	}
+	if _, _, err := svc.Count(ctx, "xyz", "y"); countRateLimited(t, err) {
+		t.Fatal("call with other key is rejected")
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := svc.Uppercase(ctx, map[string]string{"text": "abc"}); err != nil {
+			t.Fatalf("method without @rate-limit: %v", err)
+		}
+	}
+
+	// Bucket is refilled with one token every 50ms.
+	time.Sleep(60 * time.Millisecond)
+	if _, _, err := svc.Count(ctx, "abc", "b"); countRateLimited(t, err) {
+		t.Fatal("call after refill is rejected")
+	}
+	if _, _, err := svc.Count(ctx, "abc", "b"); !countRateLimited(t, err) {
+		t.Fatal("the second call after refill of one token is allowed")
+	}
+}
+
+func TestRateLimitHTTPStatus(t *testing.T) {
+	endpoints := transport.Endpoints(service.RateLimitingMiddleware()(stringService{}))
+	srv := httptest.NewServer(transporthttp.NewHTTPHandler(&endpoints, propagation.TraceContext{}))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := transporthttp.NewHTTPClient(u, noBreaker)
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := client.Count(context.Background(), "abc", "b"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, _, err = client.Count(context.Background(), "abc", "b")
+	var coder interface {
+		StatusCode() int
+	}
+	if !errors.As(err, &coder) || coder.StatusCode() != http.StatusTooManyRequests {
+		t.Fatalf("want error with status 429, got %v", err)
+	}
+}
+
+func TestRateLimitGRPCCode(t *testing.T) {
+	svc := service.RateLimitingMiddleware()(stringService{})
+	for i := 0; i < 2; i++ {
+		if _, _, err := svc.Count(context.Background(), "abc", "b"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	endpoints := transport.Endpoints(svc)
+	srv := transportgrpc.NewGRPCServer(&endpoints, propagation.TraceContext{})
+
+	_, err := srv.Count(context.Background(), &pb.CountRequest{Text: "abc", Symbol: "b"})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("want code ResourceExhausted, got %v", err)
+	}
+}
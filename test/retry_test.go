@@ -0,0 +1,191 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/go-kit/kit/endpoint"
+	generated "github.com/recolabs/microgen/examples/generated"
+	"github.com/recolabs/microgen/examples/generated/transport"
+	transporthttp "github.com/recolabs/microgen/examples/generated/transport/http"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// noBreaker passes calls of every method to endpoint.
+func noBreaker(string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint { return next }
+}
+
+// failingHandler responds with status to the first failures requests and passes next ones to handler.
+type failingHandler struct {
+	handler  http.Handler
+	status   int
+	failures int
+
+	mtx      sync.Mutex
+	requests int
+}
+
+func (h *failingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mtx.Lock()
+	h.requests++
+	fail := h.requests <= h.failures
+	h.mtx.Unlock()
+	if !fail {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(h.status)
+	json.NewEncoder(w).Encode(transport.Error{Message: http.StatusText(h.status)})
+}
+
+// notFoundService returns error of service from @errors tag.
+type notFoundService struct {
+	stringService
+}
+
+func (notFoundService) Uppercase(context.Context, map[string]string) (string, error) {
+	return "", generated.ErrNotFound
+}
+
+func newFailingHTTPClient(t *testing.T, svc generated.StringService, status, failures int, breaker func(string) endpoint.Middleware) (transport.EndpointsSet, *failingHandler, func()) {
+	endpoints := transport.Endpoints(svc)
+	h := &failingHandler{
+		handler:  transporthttp.NewHTTPHandler(&endpoints, propagation.TraceContext{}),
+		status:   status,
+		failures: failures,
+	}
+	srv := httptest.NewServer(h)
+	u, err := url.Parse(srv.URL + "/")
+	if err != nil {
+		srv.Close()
+		t.Fatal(err)
+	}
+	return transporthttp.NewHTTPClient(u, breaker), h, srv.Close
+}
+
+func TestHTTPClientRetriesUnavailable(t *testing.T) {
+	client, h, closeFn := newFailingHTTPClient(t, stringService{}, http.StatusServiceUnavailable, 2, noBreaker)
+	defer closeFn()
+
+	ans, err := client.Uppercase(context.Background(), map[string]string{"text": "retry"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ans != "RETRY" {
+		t.Errorf("Uppercase: want %q, got %q", "RETRY", ans)
+	}
+	if h.requests != 3 {
+		t.Errorf("want 3 requests, got %d", h.requests)
+	}
+}
+
+func TestHTTPClientDoesNotRetry(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		svc      generated.StringService
+		status   int
+		failures int
+		call     func(transport.EndpointsSet) error
+	}{
+		{
+			name: "error of service",
+			svc:  notFoundService{},
+			call: func(client transport.EndpointsSet) error {
+				_, err := client.Uppercase(context.Background(), map[string]string{"text": "retry"})
+				if !errors.Is(err, generated.ErrNotFound) {
+					t.Errorf("want ErrNotFound, got %v", err)
+				}
+				return err
+			},
+		},
+		{
+			name:     "error of request",
+			svc:      stringService{},
+			status:   http.StatusBadRequest,
+			failures: 3,
+			call: func(client transport.EndpointsSet) error {
+				_, err := client.Uppercase(context.Background(), map[string]string{"text": "retry"})
+				return err
+			},
+		},
+		{
+			name:     "method without @retry",
+			svc:      stringService{},
+			status:   http.StatusServiceUnavailable,
+			failures: 3,
+			call: func(client transport.EndpointsSet) error {
+				_, _, err := client.Count(context.Background(), "abc", "b")
+				return err
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			client, h, closeFn := newFailingHTTPClient(t, tc.svc, tc.status, tc.failures, noBreaker)
+			defer closeFn()
+			if err := tc.call(client); err == nil {
+				t.Fatal("want error")
+			}
+			if h.requests != 1 {
+				t.Errorf("want 1 request, got %d", h.requests)
+			}
+		})
+	}
+}
+
+var errBreakerOpen = errors.New("breaker is open")
+
+// openingBreaker opens after the first failed call of method and records names of methods.
+type openingBreaker struct {
+	mtx     sync.Mutex
+	methods []string
+}
+
+func (b *openingBreaker) breaker(method string) endpoint.Middleware {
+	b.mtx.Lock()
+	b.methods = append(b.methods, method)
+	b.mtx.Unlock()
+	var open bool
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			if open {
+				return nil, errBreakerOpen
+			}
+			response, err := next(ctx, request)
+			open = err != nil
+			return response, err
+		}
+	}
+}
+
+func TestHTTPClientCircuitBreaker(t *testing.T) {
+	b := &openingBreaker{}
+	client, h, closeFn := newFailingHTTPClient(t, stringService{}, http.StatusServiceUnavailable, 1, b.breaker)
+	defer closeFn()
+
+	if _, err := client.Uppercase(context.Background(), map[string]string{"text": "breaker"}); !errors.Is(err, errBreakerOpen) {
+		t.Fatalf("want open breaker, got %v", err)
+	}
+	// Open breaker is not retried, so the only request is the first failed one.
+	if h.requests != 1 {
+		t.Errorf("want 1 request, got %d", h.requests)
+	}
+	if _, _, err := client.Count(context.Background(), "abc", "b"); err != nil {
+		t.Errorf("Count: breaker of other method should be closed, got %v", err)
+	}
+
+	var uppercase bool
+	for _, method := range b.methods {
+		uppercase = uppercase || method == "StringService.Uppercase"
+	}
+	if !uppercase {
+		t.Errorf("want breaker of StringService.Uppercase, got %v", b.methods)
+	}
+}
@@ -0,0 +1,178 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd/lb"
+	generated "github.com/recolabs/microgen/examples/generated"
+	"github.com/recolabs/microgen/examples/generated/transport"
+	transporthttp "github.com/recolabs/microgen/examples/generated/transport/http"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// countingService counts calls of Uppercase.
+type countingService struct {
+	stringService
+	mtx   sync.Mutex
+	calls int
+}
+
+func (s *countingService) Uppercase(ctx context.Context, stringsMap map[string]string) (string, error) {
+	s.mtx.Lock()
+	s.calls++
+	s.mtx.Unlock()
+	return s.stringService.Uppercase(ctx, stringsMap)
+}
+
+// discovered calls fn until endpointer of method receives instances, which it does asynchronously.
+func discovered(fn func() error) error {
+	deadline := time.Now().Add(time.Second)
+	for {
+		err := fn()
+		if err == nil || err.Error() != lb.ErrNoEndpoints.Error() || time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHTTPClientSDRoundRobin(t *testing.T) {
+	var services []*countingService
+	var instances []string
+	for i := 0; i < 2; i++ {
+		svc := &countingService{}
+		endpoints := transport.Endpoints(svc)
+		srv := httptest.NewServer(transporthttp.NewHTTPHandler(&endpoints, propagation.TraceContext{}))
+		defer srv.Close()
+		services = append(services, svc)
+		// Instance without scheme, like addresses of consul or DNS SRV.
+		instances = append(instances, strings.TrimPrefix(srv.URL, "http://"))
+	}
+
+	client, closer := transporthttp.NewHTTPClientSD(transport.StaticInstancer(instances...), log.NewNopLogger(), 1, time.Second, noBreaker)
+	defer closer.Close()
+	for i := 0; i < 4; i++ {
+		var ans string
+		err := discovered(func() (err error) {
+			ans, err = client.Uppercase(context.Background(), map[string]string{"text": "sd"})
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ans != "SD" {
+			t.Errorf("Uppercase: want %q, got %q", "SD", ans)
+		}
+	}
+	for i, svc := range services {
+		if svc.calls != 2 {
+			t.Errorf("instance %d: want 2 calls, got %d", i, svc.calls)
+		}
+	}
+}
+
+// instanceCloser counts closes of endpoints of instance.
+type instanceCloser struct {
+	closes *int
+}
+
+func (c instanceCloser) Close() error {
+	*c.closes++
+	return nil
+}
+
+func TestBalancedEndpointsSharesInstance(t *testing.T) {
+	var mtx sync.Mutex
+	created := make(map[string]int)
+	closed := make(map[string]*int)
+	factory := func(instance string) (transport.EndpointsSet, io.Closer, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		created[instance]++
+		closed[instance] = new(int)
+		return transport.Endpoints(stringService{}), instanceCloser{closes: closed[instance]}, nil
+	}
+
+	client, closer := transport.BalancedEndpoints(transport.StaticInstancer("a", "b"), factory, log.NewNopLogger(), 1, time.Second)
+	ctx := context.Background()
+	for _, call := range []func() error{
+		func() error { _, err := client.Uppercase(ctx, map[string]string{"text": "a"}); return err },
+		func() error { _, _, err := client.Count(ctx, "abc", "b"); return err },
+		func() error { _, err := client.TestCase(ctx, []*generated.Comment{{Text: "a"}}); return err },
+		func() error { return client.DummyMethod(ctx) },
+	} {
+		if err := discovered(call); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := closer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	mtx.Lock()
+	defer mtx.Unlock()
+	for _, instance := range []string{"a", "b"} {
+		if created[instance] != 1 {
+			t.Errorf("instance %s: want endpoints created once, got %d", instance, created[instance])
+		}
+		if closed[instance] == nil || *closed[instance] != 1 {
+			t.Errorf("instance %s: want endpoints closed once, got %v", instance, closed[instance])
+		}
+	}
+}
+
+var errDown = errors.New("instance is down")
+
+// downService fails calls of Uppercase and Count.
+type downService struct {
+	stringService
+}
+
+func (downService) Uppercase(context.Context, map[string]string) (string, error) {
+	return "", errDown
+}
+
+func (downService) Count(context.Context, string, string) (int, []int, error) {
+	return 0, nil, errDown
+}
+
+func TestBalancedEndpointsRetriesIdempotent(t *testing.T) {
+	factory := func(instance string) (transport.EndpointsSet, io.Closer, error) {
+		if instance == "down" {
+			return transport.Endpoints(downService{}), nil, nil
+		}
+		return transport.Endpoints(stringService{}), nil, nil
+	}
+	client, closer := transport.BalancedEndpoints(transport.StaticInstancer("down", "up"), factory, log.NewNopLogger(), 2, time.Second)
+	defer closer.Close()
+	ctx := context.Background()
+
+	// Uppercase is idempotent by @retry tag, so failed call is tried on next instance.
+	for i := 0; i < 4; i++ {
+		err := discovered(func() error { _, err := client.Uppercase(ctx, map[string]string{"text": "a"}); return err })
+		if err != nil {
+			t.Fatalf("Uppercase: %v", err)
+		}
+	}
+	// Count is called once, error of instance is returned as is.
+	var failed int
+	for i := 0; i < 4; i++ {
+		err := discovered(func() error { _, _, err := client.Count(ctx, "abc", "b"); return err })
+		if errors.Is(err, errDown) {
+			failed++
+		} else if err != nil {
+			t.Fatalf("Count: %v", err)
+		}
+	}
+	if failed != 2 {
+		t.Errorf("Count: want 2 failed calls of 4, got %d", failed)
+	}
+}
@@ -0,0 +1,290 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/recolabs/microgen/examples/stream/pb"
+	"github.com/recolabs/microgen/examples/stream/transport"
+	transporthttp "github.com/recolabs/microgen/examples/stream/transport/http"
+)
+
+// streamService sends letters of text, answers messages in upper case and sums numbers.
+type streamService struct {
+	// afterFirst is called after the first letter is sent, its error finishes stream.
+	afterFirst func(ctx context.Context) error
+}
+
+func (s *streamService) Count(text string, stream pb.StreamService_CountServer) error {
+	for i, r := range text {
+		if err := stream.Send(&pb.CountResponse{Letter: string(r)}); err != nil {
+			return err
+		}
+		if i == 0 && s.afterFirst != nil {
+			if err := s.afterFirst(stream.Context()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *streamService) Chat(stream pb.StreamService_ChatServer) error {
+	for {
+		m, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if m.Text == "fail" {
+			return errors.New("chat failed")
+		}
+		if err := stream.Send(&pb.ChatResponse{Text: strings.ToUpper(m.Text)}); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *streamService) Sum(stream pb.StreamService_SumServer) error {
+	var sum int64
+	for {
+		m, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&pb.SumResponse{Sum: sum})
+		}
+		if err != nil {
+			return err
+		}
+		sum += m.Number
+	}
+}
+
+func newStreamServer(t *testing.T, svc *streamService) (*httptest.Server, *transporthttp.HTTPStreamClient) {
+	endpoints := transport.Endpoints(svc)
+	srv := httptest.NewServer(transporthttp.NewHTTPHandler(&endpoints))
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, transporthttp.NewHTTPStreamClient(u, srv.Client(), nil)
+}
+
+// get returns content type and body of response to request of stream with accept header.
+func get(t *testing.T, u, accept string) (string, string) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", accept)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.Header.Get("Content-Type"), string(body)
+}
+
+// recvLetters receives letters until error of stream.
+func recvLetters(stream pb.StreamService_CountClient) (string, error) {
+	var letters string
+	for {
+		m, err := stream.Recv()
+		if err != nil {
+			return letters, err
+		}
+		letters += m.Letter
+	}
+}
+
+var errCount = errors.New("count failed")
+
+func TestHTTPStreamFraming(t *testing.T) {
+	failing := &streamService{afterFirst: func(context.Context) error { return errCount }}
+	for _, tc := range []struct {
+		name        string
+		svc         *streamService
+		accept      string
+		contentType string
+		body        string
+	}{
+		{
+			name:        "ndjson",
+			svc:         &streamService{},
+			accept:      "application/x-ndjson",
+			contentType: "application/x-ndjson",
+			body:        "{\"letter\":\"a\"}\n{\"letter\":\"b\"}\n",
+		},
+		{
+			name:        "sse",
+			svc:         &streamService{},
+			accept:      "text/event-stream",
+			contentType: "text/event-stream",
+			body:        "data: {\"letter\":\"a\"}\n\ndata: {\"letter\":\"b\"}\n\n",
+		},
+		{
+			name:        "sse error",
+			svc:         failing,
+			accept:      "text/event-stream",
+			contentType: "text/event-stream",
+			body:        "data: {\"letter\":\"a\"}\n\nevent: error\ndata: count failed\n\n",
+		},
+	} {
+		srv, _ := newStreamServer(t, tc.svc)
+		contentType, body := get(t, srv.URL+"/count?text=ab", tc.accept)
+		if contentType != tc.contentType {
+			t.Errorf("%s: want content type %q, got %q", tc.name, tc.contentType, contentType)
+		}
+		if body != tc.body {
+			t.Errorf("%s: want body %q, got %q", tc.name, tc.body, body)
+		}
+	}
+}
+
+func TestHTTPStreamClient(t *testing.T) {
+	_, client := newStreamServer(t, &streamService{})
+	stream, err := client.Count(context.Background(), "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	letters, err := recvLetters(stream)
+	if err != io.EOF || letters != "abc" {
+		t.Errorf("want abc and EOF, got %q and %v", letters, err)
+	}
+
+	// Error after the first message is sent in trailer.
+	_, client = newStreamServer(t, &streamService{afterFirst: func(context.Context) error { return errCount }})
+	stream, err = client.Count(context.Background(), "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	letters, err = recvLetters(stream)
+	if err == nil || err.Error() != errCount.Error() || letters != "a" {
+		t.Errorf("want a and %v, got %q and %v", errCount, letters, err)
+	}
+}
+
+func TestHTTPStreamFlush(t *testing.T) {
+	received := make(chan struct{})
+	_, client := newStreamServer(t, &streamService{afterFirst: func(context.Context) error {
+		select {
+		case <-received:
+			return nil
+		case <-time.After(time.Second):
+			return errors.New("the first letter is not flushed")
+		}
+	}})
+	stream, err := client.Count(context.Background(), "ab")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first letter is received, while server waits, so it must be flushed.
+	m, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	close(received)
+	letters, err := recvLetters(stream)
+	if err != io.EOF || m.Letter+letters != "ab" {
+		t.Errorf("want ab and EOF, got %q and %v", m.Letter+letters, err)
+	}
+}
+
+func TestHTTPStreamCancel(t *testing.T) {
+	closed := make(chan error, 1)
+	_, client := newStreamServer(t, &streamService{afterFirst: func(ctx context.Context) error {
+		<-ctx.Done()
+		closed <- ctx.Err()
+		return ctx.Err()
+	}})
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.Count(ctx, "ab")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	select {
+	case err := <-closed:
+		if err != context.Canceled {
+			t.Errorf("want context of server stream canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("server stream is not closed after cancel of client")
+	}
+	if _, err := stream.Recv(); err == nil {
+		t.Error("stream receives after cancel")
+	}
+}
+
+func TestWebSocketStream(t *testing.T) {
+	_, client := newStreamServer(t, &streamService{})
+	ctx := context.Background()
+
+	chat, err := client.Chat(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range []string{"a", "b"} {
+		if err := chat.Send(&pb.ChatRequest{Text: text}); err != nil {
+			t.Fatal(err)
+		}
+		m, err := chat.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Text != strings.ToUpper(text) {
+			t.Errorf("Chat: want %q, got %q", strings.ToUpper(text), m.Text)
+		}
+	}
+	if err := chat.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chat.Recv(); err != io.EOF {
+		t.Errorf("Chat: want EOF after close, got %v", err)
+	}
+
+	chat, err = client.Chat(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := chat.Send(&pb.ChatRequest{Text: "fail"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chat.Recv(); err == nil || err.Error() != "chat failed" {
+		t.Errorf("Chat: want error of service, got %v", err)
+	}
+
+	sum, err := client.Sum(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int64{1, 2, 3} {
+		if err := sum.Send(&pb.SumRequest{Number: n}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, err := sum.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Sum != 6 {
+		t.Errorf("Sum: want 6, got %d", m.Sum)
+	}
+}